@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"reflect"
 
@@ -12,6 +13,16 @@ const (
 	EActorNotFound
 )
 
+// EInvalidParams is the JSON-RPC 2.0 error code for invalid method parameters.
+const EInvalidParams = -32602
+
+// EMethodNotFound is the JSON-RPC 2.0 error code for methods which aren't available.
+const EMethodNotFound = -32601
+
+// ELimitExceeded is the Ethereum JSON-RPC error code for requests exceeding a
+// server side limit.
+const ELimitExceeded = -32005
+
 type ErrOutOfGas struct{}
 
 func (e *ErrOutOfGas) Error() string {
@@ -24,6 +35,60 @@ func (e *ErrActorNotFound) Error() string {
 	return "actor not found"
 }
 
+// ErrInvalidParams is returned when a method rejects its parameters; it is
+// reported to RPC clients with the EInvalidParams code.
+type ErrInvalidParams struct {
+	Message string
+}
+
+func (e *ErrInvalidParams) Error() string {
+	return e.Message
+}
+
+func (e *ErrInvalidParams) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Message)
+}
+
+func (e *ErrInvalidParams) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &e.Message)
+}
+
+// ErrMethodNotFound is returned when a method isn't served for the given
+// parameters; it is reported to RPC clients with the EMethodNotFound code.
+type ErrMethodNotFound struct {
+	Message string
+}
+
+func (e *ErrMethodNotFound) Error() string {
+	return e.Message
+}
+
+func (e *ErrMethodNotFound) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Message)
+}
+
+func (e *ErrMethodNotFound) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &e.Message)
+}
+
+// ErrLimitExceeded is returned when a request exceeds a limit of the node; it
+// is reported to RPC clients with the ELimitExceeded code.
+type ErrLimitExceeded struct {
+	Message string
+}
+
+func (e *ErrLimitExceeded) Error() string {
+	return e.Message
+}
+
+func (e *ErrLimitExceeded) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Message)
+}
+
+func (e *ErrLimitExceeded) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &e.Message)
+}
+
 var RPCErrors = jsonrpc.NewErrors()
 
 func ErrorIsIn(err error, errorTypes []error) bool {
@@ -39,4 +104,7 @@ func ErrorIsIn(err error, errorTypes []error) bool {
 func init() {
 	RPCErrors.Register(EOutOfGas, new(*ErrOutOfGas))
 	RPCErrors.Register(EActorNotFound, new(*ErrActorNotFound))
+	RPCErrors.Register(EInvalidParams, new(*ErrInvalidParams))
+	RPCErrors.Register(EMethodNotFound, new(*ErrMethodNotFound))
+	RPCErrors.Register(ELimitExceeded, new(*ErrLimitExceeded))
 }
@@ -787,8 +787,15 @@ type FullNode interface {
 	// MethodGroup: Eth
 	// These methods are used for Ethereum-compatible JSON-RPC calls
 	//
-	// EthAccounts will always return [] since we don't expect Lotus to manage private keys
+	// EthAccounts returns the Ethereum (f4) addresses in the node wallet when Fevm.EnableEthWalletMethods is
+	// set and the caller has admin permission, otherwise it always returns []
 	EthAccounts(ctx context.Context) ([]ethtypes.EthAddress, error) //perm:read
+	// EthSign signs data with an Ethereum (f4) address in the node wallet, prefixed as described by EIP-191.
+	// Requires Fevm.EnableEthWalletMethods
+	EthSign(ctx context.Context, sender ethtypes.EthAddress, data ethtypes.EthBytes) (ethtypes.EthBytes, error) //perm:admin
+	// EthSignTypedData signs EIP-712 typed data with an Ethereum (f4) address in the node wallet, see
+	// eth_signTypedData_v4. Requires Fevm.EnableEthWalletMethods
+	EthSignTypedData(ctx context.Context, sender ethtypes.EthAddress, data ethtypes.EthTypedData) (ethtypes.EthBytes, error) //perm:admin
 	// EthAddressToFilecoinAddress converts an EthAddress into an f410 Filecoin Address
 	EthAddressToFilecoinAddress(ctx context.Context, ethAddress ethtypes.EthAddress) (address.Address, error) //perm:read
 	// FilecoinAddressToEthAddress converts an f410 or f0 Filecoin Address to an EthAddress
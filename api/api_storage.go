@@ -121,6 +121,10 @@ type StorageMiner interface {
 	SectorPreCommitFlush(ctx context.Context) ([]sealiface.PreCommitBatchRes, error) //perm:admin
 	// SectorPreCommitPending returns a list of pending PreCommit sectors to be sent in the next batch message
 	SectorPreCommitPending(ctx context.Context) ([]abi.SectorID, error) //perm:admin
+	// SectorPreCommitCollateral returns the pre-commit deposit last computed for the sector, including the
+	// Sealing.PledgeCollateralBuffer safety margin. This is a debugging aid; the values are kept in memory
+	// until the sector reaches the Proving state or the miner restarts.
+	SectorPreCommitCollateral(ctx context.Context, sid abi.SectorNumber) (PreCommitCollateral, error) //perm:read
 	// SectorCommitFlush immediately sends a Commit message with sectors aggregated for Commit.
 	// Returns null if message wasn't sent
 	SectorCommitFlush(ctx context.Context) ([]sealiface.CommitBatchRes, error) //perm:admin
@@ -441,6 +445,9 @@ type AddressConfig struct {
 	CommitControl      []address.Address
 	TerminateControl   []address.Address
 	DealPublishControl []address.Address
+	// DealPublishWorker, when set, is the only address deal publish messages
+	// are sent from
+	DealPublishWorker address.Address
 
 	DisableOwnerFallback  bool
 	DisableWorkerFallback bool
@@ -512,6 +519,16 @@ type DagstoreInitializeAllEvent struct {
 	Current int
 }
 
+// PreCommitCollateral is the pre-commit deposit computed for a sector.
+type PreCommitCollateral struct {
+	// Estimated is the deposit required by the chain when the pre-commit was prepared
+	Estimated abi.TokenAmount
+	// Buffer is the Sealing.PledgeCollateralBuffer added on top of the estimate
+	Buffer abi.TokenAmount
+	// Effective is the deposit attached to the pre-commit message
+	Effective abi.TokenAmount
+}
+
 type NumAssignerMeta struct {
 	Reserved  bitfield.BitField
 	Allocated bitfield.BitField
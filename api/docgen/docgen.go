@@ -403,6 +403,16 @@ func init() {
 		Address:   []ethtypes.EthAddress{ethaddr},
 	})
 
+	addExample(ethtypes.EthTypedData{
+		Types: map[string][]ethtypes.EthTypedDataField{
+			"EIP712Domain": {{Name: "name", Type: "string"}, {Name: "chainId", Type: "uint256"}},
+			"Mail":         {{Name: "contents", Type: "string"}},
+		},
+		PrimaryType: "Mail",
+		Domain:      map[string]interface{}{"name": "Ether Mail", "chainId": 314},
+		Message:     map[string]interface{}{"contents": "Hello, Bob!"},
+	})
+
 	percent := types.Percent(123)
 	addExample(percent)
 	addExample(&percent)
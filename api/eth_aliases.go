@@ -5,6 +5,8 @@ import apitypes "github.com/filecoin-project/lotus/api/types"
 func CreateEthRPCAliases(as apitypes.Aliaser) {
 	// TODO: maybe use reflect to automatically register all the eth aliases
 	as.AliasMethod("eth_accounts", "Filecoin.EthAccounts")
+	as.AliasMethod("eth_sign", "Filecoin.EthSign")
+	as.AliasMethod("eth_signTypedData_v4", "Filecoin.EthSignTypedData")
 	as.AliasMethod("eth_blockNumber", "Filecoin.EthBlockNumber")
 	as.AliasMethod("eth_getBlockTransactionCountByNumber", "Filecoin.EthGetBlockTransactionCountByNumber")
 	as.AliasMethod("eth_getBlockTransactionCountByHash", "Filecoin.EthGetBlockTransactionCountByHash")
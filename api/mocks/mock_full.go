@@ -1461,6 +1461,36 @@ func (mr *MockFullNodeMockRecorder) EthSendRawTransaction(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthSendRawTransaction", reflect.TypeOf((*MockFullNode)(nil).EthSendRawTransaction), arg0, arg1)
 }
 
+// EthSign mocks base method.
+func (m *MockFullNode) EthSign(arg0 context.Context, arg1 ethtypes.EthAddress, arg2 ethtypes.EthBytes) (ethtypes.EthBytes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthSign", arg0, arg1, arg2)
+	ret0, _ := ret[0].(ethtypes.EthBytes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthSign indicates an expected call of EthSign.
+func (mr *MockFullNodeMockRecorder) EthSign(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthSign", reflect.TypeOf((*MockFullNode)(nil).EthSign), arg0, arg1, arg2)
+}
+
+// EthSignTypedData mocks base method.
+func (m *MockFullNode) EthSignTypedData(arg0 context.Context, arg1 ethtypes.EthAddress, arg2 ethtypes.EthTypedData) (ethtypes.EthBytes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthSignTypedData", arg0, arg1, arg2)
+	ret0, _ := ret[0].(ethtypes.EthBytes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthSignTypedData indicates an expected call of EthSignTypedData.
+func (mr *MockFullNodeMockRecorder) EthSignTypedData(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthSignTypedData", reflect.TypeOf((*MockFullNode)(nil).EthSignTypedData), arg0, arg1, arg2)
+}
+
 // EthSubscribe mocks base method.
 func (m *MockFullNode) EthSubscribe(arg0 context.Context, arg1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
 	m.ctrl.T.Helper()
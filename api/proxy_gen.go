@@ -311,6 +311,10 @@ type FullNodeMethods struct {
 
 	EthSendRawTransaction func(p0 context.Context, p1 ethtypes.EthBytes) (ethtypes.EthHash, error) `perm:"read"`
 
+	EthSign func(p0 context.Context, p1 ethtypes.EthAddress, p2 ethtypes.EthBytes) (ethtypes.EthBytes, error) `perm:"admin"`
+
+	EthSignTypedData func(p0 context.Context, p1 ethtypes.EthAddress, p2 ethtypes.EthTypedData) (ethtypes.EthBytes, error) `perm:"admin"`
+
 	EthSubscribe func(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) `perm:"read"`
 
 	EthSyncing func(p0 context.Context) (ethtypes.EthSyncingResult, error) `perm:"read"`
@@ -1096,6 +1100,8 @@ type StorageMinerMethods struct {
 
 	SectorNumReserveCount func(p0 context.Context, p1 string, p2 uint64) (bitfield.BitField, error) `perm:"admin"`
 
+	SectorPreCommitCollateral func(p0 context.Context, p1 abi.SectorNumber) (PreCommitCollateral, error) `perm:"read"`
+
 	SectorPreCommitFlush func(p0 context.Context) ([]sealiface.PreCommitBatchRes, error) `perm:"admin"`
 
 	SectorPreCommitPending func(p0 context.Context) ([]abi.SectorID, error) `perm:"admin"`
@@ -2442,6 +2448,28 @@ func (s *FullNodeStub) EthSendRawTransaction(p0 context.Context, p1 ethtypes.Eth
 	return *new(ethtypes.EthHash), ErrNotSupported
 }
 
+func (s *FullNodeStruct) EthSign(p0 context.Context, p1 ethtypes.EthAddress, p2 ethtypes.EthBytes) (ethtypes.EthBytes, error) {
+	if s.Internal.EthSign == nil {
+		return *new(ethtypes.EthBytes), ErrNotSupported
+	}
+	return s.Internal.EthSign(p0, p1, p2)
+}
+
+func (s *FullNodeStub) EthSign(p0 context.Context, p1 ethtypes.EthAddress, p2 ethtypes.EthBytes) (ethtypes.EthBytes, error) {
+	return *new(ethtypes.EthBytes), ErrNotSupported
+}
+
+func (s *FullNodeStruct) EthSignTypedData(p0 context.Context, p1 ethtypes.EthAddress, p2 ethtypes.EthTypedData) (ethtypes.EthBytes, error) {
+	if s.Internal.EthSignTypedData == nil {
+		return *new(ethtypes.EthBytes), ErrNotSupported
+	}
+	return s.Internal.EthSignTypedData(p0, p1, p2)
+}
+
+func (s *FullNodeStub) EthSignTypedData(p0 context.Context, p1 ethtypes.EthAddress, p2 ethtypes.EthTypedData) (ethtypes.EthBytes, error) {
+	return *new(ethtypes.EthBytes), ErrNotSupported
+}
+
 func (s *FullNodeStruct) EthSubscribe(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
 	if s.Internal.EthSubscribe == nil {
 		return *new(ethtypes.EthSubscriptionID), ErrNotSupported
@@ -6523,6 +6551,17 @@ func (s *StorageMinerStub) SectorNumReserveCount(p0 context.Context, p1 string,
 	return *new(bitfield.BitField), ErrNotSupported
 }
 
+func (s *StorageMinerStruct) SectorPreCommitCollateral(p0 context.Context, p1 abi.SectorNumber) (PreCommitCollateral, error) {
+	if s.Internal.SectorPreCommitCollateral == nil {
+		return *new(PreCommitCollateral), ErrNotSupported
+	}
+	return s.Internal.SectorPreCommitCollateral(p0, p1)
+}
+
+func (s *StorageMinerStub) SectorPreCommitCollateral(p0 context.Context, p1 abi.SectorNumber) (PreCommitCollateral, error) {
+	return *new(PreCommitCollateral), ErrNotSupported
+}
+
 func (s *StorageMinerStruct) SectorPreCommitFlush(p0 context.Context) ([]sealiface.PreCommitBatchRes, error) {
 	if s.Internal.SectorPreCommitFlush == nil {
 		return *new([]sealiface.PreCommitBatchRes), ErrNotSupported
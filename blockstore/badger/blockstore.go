@@ -56,6 +56,12 @@ type Options struct {
 
 	// Prefix is an optional prefix to prepend to keys. Default: "".
 	Prefix string
+
+	// CompressValues enables zstd compression of DAG-CBOR blocks on write.
+	// Compressed blocks are always decompressed on read, whether or not this
+	// is set. Stores holding compressed blocks can't be read by releases or
+	// tools which don't know about compression.
+	CompressValues bool
 }
 
 func DefaultOptions(path string) Options {
@@ -609,7 +615,13 @@ func (b *Blockstore) View(ctx context.Context, cid cid.Cid, fn func([]byte) erro
 	return b.db.View(func(txn *badger.Txn) error {
 		switch item, err := txn.Get(k); err {
 		case nil:
-			return item.Value(fn)
+			return item.Value(func(val []byte) error {
+				data, err := decompressValue(cid, val)
+				if err != nil {
+					return err
+				}
+				return fn(data)
+			})
 		case badger.ErrKeyNotFound:
 			return ipld.ErrNotFound{Cid: cid}
 		default:
@@ -683,8 +695,14 @@ func (b *Blockstore) Get(ctx context.Context, cid cid.Cid) (blocks.Block, error)
 	err := b.db.View(func(txn *badger.Txn) error {
 		switch item, err := txn.Get(k); err {
 		case nil:
-			val, err = item.ValueCopy(nil)
-			return err
+			return item.Value(func(v []byte) error {
+				if isCompressed(cid, v) {
+					val, err = decompressValue(cid, v)
+					return err
+				}
+				val = append([]byte(nil), v...)
+				return nil
+			})
 		case badger.ErrKeyNotFound:
 			return ipld.ErrNotFound{Cid: cid}
 		default:
@@ -697,7 +715,10 @@ func (b *Blockstore) Get(ctx context.Context, cid cid.Cid) (blocks.Block, error)
 	return blocks.NewBlockWithCid(val, cid)
 }
 
-// GetSize implements Blockstore.GetSize.
+// GetSize implements Blockstore.GetSize. The size of a DAG-CBOR block, which
+// may be stored compressed, is read from the zstd frame header, so unlike for
+// other blocks the stored value has to be read (from the value log for large
+// values); only small frames without a recorded content size are decompressed.
 func (b *Blockstore) GetSize(ctx context.Context, cid cid.Cid) (int, error) {
 	if err := b.access(); err != nil {
 		return 0, err
@@ -716,13 +737,19 @@ func (b *Blockstore) GetSize(ctx context.Context, cid cid.Cid) (int, error) {
 	err := b.db.View(func(txn *badger.Txn) error {
 		switch item, err := txn.Get(k); err {
 		case nil:
-			size = int(item.ValueSize())
+			if !compressible(cid) {
+				size = int(item.ValueSize())
+				return nil
+			}
+			return item.Value(func(val []byte) error {
+				size, err = decompressedSize(cid, val)
+				return err
+			})
 		case badger.ErrKeyNotFound:
 			return ipld.ErrNotFound{Cid: cid}
 		default:
 			return fmt.Errorf("failed to get block size from badger blockstore: %w", err)
 		}
-		return nil
 	})
 	if err != nil {
 		size = -1
@@ -745,6 +772,11 @@ func (b *Blockstore) Put(ctx context.Context, block blocks.Block) error {
 		defer KeyPool.Put(k)
 	}
 
+	val := block.RawData()
+	if b.opts.CompressValues {
+		val = compressValue(block.Cid(), val)
+	}
+
 	put := func(db *badger.DB) error {
 		// Check if we have it before writing it.
 		switch err := db.View(func(txn *badger.Txn) error {
@@ -761,7 +793,7 @@ func (b *Blockstore) Put(ctx context.Context, block blocks.Block) error {
 
 		// Then write it.
 		err := db.Update(func(txn *badger.Txn) error {
-			return txn.Set(k, block.RawData())
+			return txn.Set(k, val)
 		})
 		if err != nil {
 			return fmt.Errorf("failed to put block in badger blockstore: %w", err)
@@ -842,7 +874,11 @@ func (b *Blockstore) PutMany(ctx context.Context, blocks []blocks.Block) error {
 				// skipped because we already have it.
 				continue
 			}
-			if err := batch.Set(k, block.RawData()); err != nil {
+			val := block.RawData()
+			if b.opts.CompressValues {
+				val = compressValue(block.Cid(), val)
+			}
+			if err := batch.Set(k, val); err != nil {
 				return err
 			}
 		}
@@ -10,8 +10,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/dgraph-io/badger/v2"
 	blocks "github.com/ipfs/go-block-format"
 	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 
@@ -36,6 +38,111 @@ func TestBadgerBlockstore(t *testing.T) {
 		NewBlockstore:  newBlockstore(prefixed),
 		OpenBlockstore: openBlockstore(prefixed),
 	}).RunTests(t, "prefixed")
+
+	compressed := func(path string) Options {
+		opts := DefaultOptions(path)
+		opts.CompressValues = true
+		return opts
+	}
+
+	(&Suite{
+		NewBlockstore:  newBlockstore(compressed),
+		OpenBlockstore: openBlockstore(compressed),
+	}).RunTests(t, "compressed")
+}
+
+// cborBlock returns a DAG-CBOR block holding a byte string of n repetitive bytes.
+func cborBlock(tb testing.TB, n int) blocks.Block {
+	data := append([]byte{0x59, byte(n >> 8), byte(n)}, bytes.Repeat([]byte("state"), n/5+1)[:n]...)
+	c, err := cid.V1Builder{Codec: cid.DagCBOR, MhType: multihash.BLAKE2B_MIN + 31}.Sum(data)
+	require.NoError(tb, err)
+	blk, err := blocks.NewBlockWithCid(data, c)
+	require.NoError(tb, err)
+	return blk
+}
+
+func storedValue(t *testing.T, bs *Blockstore, c cid.Cid) []byte {
+	var val []byte
+	err := bs.DB().View(func(txn *badger.Txn) error {
+		item, err := txn.Get(bs.StorageKey(nil, c))
+		if err != nil {
+			return err
+		}
+		val, err = item.ValueCopy(nil)
+		return err
+	})
+	require.NoError(t, err)
+	return val
+}
+
+func TestCompressedValues(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir()
+
+	opts := DefaultOptions(path)
+	opts.CompressValues = true
+	bs, err := Open(opts)
+	require.NoError(t, err)
+
+	large := cborBlock(t, 4096)
+	small := cborBlock(t, 16)
+	raw := blocks.NewBlock(bytes.Repeat([]byte("raw"), 1000))
+	require.NoError(t, bs.Put(ctx, large))
+	require.NoError(t, bs.PutMany(ctx, []blocks.Block{small, raw}))
+
+	// only large DAG-CBOR blocks are compressed
+	require.Less(t, len(storedValue(t, bs, large.Cid())), len(large.RawData()))
+	require.Equal(t, small.RawData(), storedValue(t, bs, small.Cid()))
+	require.Equal(t, raw.RawData(), storedValue(t, bs, raw.Cid()))
+
+	check := func(bs *Blockstore) {
+		for _, blk := range []blocks.Block{large, small, raw} {
+			got, err := bs.Get(ctx, blk.Cid())
+			require.NoError(t, err)
+			require.Equal(t, blk.RawData(), got.RawData())
+
+			size, err := bs.GetSize(ctx, blk.Cid())
+			require.NoError(t, err)
+			require.Equal(t, len(blk.RawData()), size)
+
+			err = bs.View(ctx, blk.Cid(), func(data []byte) error {
+				require.Equal(t, blk.RawData(), data)
+				return nil
+			})
+			require.NoError(t, err)
+		}
+	}
+	check(bs)
+	require.NoError(t, bs.Close())
+
+	// compressed values stay readable once compression is disabled
+	bs, err = Open(DefaultOptions(path))
+	require.NoError(t, err)
+	defer bs.Close() //nolint:errcheck
+	check(bs)
+}
+
+func BenchmarkView(b *testing.B) {
+	for _, compress := range []bool{false, true} {
+		b.Run(fmt.Sprintf("compress=%t", compress), func(b *testing.B) {
+			ctx := context.Background()
+			opts := DefaultOptions(b.TempDir())
+			opts.CompressValues = compress
+			bs, err := Open(opts)
+			require.NoError(b, err)
+			defer bs.Close() //nolint:errcheck
+
+			blk := cborBlock(b, 2048)
+			require.NoError(b, bs.Put(ctx, blk))
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := bs.View(ctx, blk.Cid(), func([]byte) error { return nil }); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
 }
 
 func TestStorageKey(t *testing.T) {
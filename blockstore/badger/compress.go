@@ -0,0 +1,101 @@
+package badgerbs
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Values are compressed with zstd and stored as a plain zstd frame. Only
+// DAG-CBOR blocks are ever compressed: a DAG-CBOR block is a single CBOR data
+// item, and the only such item starting with the first byte of the zstd magic
+// (0x28, the integer -9) is one byte long. Any longer DAG-CBOR value starting
+// with the magic is therefore unambiguously compressed, which lets stores mix
+// compressed and uncompressed values and read both regardless of whether
+// compression is currently enabled.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// compressMinSize is the size below which values are stored as-is; the frame
+// overhead outweighs any gain on such small blocks.
+const compressMinSize = 128
+
+var (
+	zstdOnce sync.Once
+	zstdEnc  *zstd.Encoder
+	zstdDec  *zstd.Decoder
+)
+
+func initZstd() {
+	zstdOnce.Do(func() {
+		var err error
+		zstdEnc, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			panic(fmt.Sprintf("creating zstd encoder: %s", err))
+		}
+		zstdDec, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+		if err != nil {
+			panic(fmt.Sprintf("creating zstd decoder: %s", err))
+		}
+	})
+}
+
+// compressValue returns the value to store for the block with the given CID,
+// which is the zstd compressed data if that is smaller, or data itself.
+func compressValue(c cid.Cid, data []byte) []byte {
+	if !compressible(c) || len(data) < compressMinSize {
+		return data
+	}
+
+	initZstd()
+	out := zstdEnc.EncodeAll(data, make([]byte, 0, len(data)))
+	if len(out) >= len(data) {
+		return data
+	}
+	return out
+}
+
+// compressible reports whether values stored under c may be compressed.
+func compressible(c cid.Cid) bool {
+	return c.Type() == cid.DagCBOR
+}
+
+func isCompressed(c cid.Cid, val []byte) bool {
+	return len(val) > len(zstdMagic) && bytes.HasPrefix(val, zstdMagic) && compressible(c)
+}
+
+// decompressValue returns the block data for a stored value, decompressing it
+// if needed. The returned slice aliases val when no decompression took place.
+func decompressValue(c cid.Cid, val []byte) ([]byte, error) {
+	if !isCompressed(c, val) {
+		return val, nil
+	}
+
+	initZstd()
+	out, err := zstdDec.DecodeAll(val, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress block %s: %w", c, err)
+	}
+	return out, nil
+}
+
+// decompressedSize returns the size of the block data for a stored value,
+// without decompressing it when the frame header records the content size.
+func decompressedSize(c cid.Cid, val []byte) (int, error) {
+	if !isCompressed(c, val) {
+		return len(val), nil
+	}
+
+	var h zstd.Header
+	if err := h.Decode(val); err == nil && h.HasFCS {
+		return int(h.FrameContentSize), nil
+	}
+
+	out, err := decompressValue(c, val)
+	if err != nil {
+		return 0, err
+	}
+	return len(out), nil
+}
@@ -0,0 +1,206 @@
+package blockstore
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+// CachedBlockstore is a blockstore keeping the most recently used blocks in
+// memory, in an LRU cache bounded by the total size of the cached block data.
+// Blocks are cached when they are read or written through the cache. Reads
+// (Has, Get, GetSize and View) are served from the cache; all other methods go
+// to the underlying blockstore. Blocks must only be deleted through the cache,
+// so it must not be layered above a blockstore which deletes blocks on its
+// own, like the splitstore.
+//
+// Create a new instance by calling the NewCachedBlockstore constructor.
+type CachedBlockstore struct {
+	Blockstore
+
+	name    string
+	maxSize uint64
+
+	lk      sync.Mutex
+	size    uint64
+	lru     *list.List
+	entries map[cid.Cid]*list.Element
+
+	hits, misses, adds, evictions, costAdded, costEvicted atomic.Int64
+}
+
+var _ Blockstore = (*CachedBlockstore)(nil)
+
+// NewCachedBlockstore wraps bs in a cache holding up to maxSize bytes of block
+// data. The name is used to tag the metrics of the cache.
+func NewCachedBlockstore(bs Blockstore, name string, maxSize uint64) *CachedBlockstore {
+	return &CachedBlockstore{
+		Blockstore: bs,
+		name:       name,
+		maxSize:    maxSize,
+		lru:        list.New(),
+		entries:    map[cid.Cid]*list.Element{},
+	}
+}
+
+func (c *CachedBlockstore) get(k cid.Cid) (blocks.Block, bool) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	e, ok := c.entries[k]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	c.lru.MoveToFront(e)
+	return e.Value.(blocks.Block), true
+}
+
+func (c *CachedBlockstore) add(blk blocks.Block) {
+	size := uint64(len(blk.RawData()))
+	if size > c.maxSize {
+		return
+	}
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	if e, ok := c.entries[blk.Cid()]; ok {
+		c.lru.MoveToFront(e)
+		return
+	}
+
+	for c.size+size > c.maxSize {
+		c.removeElement(c.lru.Back(), true)
+	}
+
+	c.entries[blk.Cid()] = c.lru.PushFront(blk)
+	c.size += size
+	c.adds.Add(1)
+	c.costAdded.Add(int64(size))
+}
+
+func (c *CachedBlockstore) remove(k cid.Cid) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	if e, ok := c.entries[k]; ok {
+		c.removeElement(e, false)
+	}
+}
+
+func (c *CachedBlockstore) removeElement(e *list.Element, evicted bool) {
+	blk := c.lru.Remove(e).(blocks.Block)
+	delete(c.entries, blk.Cid())
+
+	size := uint64(len(blk.RawData()))
+	c.size -= size
+	if evicted {
+		c.evictions.Add(1)
+		c.costEvicted.Add(int64(size))
+	}
+}
+
+func (c *CachedBlockstore) Get(ctx context.Context, k cid.Cid) (blocks.Block, error) {
+	if blk, ok := c.get(k); ok {
+		return blk, nil
+	}
+
+	blk, err := c.Blockstore.Get(ctx, k)
+	if err != nil {
+		return nil, err
+	}
+	c.add(blk)
+	return blk, nil
+}
+
+func (c *CachedBlockstore) Has(ctx context.Context, k cid.Cid) (bool, error) {
+	if _, ok := c.get(k); ok {
+		return true, nil
+	}
+	return c.Blockstore.Has(ctx, k)
+}
+
+func (c *CachedBlockstore) GetSize(ctx context.Context, k cid.Cid) (int, error) {
+	if blk, ok := c.get(k); ok {
+		return len(blk.RawData()), nil
+	}
+	return c.Blockstore.GetSize(ctx, k)
+}
+
+func (c *CachedBlockstore) View(ctx context.Context, k cid.Cid, callback func([]byte) error) error {
+	if blk, ok := c.get(k); ok {
+		return callback(blk.RawData())
+	}
+
+	// the callback must not retain the data, so copy it into the cache
+	return c.Blockstore.View(ctx, k, func(data []byte) error {
+		if blk, err := blocks.NewBlockWithCid(append([]byte(nil), data...), k); err == nil {
+			c.add(blk)
+		}
+		return callback(data)
+	})
+}
+
+func (c *CachedBlockstore) Put(ctx context.Context, blk blocks.Block) error {
+	if err := c.Blockstore.Put(ctx, blk); err != nil {
+		return err
+	}
+	c.add(blk)
+	return nil
+}
+
+func (c *CachedBlockstore) PutMany(ctx context.Context, blks []blocks.Block) error {
+	if err := c.Blockstore.PutMany(ctx, blks); err != nil {
+		return err
+	}
+	for _, blk := range blks {
+		c.add(blk)
+	}
+	return nil
+}
+
+func (c *CachedBlockstore) DeleteBlock(ctx context.Context, k cid.Cid) error {
+	c.remove(k)
+	return c.Blockstore.DeleteBlock(ctx, k)
+}
+
+func (c *CachedBlockstore) DeleteMany(ctx context.Context, ks []cid.Cid) error {
+	for _, k := range ks {
+		c.remove(k)
+	}
+	return c.Blockstore.DeleteMany(ctx, ks)
+}
+
+// EmitMetrics records the current metrics of the cache, tagged with its name.
+func (c *CachedBlockstore) EmitMetrics(ctx context.Context) {
+	c.lk.Lock()
+	entries := int64(len(c.entries))
+	c.lk.Unlock()
+
+	hits, misses := c.hits.Load(), c.misses.Load()
+	var ratio float64
+	if hits+misses > 0 {
+		ratio = float64(hits) / float64(hits+misses)
+	}
+
+	ctx, _ = tag.New(ctx, tag.Upsert(CacheName, c.name))
+	stats.Record(ctx,
+		CacheMeasures.HitRatio.M(ratio),
+		CacheMeasures.Hits.M(hits),
+		CacheMeasures.Misses.M(misses),
+		CacheMeasures.Entries.M(entries),
+		CacheMeasures.QueriesServed.M(hits+misses),
+		CacheMeasures.Adds.M(c.adds.Load()),
+		CacheMeasures.Evictions.M(c.evictions.Load()),
+		CacheMeasures.CostAdded.M(c.costAdded.Load()),
+		CacheMeasures.CostEvicted.M(c.costEvicted.Load()),
+	)
+}
@@ -0,0 +1,61 @@
+package blockstore
+
+import (
+	"context"
+	"testing"
+
+	blocks "github.com/ipfs/go-block-format"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedBlockstore(t *testing.T) {
+	ctx := context.Background()
+	under := NewMemory()
+	bs := NewCachedBlockstore(under, "test", 20)
+
+	a := blocks.NewBlock([]byte("aaaaaaaaaa"))
+	b := blocks.NewBlock([]byte("bbbbbbbbbb"))
+	c := blocks.NewBlock([]byte("cccccccccc"))
+	for _, blk := range []blocks.Block{a, b, c} {
+		require.NoError(t, under.Put(ctx, blk))
+	}
+
+	// misses populate the cache
+	_, err := bs.Get(ctx, a.Cid())
+	require.NoError(t, err)
+	_, err = bs.Get(ctx, b.Cid())
+	require.NoError(t, err)
+	require.EqualValues(t, 2, bs.misses.Load())
+
+	// served from the cache, even once removed from the underlying store
+	require.NoError(t, under.DeleteBlock(ctx, a.Cid()))
+	got, err := bs.Get(ctx, a.Cid())
+	require.NoError(t, err)
+	require.Equal(t, a.RawData(), got.RawData())
+	require.EqualValues(t, 1, bs.hits.Load())
+	has, err := bs.Has(ctx, a.Cid())
+	require.NoError(t, err)
+	require.True(t, has)
+	require.EqualValues(t, 2, bs.hits.Load())
+
+	// the cache is full, so c evicts the least recently used block, b
+	err = bs.View(ctx, c.Cid(), func(data []byte) error {
+		require.Equal(t, c.RawData(), data)
+		return nil
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, bs.evictions.Load())
+	require.EqualValues(t, 20, bs.size)
+	require.NotContains(t, bs.entries, b.Cid())
+
+	// deletes go through the cache
+	require.NoError(t, bs.DeleteBlock(ctx, c.Cid()))
+	_, err = bs.Get(ctx, c.Cid())
+	require.True(t, ipld.IsNotFound(err))
+
+	// blocks larger than the cache aren't cached
+	big := blocks.NewBlock(make([]byte, 21))
+	require.NoError(t, bs.Put(ctx, big))
+	require.NotContains(t, bs.entries, big.Cid())
+}
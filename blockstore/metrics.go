@@ -9,9 +9,9 @@ import (
 )
 
 //
-// Currently unused, but kept in repo in case we introduce one of the candidate
-// cache implementations (Freecache, Ristretto), both of which report these
-// metrics.
+// Reported by CachedBlockstore; the remaining measures are kept in case we
+// introduce one of the candidate cache implementations (Freecache, Ristretto),
+// both of which report these metrics.
 //
 
 // CacheMetricsEmitInterval is the interval at which metrics are emitted onto
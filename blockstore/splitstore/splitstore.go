@@ -132,6 +132,17 @@ type Config struct {
 	// Moving GC will not occur when total moving size exceeds
 	// HotstoreMaxSpaceTarget - HotstoreMaxSpaceSafetyBuffer
 	HotstoreMaxSpaceSafetyBuffer uint64
+
+	// Moving GC will also be triggered when total moving size exceeds
+	// HotstoreMaxSpaceTarget * MovingGCThresholdRatio, if that is lower than
+	// HotstoreMaxSpaceTarget - HotstoreMaxSpaceThreshold. 0 disables the ratio.
+	MovingGCThresholdRatio float64
+
+	// GCLockTimeout bounds how long compaction may hold the transaction lock while protecting
+	// transactional references when entering the critical section. If it takes longer, the
+	// compaction is aborted before anything is deleted and retried on the next head change.
+	// A value of 0 disables the timeout.
+	GCLockTimeout time.Duration
 }
 
 // ChainAccessor allows the Splitstore to access the chain. It will most likely
@@ -63,6 +63,8 @@ var (
 var (
 	// used to signal end of walk
 	errStopWalk = errors.New("stop walk")
+	// used to signal that the transaction lock was held for longer than the GC lock timeout
+	errLockTimeout = errors.New("timed out holding the transaction lock")
 )
 
 const (
@@ -382,7 +384,21 @@ func (s *SplitStore) trackTxnRefMany(cids []cid.Cid) {
 
 // protect all pending transactional references
 func (s *SplitStore) protectTxnRefs(markSet MarkSet) error {
+	return s.protectTxnRefsUntil(markSet, time.Time{})
+}
+
+// protect all pending transactional references, giving up with errLockTimeout once the deadline
+// has passed; a zero deadline never expires.
+func (s *SplitStore) protectTxnRefsUntil(markSet MarkSet, deadline time.Time) error {
+	expired := func() bool {
+		return !deadline.IsZero() && time.Now().After(deadline)
+	}
+
 	for {
+		if expired() {
+			return errLockTimeout
+		}
+
 		var txnRefs map[cid.Cid]struct{}
 
 		s.txnRefsMx.Lock()
@@ -431,6 +447,10 @@ func (s *SplitStore) protectTxnRefs(markSet MarkSet) error {
 
 		worker := func() error {
 			for c := range workch {
+				if expired() {
+					return errLockTimeout
+				}
+
 				szTxn, err := s.doTxnProtect(c, markSet)
 				if err != nil {
 					return xerrors.Errorf("error protecting transactional references to %s: %w", c, err)
@@ -446,6 +466,9 @@ func (s *SplitStore) protectTxnRefs(markSet MarkSet) error {
 		}
 
 		if err := g.Wait(); err != nil {
+			if errors.Is(err, errLockTimeout) {
+				log.Warnw("protecting transactional refs timed out", "took", time.Since(startProtect), "refs", count)
+			}
 			return err
 		}
 		s.szProtectedTxns += atomic.LoadInt64(sz)
@@ -887,7 +910,18 @@ func (s *SplitStore) beginCriticalSection(markSet MarkSet) error {
 	// and do it again while holding the lock to mark references that might have been created
 	// in the meantime and avoid races of the type Has->txnRef->enterCS->Get fails because
 	// it's not in the markset
-	if err := s.protectTxnRefs(markSet); err != nil {
+	var deadline time.Time
+	if s.cfg.GCLockTimeout > 0 {
+		deadline = time.Now().Add(s.cfg.GCLockTimeout)
+	}
+	if err := s.protectTxnRefsUntil(markSet, deadline); err != nil {
+		if errors.Is(err, errLockTimeout) {
+			// nothing has been purged yet, so we can leave the critical section and let the
+			// next head change retry the compaction
+			s.txnMarkSet = nil
+			markSet.EndCriticalSection()
+			log.Warnw("aborting compaction; the transaction lock was held for too long", "timeout", s.cfg.GCLockTimeout, "protected size", s.szProtectedTxns)
+		}
 		return xerrors.Errorf("error protecting transactional references: %w", err)
 	}
 
@@ -16,7 +16,8 @@ func (s *SplitStore) gcHotAfterCompaction() {
 	// Measure hotstore size, determine if we should do full GC, determine if we can do full GC.
 	// We should do full GC if
 	//  FullGCFrequency is specified and compaction index matches frequency
-	//  OR HotstoreMaxSpaceTarget is specified and total moving space is within 150 GB of target
+	//  OR HotstoreMaxSpaceTarget is specified and total moving space is within 150 GB of target,
+	//     or above MovingGCThresholdRatio of the target
 	// We can do full if
 	//  HotstoreMaxSpaceTarget is not specified
 	//  OR total moving space would not exceed 50 GB below target
@@ -40,7 +41,7 @@ func (s *SplitStore) gcHotAfterCompaction() {
 	hotSize := getSize()
 
 	copySizeApprox := s.szKeys + s.szMarkedLiveRefs + s.szProtectedTxns + s.szWalk
-	shouldTarget := s.cfg.HotstoreMaxSpaceTarget > 0 && hotSize+copySizeApprox > int64(s.cfg.HotstoreMaxSpaceTarget)-int64(s.cfg.HotstoreMaxSpaceThreshold)
+	shouldTarget := s.cfg.HotstoreMaxSpaceTarget > 0 && hotSize+copySizeApprox > s.movingGCTrigger()
 	shouldFreq := s.cfg.HotStoreFullGCFrequency > 0 && s.compactionIndex%int64(s.cfg.HotStoreFullGCFrequency) == 0
 	shouldDoFull := shouldTarget || shouldFreq
 	canDoFull := s.cfg.HotstoreMaxSpaceTarget == 0 || hotSize+copySizeApprox < int64(s.cfg.HotstoreMaxSpaceTarget)-int64(s.cfg.HotstoreMaxSpaceSafetyBuffer)
@@ -64,6 +65,18 @@ func (s *SplitStore) gcHotAfterCompaction() {
 	log.Infof("measured hot store size after GC: %d", getSize())
 }
 
+// movingGCTrigger returns the approximate hotstore size after moving GC above which
+// moving GC is triggered when HotstoreMaxSpaceTarget is set.
+func (s *SplitStore) movingGCTrigger() int64 {
+	trigger := int64(s.cfg.HotstoreMaxSpaceTarget) - int64(s.cfg.HotstoreMaxSpaceThreshold)
+	if s.cfg.MovingGCThresholdRatio > 0 {
+		if byRatio := int64(float64(s.cfg.HotstoreMaxSpaceTarget) * s.cfg.MovingGCThresholdRatio); byRatio < trigger {
+			trigger = byRatio
+		}
+	}
+	return trigger
+}
+
 func (s *SplitStore) gcBlockstore(b bstore.Blockstore, opts []bstore.BlockstoreGCOption) error {
 	if err := s.checkYield(); err != nil {
 		return err
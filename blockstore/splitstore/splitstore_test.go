@@ -573,6 +573,58 @@ func testSplitStoreReificationLimit(t *testing.T, f func(context.Context, blocks
 
 }
 
+func TestMovingGCTrigger(t *testing.T) {
+	ss := &SplitStore{cfg: &Config{
+		HotstoreMaxSpaceTarget:    1000,
+		HotstoreMaxSpaceThreshold: 300,
+	}}
+
+	// the ratio only applies when it triggers moving GC earlier
+	for _, tc := range []struct {
+		ratio   float64
+		trigger int64
+	}{
+		{0, 700},
+		{0.9, 700},
+		{0.6, 600},
+	} {
+		ss.cfg.MovingGCThresholdRatio = tc.ratio
+		if trigger := ss.movingGCTrigger(); trigger != tc.trigger {
+			t.Fatalf("expected trigger %d with ratio %f, got %d", tc.trigger, tc.ratio, trigger)
+		}
+	}
+}
+
+func TestProtectTxnRefsTimeout(t *testing.T) {
+	env, err := NewMapMarkSetEnv(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	markSet, err := env.New("test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer markSet.Close() //nolint:errcheck
+
+	c := blocks.NewBlock([]byte("protected")).Cid()
+	if err := markSet.Mark(c); err != nil {
+		t.Fatal(err)
+	}
+
+	ss := &SplitStore{cfg: &Config{}}
+
+	ss.txnRefs = map[cid.Cid]struct{}{c: {}}
+	if err := ss.protectTxnRefsUntil(markSet, time.Now().Add(-time.Second)); !errors.Is(err, errLockTimeout) {
+		t.Fatalf("expected lock timeout, got %v", err)
+	}
+
+	// without a deadline, already marked references are protected
+	ss.txnRefs = map[cid.Cid]struct{}{c: {}}
+	if err := ss.protectTxnRefsUntil(markSet, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestSplitStoreReification(t *testing.T) {
 	t.Log("test reification with Has")
 	testSplitStoreReification(t, func(ctx context.Context, s blockstore.Blockstore, c cid.Cid) error {
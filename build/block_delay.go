@@ -0,0 +1,29 @@
+//go:build debug || 2k || testground || calibnet || butterflynet || interopnet
+// +build debug 2k testground calibnet butterflynet interopnet
+
+package build
+
+import (
+	"os"
+	"strconv"
+)
+
+// BlockDelayEnvVar overrides the block time, for private networks with custom
+// block times. It is read when the package is initialised, so that values
+// derived from the block time follow it, and it must be set to the same value
+// for every daemon and miner of the network.
+const BlockDelayEnvVar = "LOTUS_BLOCK_DELAY_SECS"
+
+func init() {
+	bds, found := os.LookupEnv(BlockDelayEnvVar)
+	if !found {
+		return
+	}
+
+	secs, err := strconv.ParseUint(bds, 10, 64)
+	if err != nil || secs == 0 {
+		log.Panicf("failed to parse %s env var: must be a positive number of seconds", BlockDelayEnvVar)
+	}
+	BlockDelaySecs = secs
+	log.Warnf("block time is set to %d seconds; all nodes and miners of the network must use the same block time", secs)
+}
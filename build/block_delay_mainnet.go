@@ -0,0 +1,16 @@
+//go:build !debug && !2k && !testground && !calibnet && !butterflynet && !interopnet
+// +build !debug,!2k,!testground,!calibnet,!butterflynet,!interopnet
+
+package build
+
+import "os"
+
+// BlockDelayEnvVar overrides the block time, for private networks with custom
+// block times. The mainnet block time can't be changed.
+const BlockDelayEnvVar = "LOTUS_BLOCK_DELAY_SECS"
+
+func init() {
+	if _, found := os.LookupEnv(BlockDelayEnvVar); found {
+		log.Panicf("%s env var is set, but the mainnet block time can't be changed", BlockDelayEnvVar)
+	}
+}
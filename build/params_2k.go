@@ -128,7 +128,7 @@ func init() {
 
 }
 
-const BlockDelaySecs = uint64(4)
+var BlockDelaySecs = uint64(4)
 
 const PropagationDelaySecs = uint64(1)
 
@@ -79,7 +79,7 @@ func init() {
 	BuildType = BuildButterflynet
 }
 
-const BlockDelaySecs = uint64(builtin2.EpochDurationSeconds)
+var BlockDelaySecs = uint64(builtin2.EpochDurationSeconds)
 
 const PropagationDelaySecs = uint64(6)
 
@@ -119,7 +119,7 @@ func init() {
 
 }
 
-const BlockDelaySecs = uint64(builtin2.EpochDurationSeconds)
+var BlockDelaySecs = uint64(builtin2.EpochDurationSeconds)
 
 var PropagationDelaySecs = uint64(10)
 
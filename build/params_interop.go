@@ -117,7 +117,7 @@ func init() {
 
 }
 
-const BlockDelaySecs = uint64(builtin2.EpochDurationSeconds)
+var BlockDelaySecs = uint64(builtin2.EpochDurationSeconds)
 
 const PropagationDelaySecs = uint64(6)
 
@@ -19,6 +19,16 @@ import (
 
 var zero = types.NewInt(0)
 
+// FlatWeight is an alternative to Weight for test networks, where every block
+// of a tipset adds 1 to the weight of the chain, regardless of network power
+// and win counts. All nodes of a network must use the same weight function.
+func FlatWeight(ctx context.Context, stateBs bstore.Blockstore, ts *types.TipSet) (types.BigInt, error) {
+	if ts == nil {
+		return types.NewInt(0), nil
+	}
+	return types.BigAdd(ts.ParentWeight(), types.NewInt(uint64(len(ts.Blocks())))), nil
+}
+
 func Weight(ctx context.Context, stateBs bstore.Blockstore, ts *types.TipSet) (types.BigInt, error) {
 	if ts == nil {
 		return types.NewInt(0), nil
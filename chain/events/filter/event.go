@@ -363,6 +363,12 @@ func (m *EventFilterManager) Revert(ctx context.Context, from, to *types.TipSet)
 }
 
 func (m *EventFilterManager) Install(ctx context.Context, minHeight, maxHeight abi.ChainEpoch, tipsetCid cid.Cid, addresses []address.Address, keys map[string][][]byte) (*EventFilter, error) {
+	return m.InstallWithMaxResults(ctx, m.MaxFilterResults, minHeight, maxHeight, tipsetCid, addresses, keys)
+}
+
+// InstallWithMaxResults installs a filter collecting at most maxResults events,
+// instead of MaxFilterResults. 0 is unlimited.
+func (m *EventFilterManager) InstallWithMaxResults(ctx context.Context, maxResults int, minHeight, maxHeight abi.ChainEpoch, tipsetCid cid.Cid, addresses []address.Address, keys map[string][][]byte) (*EventFilter, error) {
 	m.mu.Lock()
 	currentHeight := m.currentHeight
 	m.mu.Unlock()
@@ -383,7 +389,7 @@ func (m *EventFilterManager) Install(ctx context.Context, minHeight, maxHeight a
 		tipsetCid:  tipsetCid,
 		addresses:  addresses,
 		keys:       keys,
-		maxResults: m.MaxFilterResults,
+		maxResults: maxResults,
 	}
 
 	if m.EventIndex != nil && minHeight != -1 && minHeight < currentHeight {
@@ -33,10 +33,16 @@ type client struct {
 	host host.Host
 
 	peerTracker *bsPeerTracker
+
+	// peers rejected by the filter aren't queried; nil accepts all peers
+	peerFilter PeerFilter
 }
 
 var _ Client = (*client)(nil)
 
+// PeerFilter reports whether a peer may be queried for chain data.
+type PeerFilter func(peer.ID) bool
+
 // NewClient creates a new libp2p-based exchange.Client that uses the libp2p
 // ChainExhange protocol as the fetching mechanism.
 func NewClient(lc fx.Lifecycle, host host.Host, pmgr peermgr.MaybePeerMgr) Client {
@@ -46,6 +52,19 @@ func NewClient(lc fx.Lifecycle, host host.Host, pmgr peermgr.MaybePeerMgr) Clien
 	}
 }
 
+// NewClientWithPeerFilter returns a constructor for exchange clients which
+// only query the peers accepted by filter. Requests for a rejected peer are
+// sent to the other peers instead.
+func NewClientWithPeerFilter(filter PeerFilter) func(lc fx.Lifecycle, host host.Host, pmgr peermgr.MaybePeerMgr) Client {
+	return func(lc fx.Lifecycle, host host.Host, pmgr peermgr.MaybePeerMgr) Client {
+		return &client{
+			host:        host,
+			peerTracker: newPeerTracker(lc, host, pmgr.Mgr),
+			peerFilter:  filter,
+		}
+	}
+}
+
 // Main logic of the client request service. The provided `Request`
 // is sent to the `singlePeer` if one is indicated or to all available
 // ones otherwise. The response is processed and validated according
@@ -88,6 +107,10 @@ func (c *client) doRequest(
 	// `singlePeer` indicated or all peers available (sorted
 	// by an internal peer tracker with some randomness injected).
 	var peers []peer.ID
+	if singlePeer != nil && !c.acceptPeer(*singlePeer) {
+		log.Debugw("peer rejected by the peer filter, querying other peers", "peer", *singlePeer)
+		singlePeer = nil
+	}
 	if singlePeer != nil {
 		peers = []peer.ID{*singlePeer}
 	} else {
@@ -476,10 +499,23 @@ func (c *client) RemovePeer(p peer.ID) {
 // FIXME: Consider merging with `shufflePrefix()s`.
 func (c *client) getShuffledPeers() []peer.ID {
 	peers := c.peerTracker.prefSortedPeers()
+	if c.peerFilter != nil {
+		accepted := peers[:0]
+		for _, p := range peers {
+			if c.peerFilter(p) {
+				accepted = append(accepted, p)
+			}
+		}
+		peers = accepted
+	}
 	shufflePrefix(peers)
 	return peers
 }
 
+func (c *client) acceptPeer(p peer.ID) bool {
+	return c.peerFilter == nil || c.peerFilter(p)
+}
+
 func shufflePrefix(peers []peer.ID) {
 	prefix := ShufflePeersPrefix
 	if len(peers) < prefix {
@@ -0,0 +1,75 @@
+package messagepool
+
+import (
+	"context"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/lotus/build"
+)
+
+// RunEthExpiry starts a background loop removing pending messages sent from
+// delegated (Ethereum) addresses which have been in the pool for longer than
+// timeout. The loop stops when the pool is closed.
+func (mp *MessagePool) RunEthExpiry(timeout time.Duration) {
+	interval := time.Minute
+	if timeout < interval {
+		interval = timeout
+	}
+
+	go func() {
+		tk := build.Clock.Ticker(interval)
+		defer tk.Stop()
+
+		for {
+			select {
+			case <-tk.C:
+				mp.expireEthMessages(context.TODO(), timeout)
+			case <-mp.closer:
+				return
+			}
+		}
+	}()
+}
+
+func (mp *MessagePool) expireEthMessages(ctx context.Context, timeout time.Duration) {
+	mp.curTsLk.RLock()
+	defer mp.curTsLk.RUnlock()
+
+	mp.lk.Lock()
+	defer mp.lk.Unlock()
+
+	type expiredMsg struct {
+		from  address.Address
+		nonce uint64
+		cid   cid.Cid
+		age   time.Duration
+	}
+
+	now := build.Clock.Now()
+	var expired []expiredMsg
+	mp.forEachPending(func(from address.Address, mset *msgSet) {
+		if from.Protocol() != address.Delegated {
+			return
+		}
+
+		for nonce, added := range mset.addedAt {
+			if age := now.Sub(added); age > timeout {
+				expired = append(expired, expiredMsg{from: from, nonce: nonce, cid: mset.msgs[nonce].Cid(), age: age})
+			}
+		}
+	})
+
+	for _, e := range expired {
+		log.Warnw("evicting expired pending eth transaction", "from", e.from, "nonce", e.nonce, "age", e.age, "timeout", timeout)
+		mp.remove(ctx, e.from, e.nonce, false)
+		// local messages are reloaded from the datastore on restart, drop them there too
+		if err := mp.localMsgs.Delete(ctx, datastore.NewKey(string(e.cid.Bytes()))); err != nil {
+			log.Warnf("error deleting local message: %s", err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// stm: #unit
+package messagepool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/consensus/filcns"
+	"github.com/filecoin-project/lotus/chain/messagepool/gasguess"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/chain/wallet"
+)
+
+func makeTestEthMessage(w *wallet.LocalWallet, from address.Address, nonce uint64, gasLimit int64) *types.SignedMessage {
+	to := ethtypes.EthAddress{1}
+	tx := ethtypes.EthTxArgs{
+		ChainID:              build.Eip155ChainId,
+		Nonce:                int(nonce),
+		To:                   &to,
+		Value:                big.Zero(),
+		MaxFeePerGas:         types.NewInt(200),
+		MaxPriorityFeePerGas: types.NewInt(100),
+		GasLimit:             int(gasLimit),
+	}
+
+	msg, err := tx.ToUnsignedMessage(from)
+	if err != nil {
+		panic(err)
+	}
+	preimage, err := tx.ToRlpUnsignedMsg()
+	if err != nil {
+		panic(err)
+	}
+	sig, err := w.WalletSign(context.TODO(), from, preimage, api.MsgMeta{})
+	if err != nil {
+		panic(err)
+	}
+	return &types.SignedMessage{
+		Message:   *msg,
+		Signature: *sig,
+	}
+}
+
+func TestEthExpiryDropsLocalMessages(t *testing.T) {
+	tma := newTestMpoolAPI()
+	ds := datastore.NewMapDatastore()
+
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ethSender, err := w.WalletNew(context.Background(), types.KTDelegated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secpSender, err := w.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := w.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tma.setBalance(ethSender, 1) // in FIL
+	tma.setBalance(secpSender, 1)
+
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+
+	ethMsg := makeTestEthMessage(w, ethSender, 0, gasLimit)
+	if _, err := mp.Push(context.TODO(), ethMsg, true); err != nil {
+		t.Fatal(err)
+	}
+	secpMsg := makeTestMessage(w, secpSender, to, 0, gasLimit, 1)
+	if _, err := mp.Push(context.TODO(), secpMsg, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// backdate both messages; only the one from the delegated sender is expired
+	mp.lk.Lock()
+	for _, a := range []address.Address{ethSender, secpSender} {
+		mset, ok, err := mp.getPendingMset(context.TODO(), a)
+		if err != nil || !ok {
+			t.Fatalf("no pending messages for %s: %v", a, err)
+		}
+		mset.addedAt[0] = build.Clock.Now().Add(-2 * time.Hour)
+	}
+	mp.lk.Unlock()
+
+	mp.expireEthMessages(context.TODO(), time.Hour)
+
+	checkPending := func(mp *MessagePool) {
+		pending, _ := mp.Pending(context.TODO())
+		if len(pending) != 1 || pending[0].Cid() != secpMsg.Cid() {
+			t.Fatalf("expected only the secp message to be pending, got %d messages", len(pending))
+		}
+	}
+	checkPending(mp)
+
+	if err := mp.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the expired message must not come back from the local message store on restart
+	mp, err = New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mp.Close() //nolint:errcheck
+
+	checkPending(mp)
+}
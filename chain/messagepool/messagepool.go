@@ -113,11 +113,25 @@ type MessagePoolEvtMessage struct {
 }
 
 func init() {
-	// if the republish interval is too short compared to the pubsub timecache, adjust it
+	RepublishInterval = clampRepublishInterval(RepublishInterval)
+}
+
+// if the republish interval is too short compared to the pubsub timecache, adjust it
+func clampRepublishInterval(interval time.Duration) time.Duration {
 	minInterval := pubsub.TimeCacheDuration + time.Duration(build.PropagationDelaySecs)*time.Second
-	if RepublishInterval < minInterval {
-		RepublishInterval = minInterval
+	if interval < minInterval {
+		return minInterval
+	}
+	return interval
+}
+
+// republishInterval returns the republish interval to use for the configured interval, which is 0
+// for the default.
+func republishInterval(configured time.Duration) time.Duration {
+	if configured == 0 {
+		return RepublishInterval
 	}
+	return clampRepublishInterval(configured)
 }
 
 type MessagePool struct {
@@ -134,6 +148,13 @@ type MessagePool struct {
 
 	republished map[cid.Cid]struct{}
 
+	// repubAttempts counts how often pending local messages have been republished,
+	// protected by lk
+	repubAttempts map[cid.Cid]int
+	// maxRepubAttempts is the number of republications after which a message is
+	// dropped, protected by lk; 0 means no limit
+	maxRepubAttempts int
+
 	// do NOT access this map directly, use isLocal, setLocal, and forEachLocal respectively
 	localAddrs map[address.Address]struct{}
 
@@ -148,6 +169,12 @@ type MessagePool struct {
 	cfgLk sync.RWMutex
 	cfg   *types.MpoolConfig
 
+	// selector picks the messages for new blocks, protected by lk
+	selector MsgSelector
+	// maxPendingPerSender bounds the pending messages of a sender accepted by
+	// Push, protected by lk; 0 means no limit
+	maxPendingPerSender int
+
 	api Provider
 
 	minGasPrice types.BigInt
@@ -185,6 +212,7 @@ type stateNonceCacheKey struct {
 
 type msgSet struct {
 	msgs          map[uint64]*types.SignedMessage
+	addedAt       map[uint64]time.Time
 	nextNonce     uint64
 	requiredFunds *stdbig.Int
 }
@@ -192,6 +220,7 @@ type msgSet struct {
 func newMsgSet(nonce uint64) *msgSet {
 	return &msgSet{
 		msgs:          make(map[uint64]*types.SignedMessage),
+		addedAt:       make(map[uint64]time.Time),
 		nextNonce:     nonce,
 		requiredFunds: stdbig.NewInt(0),
 	}
@@ -303,6 +332,7 @@ func (ms *msgSet) add(m *types.SignedMessage, mp *MessagePool, strict, untrusted
 
 	ms.nextNonce = nextNonce
 	ms.msgs[m.Message.Nonce] = m
+	ms.addedAt[m.Message.Nonce] = build.Clock.Now()
 	ms.requiredFunds.Add(ms.requiredFunds, m.Message.RequiredFunds().Int)
 	// ms.requiredFunds.Add(ms.requiredFunds, m.Message.Value.Int)
 
@@ -326,6 +356,7 @@ func (ms *msgSet) rm(nonce uint64, applied bool) {
 	ms.requiredFunds.Sub(ms.requiredFunds, m.Message.RequiredFunds().Int)
 	// ms.requiredFunds.Sub(ms.requiredFunds, m.Message.Value.Int)
 	delete(ms.msgs, nonce)
+	delete(ms.addedAt, nonce)
 
 	// adjust next nonce
 	if applied {
@@ -370,7 +401,10 @@ func (ms *msgSet) toSlice() []*types.SignedMessage {
 	return set
 }
 
-func New(ctx context.Context, api Provider, ds dtypes.MetadataDS, us stmgr.UpgradeSchedule, netName dtypes.NetworkName, j journal.Journal) (*MessagePool, error) {
+// New creates a message pool which republishes local messages every repubInterval, raised to the
+// pubsub timecache duration plus the propagation delay if shorter. A zero repubInterval keeps the
+// default RepublishInterval.
+func New(ctx context.Context, api Provider, ds dtypes.MetadataDS, us stmgr.UpgradeSchedule, netName dtypes.NetworkName, j journal.Journal, repubInterval time.Duration) (*MessagePool, error) {
 	cache, _ := lru.New2Q[cid.Cid, crypto.Signature](build.BlsSignatureCacheSize)
 	verifcache, _ := lru.New2Q[string, struct{}](build.VerifSigCacheSize)
 	stateNonceCache, _ := lru.New[stateNonceCacheKey, uint64](32768) // 32k * ~200 bytes = 6MB
@@ -389,8 +423,9 @@ func New(ctx context.Context, api Provider, ds dtypes.MetadataDS, us stmgr.Upgra
 		ds:              ds,
 		addSema:         make(chan struct{}, 1),
 		closer:          make(chan struct{}),
-		repubTk:         build.Clock.Ticker(RepublishInterval),
+		repubTk:         build.Clock.Ticker(republishInterval(repubInterval)),
 		repubTrigger:    make(chan struct{}, 1),
+		repubAttempts:   make(map[cid.Cid]int),
 		localAddrs:      make(map[address.Address]struct{}),
 		pending:         make(map[address.Address]*msgSet),
 		keyCache:        keycache,
@@ -406,6 +441,7 @@ func New(ctx context.Context, api Provider, ds dtypes.MetadataDS, us stmgr.Upgra
 		api:             api,
 		netName:         netName,
 		cfg:             cfg,
+		selector:        feeSelector{},
 		evtTypes: [...]journal.EventType{
 			evtTypeMpoolAdd:    j.RegisterEventType("mpool", "add"),
 			evtTypeMpoolRemove: j.RegisterEventType("mpool", "remove"),
@@ -700,6 +736,10 @@ func (mp *MessagePool) Push(ctx context.Context, m *types.SignedMessage, publish
 		<-mp.addSema
 	}()
 
+	if err := mp.checkPendingLimit(ctx, m); err != nil {
+		return cid.Undef, err
+	}
+
 	mp.curTsLk.Lock()
 	ok, err := mp.addTs(ctx, m, mp.curTs, true, false)
 	if err != nil {
@@ -723,6 +763,49 @@ func (mp *MessagePool) Push(ctx context.Context, m *types.SignedMessage, publish
 	return m.Cid(), nil
 }
 
+// SetMaxPendingPerSender sets the maximum number of pending messages a sender
+// can have for Push to accept another of its messages. 0 means no limit.
+func (mp *MessagePool) SetMaxPendingPerSender(n int) {
+	mp.lk.Lock()
+	defer mp.lk.Unlock()
+
+	mp.maxPendingPerSender = n
+}
+
+// SetMaxRepublishAttempts sets the number of times a pending local message is
+// republished before it is dropped from the pool. 0 means no limit.
+func (mp *MessagePool) SetMaxRepublishAttempts(n int) {
+	mp.lk.Lock()
+	defer mp.lk.Unlock()
+
+	mp.maxRepubAttempts = n
+}
+
+// checkPendingLimit rejects m if its sender already has the maximum number of
+// pending messages, unless m replaces one of them.
+func (mp *MessagePool) checkPendingLimit(ctx context.Context, m *types.SignedMessage) error {
+	mp.lk.RLock()
+	defer mp.lk.RUnlock()
+
+	if mp.maxPendingPerSender <= 0 {
+		return nil
+	}
+
+	// failing to resolve the sender is reported when adding the message
+	mset, ok, err := mp.getPendingMset(ctx, m.Message.From)
+	if err != nil || !ok {
+		return nil
+	}
+
+	if _, has := mset.msgs[m.Message.Nonce]; has {
+		return nil
+	}
+	if len(mset.msgs) >= mp.maxPendingPerSender {
+		return xerrors.Errorf("sender %s already has %d pending messages: %w", m.Message.From, len(mset.msgs), ErrTooManyPendingMessages)
+	}
+	return nil
+}
+
 func (mp *MessagePool) checkMessage(ctx context.Context, m *types.SignedMessage) error {
 	// big messages are bad, anti DOS
 	if m.Size() > MaxMessageSize {
@@ -1201,6 +1284,7 @@ func (mp *MessagePool) remove(ctx context.Context, from address.Address, nonce u
 		})
 
 		mp.currentSize--
+		delete(mp.repubAttempts, m.Cid())
 	}
 
 	// NB: This deletes any message with the given nonce. This makes sense
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
@@ -29,6 +30,7 @@ import (
 	"github.com/filecoin-project/lotus/chain/wallet"
 	_ "github.com/filecoin-project/lotus/lib/sigs/bls"
 	_ "github.com/filecoin-project/lotus/lib/sigs/secp"
+	"github.com/filecoin-project/lotus/node/config"
 )
 
 func init() {
@@ -262,7 +264,7 @@ func TestMessagePool(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -306,7 +308,7 @@ func TestCheckMessageBig(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	assert.NoError(t, err)
 
 	to := mock.Address(1001)
@@ -370,7 +372,7 @@ func TestMessagePoolMessagesInEachBlock(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -421,7 +423,7 @@ func TestRevertMessages(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -485,7 +487,7 @@ func TestPruningSimple(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -560,7 +562,7 @@ func TestLoadLocal(t *testing.T) {
 	tma := newTestMpoolAPI()
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -604,7 +606,7 @@ func TestLoadLocal(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	mp, err = New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err = New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -634,7 +636,7 @@ func TestClearAll(t *testing.T) {
 	tma := newTestMpoolAPI()
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -687,11 +689,69 @@ func TestClearAll(t *testing.T) {
 	}
 }
 
+func TestPushPendingLimit(t *testing.T) {
+	tma := newTestMpoolAPI()
+	ds := datastore.NewMapDatastore()
+
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp.SetMaxPendingPerSender(3)
+
+	w1, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a1, err := w1.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w2, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a2, err := w2.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tma.setBalance(a1, 1) // in FIL
+	tma.setBalance(a2, 1) // in FIL
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+	for i := 0; i < 3; i++ {
+		m := makeTestMessage(w1, a1, a2, uint64(i), gasLimit, uint64(i+1))
+		// stm: @CHAIN_MEMPOOL_PUSH_001
+		if _, err := mp.Push(context.TODO(), m, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := makeTestMessage(w1, a1, a2, 3, gasLimit, 4)
+	_, err = mp.Push(context.TODO(), m, true)
+	require.ErrorIs(t, err, ErrTooManyPendingMessages)
+
+	// replacing a pending message is still allowed
+	m = makeTestMessage(w1, a1, a2, 2, gasLimit, 100)
+	if _, err := mp.Push(context.TODO(), m, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// other senders aren't affected
+	m = makeTestMessage(w2, a2, a1, 0, gasLimit, 1)
+	if _, err := mp.Push(context.TODO(), m, true); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestClearNonLocal(t *testing.T) {
 	tma := newTestMpoolAPI()
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -755,7 +815,7 @@ func TestUpdates(t *testing.T) {
 	tma := newTestMpoolAPI()
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -833,7 +893,7 @@ func TestMessageBelowMinGasFee(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	assert.NoError(t, err)
 
 	to := mock.Address(1001)
@@ -879,7 +939,7 @@ func TestMessageValueTooHigh(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	assert.NoError(t, err)
 
 	to := mock.Address(1001)
@@ -927,7 +987,7 @@ func TestMessageSignatureInvalid(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	assert.NoError(t, err)
 
 	to := mock.Address(1001)
@@ -972,7 +1032,7 @@ func TestAddMessageTwice(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	assert.NoError(t, err)
 
 	to := mock.Address(1001)
@@ -1018,7 +1078,7 @@ func TestAddMessageTwiceNonceGap(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	assert.NoError(t, err)
 
 	to := mock.Address(1001)
@@ -1047,7 +1107,7 @@ func TestAddMessageTwiceCidDiff(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	assert.NoError(t, err)
 
 	to := mock.Address(1001)
@@ -1081,7 +1141,7 @@ func TestAddMessageTwiceCidDiffReplaced(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	assert.NoError(t, err)
 
 	to := mock.Address(1001)
@@ -1110,7 +1170,7 @@ func TestRemoveMessage(t *testing.T) {
 
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	assert.NoError(t, err)
 
 	to := mock.Address(1001)
@@ -1166,3 +1226,12 @@ func TestCapGasFee(t *testing.T) {
 		assert.Equal(t, msg.GasPremium.Int.Int64(), int64(100_000))
 	})
 }
+
+func TestRepublishInterval(t *testing.T) {
+	// the default config keeps the default interval
+	require.Equal(t, RepublishInterval, republishInterval(time.Duration(config.DefaultFullNode().Chainstore.MsgPoolRepublishInterval)))
+
+	minInterval := clampRepublishInterval(0)
+	require.Equal(t, minInterval, republishInterval(time.Second))
+	require.Equal(t, minInterval+time.Minute, republishInterval(minInterval+time.Minute))
+}
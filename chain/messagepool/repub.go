@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
@@ -63,6 +64,13 @@ func (mp *MessagePool) republishPendingMessages(ctx context.Context) error {
 		return nil
 	}
 
+	pendingCids := make(map[cid.Cid]struct{})
+	for _, mset := range pending {
+		for _, m := range mset {
+			pendingCids[m.Cid()] = struct{}{}
+		}
+	}
+
 	var chains []*msgChain
 	for actor, mset := range pending {
 		// We use the baseFee lower bound for createChange so that we optimistically include
@@ -138,6 +146,8 @@ loop:
 		msgs = msgs[:repubMsgLimit]
 	}
 
+	msgs = mp.dropRepublishedTooOften(ctx, msgs)
+
 	log.Infof("republishing %d messages", len(msgs))
 	for _, m := range msgs {
 		mb, err := m.Serialize()
@@ -181,7 +191,56 @@ loop:
 	// update the republished set so that we can trigger early republish from head changes
 	mp.lk.Lock()
 	mp.republished = republished
+	for c := range mp.repubAttempts {
+		if _, ok := pendingCids[c]; !ok {
+			delete(mp.repubAttempts, c) // replaced by fee
+		}
+	}
+	for c := range republished {
+		mp.repubAttempts[c]++
+	}
 	mp.lk.Unlock()
 
 	return nil
 }
+
+// dropRepublishedTooOften removes the messages which have already been republished
+// the maximum number of times from the pool, returning the remaining messages.
+func (mp *MessagePool) dropRepublishedTooOften(ctx context.Context, msgs []*types.SignedMessage) []*types.SignedMessage {
+	mp.lk.Lock()
+	defer mp.lk.Unlock()
+
+	if mp.maxRepubAttempts <= 0 {
+		return msgs
+	}
+
+	out := msgs[:0]
+	for _, m := range msgs {
+		c := m.Cid()
+		if mp.repubAttempts[c] < mp.maxRepubAttempts {
+			out = append(out, m)
+			continue
+		}
+
+		mset, ok, err := mp.getPendingMset(ctx, m.Message.From)
+		if err != nil {
+			log.Debugf("failed to get mset: %s", err)
+			continue
+		}
+		if !ok || mset.msgs[m.Message.Nonce] == nil || mset.msgs[m.Message.Nonce].Cid() != c {
+			// included or replaced since the messages were selected
+			delete(mp.repubAttempts, c)
+			continue
+		}
+
+		log.Warnw("dropping message republished too many times without inclusion", "cid", c, "from", m.Message.From, "attempts", mp.repubAttempts[c])
+
+		mp.remove(ctx, m.Message.From, m.Message.Nonce, false)
+		delete(mp.repubAttempts, c)
+		if err := mp.localMsgs.Delete(ctx, datastore.NewKey(string(c.Bytes()))); err != nil {
+			log.Warnf("error deleting local message: %s", err)
+		}
+	}
+
+	return out
+}
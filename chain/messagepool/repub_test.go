@@ -27,7 +27,7 @@ func TestRepubMessages(t *testing.T) {
 	tma := newTestMpoolAPI()
 	ds := datastore.NewMapDatastore()
 
-	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil)
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -77,3 +77,76 @@ func TestRepubMessages(t *testing.T) {
 		t.Fatalf("expected to have published 20 messages, but got %d instead", tma.published)
 	}
 }
+
+func TestRepubMaxAttempts(t *testing.T) {
+	oldRepublishBatchDelay := RepublishBatchDelay
+	RepublishBatchDelay = time.Microsecond
+	defer func() {
+		RepublishBatchDelay = oldRepublishBatchDelay
+	}()
+
+	tma := newTestMpoolAPI()
+	ds := datastore.NewMapDatastore()
+
+	mp, err := New(context.Background(), tma, ds, filcns.DefaultUpgradeSchedule(), "mptest", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp.SetMaxRepublishAttempts(2)
+
+	w1, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a1, err := w1.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w2, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a2, err := w2.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+
+	tma.setBalance(a1, 1) // in FIL
+
+	for i := 0; i < 5; i++ {
+		m := makeTestMessage(w1, a1, a2, uint64(i), gasLimit, uint64(i+1))
+		_, err := mp.Push(context.TODO(), m, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := mp.republishPendingMessages(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if tma.published != 15 {
+		t.Fatalf("expected to have published 15 messages, but got %d instead", tma.published)
+	}
+
+	// the third round drops the messages instead of publishing them again
+	if err := mp.republishPendingMessages(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	if tma.published != 15 {
+		t.Fatalf("expected to have published 15 messages, but got %d instead", tma.published)
+	}
+
+	pending, _ := mp.Pending(context.TODO())
+	if len(pending) != 0 {
+		t.Fatalf("expected all messages to be dropped, but %d are pending", len(pending))
+	}
+}
@@ -56,17 +56,7 @@ func (mp *MessagePool) SelectMessages(ctx context.Context, ts *types.TipSet, tq
 		}
 	}
 
-	// if the ticket quality is high enough that the first block has higher probability
-	// than any other block, then we don't bother with optimal selection because the
-	// first block will always have higher effective performance
-	var sm *selectedMessages
-	var err error
-	if tq > 0.84 {
-		sm, err = mp.selectMessagesGreedy(ctx, mp.curTs, ts)
-	} else {
-		sm, err = mp.selectMessagesOptimal(ctx, mp.curTs, ts, tq)
-	}
-
+	sm, err := mp.selector.selectMessages(ctx, mp, mp.curTs, ts, tq)
 	if err != nil {
 		return nil, err
 	}
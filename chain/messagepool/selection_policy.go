@@ -0,0 +1,259 @@
+package messagepool
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/crypto"
+
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/messagepool/gasguess"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+const (
+	// MsgSelectPolicyFee selects the messages paying the most per unit of gas.
+	MsgSelectPolicyFee = "fee"
+	// MsgSelectPolicyTime selects the messages in the order they entered the pool.
+	MsgSelectPolicyTime = "time"
+	// MsgSelectPolicyFair selects one message per sender in turn, so that no
+	// sender can take the whole block.
+	MsgSelectPolicyFair = "fair"
+)
+
+// MsgSelector picks the messages to include in a block out of the pending
+// messages of the pool, for a block with ticket quality tq on top of ts.
+// Priority messages are always selected first.
+type MsgSelector interface {
+	Policy() string
+
+	selectMessages(ctx context.Context, mp *MessagePool, curTs, ts *types.TipSet, tq float64) (*selectedMessages, error)
+}
+
+// NewMsgSelector returns the selector implementing the given policy; an empty
+// policy selects by fee.
+func NewMsgSelector(policy string) (MsgSelector, error) {
+	switch policy {
+	case "", MsgSelectPolicyFee:
+		return feeSelector{}, nil
+	case MsgSelectPolicyTime:
+		return queueSelector{policy: MsgSelectPolicyTime}, nil
+	case MsgSelectPolicyFair:
+		return queueSelector{policy: MsgSelectPolicyFair}, nil
+	default:
+		return nil, xerrors.Errorf("unknown message selection policy %q", policy)
+	}
+}
+
+// SetMsgSelector sets the selector used by SelectMessages.
+func (mp *MessagePool) SetMsgSelector(sel MsgSelector) {
+	mp.lk.Lock()
+	defer mp.lk.Unlock()
+
+	mp.selector = sel
+}
+
+type feeSelector struct{}
+
+func (feeSelector) Policy() string {
+	return MsgSelectPolicyFee
+}
+
+func (feeSelector) selectMessages(ctx context.Context, mp *MessagePool, curTs, ts *types.TipSet, tq float64) (*selectedMessages, error) {
+	// if the ticket quality is high enough that the first block has higher probability
+	// than any other block, then we don't bother with optimal selection because the
+	// first block will always have higher effective performance
+	if tq > 0.84 {
+		return mp.selectMessagesGreedy(ctx, curTs, ts)
+	}
+	return mp.selectMessagesOptimal(ctx, curTs, ts, tq)
+}
+
+// queueSelector selects messages from per sender queues, either oldest first
+// or round-robin across senders.
+type queueSelector struct {
+	policy string
+}
+
+func (s queueSelector) Policy() string {
+	return s.policy
+}
+
+// senderQueue holds the messages of a sender which can be included, in nonce
+// order.
+type senderQueue struct {
+	actor   address.Address
+	msgs    []*types.SignedMessage
+	sigType crypto.SigType
+	// gasPerf of the first message
+	gasPerf float64
+	// when each message entered the pool, never earlier than the previous
+	// message so that nonces are kept in order
+	addedAt []time.Time
+}
+
+// next wraps the first message of the queue as a chain, for tryToAdd.
+func (q *senderQueue) next() *msgChain {
+	return &msgChain{
+		msgs:     q.msgs[:1],
+		gasLimit: q.msgs[0].Message.GasLimit,
+		sigType:  q.sigType,
+	}
+}
+
+func (s queueSelector) selectMessages(ctx context.Context, mp *MessagePool, curTs, ts *types.TipSet, _ float64) (*selectedMessages, error) {
+	start := time.Now()
+
+	baseFee, err := mp.api.ChainComputeBaseFee(context.TODO(), ts)
+	if err != nil {
+		return nil, xerrors.Errorf("computing basefee: %w", err)
+	}
+
+	pending, err := mp.getPendingMessages(ctx, curTs, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	defer func() {
+		log.Infow("message selection done", "policy", s.policy, "took", time.Since(start))
+	}()
+
+	minGas := int64(gasguess.MinGas)
+	result := mp.selectPriorityMessages(ctx, pending, baseFee, ts)
+
+	// have we filled the block?
+	if result.gasLimit < minGas || len(result.msgs) > build.BlockMessageLimit {
+		return result, nil
+	}
+
+	queues := make([]*senderQueue, 0, len(pending))
+	for actor, mset := range pending {
+		if q := mp.createSenderQueue(ctx, actor, mset, baseFee, ts); q != nil {
+			queues = append(queues, q)
+		}
+	}
+
+	if s.policy == MsgSelectPolicyTime {
+		result.addOldestFirst(queues, minGas)
+	} else {
+		result.addRoundRobin(queues, minGas)
+	}
+
+	return result, nil
+}
+
+// createSenderQueue returns the messages of actor which can be included, up to
+// the first chain which doesn't pay for its gas, or nil if there are none.
+func (mp *MessagePool) createSenderQueue(ctx context.Context, actor address.Address, mset map[uint64]*types.SignedMessage, baseFee types.BigInt, ts *types.TipSet) *senderQueue {
+	chains := mp.createMessageChains(actor, mset, baseFee, ts)
+	if len(chains) == 0 || chains[0].gasPerf < 0 {
+		return nil
+	}
+
+	var addedAt map[uint64]time.Time
+	if ms, ok, err := mp.getPendingMset(ctx, actor); err == nil && ok {
+		addedAt = ms.addedAt
+	}
+
+	q := &senderQueue{
+		actor:   actor,
+		sigType: chains[0].sigType,
+		gasPerf: mp.getGasPerf(mp.getGasReward(chains[0].msgs[0], baseFee), chains[0].msgs[0].Message.GasLimit),
+	}
+	var last time.Time
+	for _, chain := range chains {
+		if chain.gasPerf < 0 {
+			break
+		}
+		for _, m := range chain.msgs {
+			// messages reverted from the chain aren't timestamped, they go first
+			t := addedAt[m.Message.Nonce]
+			if t.Before(last) {
+				t = last
+			}
+			last = t
+
+			q.msgs = append(q.msgs, m)
+			q.addedAt = append(q.addedAt, t)
+		}
+	}
+
+	return q
+}
+
+// addOldestFirst adds the messages of the queues in the order they entered the
+// pool, until the block is full. Once a message of a sender doesn't fit, the
+// following messages of that sender are skipped.
+func (sm *selectedMessages) addOldestFirst(queues []*senderQueue, minGas int64) {
+	type entry struct {
+		q       *senderQueue
+		addedAt time.Time
+	}
+
+	var entries []entry
+	for _, q := range queues {
+		for _, t := range q.addedAt {
+			entries = append(entries, entry{q: q, addedAt: t})
+		}
+	}
+
+	// the entries of a queue are already in order, so a stable sort keeps them
+	// in nonce order
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].addedAt.Before(entries[j].addedAt)
+	})
+
+	for _, e := range entries {
+		if sm.gasLimit < minGas {
+			break
+		}
+
+		q := e.q
+		if len(q.msgs) == 0 {
+			continue
+		}
+
+		if !sm.tryToAdd(q.next()) {
+			q.msgs = nil
+			continue
+		}
+		q.msgs = q.msgs[1:]
+	}
+}
+
+// addRoundRobin adds one message of each queue in turn, until the block is
+// full. In each round the senders paying more go first. Once a message of a
+// sender doesn't fit, the sender is dropped.
+func (sm *selectedMessages) addRoundRobin(queues []*senderQueue, minGas int64) {
+	sort.Slice(queues, func(i, j int) bool {
+		if queues[i].gasPerf != queues[j].gasPerf {
+			return queues[i].gasPerf > queues[j].gasPerf
+		}
+		return queues[i].actor.String() < queues[j].actor.String()
+	})
+
+	for len(queues) > 0 && sm.gasLimit >= minGas {
+		remaining := queues[:0]
+		for _, q := range queues {
+			if sm.gasLimit < minGas {
+				break
+			}
+			if !sm.tryToAdd(q.next()) {
+				continue
+			}
+			q.msgs = q.msgs[1:]
+			if len(q.msgs) > 0 {
+				remaining = append(remaining, q)
+			}
+		}
+		queues = remaining
+	}
+}
@@ -0,0 +1,91 @@
+package messagepool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+
+	"github.com/filecoin-project/lotus/chain/messagepool/gasguess"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+	"github.com/filecoin-project/lotus/chain/wallet"
+)
+
+func TestMsgSelectPolicies(t *testing.T) {
+	//stm: @TOKEN_WALLET_NEW_001, @CHAIN_MEMPOOL_SELECT_001
+	mp, tma := makeTestMpool()
+
+	w1, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1, err := w1.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w2, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, err := w2.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := tma.nextBlock()
+	ts := mock.TipSet(block)
+	tma.applyBlock(t, block)
+
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+
+	tma.setBalance(a1, 1) // in FIL
+	tma.setBalance(a2, 1) // in FIL
+
+	// the second actor sends its messages first, but pays less for them
+	for i := 0; i < 5; i++ {
+		m := makeTestMessage(w2, a2, a1, uint64(i), gasLimit, uint64(i+1))
+		mustAdd(t, mp, m)
+	}
+	for i := 0; i < 5; i++ {
+		m := makeTestMessage(w1, a1, a2, uint64(i), gasLimit, uint64(10*i+100))
+		mustAdd(t, mp, m)
+	}
+
+	expectSenders := func(policy string, senders ...address.Address) {
+		sel, err := NewMsgSelector(policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mp.SetMsgSelector(sel)
+
+		msgs, err := mp.SelectMessages(context.Background(), ts, 1.0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != len(senders) {
+			t.Fatalf("%s: expected %d messages, got %d", policy, len(senders), len(msgs))
+		}
+
+		nonces := map[address.Address]uint64{}
+		for i, m := range msgs {
+			if m.Message.From != senders[i] {
+				t.Fatalf("%s: expected message %d from %s, got %s", policy, i, senders[i], m.Message.From)
+			}
+			if m.Message.Nonce != nonces[senders[i]] {
+				t.Fatalf("%s: expected nonce %d, got %d", policy, nonces[senders[i]], m.Message.Nonce)
+			}
+			nonces[senders[i]]++
+		}
+	}
+
+	expectSenders(MsgSelectPolicyFee, a1, a1, a1, a1, a1, a2, a2, a2, a2, a2)
+	expectSenders(MsgSelectPolicyTime, a2, a2, a2, a2, a2, a1, a1, a1, a1, a1)
+	expectSenders(MsgSelectPolicyFair, a1, a2, a1, a2, a1, a2, a1, a2, a1, a2)
+
+	if _, err := NewMsgSelector("random"); err == nil {
+		t.Fatal("expected an error for an unknown policy")
+	}
+}
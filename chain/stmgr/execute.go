@@ -131,8 +131,8 @@ func (sm *StateManager) ExecutionTrace(ctx context.Context, ts *types.TipSet) (c
 	tsKey := ts.Key()
 
 	// check if we have the trace for this tipset in the cache
-	if execTraceCacheSize > 0 {
-		sm.execTraceCacheLock.Lock()
+	sm.execTraceCacheLock.Lock()
+	if sm.execTraceCache != nil {
 		if entry, ok := sm.execTraceCache.Get(tsKey); ok {
 			// we have to make a deep copy since caller can modify the invocTrace
 			// and we don't want that to change what we store in cache
@@ -140,8 +140,8 @@ func (sm *StateManager) ExecutionTrace(ctx context.Context, ts *types.TipSet) (c
 			sm.execTraceCacheLock.Unlock()
 			return entry.postStateRoot, invocTraceCopy, nil
 		}
-		sm.execTraceCacheLock.Unlock()
 	}
+	sm.execTraceCacheLock.Unlock()
 
 	var invocTrace []*api.InvocResult
 	st, err := sm.ExecutionTraceWithMonitor(ctx, ts, &InvocationTracer{trace: &invocTrace})
@@ -149,13 +149,11 @@ func (sm *StateManager) ExecutionTrace(ctx context.Context, ts *types.TipSet) (c
 		return cid.Undef, nil, err
 	}
 
-	if execTraceCacheSize > 0 {
-		invocTraceCopy := makeDeepCopy(invocTrace)
-
-		sm.execTraceCacheLock.Lock()
-		sm.execTraceCache.Add(tsKey, tipSetCacheEntry{st, invocTraceCopy})
-		sm.execTraceCacheLock.Unlock()
+	sm.execTraceCacheLock.Lock()
+	if sm.execTraceCache != nil {
+		sm.execTraceCache.Add(tsKey, tipSetCacheEntry{st, makeDeepCopy(invocTrace)})
 	}
+	sm.execTraceCacheLock.Unlock()
 
 	return st, invocTrace, nil
 }
@@ -137,6 +137,7 @@ type StateManager struct {
 
 	stCache             map[string][]cid.Cid
 	tCache              treeCache
+	noTreeCache         bool
 	compWait            map[string]chan struct{}
 	stlk                sync.Mutex
 	genesisMsigLk       sync.Mutex
@@ -252,6 +253,18 @@ func NewStateManagerWithUpgradeScheduleAndMonitor(cs *store.ChainStore, exec Exe
 	return sm, nil
 }
 
+// DisableCache stops the state manager from keeping the last loaded lookback
+// state tree and the execution traces of recent tipsets in memory. They're
+// loaded from the blockstore, or computed again, every time instead.
+func (sm *StateManager) DisableCache() {
+	sm.noTreeCache = true
+	sm.tCache = treeCache{}
+
+	sm.execTraceCacheLock.Lock()
+	sm.execTraceCache = nil
+	sm.execTraceCacheLock.Unlock()
+}
+
 func cidsToKey(cids []cid.Cid) string {
 	var out string
 	for _, c := range cids {
@@ -369,9 +382,11 @@ func (sm *StateManager) ResolveToDeterministicAddressAtFinality(ctx context.Cont
 			return address.Undef, xerrors.Errorf("failed to load parent state tree: %w", err)
 		}
 
-		sm.tCache = treeCache{
-			root: ts.ParentState(),
-			tree: tree,
+		if !sm.noTreeCache {
+			sm.tCache = treeCache{
+				root: ts.ParentState(),
+				tree: tree,
+			}
 		}
 	}
 
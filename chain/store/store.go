@@ -285,13 +285,26 @@ const (
 	HCCurrent = "current"
 )
 
+// DefaultHeadChangeBufferSize is the number of head changes buffered for a SubHeadChanges subscriber
+// before the subscription is closed as too slow.
+const DefaultHeadChangeBufferSize = 16
+
 func (cs *ChainStore) SubHeadChanges(ctx context.Context) chan []*api.HeadChange {
+	return cs.SubHeadChangesWithBuffer(ctx, DefaultHeadChangeBufferSize)
+}
+
+// SubHeadChangesWithBuffer is like SubHeadChanges, buffering up to bufferSize head changes for the subscriber.
+func (cs *ChainStore) SubHeadChangesWithBuffer(ctx context.Context, bufferSize int) chan []*api.HeadChange {
+	if bufferSize < 1 {
+		bufferSize = DefaultHeadChangeBufferSize
+	}
+
 	cs.pubLk.Lock()
 	subch := cs.bestTips.Sub("headchange")
 	head := cs.GetHeaviestTipSet()
 	cs.pubLk.Unlock()
 
-	out := make(chan []*api.HeadChange, 16)
+	out := make(chan []*api.HeadChange, bufferSize)
 	out <- []*api.HeadChange{{
 		Type: HCCurrent,
 		Val:  head,
@@ -43,11 +43,19 @@ var msgCidPrefix = cid.Prefix{
 	MhLength: 32,
 }
 
-func HandleIncomingBlocks(ctx context.Context, bsub *pubsub.Subscription, s *chain.Syncer, bs bserv.BlockService, cmgr connmgr.ConnManager) {
+// HandleIncomingBlocks fetches the messages of blocks received over pubsub and
+// passes the blocks to the syncer. At most fetchLimit blocks are fetched at the
+// same time; 0 means no limit.
+func HandleIncomingBlocks(ctx context.Context, bsub *pubsub.Subscription, s *chain.Syncer, bs bserv.BlockService, cmgr connmgr.ConnManager, fetchLimit int) {
 	// Timeout after (block time + propagation delay). This is useless at
 	// this point.
 	timeout := time.Duration(build.BlockDelaySecs+build.PropagationDelaySecs) * time.Second
 
+	var fetchSema chan struct{}
+	if fetchLimit > 0 {
+		fetchSema = make(chan struct{}, fetchLimit)
+	}
+
 	for {
 		msg, err := bsub.Next(ctx)
 		if err != nil {
@@ -71,6 +79,16 @@ func HandleIncomingBlocks(ctx context.Context, bsub *pubsub.Subscription, s *cha
 			ctx, cancel := context.WithTimeout(ctx, timeout)
 			defer cancel()
 
+			if fetchSema != nil {
+				select {
+				case fetchSema <- struct{}{}:
+					defer func() { <-fetchSema }()
+				case <-ctx.Done():
+					log.Warnw("timed out waiting to fetch messages for block received over pubsub", "cid", blk.Header.Cid(), "source", src)
+					return
+				}
+			}
+
 			// NOTE: we could also share a single session between
 			// all requests but that may have other consequences.
 			ses := bserv.NewSession(ctx, bs)
@@ -228,18 +246,37 @@ type BlockValidator struct {
 	// necessary for block validation
 	chain     *store.ChainStore
 	consensus consensus.Consensus
+
+	// blocks not validated within this time are dropped; 0 = no timeout
+	validationTimeout time.Duration
+
+	// results of validating blocks from remote peers; nil when caching is disabled
+	results *lru.Cache[cid.Cid, blockValidationResult]
+}
+
+type blockValidationResult struct {
+	valid    bool
+	errorMsg string
 }
 
-func NewBlockValidator(self peer.ID, chain *store.ChainStore, cns consensus.Consensus, blacklist func(peer.ID)) *BlockValidator {
+func NewBlockValidator(self peer.ID, chain *store.ChainStore, cns consensus.Consensus, blacklist func(peer.ID), validationTimeout time.Duration, resultCacheSize int) *BlockValidator {
 	p, _ := lru.New2Q[peer.ID, int](4096)
+
+	var results *lru.Cache[cid.Cid, blockValidationResult]
+	if resultCacheSize > 0 {
+		results, _ = lru.New[cid.Cid, blockValidationResult](resultCacheSize)
+	}
+
 	return &BlockValidator{
-		self:       self,
-		peers:      p,
-		killThresh: 10,
-		blacklist:  blacklist,
-		recvBlocks: newBlockReceiptCache(),
-		chain:      chain,
-		consensus:  cns,
+		self:              self,
+		peers:             p,
+		killThresh:        10,
+		blacklist:         blacklist,
+		recvBlocks:        newBlockReceiptCache(),
+		chain:             chain,
+		consensus:         cns,
+		validationTimeout: validationTimeout,
+		results:           results,
 	}
 }
 
@@ -271,7 +308,44 @@ func (bv *BlockValidator) Validate(ctx context.Context, pid peer.ID, msg *pubsub
 	}()
 
 	var what string
-	res, what = consensus.ValidateBlockPubsub(ctx, bv.consensus, pid == bv.self, msg)
+	blk, cached, ok := bv.cachedResult(pid, msg)
+	if ok {
+		stats.Record(ctx, metrics.BlockReceived.M(1))
+
+		// the consensus range moves with the chain, so it's checked on every receipt
+		switch {
+		case !bv.consensus.IsEpochInConsensusRange(blk.Header.Height):
+			log.Warnf("received block outside of consensus range (%d)", blk.Header.Height)
+			res, what = pubsub.ValidationIgnore, "invalid_block_height"
+		case cached.valid:
+			// attach the block as validation would have
+			msg.ValidatorData = blk
+			stats.Record(ctx, metrics.BlockValidationSuccess.M(1))
+			res = pubsub.ValidationAccept
+		default:
+			res, what = pubsub.ValidationReject, cached.errorMsg
+		}
+	} else {
+		vctx := ctx
+		if bv.validationTimeout > 0 {
+			var cancel context.CancelFunc
+			vctx, cancel = context.WithTimeout(ctx, bv.validationTimeout)
+			defer cancel()
+		}
+
+		res, what = consensus.ValidateBlockPubsub(vctx, bv.consensus, pid == bv.self, msg)
+		if res != pubsub.ValidationAccept && xerrors.Is(vctx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			// not the peer's fault necessarily, so drop the block without penalizing the sender
+			log.Warnw("block validation timed out", "peer", pid, "timeout", bv.validationTimeout, "reason", what)
+			stats.Record(ctx, metrics.BlockValidationTimeout.M(1))
+			return pubsub.ValidationIgnore
+		}
+
+		if blk != nil && (res == pubsub.ValidationAccept || headerRejections[what]) {
+			bv.results.Add(blk.Cid(), blockValidationResult{valid: res == pubsub.ValidationAccept, errorMsg: what})
+		}
+	}
+
 	if res == pubsub.ValidationAccept {
 		// it's a good block! make sure we've only seen it once
 		if count := bv.recvBlocks.add(msg.ValidatorData.(*types.BlockMsg).Cid()); count > 0 {
@@ -290,6 +364,32 @@ func (bv *BlockValidator) Validate(ctx context.Context, pid peer.ID, msg *pubsub
 	return res
 }
 
+// headerRejections are the reasons for rejecting a block which only depend on the block header, which
+// results are cached by. Rejections of the message lists sent along with the header, e.g. for not
+// matching the header's messages root, don't hold for the same header sent with its actual messages.
+// Other rejections, e.g. of blocks from miners unknown at the current head, may not hold once the node
+// has synced further. Neither are cached.
+var headerRejections = map[string]bool{
+	"missing_signature": true,
+}
+
+// cachedResult looks up the result of a previous validation of a block received from a remote
+// peer. The returned block is non-nil whenever the result should be cached after validating.
+func (bv *BlockValidator) cachedResult(pid peer.ID, msg *pubsub.Message) (*types.BlockMsg, blockValidationResult, bool) {
+	if bv.results == nil || pid == bv.self {
+		return nil, blockValidationResult{}, false
+	}
+
+	blk, err := types.DecodeBlockMsg(msg.GetData())
+	if err != nil {
+		// leave it to validation to reject the block
+		return nil, blockValidationResult{}, false
+	}
+
+	res, ok := bv.results.Get(blk.Cid())
+	return blk, res, ok
+}
+
 type blockReceiptCache struct {
 	blocks *lru.TwoQueueCache[cid.Cid, int]
 }
@@ -9,17 +9,24 @@ import (
 	"github.com/golang/mock/gomock"
 	blocks "github.com/ipfs/go-block-format"
 	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
 	"github.com/ipni/go-libipni/announce/message"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	pb "github.com/libp2p/go-libp2p-pubsub/pb"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	blockadt "github.com/filecoin-project/specs-actors/actors/util/adt"
 
 	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/api/mocks"
+	bstore "github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/consensus"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
 )
 
 type getter struct {
@@ -256,3 +263,79 @@ func TestIdxValidator(t *testing.T) {
 		t.Fatal("Did not receive ValidationAccept")
 	}
 }
+
+type epochRangeConsensus struct {
+	consensus.Consensus
+	maxEpoch abi.ChainEpoch
+}
+
+func (c *epochRangeConsensus) IsEpochInConsensusRange(epoch abi.ChainEpoch) bool {
+	return epoch <= c.maxEpoch
+}
+
+func (c *epochRangeConsensus) ValidateBlockHeader(context.Context, *types.BlockHeader) (string, error) {
+	return "", nil
+}
+
+func TestBlockValidatorCachedResults(t *testing.T) {
+	cns := &epochRangeConsensus{maxEpoch: 100}
+	bv := NewBlockValidator("self", nil, cns, func(peer.ID) {}, 0, 16)
+
+	blockMsg := func(ticketNonce uint64) (*types.BlockMsg, *pubsub.Message) {
+		blk := &types.BlockMsg{Header: mock.MkBlock(nil, 1, ticketNonce)}
+		data, err := blk.Serialize()
+		require.NoError(t, err)
+		return blk, &pubsub.Message{Message: &pb.Message{Data: data}}
+	}
+
+	// cached acceptances are returned as long as the block is in the consensus range
+	accepted, msg := blockMsg(1)
+	bv.results.Add(accepted.Cid(), blockValidationResult{valid: true})
+	require.Equal(t, pubsub.ValidationAccept, bv.Validate(context.Background(), "peer", msg))
+	require.Equal(t, accepted.Cid(), msg.ValidatorData.(*types.BlockMsg).Cid())
+
+	cns.maxEpoch = accepted.Header.Height - 1
+	_, msg = blockMsg(1)
+	require.Equal(t, pubsub.ValidationIgnore, bv.Validate(context.Background(), "peer", msg))
+	cns.maxEpoch = 100
+
+	rejected, msg := blockMsg(2)
+	bv.results.Add(rejected.Cid(), blockValidationResult{errorMsg: "invalid_block_meta"})
+	require.Equal(t, pubsub.ValidationReject, bv.Validate(context.Background(), "peer", msg))
+	require.Nil(t, msg.ValidatorData)
+
+	require.True(t, headerRejections["missing_signature"])
+	require.False(t, headerRejections["invalid_block_meta"])
+	require.False(t, headerRejections["unknown_miner"])
+}
+
+func TestBlockValidatorCacheIgnoresMessageLists(t *testing.T) {
+	cns := &epochRangeConsensus{maxEpoch: 100}
+	bv := NewBlockValidator("self", nil, cns, func(peer.ID) {}, 0, 16)
+
+	// the messages root of a block without messages
+	store := blockadt.WrapStore(context.Background(), cbor.NewCborStore(bstore.NewMemory()))
+	emptyRoot, err := blockadt.MakeEmptyArray(store).Root()
+	require.NoError(t, err)
+	metaCid, err := store.Put(context.Background(), &types.MsgMeta{BlsMessages: emptyRoot, SecpkMessages: emptyRoot})
+	require.NoError(t, err)
+
+	hdr := mock.MkBlock(nil, 1, 1)
+	hdr.Messages = metaCid
+
+	receive := func(blk *types.BlockMsg) pubsub.ValidationResult {
+		data, err := blk.Serialize()
+		require.NoError(t, err)
+		return bv.Validate(context.Background(), "peer", &pubsub.Message{Message: &pb.Message{Data: data}})
+	}
+
+	// the header sent with messages it doesn't commit to is rejected, but the rejection isn't cached
+	require.Equal(t, pubsub.ValidationReject, receive(&types.BlockMsg{Header: hdr, BlsMessages: []cid.Cid{metaCid}}))
+	require.False(t, bv.results.Contains(hdr.Cid()))
+
+	// so the header sent with its actual messages is still accepted
+	require.Equal(t, pubsub.ValidationAccept, receive(&types.BlockMsg{Header: hdr}))
+	res, ok := bv.results.Get(hdr.Cid())
+	require.True(t, ok)
+	require.True(t, res.valid)
+}
@@ -26,6 +26,10 @@ var (
 
 	InitialSyncTimeThreshold = 15 * time.Minute
 
+	// BootstrapWaitLogInterval is how often a warning is logged while waiting for
+	// enough peers to start the initial sync
+	BootstrapWaitLogInterval = 30 * time.Second
+
 	coalesceTipsets = false
 )
 
@@ -86,6 +90,13 @@ type syncManager struct {
 	history  []*workerState
 	historyI int
 
+	// bounds on the number of workers kept busy once the initial sync is done
+	minWorkers int
+	maxWorkers int
+
+	// number of peers to track before starting the initial sync; 0 uses BootstrapPeerThreshold
+	bootstrapPeers int
+
 	doSync func(context.Context, *types.TipSet) error
 }
 
@@ -111,6 +122,35 @@ type workerStatus struct {
 
 // sync manager interface
 func NewSyncManager(sync SyncFunc) SyncManager {
+	return newSyncManager(sync, 1, MaxSyncWorkers)
+}
+
+// SyncManagerWithWorkerLimits returns a SyncManagerCtor for sync managers which
+// run at most maxWorkers sync workers, and which keep at least minWorkers of them
+// busy with deferred sync targets once the initial sync is done.
+func SyncManagerWithWorkerLimits(minWorkers, maxWorkers int) SyncManagerCtor {
+	return SyncManagerWithLimits(minWorkers, maxWorkers, 0)
+}
+
+// SyncManagerWithLimits is like SyncManagerWithWorkerLimits, for sync managers which
+// also wait until heads from bootstrapPeers peers are tracked before starting the
+// initial sync. 0 uses BootstrapPeerThreshold.
+func SyncManagerWithLimits(minWorkers, maxWorkers, bootstrapPeers int) SyncManagerCtor {
+	return func(sync SyncFunc) SyncManager {
+		sm := newSyncManager(sync, minWorkers, maxWorkers)
+		sm.bootstrapPeers = bootstrapPeers
+		return sm
+	}
+}
+
+func newSyncManager(sync SyncFunc, minWorkers, maxWorkers int) *syncManager {
+	if minWorkers < 1 {
+		minWorkers = 1
+	}
+	if maxWorkers < minWorkers {
+		maxWorkers = minWorkers
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	return &syncManager{
 		ctx:    ctx,
@@ -124,6 +164,9 @@ func NewSyncManager(sync SyncFunc) SyncManager {
 		recent:  newSyncBuffer(RecentSyncBufferSize),
 		history: make([]*workerState, SyncWorkerHistory),
 
+		minWorkers: minWorkers,
+		maxWorkers: maxWorkers,
+
 		doSync: sync,
 	}
 }
@@ -174,13 +217,31 @@ func (sm *syncManager) State() []SyncerStateSnapshot {
 }
 
 // sync manager internals
+func (sm *syncManager) bootstrapPeerThreshold() int {
+	if sm.bootstrapPeers > 0 {
+		return sm.bootstrapPeers
+	}
+	return BootstrapPeerThreshold
+}
+
 func (sm *syncManager) scheduler() {
 	ticker := time.NewTicker(time.Minute)
 	tickerC := ticker.C
+	waitTicker := time.NewTicker(BootstrapWaitLogInterval)
+	waitC := waitTicker.C
+	defer waitTicker.Stop()
 	for {
 		select {
 		case head := <-sm.workq:
 			sm.handlePeerHead(head)
+		case <-waitC:
+			if sm.nextWorker != 0 {
+				// syncing started
+				waitTicker.Stop()
+				waitC = nil
+				continue
+			}
+			log.Warnw("waiting for enough peers to start chain sync", "have", len(sm.heads), "need", sm.bootstrapPeerThreshold())
 		case status := <-sm.statusq:
 			sm.handleWorkerStatus(status)
 		case <-tickerC:
@@ -204,8 +265,8 @@ func (sm *syncManager) handlePeerHead(head peerHead) {
 		sm.heads[head.p] = head.ts
 
 		// not yet; do we have enough peers?
-		if len(sm.heads) < BootstrapPeerThreshold {
-			log.Debugw("not tracking enough peers to start sync worker", "have", len(sm.heads), "need", BootstrapPeerThreshold)
+		if len(sm.heads) < sm.bootstrapPeerThreshold() {
+			log.Debugw("not tracking enough peers to start sync worker", "have", len(sm.heads), "need", sm.bootstrapPeerThreshold())
 			// not enough peers; track it and wait
 			return
 		}
@@ -274,12 +335,23 @@ func (sm *syncManager) handleWorkerStatus(status workerStatus) {
 		log.Infof("selected sync target: %s", target)
 		sm.spawnWorker(target)
 	}
+
+	// keep at least minWorkers busy while there are deferred syncs
+	if sm.initialSyncDone {
+		sm.spawnDeferredWorkers(sm.minWorkers)
+	}
 }
 
 func (sm *syncManager) handleInitialSyncDone() {
 	// we have just finished the initial sync; spawn some additional workers in deferred syncs
-	// as needed (and up to MaxSyncWorkers) to ramp up chain sync
-	for len(sm.state) < MaxSyncWorkers {
+	// as needed (and up to maxWorkers) to ramp up chain sync
+	sm.spawnDeferredWorkers(sm.maxWorkers)
+}
+
+// spawnDeferredWorkers spawns workers for deferred sync targets until there are
+// limit active workers or no deferred targets left
+func (sm *syncManager) spawnDeferredWorkers(limit int) {
+	for len(sm.state) < limit {
 		target, work, err := sm.selectDeferredSyncTarget()
 		if err != nil {
 			log.Errorf("error selecting deferred sync target: %s", err)
@@ -412,7 +484,7 @@ func (sm *syncManager) addSyncTarget(ts *types.TipSet) (*types.TipSet, bool, err
 
 	// if we have not finished the initial sync or have too many workers, add it to the deferred queue;
 	// it will be processed once a worker is freed from syncing a chain (or the initial sync finishes)
-	if !sm.initialSyncDone || len(sm.state) >= MaxSyncWorkers {
+	if !sm.initialSyncDone || len(sm.state) >= sm.maxWorkers {
 		log.Debugf("deferring sync on %s", ts)
 		sm.deferred.Insert(ts)
 		return nil, false, nil
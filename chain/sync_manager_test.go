@@ -276,3 +276,27 @@ func TestSyncManagerBucketSet(t *testing.T) {
 	bucketSet.PopRelated(ts4fork)
 	require.Equal(t, 0, len(bucketSet.buckets))
 }
+
+func TestSyncManagerWorkerLimits(t *testing.T) {
+	noSync := func(context.Context, *types.TipSet) error { return nil }
+
+	sm := SyncManagerWithWorkerLimits(2, 8)(noSync).(*syncManager)
+	require.Equal(t, 2, sm.minWorkers)
+	require.Equal(t, 8, sm.maxWorkers)
+
+	sm = SyncManagerWithWorkerLimits(0, 0)(noSync).(*syncManager)
+	require.Equal(t, 1, sm.minWorkers)
+	require.Equal(t, 1, sm.maxWorkers)
+
+	sm = SyncManagerWithWorkerLimits(4, 2)(noSync).(*syncManager)
+	require.Equal(t, 4, sm.minWorkers)
+	require.Equal(t, 4, sm.maxWorkers)
+
+	sm = NewSyncManager(noSync).(*syncManager)
+	require.Equal(t, 1, sm.minWorkers)
+	require.Equal(t, MaxSyncWorkers, sm.maxWorkers)
+	require.Equal(t, BootstrapPeerThreshold, sm.bootstrapPeerThreshold())
+
+	sm = SyncManagerWithLimits(1, 5, 7)(noSync).(*syncManager)
+	require.Equal(t, 7, sm.bootstrapPeerThreshold())
+}
@@ -0,0 +1,335 @@
+package ethtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/xerrors"
+)
+
+const eip712DomainType = "EIP712Domain"
+
+// EthTypedDataField is a member of an EIP-712 struct type.
+type EthTypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// EthTypedData is the structured data signed by eth_signTypedData_v4, as defined by EIP-712.
+type EthTypedData struct {
+	Types       map[string][]EthTypedDataField `json:"types"`
+	PrimaryType string                         `json:"primaryType"`
+	Domain      map[string]interface{}         `json:"domain"`
+	Message     map[string]interface{}         `json:"message"`
+}
+
+// UnmarshalJSON accepts the typed data either as an object or, as some wallets send it, as a string
+// containing the JSON object. Numbers are kept as json.Number so that uint256 values aren't truncated.
+func (td *EthTypedData) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		b = []byte(s)
+	}
+
+	type raw EthTypedData
+	var out raw
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&out); err != nil {
+		return err
+	}
+
+	*td = EthTypedData(out)
+	return nil
+}
+
+// SigningPayload returns 0x1901 || domainSeparator || hashStruct(message). Its keccak256 hash is the
+// digest signed by eth_signTypedData_v4.
+func (td *EthTypedData) SigningPayload() ([]byte, error) {
+	if _, ok := td.Types[td.PrimaryType]; !ok {
+		return nil, xerrors.Errorf("primary type %q is not defined", td.PrimaryType)
+	}
+
+	types := td.Types
+	if _, ok := types[eip712DomainType]; !ok {
+		// the domain type can be left out, in which case it's made of the domain fields present
+		types = make(map[string][]EthTypedDataField, len(td.Types)+1)
+		for name, fields := range td.Types {
+			types[name] = fields
+		}
+		types[eip712DomainType] = domainFields(td.Domain)
+	}
+	enc := typedDataEncoder{types: types}
+
+	domainSeparator, err := enc.hashStruct(eip712DomainType, td.Domain)
+	if err != nil {
+		return nil, xerrors.Errorf("hashing domain: %w", err)
+	}
+
+	message, err := enc.hashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, xerrors.Errorf("hashing message: %w", err)
+	}
+
+	out := []byte{0x19, 0x01}
+	out = append(out, domainSeparator...)
+	return append(out, message...), nil
+}
+
+func domainFields(domain map[string]interface{}) []EthTypedDataField {
+	var fields []EthTypedDataField
+	for _, f := range []EthTypedDataField{
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+		{Name: "salt", Type: "bytes32"},
+	} {
+		if _, ok := domain[f.Name]; ok {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+type typedDataEncoder struct {
+	types map[string][]EthTypedDataField
+}
+
+func keccak256(data ...[]byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		hasher.Write(d)
+	}
+	return hasher.Sum(nil)
+}
+
+// baseType strips array suffixes from a type, e.g. Person[][2] -> Person.
+func baseType(typ string) string {
+	if i := strings.IndexByte(typ, '['); i >= 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+func (e *typedDataEncoder) dependencies(typ string, found map[string]struct{}) {
+	typ = baseType(typ)
+	if _, ok := found[typ]; ok {
+		return
+	}
+	fields, ok := e.types[typ]
+	if !ok {
+		return
+	}
+
+	found[typ] = struct{}{}
+	for _, f := range fields {
+		e.dependencies(f.Type, found)
+	}
+}
+
+// encodeType returns the primary type followed by the types it references, sorted by name,
+// e.g. Mail(Person from,Person to,string contents)Person(string name,address wallet).
+func (e *typedDataEncoder) encodeType(primary string) string {
+	found := map[string]struct{}{}
+	e.dependencies(primary, found)
+	delete(found, primary)
+
+	deps := make([]string, 0, len(found))
+	for dep := range found {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+
+	var sb strings.Builder
+	for _, typ := range append([]string{primary}, deps...) {
+		sb.WriteString(typ)
+		sb.WriteByte('(')
+		for i, f := range e.types[typ] {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(f.Type)
+			sb.WriteByte(' ')
+			sb.WriteString(f.Name)
+		}
+		sb.WriteByte(')')
+	}
+	return sb.String()
+}
+
+func (e *typedDataEncoder) hashStruct(typ string, data map[string]interface{}) ([]byte, error) {
+	fields, ok := e.types[typ]
+	if !ok {
+		return nil, xerrors.Errorf("type %q is not defined", typ)
+	}
+
+	enc := [][]byte{keccak256([]byte(e.encodeType(typ)))}
+	for _, f := range fields {
+		v, ok := data[f.Name]
+		if !ok || v == nil {
+			return nil, xerrors.Errorf("%s.%s: missing value", typ, f.Name)
+		}
+
+		ev, err := e.encodeValue(f.Type, v)
+		if err != nil {
+			return nil, xerrors.Errorf("%s.%s: %w", typ, f.Name, err)
+		}
+		enc = append(enc, ev)
+	}
+
+	return keccak256(enc...), nil
+}
+
+// encodeValue returns the 32 byte encoding of a value of the given type.
+func (e *typedDataEncoder) encodeValue(typ string, v interface{}) ([]byte, error) {
+	if strings.HasSuffix(typ, "]") {
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, xerrors.Errorf("expected an array for %s, got %T", typ, v)
+		}
+
+		elem := typ[:strings.LastIndexByte(typ, '[')]
+		if n := typ[len(elem)+1 : len(typ)-1]; n != "" {
+			if size, err := strconv.Atoi(n); err != nil || size != len(items) {
+				return nil, xerrors.Errorf("expected %s elements for %s, got %d", n, typ, len(items))
+			}
+		}
+
+		enc := make([][]byte, len(items))
+		for i, item := range items {
+			ev, err := e.encodeValue(elem, item)
+			if err != nil {
+				return nil, xerrors.Errorf("[%d]: %w", i, err)
+			}
+			enc[i] = ev
+		}
+		return keccak256(enc...), nil
+	}
+
+	if _, ok := e.types[typ]; ok {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, xerrors.Errorf("expected an object for %s, got %T", typ, v)
+		}
+		return e.hashStruct(typ, m)
+	}
+
+	switch {
+	case typ == "string":
+		s, ok := v.(string)
+		if !ok {
+			return nil, xerrors.Errorf("expected a string, got %T", v)
+		}
+		return keccak256([]byte(s)), nil
+	case typ == "bytes":
+		b, err := typedDataBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		return keccak256(b), nil
+	case typ == "bool":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, xerrors.Errorf("expected a bool, got %T", v)
+		}
+		out := make([]byte, 32)
+		if b {
+			out[31] = 1
+		}
+		return out, nil
+	case typ == "address":
+		b, err := typedDataBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != EthAddressLength {
+			return nil, xerrors.Errorf("expected a %d byte address, got %d bytes", EthAddressLength, len(b))
+		}
+		return leftPad32(b), nil
+	case strings.HasPrefix(typ, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(typ, "bytes"))
+		if err != nil || size < 1 || size > 32 {
+			return nil, xerrors.Errorf("unknown type %q", typ)
+		}
+		b, err := typedDataBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != size {
+			return nil, xerrors.Errorf("expected %d bytes, got %d", size, len(b))
+		}
+		out := make([]byte, 32)
+		copy(out, b)
+		return out, nil
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		signed := strings.HasPrefix(typ, "int")
+		bits, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"))
+		if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, xerrors.Errorf("unknown type %q", typ)
+		}
+		return encodeTypedDataInt(v, bits, signed)
+	}
+
+	return nil, xerrors.Errorf("unknown type %q", typ)
+}
+
+func typedDataBytes(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, xerrors.Errorf("expected a hex string, got %T", v)
+	}
+	if !strings.HasPrefix(s, "0x") {
+		return nil, xerrors.Errorf("expected a 0x prefixed hex string, got %q", s)
+	}
+	return DecodeHexString(s)
+}
+
+func leftPad32(b []byte) []byte {
+	out := make([]byte, 32)
+	copy(out[32-len(b):], b)
+	return out
+}
+
+func encodeTypedDataInt(v interface{}, bits int, signed bool) ([]byte, error) {
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, xerrors.Errorf("expected a number, got %T", v)
+	}
+
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, xerrors.Errorf("invalid integer %q", s)
+	}
+
+	lo, hi := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	if signed {
+		hi.Rsh(hi, 1)
+		lo.Neg(hi)
+	}
+	if n.Cmp(lo) < 0 || n.Cmp(hi) >= 0 {
+		return nil, xerrors.Errorf("%s out of range for %d bit integer", s, bits)
+	}
+
+	if n.Sign() < 0 {
+		// two's complement
+		n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return leftPad32(n.Bytes()), nil
+}
@@ -0,0 +1,105 @@
+package ethtypes
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The example from EIP-712, https://eips.ethereum.org/EIPS/eip-712
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestEthTypedDataSigningPayload(t *testing.T) {
+	var td EthTypedData
+	require.NoError(t, json.Unmarshal([]byte(mailTypedData), &td))
+
+	enc := typedDataEncoder{types: td.Types}
+	require.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", enc.encodeType("Mail"))
+
+	payload, err := td.SigningPayload()
+	require.NoError(t, err)
+	require.Equal(t, "1901"+
+		"f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"+
+		"c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e", hex.EncodeToString(payload))
+	require.Equal(t, "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hex.EncodeToString(keccak256(payload)))
+
+	// the same data sent as a JSON string
+	str, err := json.Marshal(mailTypedData)
+	require.NoError(t, err)
+	var tds EthTypedData
+	require.NoError(t, json.Unmarshal(str, &tds))
+	payloadStr, err := tds.SigningPayload()
+	require.NoError(t, err)
+	require.Equal(t, payload, payloadStr)
+
+	// without the domain type, it's made of the domain fields
+	delete(td.Types, eip712DomainType)
+	payloadNoDomain, err := td.SigningPayload()
+	require.NoError(t, err)
+	require.Equal(t, payload, payloadNoDomain)
+}
+
+func TestEthTypedDataValues(t *testing.T) {
+	enc := typedDataEncoder{}
+
+	testcases := []struct {
+		typ string
+		v   interface{}
+		out string
+		err bool
+	}{
+		{"uint8", json.Number("255"), "00000000000000000000000000000000000000000000000000000000000000ff", false},
+		{"uint8", json.Number("256"), "", true},
+		{"uint256", "0x10", "0000000000000000000000000000000000000000000000000000000000000010", false},
+		{"int16", json.Number("-1"), "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", false},
+		{"int16", json.Number("-32769"), "", true},
+		{"bool", true, "0000000000000000000000000000000000000000000000000000000000000001", false},
+		{"bytes4", "0xdeadbeef", "deadbeef00000000000000000000000000000000000000000000000000000000", false},
+		{"bytes4", "0xdead", "", true},
+		{"address", "0x0000000000000000000000000000000000000001", "0000000000000000000000000000000000000000000000000000000000000001", false},
+		{"uint8[2]", []interface{}{json.Number("1")}, "", true},
+		{"float", json.Number("1"), "", true},
+	}
+
+	for _, tc := range testcases {
+		out, err := enc.encodeValue(tc.typ, tc.v)
+		if tc.err {
+			require.Error(t, err, tc.typ)
+			continue
+		}
+		require.NoError(t, err, tc.typ)
+		require.Equal(t, tc.out, hex.EncodeToString(out), tc.typ)
+	}
+}
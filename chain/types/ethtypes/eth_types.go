@@ -254,9 +254,9 @@ func (sr EthSyncingResult) MarshalJSON() ([]byte, error) {
 
 	// need to do an anonymous struct to avoid infinite recursion
 	return json.Marshal(&struct {
-		StartingBlock EthUint64 `json:"startingblock"`
-		CurrentBlock  EthUint64 `json:"currentblock"`
-		HighestBlock  EthUint64 `json:"highestblock"`
+		StartingBlock EthUint64 `json:"startingBlock"`
+		CurrentBlock  EthUint64 `json:"currentBlock"`
+		HighestBlock  EthUint64 `json:"highestBlock"`
 	}{
 		StartingBlock: sr.StartingBlock,
 		CurrentBlock:  sr.CurrentBlock,
@@ -73,13 +73,13 @@ func (f FIL) MarshalText() (text []byte, err error) {
 	return []byte(f.String()), nil
 }
 
-func (f FIL) UnmarshalText(text []byte) error {
+func (f *FIL) UnmarshalText(text []byte) error {
 	p, err := ParseFIL(string(text))
 	if err != nil {
 		return err
 	}
 
-	f.Int.Set(p.Int)
+	*f = p
 	return nil
 }
 
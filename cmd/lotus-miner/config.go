@@ -30,8 +30,9 @@ var configDefaultCmd = &cli.Command{
 	},
 	Action: func(cctx *cli.Context) error {
 		c := config.DefaultStorageMiner()
+		c.Version = config.CurrentConfigVersion
 
-		cb, err := config.ConfigUpdate(c, nil, config.Commented(!cctx.Bool("no-comment")))
+		cb, err := config.ConfigUpdate(c, nil, config.Commented(!cctx.Bool("no-comment")), config.KeepUncommented(config.MatchVersionField))
 		if err != nil {
 			return err
 		}
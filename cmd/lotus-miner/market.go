@@ -352,6 +352,7 @@ var storageDealsCmd = &cli.Command{
 		resetBlocklistCmd,
 		setSealDurationCmd,
 		dealsPendingPublish,
+		dealsPublishCmd,
 		dealsRetryPublish,
 	},
 }
@@ -914,9 +915,14 @@ var dealsPendingPublish = &cli.Command{
 		if len(pending.Deals) > 0 {
 			endsIn := pending.PublishPeriodStart.Add(pending.PublishPeriod).Sub(time.Now())
 			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
-			_, _ = fmt.Fprintf(w, "Publish period:             %s (ends in %s)\n", pending.PublishPeriod, endsIn.Round(time.Second))
-			_, _ = fmt.Fprintf(w, "First deal queued at:       %s\n", pending.PublishPeriodStart)
-			_, _ = fmt.Fprintf(w, "Deals will be published at: %s\n", pending.PublishPeriodStart.Add(pending.PublishPeriod))
+			if pending.PublishPeriodStart.IsZero() {
+				// no publish period is running with manual publishing
+				_, _ = fmt.Fprintf(w, "Deals will be published with: lotus-miner storage-deals publish\n")
+			} else {
+				_, _ = fmt.Fprintf(w, "Publish period:             %s (ends in %s)\n", pending.PublishPeriod, endsIn.Round(time.Second))
+				_, _ = fmt.Fprintf(w, "First deal queued at:       %s\n", pending.PublishPeriodStart)
+				_, _ = fmt.Fprintf(w, "Deals will be published at: %s\n", pending.PublishPeriodStart.Add(pending.PublishPeriod))
+			}
 			_, _ = fmt.Fprintf(w, "%d deals queued to be published:\n", len(pending.Deals))
 			_, _ = fmt.Fprintf(w, "ProposalCID\tClient\tSize\n")
 			for _, deal := range pending.Deals {
@@ -935,6 +941,37 @@ var dealsPendingPublish = &cli.Command{
 	},
 }
 
+var dealsPublishCmd = &cli.Command{
+	Name:  "publish",
+	Usage: "publish all deals waiting in publish queue",
+	Description: `Sends publish messages for the deals waiting in the publish queue, with at most
+   MaxDealsPerPublishMsg deals per message. With Dealmaking.AutoPublishEnabled disabled
+   in the config, deals are only published with this command.`,
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := lcli.GetMarketsAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := lcli.ReqContext(cctx)
+
+		pending, err := api.MarketPendingDeals(ctx)
+		if err != nil {
+			return xerrors.Errorf("getting pending deals: %w", err)
+		}
+		if len(pending.Deals) == 0 {
+			fmt.Println("No deals queued to be published")
+			return nil
+		}
+
+		if err := api.MarketPublishPendingDeals(ctx); err != nil {
+			return xerrors.Errorf("publishing deals: %w", err)
+		}
+		fmt.Printf("triggered publishing of %d deals\n", len(pending.Deals))
+		return nil
+	},
+}
+
 var dealsRetryPublish = &cli.Command{
 	Name:      "retry-publish",
 	Usage:     "retry publishing a deal",
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	_ "net/http/pprof"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/lotus/api"
@@ -17,6 +19,8 @@ import (
 	"github.com/filecoin-project/lotus/api/v1api"
 	"github.com/filecoin-project/lotus/build"
 	lcli "github.com/filecoin-project/lotus/cli"
+	"github.com/filecoin-project/lotus/lib/requestid"
+	"github.com/filecoin-project/lotus/lib/tracing"
 	"github.com/filecoin-project/lotus/lib/ulimit"
 	"github.com/filecoin-project/lotus/metrics"
 	"github.com/filecoin-project/lotus/node"
@@ -137,6 +141,10 @@ var runCmd = &cli.Command{
 			return err
 		}
 
+		if err := cfg.Validate(); err != nil {
+			return xerrors.Errorf("validating config: %w", err)
+		}
+
 		shutdownChan := make(chan struct{})
 
 		var minerapi api.StorageMiner
@@ -177,22 +185,50 @@ var runCmd = &cli.Command{
 
 		log.Infof("Remote version %s", v)
 
+		var tracer trace.Tracer
+		if cfg.API.OpenTelemetryEndpoint != "" {
+			tp, err := tracing.SetupOTLPTracing(ctx, cfg.API.OpenTelemetryEndpoint, cfg.API.OpenTelemetryServiceName)
+			if err != nil {
+				return xerrors.Errorf("setting up otlp tracing: %w", err)
+			}
+			defer func() {
+				_ = tp.Shutdown(context.Background())
+			}()
+			tracer = tp.Tracer(cfg.API.OpenTelemetryServiceName)
+		}
+
 		// Instantiate the miner node handler.
-		handler, err := node.MinerHandler(minerapi, true)
+		handler, err := node.MinerHandler(minerapi, true, node.APICallOptions(&cfg.API, tracer), node.APIServerOptions(&cfg.API)...)
 		if err != nil {
 			return xerrors.Errorf("failed to instantiate rpc handler: %w", err)
 		}
+		handler = node.ResponseSizeLimitHandler(handler, cfg.API.MaxResponseBodySize)
+		handler = node.MetricsBasicAuthHandler(handler, cfg.API.PrometheusBasicAuthUser, cfg.API.PrometheusBasicAuthPass)
+		if cfg.API.RequestIDHeader != "" {
+			handler = requestid.Handler(cfg.API.RequestIDHeader, handler)
+		}
+		handler, err = node.ProxyHeadersHandler(handler, cfg.API.HTTPProxyHeaders, cfg.API.TrustedSubnets)
+		if err != nil {
+			return xerrors.Errorf("setting up proxy headers: %w", err)
+		}
 
 		// Serve the RPC.
 		rpcStopper, err := node.ServeRPC(handler, "lotus-miner", endpoint)
 		if err != nil {
 			return fmt.Errorf("failed to start json-rpc endpoint: %s", err)
 		}
+		shutdownHandlers := []node.ShutdownHandler{{Component: "rpc server", StopFunc: rpcStopper}}
+		if cfg.API.UnixSocketPath != "" {
+			unixStopper, err := node.ServeRPCUnix(handler, "lotus-miner", cfg.API.UnixSocketPath)
+			if err != nil {
+				return fmt.Errorf("failed to start json-rpc unix socket endpoint: %s", err)
+			}
+			shutdownHandlers = append(shutdownHandlers, node.ShutdownHandler{Component: "rpc unix socket server", StopFunc: unixStopper})
+		}
 
 		// Monitor for shutdown.
 		finishCh := node.MonitorShutdown(shutdownChan,
-			node.ShutdownHandler{Component: "rpc server", StopFunc: rpcStopper},
-			node.ShutdownHandler{Component: "miner", StopFunc: stop},
+			append(shutdownHandlers, node.ShutdownHandler{Component: "miner", StopFunc: stop})...,
 		)
 
 		<-finishCh
@@ -30,6 +30,7 @@ var configDefaultCmd = &cli.Command{
 	},
 	Action: func(cctx *cli.Context) error {
 		c := config.DefaultFullNode()
+		c.Version = config.CurrentConfigVersion
 
 		cb, err := config.ConfigUpdate(c, nil, config.Commented(!cctx.Bool("no-comment")), config.DefaultKeepUncommented())
 		if err != nil {
@@ -25,6 +25,7 @@ import (
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/xerrors"
 	"gopkg.in/cheggaaa/pb.v1"
 
@@ -46,6 +47,8 @@ import (
 	"github.com/filecoin-project/lotus/journal/fsjournal"
 	"github.com/filecoin-project/lotus/lib/httpreader"
 	"github.com/filecoin-project/lotus/lib/peermgr"
+	"github.com/filecoin-project/lotus/lib/requestid"
+	"github.com/filecoin-project/lotus/lib/tracing"
 	"github.com/filecoin-project/lotus/lib/ulimit"
 	"github.com/filecoin-project/lotus/metrics"
 	"github.com/filecoin-project/lotus/node"
@@ -368,6 +371,17 @@ var DaemonCmd = &cli.Command{
 			log.Warnf("unable to inject prometheus ipfs/go-metrics exporter; some metrics will be unavailable; err: %s", err)
 		}
 
+		// read the config before the node takes the repo lock
+		cfg, err := readFullNodeConfig(r)
+		if err != nil {
+			return err
+		}
+
+		if n := cfg.Chainstore.ActorMigrationParallelism; n != 0 {
+			filcns.MigrationMaxWorkerCount = n
+			log.Infof("migration worker count set from Chainstore.ActorMigrationParallelism (%d)", n)
+		}
+
 		var api lapi.FullNode
 		stop, err := node.New(ctx,
 			node.FullAPI(&api, node.Lite(isLite)),
@@ -415,26 +429,65 @@ var DaemonCmd = &cli.Command{
 		// ----
 
 		// Populate JSON-RPC options.
-		serverOptions := []jsonrpc.ServerOption{jsonrpc.WithServerErrors(lapi.RPCErrors)}
-		if maxRequestSize := cctx.Int("api-max-req-size"); maxRequestSize != 0 {
-			serverOptions = append(serverOptions, jsonrpc.WithMaxRequestSize(int64(maxRequestSize)))
+		serverOptions := append(node.APIServerOptions(&cfg.API), jsonrpc.WithServerErrors(lapi.RPCErrors))
+		maxRequestSize := node.APIMaxRequestSize(&cfg.API)
+		if flagSize := cctx.Int("api-max-req-size"); flagSize != 0 {
+			maxRequestSize = int64(flagSize)
+			serverOptions = append(serverOptions, jsonrpc.WithMaxRequestSize(maxRequestSize))
+		}
+
+		var tracer trace.Tracer
+		if cfg.API.OpenTelemetryEndpoint != "" {
+			tp, err := tracing.SetupOTLPTracing(ctx, cfg.API.OpenTelemetryEndpoint, cfg.API.OpenTelemetryServiceName)
+			if err != nil {
+				return xerrors.Errorf("setting up otlp tracing: %w", err)
+			}
+			defer func() {
+				_ = tp.Shutdown(context.Background())
+			}()
+			tracer = tp.Tracer(cfg.API.OpenTelemetryServiceName)
 		}
 
 		// Instantiate the full node handler.
-		h, err := node.FullNodeHandler(api, true, serverOptions...)
+		h, err := node.FullNodeHandler(api, true, node.APICallOptions(&cfg.API, tracer), serverOptions...)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate rpc handler: %s", err)
 		}
+		if cfg.Fevm.EthGetBlockByHashFullTx {
+			h = node.EthGetBlockByHashDefaultHandler(h, maxRequestSize)
+		}
+		if cfg.Fevm.EnableEthBatchRequests {
+			h = node.ParallelBatchHandler(h, cfg.Fevm.EthBatchRequestMaxSize, maxRequestSize)
+		}
+		h = node.ResponseSizeLimitHandler(h, cfg.API.MaxResponseBodySize)
+		h = node.MetricsBasicAuthHandler(h, cfg.API.PrometheusBasicAuthUser, cfg.API.PrometheusBasicAuthPass)
+		if cfg.API.SseEnabled {
+			h = node.SSEHandler(h, api)
+		}
+		if cfg.API.RequestIDHeader != "" {
+			h = requestid.Handler(cfg.API.RequestIDHeader, h)
+		}
+		h, err = node.ProxyHeadersHandler(h, cfg.API.HTTPProxyHeaders, cfg.API.TrustedSubnets)
+		if err != nil {
+			return xerrors.Errorf("setting up proxy headers: %w", err)
+		}
 
 		// Serve the RPC.
 		rpcStopper, err := node.ServeRPC(h, "lotus-daemon", endpoint)
 		if err != nil {
 			return fmt.Errorf("failed to start json-rpc endpoint: %s", err)
 		}
+		shutdownHandlers := []node.ShutdownHandler{{Component: "rpc server", StopFunc: rpcStopper}}
+		if cfg.API.UnixSocketPath != "" {
+			unixStopper, err := node.ServeRPCUnix(h, "lotus-daemon", cfg.API.UnixSocketPath)
+			if err != nil {
+				return fmt.Errorf("failed to start json-rpc unix socket endpoint: %s", err)
+			}
+			shutdownHandlers = append(shutdownHandlers, node.ShutdownHandler{Component: "rpc unix socket server", StopFunc: unixStopper})
+		}
 		// Monitor for shutdown.
 		finishCh := node.MonitorShutdown(shutdownChan,
-			node.ShutdownHandler{Component: "rpc server", StopFunc: rpcStopper},
-			node.ShutdownHandler{Component: "node", StopFunc: stop},
+			append(shutdownHandlers, node.ShutdownHandler{Component: "node", StopFunc: stop})...,
 		)
 		<-finishCh // fires when shutdown is complete.
 
@@ -684,3 +737,27 @@ func deleteSplitstoreDir(lr repo.LockedRepo) error {
 
 	return os.RemoveAll(path)
 }
+
+func readFullNodeConfig(r repo.Repo) (*config.FullNode, error) {
+	lr, err := r.Lock(repo.FullNode)
+	if err != nil {
+		return nil, xerrors.Errorf("locking repo: %w", err)
+	}
+	defer lr.Close() //nolint:errcheck
+
+	c, err := lr.Config()
+	if err != nil {
+		return nil, xerrors.Errorf("loading config: %w", err)
+	}
+
+	cfg, ok := c.(*config.FullNode)
+	if !ok {
+		return nil, xerrors.Errorf("invalid config for repo, got: %T", c)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, xerrors.Errorf("validating config: %w", err)
+	}
+
+	return cfg, nil
+}
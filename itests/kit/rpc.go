@@ -44,7 +44,7 @@ func CreateRPCServer(t *testing.T, handler http.Handler, listener net.Listener)
 }
 
 func fullRpc(t *testing.T, f *TestFullNode) (*TestFullNode, Closer) {
-	handler, err := node.FullNodeHandler(f.FullNode, false)
+	handler, err := node.FullNodeHandler(f.FullNode, false, nil)
 	require.NoError(t, err)
 
 	l, err := net.Listen("tcp", "127.0.0.1:0")
@@ -67,7 +67,7 @@ func fullRpc(t *testing.T, f *TestFullNode) (*TestFullNode, Closer) {
 }
 
 func minerRpc(t *testing.T, m *TestMiner) *TestMiner {
-	handler, err := node.MinerHandler(m.StorageMiner, false)
+	handler, err := node.MinerHandler(m.StorageMiner, false, nil)
 	require.NoError(t, err)
 
 	srv, maddr, _ := CreateRPCServer(t, handler, m.RemoteListener)
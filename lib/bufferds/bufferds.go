@@ -0,0 +1,186 @@
+// Package bufferds implements a datastore wrapper which buffers writes in
+// memory and writes them to the wrapped datastore in batches.
+package bufferds
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("bufferds")
+
+// Datastore buffers Puts and Deletes in memory, writing them to the wrapped
+// datastore as a single batch, followed by a Sync, every flush interval.
+// Reads see buffered writes; queries flush the buffer first.
+type Datastore struct {
+	child datastore.Batching
+
+	lk  sync.Mutex
+	buf map[datastore.Key][]byte // nil values are buffered deletes
+
+	closing chan struct{}
+	closed  chan struct{}
+}
+
+var _ datastore.Batching = &Datastore{}
+
+// Wrap starts flushing writes buffered for child every interval. Close must be
+// called to flush the remaining writes.
+func Wrap(child datastore.Batching, interval time.Duration) *Datastore {
+	d := &Datastore{
+		child: child,
+		buf:   map[datastore.Key][]byte{},
+
+		closing: make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+
+	go d.run(interval)
+
+	return d
+}
+
+func (d *Datastore) run(interval time.Duration) {
+	defer close(d.closed)
+
+	tk := time.NewTicker(interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			if err := d.Flush(context.TODO()); err != nil {
+				log.Errorw("flushing buffered writes", "error", err)
+			}
+		case <-d.closing:
+			return
+		}
+	}
+}
+
+// Flush writes the buffered writes to the wrapped datastore and syncs them.
+// The writes stay buffered if that fails.
+func (d *Datastore) Flush(ctx context.Context) error {
+	d.lk.Lock()
+	defer d.lk.Unlock()
+
+	if len(d.buf) == 0 {
+		return nil
+	}
+
+	b, err := d.child.Batch(ctx)
+	if err != nil {
+		return xerrors.Errorf("creating batch: %w", err)
+	}
+
+	for k, v := range d.buf {
+		if v == nil {
+			err = b.Delete(ctx, k)
+		} else {
+			err = b.Put(ctx, k, v)
+		}
+		if err != nil {
+			return xerrors.Errorf("batching write of %s: %w", k, err)
+		}
+	}
+
+	if err := b.Commit(ctx); err != nil {
+		return xerrors.Errorf("committing batch: %w", err)
+	}
+	if err := d.child.Sync(ctx, datastore.NewKey("/")); err != nil {
+		return xerrors.Errorf("syncing: %w", err)
+	}
+
+	d.buf = map[datastore.Key][]byte{}
+	return nil
+}
+
+func (d *Datastore) Get(ctx context.Context, key datastore.Key) ([]byte, error) {
+	d.lk.Lock()
+	v, ok := d.buf[key]
+	d.lk.Unlock()
+
+	if !ok {
+		return d.child.Get(ctx, key)
+	}
+	if v == nil {
+		return nil, datastore.ErrNotFound
+	}
+	return append([]byte{}, v...), nil
+}
+
+func (d *Datastore) Has(ctx context.Context, key datastore.Key) (bool, error) {
+	d.lk.Lock()
+	v, ok := d.buf[key]
+	d.lk.Unlock()
+
+	if !ok {
+		return d.child.Has(ctx, key)
+	}
+	return v != nil, nil
+}
+
+func (d *Datastore) GetSize(ctx context.Context, key datastore.Key) (int, error) {
+	d.lk.Lock()
+	v, ok := d.buf[key]
+	d.lk.Unlock()
+
+	if !ok {
+		return d.child.GetSize(ctx, key)
+	}
+	if v == nil {
+		return -1, datastore.ErrNotFound
+	}
+	return len(v), nil
+}
+
+func (d *Datastore) Query(ctx context.Context, q query.Query) (query.Results, error) {
+	if err := d.Flush(ctx); err != nil {
+		return nil, xerrors.Errorf("flushing before query: %w", err)
+	}
+
+	return d.child.Query(ctx, q)
+}
+
+func (d *Datastore) Put(ctx context.Context, key datastore.Key, value []byte) error {
+	if value == nil {
+		value = []byte{}
+	}
+
+	d.lk.Lock()
+	defer d.lk.Unlock()
+
+	d.buf[key] = append([]byte{}, value...)
+	return nil
+}
+
+func (d *Datastore) Delete(ctx context.Context, key datastore.Key) error {
+	d.lk.Lock()
+	defer d.lk.Unlock()
+
+	d.buf[key] = nil
+	return nil
+}
+
+func (d *Datastore) Sync(ctx context.Context, prefix datastore.Key) error {
+	return d.Flush(ctx)
+}
+
+func (d *Datastore) Batch(ctx context.Context) (datastore.Batch, error) {
+	return datastore.NewBasicBatch(d), nil
+}
+
+// Close stops the periodic flushes and flushes the remaining buffered writes.
+// The wrapped datastore isn't closed.
+func (d *Datastore) Close() error {
+	close(d.closing)
+	<-d.closed
+
+	return d.Flush(context.TODO())
+}
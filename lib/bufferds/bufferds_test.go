@@ -0,0 +1,69 @@
+package bufferds
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferedWrites(t *testing.T) {
+	ctx := context.Background()
+	child := datastore.NewMapDatastore()
+	require.NoError(t, child.Put(ctx, datastore.NewKey("/old"), []byte("old")))
+
+	d := Wrap(child, time.Hour)
+
+	require.NoError(t, d.Put(ctx, datastore.NewKey("/new"), []byte("new")))
+	require.NoError(t, d.Delete(ctx, datastore.NewKey("/old")))
+
+	// buffered writes are visible, but not written through yet
+	v, err := d.Get(ctx, datastore.NewKey("/new"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("new"), v)
+	_, err = d.Get(ctx, datastore.NewKey("/old"))
+	require.ErrorIs(t, err, datastore.ErrNotFound)
+
+	has, err := child.Has(ctx, datastore.NewKey("/new"))
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// queries flush the buffer
+	res, err := d.Query(ctx, query.Query{KeysOnly: true})
+	require.NoError(t, err)
+	entries, err := res.Rest()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "/new", entries[0].Key)
+
+	has, err = child.Has(ctx, datastore.NewKey("/old"))
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// close flushes the remaining writes
+	require.NoError(t, d.Put(ctx, datastore.NewKey("/last"), []byte("last")))
+	require.NoError(t, d.Close())
+
+	v, err = child.Get(ctx, datastore.NewKey("/last"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("last"), v)
+}
+
+func TestPeriodicFlush(t *testing.T) {
+	ctx := context.Background()
+	child := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	d := Wrap(child, 10*time.Millisecond)
+	defer d.Close() //nolint:errcheck
+
+	require.NoError(t, d.Put(ctx, datastore.NewKey("/k"), []byte("v")))
+
+	require.Eventually(t, func() bool {
+		has, err := child.Has(ctx, datastore.NewKey("/k"))
+		return err == nil && has
+	}, time.Second, 10*time.Millisecond)
+}
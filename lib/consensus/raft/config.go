@@ -20,6 +20,10 @@ var (
 	DefaultNetworkTimeout       = 100 * time.Second
 	DefaultCommitRetryDelay     = 200 * time.Millisecond
 	DefaultBackupsRotate        = 6
+
+	DefaultPeerDiscoveryRetries  = 3
+	DefaultPeerDiscoveryInterval = 5 * time.Second
+	DefaultLeaderElectionTimeout = 10 * time.Second
 )
 
 // ClusterRaftConfig allows to configure the Raft Consensus component for the node cluster.
@@ -46,6 +50,17 @@ type ClusterRaftConfig struct {
 	// BackupsRotate specifies the maximum number of Raft's DataFolder
 	// copies that we keep as backups (renaming) after cleanup.
 	BackupsRotate int
+	// SnapshotCompression enables zstd compression of Raft snapshots.
+	SnapshotCompression bool
+	// PeerDiscoveryRetries specifies how many times to retry reaching a
+	// quorum of the initial peerset on bootstrap before giving up.
+	PeerDiscoveryRetries int
+	// PeerDiscoveryInterval specifies how long to wait between peer
+	// discovery attempts.
+	PeerDiscoveryInterval time.Duration
+	// LeaderElectionTimeout specifies how long to wait for a leader to be
+	// elected once a quorum of peers has been reached.
+	LeaderElectionTimeout time.Duration
 	// A Hashicorp Raft's configuration object.
 	RaftConfig *hraft.Config
 
@@ -62,6 +77,9 @@ func DefaultClusterRaftConfig() *ClusterRaftConfig {
 	cfg.CommitRetries = DefaultCommitRetries
 	cfg.CommitRetryDelay = DefaultCommitRetryDelay
 	cfg.BackupsRotate = DefaultBackupsRotate
+	cfg.PeerDiscoveryRetries = DefaultPeerDiscoveryRetries
+	cfg.PeerDiscoveryInterval = DefaultPeerDiscoveryInterval
+	cfg.LeaderElectionTimeout = DefaultLeaderElectionTimeout
 	cfg.RaftConfig = hraft.DefaultConfig()
 
 	// These options are imposed over any Default Raft Config.
@@ -82,6 +100,10 @@ func NewClusterRaftConfig(userRaftConfig *config.UserRaftConfig) *ClusterRaftCon
 	cfg.CommitRetries = userRaftConfig.CommitRetries
 	cfg.CommitRetryDelay = time.Duration(userRaftConfig.CommitRetryDelay)
 	cfg.BackupsRotate = userRaftConfig.BackupsRotate
+	cfg.SnapshotCompression = userRaftConfig.SnapshotCompression
+	cfg.PeerDiscoveryRetries = userRaftConfig.PeerDiscoveryRetries
+	cfg.PeerDiscoveryInterval = time.Duration(userRaftConfig.PeerDiscoveryInterval)
+	cfg.LeaderElectionTimeout = time.Duration(userRaftConfig.LeaderElectionTimeout)
 
 	// Keep this to be default hraft config for now
 	cfg.RaftConfig = hraft.DefaultConfig()
@@ -123,6 +145,18 @@ func ValidateConfig(cfg *ClusterRaftConfig) error {
 		return xerrors.Errorf("backups_rotate should be larger than 0")
 	}
 
+	if cfg.PeerDiscoveryRetries < 0 {
+		return xerrors.Errorf("peer_discovery_retries is invalid")
+	}
+
+	if cfg.PeerDiscoveryInterval < 0 {
+		return xerrors.Errorf("peer_discovery_interval is invalid")
+	}
+
+	if cfg.LeaderElectionTimeout <= 0 {
+		return xerrors.Errorf("leader_election_timeout <= 0")
+	}
+
 	return hraft.ValidateConfig(cfg.RaftConfig)
 }
 
@@ -7,9 +7,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"sort"
 	"time"
 
+	"github.com/DataDog/zstd"
 	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
 
@@ -50,13 +52,73 @@ type RaftState struct {
 	// signed message which holds a copy of the unsigned message to properly perform all the
 	// needed checks
 	Mpool *messagepool.MessagePool
+
+	// compressSnapshots makes Marshal compress snapshots with zstd
+	compressSnapshots bool
 }
 
-func newRaftState(mpool *messagepool.MessagePool) *RaftState {
+func newRaftState(mpool *messagepool.MessagePool, compressSnapshots bool) *RaftState {
 	return &RaftState{
-		NonceMap: make(map[addr.Address]uint64),
-		MsgUuids: make(map[uuid.UUID]*types.SignedMessage),
-		Mpool:    mpool,
+		NonceMap:          make(map[addr.Address]uint64),
+		MsgUuids:          make(map[uuid.UUID]*types.SignedMessage),
+		Mpool:             mpool,
+		compressSnapshots: compressSnapshots,
+	}
+}
+
+// Snapshot format versions, written as the first byte of a snapshot. Snapshots
+// written before versioning was introduced start directly with the msgpack
+// encoded state, which never begins with one of these bytes (msgpack maps start
+// with 0x80-0x8f or 0xde-0xdf).
+const (
+	snapshotVersionRaw  byte = 1
+	snapshotVersionZstd byte = 2
+)
+
+// rawRaftState has the fields of RaftState but doesn't implement
+// libp2praft.Marshable, so it's encoded using the default msgpack encoding.
+type rawRaftState RaftState
+
+var _ libp2praft.Marshable = (*RaftState)(nil)
+
+// Marshal implements libp2praft.Marshable. Without compression it writes the
+// unversioned msgpack encoded state, so that snapshots stay readable by cluster
+// members which don't know the versioned format yet; with compression it writes
+// a version byte followed by the zstd compressed state.
+func (s *RaftState) Marshal(w io.Writer) error {
+	if !s.compressSnapshots {
+		return libp2praft.EncodeSnapshot((*rawRaftState)(s), w)
+	}
+
+	if _, err := w.Write([]byte{snapshotVersionZstd}); err != nil {
+		return fmt.Errorf("writing snapshot version: %w", err)
+	}
+	zw := zstd.NewWriter(w)
+	if err := libp2praft.EncodeSnapshot((*rawRaftState)(s), zw); err != nil {
+		_ = zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// Unmarshal implements libp2praft.Marshable. It reads unversioned snapshots as
+// well as the raw (version 1) and zstd compressed (version 2) formats.
+func (s *RaftState) Unmarshal(r io.Reader) error {
+	var hdr [1]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return fmt.Errorf("reading snapshot header: %w", err)
+	}
+
+	switch hdr[0] {
+	case snapshotVersionRaw:
+		return libp2praft.DecodeSnapshot((*rawRaftState)(s), r)
+	case snapshotVersionZstd:
+		zr := zstd.NewReader(r)
+		defer zr.Close() //nolint:errcheck
+		return libp2praft.DecodeSnapshot((*rawRaftState)(s), zr)
+	default:
+		// legacy unversioned snapshot, the header byte is part of the state
+		return libp2praft.DecodeSnapshot((*rawRaftState)(s), io.MultiReader(bytes.NewReader(hdr[:]), r))
 	}
 }
 
@@ -137,7 +199,7 @@ func NewConsensus(host host.Host, cfg *ClusterRaftConfig, mpool *messagepool.Mes
 	ctx, cancel := context.WithCancel(context.Background())
 
 	logger.Debug("starting Consensus and waiting for a leader...")
-	state := newRaftState(mpool)
+	state := newRaftState(mpool, cfg.SnapshotCompression)
 
 	consensus := libp2praft.NewOpLog(state, &ConsensusOp{})
 
@@ -207,13 +269,19 @@ func NewConsensusWithRPCClient(staging bool) func(host host.Host,
 // WaitForSync waits for a leader and for the state to be up to date, then returns.
 func (cc *Consensus) WaitForSync(ctx context.Context) error {
 
-	leaderCtx, cancel := context.WithTimeout(ctx, cc.config.WaitForLeaderTimeout)
-	defer cancel()
-
+	// 0 - wait for a quorum of the initial peerset, retrying as configured
 	// 1 - wait for leader
 	// 2 - wait until we are a Voter
 	// 3 - wait until last index is applied
 
+	err := retryPeerDiscovery(ctx, cc.config.PeerDiscoveryRetries, cc.config.PeerDiscoveryInterval, cc.discoverPeers)
+	if err != nil {
+		return errors.New("error discovering cluster peers: " + err.Error())
+	}
+
+	leaderCtx, cancel := context.WithTimeout(ctx, cc.config.LeaderElectionTimeout)
+	defer cancel()
+
 	// From raft docs:
 
 	// once a staging server receives enough log entries to be sufficiently
@@ -224,7 +292,7 @@ func (cc *Consensus) WaitForSync(ctx context.Context) error {
 	// up to date state. Otherwise, we might return too early (see
 	// https://github.com/ipfs-cluster/ipfs-cluster/issues/378)
 
-	_, err := cc.raft.WaitForLeader(leaderCtx)
+	_, err = cc.raft.WaitForLeader(leaderCtx)
 	if err != nil {
 		return errors.New("error waiting for leader: " + err.Error())
 	}
@@ -241,6 +309,57 @@ func (cc *Consensus) WaitForSync(ctx context.Context) error {
 	return nil
 }
 
+// discoverPeers waits, up to WaitForLeaderTimeout, until this node is
+// connected to a quorum of the initial peerset.
+func (cc *Consensus) discoverPeers(ctx context.Context) error {
+	if len(cc.peerSet) == 0 {
+		return nil // existing cluster, the peerset comes from the raft state
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cc.config.WaitForLeaderTimeout)
+	defer cancel()
+
+	quorum := len(cc.peerSet)/2 + 1
+	connected := 0
+	for _, p := range cc.peerSet {
+		if p == cc.host.ID() {
+			connected++
+			continue
+		}
+		if err := cc.host.Connect(ctx, cc.host.Peerstore().PeerInfo(p)); err != nil {
+			logger.Debugf("connecting to cluster peer %s: %s", p, err)
+			continue
+		}
+		connected++
+	}
+
+	if connected < quorum {
+		return fmt.Errorf("connected to %d of %d cluster peers, need %d", connected, len(cc.peerSet), quorum)
+	}
+	return nil
+}
+
+// retryPeerDiscovery calls discover until it succeeds, at most retries+1
+// times, waiting interval between attempts.
+func retryPeerDiscovery(ctx context.Context, retries int, interval time.Duration, discover func(context.Context) error) error {
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			logger.Infof("cluster peer discovery failed (attempt %d of %d): %s; retrying in %s", attempt, retries+1, err, interval)
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err = discover(ctx); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
 // waits until there is a consensus leader and syncs the state
 // to the tracker. If errors happen, this will return and never
 // signal the component as Ready.
@@ -262,6 +381,7 @@ func (cc *Consensus) finishBootstrap() {
 	logger.Debugf("Bootstrap finished")
 	err = cc.WaitForSync(cc.ctx)
 	if err != nil {
+		logger.Errorf("raft consensus failed to start: %s", err)
 		return
 	}
 	logger.Debug("Raft state is now up to date")
@@ -447,7 +567,7 @@ func (cc *Consensus) RmPeer(ctx context.Context, pid peer.ID) error {
 func (cc *Consensus) State(ctx context.Context) (*RaftState, error) {
 	st, err := cc.consensus.GetLogHead()
 	if err == libp2praft.ErrNoState {
-		return newRaftState(nil), nil
+		return newRaftState(nil, false), nil
 	}
 
 	if err != nil {
@@ -0,0 +1,97 @@
+package consensus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	libp2praft "github.com/libp2p/go-libp2p-raft"
+	"github.com/stretchr/testify/require"
+
+	addr "github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestRetryPeerDiscovery(t *testing.T) {
+	ctx := context.Background()
+	errNoQuorum := errors.New("no quorum")
+
+	failing := func(calls *int) func(context.Context) error {
+		return func(context.Context) error {
+			*calls++
+			return errNoQuorum
+		}
+	}
+
+	// zero retries means a single attempt
+	var calls int
+	err := retryPeerDiscovery(ctx, 0, time.Hour, failing(&calls))
+	require.ErrorIs(t, err, errNoQuorum)
+	require.Equal(t, 1, calls)
+
+	calls = 0
+	err = retryPeerDiscovery(ctx, 3, time.Millisecond, failing(&calls))
+	require.ErrorIs(t, err, errNoQuorum)
+	require.Equal(t, 4, calls)
+
+	// succeeds once peers show up
+	calls = 0
+	err = retryPeerDiscovery(ctx, 3, time.Millisecond, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errNoQuorum
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+}
+
+func TestRaftStateSnapshotRoundtrip(t *testing.T) {
+	a, err := addr.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	state := newRaftState(nil, false)
+	state.NonceMap[a] = 7
+	id := uuid.New()
+	state.MsgUuids[id] = &types.SignedMessage{
+		Message: types.Message{
+			To:         a,
+			From:       a,
+			Nonce:      7,
+			Value:      types.NewInt(1),
+			GasLimit:   1000,
+			GasFeeCap:  types.NewInt(2),
+			GasPremium: types.NewInt(3),
+		},
+	}
+
+	roundtrip := func(snapshot []byte) {
+		got := newRaftState(nil, false)
+		require.NoError(t, got.Unmarshal(bytes.NewReader(snapshot)))
+		require.Equal(t, state.NonceMap, got.NonceMap)
+		require.Len(t, got.MsgUuids, 1)
+		require.Equal(t, state.MsgUuids[id].Message, got.MsgUuids[id].Message)
+	}
+
+	// uncompressed snapshots use the legacy unversioned format
+	var legacy bytes.Buffer
+	require.NoError(t, state.Marshal(&legacy))
+	require.NotEqual(t, snapshotVersionRaw, legacy.Bytes()[0])
+	require.NotEqual(t, snapshotVersionZstd, legacy.Bytes()[0])
+	roundtrip(legacy.Bytes())
+
+	raw := bytes.NewBuffer([]byte{snapshotVersionRaw})
+	require.NoError(t, libp2praft.EncodeSnapshot((*rawRaftState)(state), raw))
+	roundtrip(raw.Bytes())
+
+	state.compressSnapshots = true
+	var compressed bytes.Buffer
+	require.NoError(t, state.Marshal(&compressed))
+	require.Equal(t, snapshotVersionZstd, compressed.Bytes()[0])
+	roundtrip(compressed.Bytes())
+}
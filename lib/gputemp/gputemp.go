@@ -0,0 +1,60 @@
+// Package gputemp reads the temperature of the NVIDIA GPUs of this machine
+// through NVML, and pauses work while they run too hot.
+package gputemp
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("gputemp")
+
+// ErrUnsupported is returned when the NVML library isn't installed.
+var ErrUnsupported = errors.New("nvml library not found")
+
+var (
+	initOnce sync.Once
+	initErr  error
+)
+
+// Read returns the temperature of each NVIDIA GPU of this machine, in degrees
+// Celsius.
+func Read() ([]int, error) {
+	initOnce.Do(func() {
+		switch ret := nvml.Init(); ret {
+		case nvml.SUCCESS:
+		case nvml.ERROR_LIBRARY_NOT_FOUND:
+			initErr = ErrUnsupported
+		default:
+			initErr = xerrors.Errorf("initializing nvml: %s", nvml.ErrorString(ret))
+		}
+	})
+	if initErr != nil {
+		return nil, initErr
+	}
+
+	n, ret := nvml.DeviceGetCount()
+	if ret != nvml.SUCCESS {
+		return nil, xerrors.Errorf("getting device count: %s", nvml.ErrorString(ret))
+	}
+
+	temps := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		dev, ret := nvml.DeviceGetHandleByIndex(i)
+		if ret != nvml.SUCCESS {
+			return nil, xerrors.Errorf("getting device %d: %s", i, nvml.ErrorString(ret))
+		}
+
+		t, ret := dev.GetTemperature(nvml.TEMPERATURE_GPU)
+		if ret != nvml.SUCCESS {
+			return nil, xerrors.Errorf("getting temperature of device %d: %s", i, nvml.ErrorString(ret))
+		}
+		temps = append(temps, int(t))
+	}
+
+	return temps, nil
+}
@@ -0,0 +1,64 @@
+package gputemp
+
+import (
+	"context"
+	"time"
+)
+
+// Hysteresis is how many degrees below the limit the GPUs have to cool down to
+// before paused work is resumed.
+const Hysteresis = 5
+
+// Guard polls GPU temperatures, calling Pause once the hottest GPU is above
+// Limit, and Resume once all GPUs are below Limit-Hysteresis again. Both are
+// given the temperature of the hottest GPU.
+type Guard struct {
+	Limit    int
+	Interval time.Duration
+
+	Read   func() ([]int, error)
+	Pause  func(temp int)
+	Resume func(temp int)
+
+	paused bool
+}
+
+// Run polls GPU temperatures until ctx is done.
+func (g *Guard) Run(ctx context.Context) {
+	t := time.NewTicker(g.Interval)
+	defer t.Stop()
+
+	for {
+		g.check()
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (g *Guard) check() {
+	temps, err := g.Read()
+	if err != nil {
+		log.Warnw("reading GPU temperature", "error", err)
+		return
+	}
+
+	hottest := 0
+	for _, t := range temps {
+		if t > hottest {
+			hottest = t
+		}
+	}
+
+	switch {
+	case !g.paused && hottest > g.Limit:
+		g.paused = true
+		g.Pause(hottest)
+	case g.paused && hottest < g.Limit-Hysteresis:
+		g.paused = false
+		g.Resume(hottest)
+	}
+}
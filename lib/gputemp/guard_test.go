@@ -0,0 +1,43 @@
+package gputemp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard(t *testing.T) {
+	var temps []int
+	var events []string
+
+	g := &Guard{
+		Limit: 90,
+		Read: func() ([]int, error) {
+			return temps, nil
+		},
+		Pause: func(int) {
+			events = append(events, "pause")
+		},
+		Resume: func(int) {
+			events = append(events, "resume")
+		},
+	}
+
+	for _, step := range []struct {
+		temps  []int
+		events []string
+	}{
+		{[]int{70, 90}, nil},
+		{[]int{70, 91}, []string{"pause"}},
+		{[]int{95, 60}, []string{"pause"}},
+		// still within the hysteresis
+		{[]int{85, 60}, []string{"pause"}},
+		{[]int{84, 60}, []string{"pause", "resume"}},
+		{nil, []string{"pause", "resume"}},
+		{[]int{92}, []string{"pause", "resume", "pause"}},
+	} {
+		temps = step.temps
+		g.check()
+		require.Equal(t, step.events, events, "temps %v", step.temps)
+	}
+}
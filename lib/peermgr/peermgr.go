@@ -3,6 +3,7 @@ package peermgr
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	logging "github.com/ipfs/go-log/v2"
@@ -17,6 +18,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/journal/alerting"
 	"github.com/filecoin-project/lotus/metrics"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 )
@@ -28,6 +30,9 @@ const (
 	MinFilPeers = 12
 )
 
+// maxBootstrapBackoff bounds the delay between bootstrap attempts, whatever the retry settings.
+const maxBootstrapBackoff = time.Hour
+
 type MaybePeerMgr struct {
 	fx.In
 
@@ -49,6 +54,15 @@ type PeerMgr struct {
 
 	expanding chan struct{}
 
+	// bootstrap retry state, only accessed from doExpand
+	bootstrapRetryMax   int
+	bootstrapRetryDelay time.Duration
+	bootstrapFailures   int
+	nextBootstrap       time.Time
+
+	alerting       *alerting.Alerting
+	bootstrapAlert alerting.AlertType
+
 	h   host.Host
 	dht *dht.IpfsDHT
 
@@ -70,44 +84,56 @@ const (
 	RemoveFilPeerEvt
 )
 
-func NewPeerMgr(lc fx.Lifecycle, h host.Host, dht *dht.IpfsDHT, bootstrap dtypes.BootstrapPeers) (*PeerMgr, error) {
-	pm := &PeerMgr{
-		h:             h,
-		dht:           dht,
-		bootstrappers: bootstrap,
+// NewPeerMgr returns a PeerMgr constructor. When no peers are connected, connecting to the
+// bootstrap peers is retried on every connection check, every 5 seconds. A non-zero
+// bootstrapRetryDelay adds exponential backoff starting at that delay. After bootstrapRetryMax
+// failed retries an alert is raised, and retries continue at the last delay.
+func NewPeerMgr(bootstrapRetryMax int, bootstrapRetryDelay time.Duration) func(lc fx.Lifecycle, h host.Host, dht *dht.IpfsDHT, bootstrap dtypes.BootstrapPeers, al *alerting.Alerting) (*PeerMgr, error) {
+	return func(lc fx.Lifecycle, h host.Host, dht *dht.IpfsDHT, bootstrap dtypes.BootstrapPeers, al *alerting.Alerting) (*PeerMgr, error) {
+		pm := &PeerMgr{
+			h:             h,
+			dht:           dht,
+			bootstrappers: bootstrap,
 
-		peers:     make(map[peer.ID]time.Duration),
-		expanding: make(chan struct{}, 1),
+			peers:     make(map[peer.ID]time.Duration),
+			expanding: make(chan struct{}, 1),
 
-		maxFilPeers: MaxFilPeers,
-		minFilPeers: MinFilPeers,
+			maxFilPeers: MaxFilPeers,
+			minFilPeers: MinFilPeers,
 
-		done: make(chan struct{}),
-	}
-	emitter, err := h.EventBus().Emitter(new(FilPeerEvt))
-	if err != nil {
-		return nil, xerrors.Errorf("creating FilPeerEvt emitter: %w", err)
-	}
-	pm.emitter = emitter
-
-	lc.Append(fx.Hook{
-		OnStop: func(ctx context.Context) error {
-			return multierr.Combine(
-				pm.emitter.Close(),
-				pm.Stop(ctx),
-			)
-		},
-	})
-
-	pm.notifee = &net.NotifyBundle{
-		DisconnectedF: func(_ net.Network, c net.Conn) {
-			pm.Disconnect(c.RemotePeer())
-		},
-	}
+			bootstrapRetryMax:   bootstrapRetryMax,
+			bootstrapRetryDelay: bootstrapRetryDelay,
+
+			alerting:       al,
+			bootstrapAlert: al.AddAlertType("peermgr", "bootstrap"),
+
+			done: make(chan struct{}),
+		}
+		emitter, err := h.EventBus().Emitter(new(FilPeerEvt))
+		if err != nil {
+			return nil, xerrors.Errorf("creating FilPeerEvt emitter: %w", err)
+		}
+		pm.emitter = emitter
+
+		lc.Append(fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				return multierr.Combine(
+					pm.emitter.Close(),
+					pm.Stop(ctx),
+				)
+			},
+		})
+
+		pm.notifee = &net.NotifyBundle{
+			DisconnectedF: func(_ net.Network, c net.Conn) {
+				pm.Disconnect(c.RemotePeer())
+			},
+		}
 
-	h.Network().Notify(pm.notifee)
+		h.Network().Notify(pm.notifee)
 
-	return pm, nil
+		return pm, nil
+	}
 }
 
 func (pmgr *PeerMgr) AddFilecoinPeer(p peer.ID) {
@@ -206,7 +232,12 @@ func (pmgr *PeerMgr) doExpand(ctx context.Context) {
 			return
 		}
 
+		if build.Clock.Now().Before(pmgr.nextBootstrap) {
+			return
+		}
+
 		log.Info("connecting to bootstrap peers")
+		var connected int64
 		wg := sync.WaitGroup{}
 		for _, bsp := range pmgr.bootstrappers {
 			wg.Add(1)
@@ -214,15 +245,75 @@ func (pmgr *PeerMgr) doExpand(ctx context.Context) {
 				defer wg.Done()
 				if err := pmgr.h.Connect(ctx, bsp); err != nil {
 					log.Warnf("failed to connect to bootstrap peer: %s", err)
+					return
 				}
+				atomic.AddInt64(&connected, 1)
 			}(bsp)
 		}
 		wg.Wait()
+
+		if connected > 0 {
+			pmgr.resetBootstrap(ctx)
+			return
+		}
+
+		pmgr.bootstrapFailures++
+		backoff := pmgr.bootstrapBackoff()
+		pmgr.nextBootstrap = build.Clock.Now().Add(backoff)
+
+		if pmgr.bootstrapFailures == pmgr.bootstrapRetryMax+1 {
+			pmgr.alerting.Raise(pmgr.bootstrapAlert, map[string]interface{}{
+				"message":  "failed to connect to any bootstrap peer",
+				"attempts": pmgr.bootstrapFailures,
+			})
+			stats.Record(ctx, metrics.BootstrapFailed.M(1))
+		}
+		if pmgr.bootstrapFailures > pmgr.bootstrapRetryMax {
+			log.Errorw("failed to connect to any bootstrap peer, retrying", "attempts", pmgr.bootstrapFailures, "retryIn", backoff)
+		} else {
+			log.Warnw("failed to connect to any bootstrap peer, retrying", "attempt", pmgr.bootstrapFailures, "retryIn", backoff)
+		}
 		return
 	}
 
+	pmgr.resetBootstrap(ctx)
+
 	// if we already have some peers and need more, the dht is really good at connecting to most peers. Use that for now until something better comes along.
 	if err := pmgr.dht.Bootstrap(ctx); err != nil {
 		log.Warnf("dht bootstrapping failed: %s", err)
 	}
 }
+
+// bootstrapBackoff returns the delay before the next bootstrap attempt. It doubles with each
+// failure, up to the delay of the last retry before the alert is raised.
+func (pmgr *PeerMgr) bootstrapBackoff() time.Duration {
+	doublings := pmgr.bootstrapFailures - 1
+	if doublings > pmgr.bootstrapRetryMax-1 {
+		doublings = pmgr.bootstrapRetryMax - 1
+	}
+
+	backoff := pmgr.bootstrapRetryDelay
+	for i := 0; i < doublings && backoff > 0 && backoff < maxBootstrapBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBootstrapBackoff {
+		backoff = maxBootstrapBackoff
+	}
+	return backoff
+}
+
+func (pmgr *PeerMgr) resetBootstrap(ctx context.Context) {
+	if pmgr.bootstrapFailures == 0 {
+		return
+	}
+
+	if pmgr.bootstrapFailures > pmgr.bootstrapRetryMax {
+		pmgr.alerting.Resolve(pmgr.bootstrapAlert, map[string]string{
+			"message": "connected to the network",
+		})
+		stats.Record(ctx, metrics.BootstrapFailed.M(0))
+	}
+
+	pmgr.bootstrapFailures = 0
+	pmgr.nextBootstrap = time.Time{}
+}
@@ -0,0 +1,47 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	logging "github.com/ipfs/go-log/v2"
+	"go.uber.org/zap"
+)
+
+type ctxKey struct{}
+
+// LogField is the name of the log field holding the request ID.
+const LogField = "requestID"
+
+// Handler reads the request ID from the given header of incoming requests and
+// attaches it to the request context. The ID is echoed back in the same
+// response header, so that clients can correlate responses.
+func Handler(header string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get(header); id != "" {
+			w.Header().Set(header, id)
+			r = r.WithContext(WithID(r.Context(), id))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WithID returns a context carrying the given request ID.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the request ID attached to the context, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(ctxKey{}).(string)
+	return id, ok
+}
+
+// Logger returns log with the request ID from the context attached as a field.
+func Logger(ctx context.Context, log *logging.ZapEventLogger) *zap.SugaredLogger {
+	if id, ok := FromContext(ctx); ok {
+		return log.With(LogField, id)
+	}
+	return &log.SugaredLogger
+}
@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	octrace "go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/bridge/opencensus"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"golang.org/x/xerrors"
+)
+
+// SetupOTLPTracing exports traces to the OTLP gRPC collector at endpoint, given as host:port. The
+// connection uses TLS unless the endpoint is prefixed with http://. The tracer provider is installed
+// globally, also capturing the spans recorded through opencensus, replacing any Jaeger exporter.
+func SetupOTLPTracing(ctx context.Context, endpoint, serviceName string) (*tracesdk.TracerProvider, error) {
+	host := strings.TrimPrefix(strings.TrimPrefix(endpoint, "http://"), "https://")
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(host)}
+	if strings.HasPrefix(endpoint, "http://") {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+
+	exp, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, xerrors.Errorf("creating otlp trace exporter: %w", err)
+	}
+
+	tp := tracesdk.NewTracerProvider(
+		tracesdk.WithBatcher(exp),
+		tracesdk.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+		)),
+		tracesdk.WithSampler(tracesdk.AlwaysSample()),
+	)
+	otel.SetTracerProvider(tp)
+	octrace.DefaultTracer = opencensus.NewTracer(tp.Tracer(serviceName))
+
+	log.Infof("otlp traces will be sent to %s", endpoint)
+	return tp, nil
+}
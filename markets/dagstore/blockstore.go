@@ -3,6 +3,7 @@ package dagstore
 import (
 	"context"
 	"io"
+	"sync"
 
 	bstore "github.com/ipfs/boxo/blockstore"
 	blocks "github.com/ipfs/go-block-format"
@@ -32,3 +33,17 @@ func (b *Blockstore) Put(context.Context, blocks.Block) error {
 func (b *Blockstore) PutMany(context.Context, []blocks.Block) error {
 	return xerrors.Errorf("PutMany called but not implemented")
 }
+
+// releaseCloser calls release once after closing the wrapped Closer.
+type releaseCloser struct {
+	io.Closer
+
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseCloser) Close() error {
+	err := r.Closer.Close()
+	r.once.Do(r.release)
+	return err
+}
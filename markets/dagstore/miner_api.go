@@ -56,6 +56,28 @@ func NewMinerAPI(store piecestore.PieceStore, sa SectorAccessor, concurrency int
 	}
 }
 
+// ErrPieceTransferDisabled is returned by MinerAPIs created with
+// WithoutPieceTransfer when the dagstore tries to fetch a piece.
+var ErrPieceTransferDisabled = xerrors.New("transferring pieces from sector storage to the dagstore is disabled")
+
+// WithoutPieceTransfer wraps api so that the dagstore can't fetch (or unseal)
+// pieces from sector storage; retrievals can then only be served from data the
+// dagstore already holds. Piece metadata lookups are still forwarded to api.
+//
+// Shard initialization reads the piece through the same mount, so shards
+// which aren't initialized yet can't be initialized or indexed either.
+func WithoutPieceTransfer(api MinerAPI) MinerAPI {
+	return &noTransferMinerAPI{MinerAPI: api}
+}
+
+type noTransferMinerAPI struct {
+	MinerAPI
+}
+
+func (m *noTransferMinerAPI) FetchUnsealedPiece(_ context.Context, pieceCid cid.Cid) (mount.Reader, error) {
+	return nil, xerrors.Errorf("fetching piece %s: %w", pieceCid, ErrPieceTransferDisabled)
+}
+
 func (m *minerAPI) Start(_ context.Context) error {
 	return m.readyMgr.FireReady(nil)
 }
@@ -134,6 +134,41 @@ func TestLotusAccessorGetUnpaddedCARSize(t *testing.T) {
 	require.EqualValues(t, 10, len)
 }
 
+func TestLotusAccessorWithoutPieceTransfer(t *testing.T) {
+	ctx := context.Background()
+	cid1, err := cid.Parse("bafkqaaa")
+	require.NoError(t, err)
+
+	ps := getPieceStore(t)
+	rpn := &mockRPN{
+		sectors: map[abi.SectorNumber]string{
+			unsealedSectorID: "unsealed",
+		},
+	}
+	api := WithoutPieceTransfer(NewMinerAPI(ps, rpn, 100, 5))
+	require.NoError(t, api.Start(ctx))
+
+	err = ps.AddDealForPiece(cid1, cid.Undef, piecestore.DealInfo{
+		DealID:   abi.DealID(1),
+		SectorID: unsealedSectorID,
+		Length:   128,
+	})
+	require.NoError(t, err)
+
+	_, err = api.FetchUnsealedPiece(ctx, cid1)
+	require.ErrorIs(t, err, ErrPieceTransferDisabled)
+	require.Zero(t, atomic.LoadInt32(&rpn.calls), "sector storage must not be read")
+
+	// metadata lookups still go through the piece store
+	uns, err := api.IsUnsealed(ctx, cid1)
+	require.NoError(t, err)
+	require.True(t, uns)
+
+	size, err := api.GetUnpaddedCARSize(ctx, cid1)
+	require.NoError(t, err)
+	require.EqualValues(t, 128, size)
+}
+
 func TestThrottle(t *testing.T) {
 	ctx := context.Background()
 	cid1, err := cid.Parse("bafkqaaa")
@@ -0,0 +1,137 @@
+package dagstore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/filecoin-project/dagstore"
+	"github.com/filecoin-project/dagstore/shard"
+)
+
+func (w *Wrapper) recoveryLoop() {
+	defer w.backgroundWg.Done()
+
+	ticker := time.NewTicker(w.recoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.recoverFailedShards()
+
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// recoverFailedShards recovers the shards in the errored state, one at a
+// time. Shards which failed to recover RecoveryRetries times are permanently
+// failed: they're skipped, and reported by the shard recovery alert until
+// they're recovered by other means.
+func (w *Wrapper) recoverFailedShards() {
+	for key, info := range w.dagst.AllShardsInfo() {
+		if info.ShardState != dagstore.ShardStateErrored {
+			// recovered, possibly manually
+			delete(w.recoveryAttempts, key)
+			continue
+		}
+
+		attempts := w.recoveryAttempts[key]
+		if attempts >= w.cfg.RecoveryRetries {
+			continue
+		}
+
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		attempts++
+		w.recoveryAttempts[key] = attempts
+
+		err := w.recoverShard(key)
+		switch {
+		case err == nil:
+			log.Infow("recovered failed shard", "shard", key, "attempt", attempts)
+			delete(w.recoveryAttempts, key)
+		case attempts >= w.cfg.RecoveryRetries:
+			log.Errorw("shard recovery failed, giving up", "shard", key, "attempts", attempts, "error", err)
+		default:
+			log.Warnw("shard recovery failed", "shard", key, "attempt", attempts, "error", err)
+		}
+	}
+
+	w.updateRecoveryAlert()
+}
+
+func (w *Wrapper) recoverShard(key shard.Key) error {
+	res := make(chan dagstore.ShardResult, 1)
+	if err := w.dagst.RecoverShard(w.ctx, key, res, dagstore.RecoverOpts{}); err != nil {
+		return err
+	}
+
+	select {
+	case r := <-res:
+		return r.Error
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	}
+}
+
+// PermanentlyFailedShards returns the shards which failed to recover
+// RecoveryRetries times in the background.
+func (w *Wrapper) PermanentlyFailedShards() []shard.Key {
+	w.recoveryLk.Lock()
+	defer w.recoveryLk.Unlock()
+
+	return append([]shard.Key{}, w.permanentlyFailed...)
+}
+
+func (w *Wrapper) updateRecoveryAlert() {
+	var failed []shard.Key
+	for key, attempts := range w.recoveryAttempts {
+		if attempts >= w.cfg.RecoveryRetries {
+			failed = append(failed, key)
+		}
+	}
+	sort.Slice(failed, func(i, j int) bool {
+		return failed[i].String() < failed[j].String()
+	})
+
+	w.recoveryLk.Lock()
+	changed := !shardsEqual(w.permanentlyFailed, failed)
+	w.permanentlyFailed = failed
+	w.recoveryLk.Unlock()
+
+	if w.alerting == nil || !changed {
+		return
+	}
+
+	if len(failed) == 0 {
+		w.alerting.Resolve(w.recoveryAlert, map[string]string{
+			"message": "no permanently failed shards",
+		})
+		return
+	}
+
+	shards := make([]string, len(failed))
+	for i, key := range failed {
+		shards[i] = key.String()
+	}
+	w.alerting.Raise(w.recoveryAlert, map[string]interface{}{
+		"message": "shards failed to recover, recover them manually with lotus-miner dagstore recover-shard",
+		"shards":  shards,
+	})
+}
+
+func shardsEqual(a, b []shard.Key) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
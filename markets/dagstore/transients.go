@@ -0,0 +1,168 @@
+package dagstore
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+// The DAG store keeps the transient copy of a shard in the transients
+// directory under this name; partial copies are being fetched.
+const (
+	transientPrefix         = "transient-"
+	transientCompleteSuffix = ".complete"
+)
+
+// TransientEvictionEvent records the eviction of the transient copy of a shard.
+type TransientEvictionEvent struct {
+	Shard string
+	Path  string
+	Size  int64
+}
+
+// transientEvictor keeps the total size of the transients directory under a
+// cap by deleting the transients of shards which aren't in use. The DAG store
+// refetches (unseals) a shard whose transient is gone the next time it is
+// acquired.
+type transientEvictor struct {
+	dir     string
+	sizeCap uint64
+	policy  string
+
+	lk         sync.Mutex
+	lastAccess map[string]time.Time
+	inUse      map[string]int
+}
+
+func newTransientEvictor(dir string, sizeCap uint64, policy string) *transientEvictor {
+	return &transientEvictor{
+		dir:        dir,
+		sizeCap:    sizeCap,
+		policy:     policy,
+		lastAccess: map[string]time.Time{},
+		inUse:      map[string]int{},
+	}
+}
+
+func (e *transientEvictor) enabled() bool {
+	return e.sizeCap > 0 && (e.policy == config.TransientEvictionLRU || e.policy == config.TransientEvictionFIFO)
+}
+
+// acquire marks the transient of the shard as in use, protecting it from
+// eviction until the returned function is called.
+func (e *transientEvictor) acquire(key string) (release func()) {
+	e.lk.Lock()
+	defer e.lk.Unlock()
+
+	e.lastAccess[key] = time.Now()
+	e.inUse[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.lk.Lock()
+			defer e.lk.Unlock()
+
+			e.lastAccess[key] = time.Now()
+			if e.inUse[key]--; e.inUse[key] <= 0 {
+				delete(e.inUse, key)
+			}
+		})
+	}
+}
+
+type transientFile struct {
+	key     string
+	path    string
+	size    int64
+	created time.Time
+	used    time.Time
+}
+
+// sweep evicts transients until their total size is within the cap, and
+// returns the evictions made.
+func (e *transientEvictor) sweep() ([]TransientEvictionEvent, error) {
+	if !e.enabled() {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(e.dir)
+	if err != nil {
+		return nil, xerrors.Errorf("reading transients directory: %w", err)
+	}
+
+	e.lk.Lock()
+	defer e.lk.Unlock()
+
+	var total uint64
+	var candidates []transientFile
+	for _, ent := range entries {
+		if !ent.Type().IsRegular() {
+			continue
+		}
+		fi, err := ent.Info()
+		if err != nil {
+			continue // removed in the meantime
+		}
+		total += uint64(fi.Size())
+
+		name := ent.Name()
+		if !strings.HasPrefix(name, transientPrefix) || !strings.HasSuffix(name, transientCompleteSuffix) {
+			continue
+		}
+		key := strings.TrimSuffix(strings.TrimPrefix(name, transientPrefix), transientCompleteSuffix)
+		if e.inUse[key] > 0 {
+			continue
+		}
+
+		used, ok := e.lastAccess[key]
+		if !ok {
+			used = fi.ModTime()
+		}
+		candidates = append(candidates, transientFile{
+			key:     key,
+			path:    filepath.Join(e.dir, name),
+			size:    fi.Size(),
+			created: fi.ModTime(),
+			used:    used,
+		})
+	}
+
+	if total <= e.sizeCap {
+		return nil, nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if e.policy == config.TransientEvictionLRU {
+			return candidates[i].used.Before(candidates[j].used)
+		}
+		return candidates[i].created.Before(candidates[j].created)
+	})
+
+	var evicted []TransientEvictionEvent
+	for _, c := range candidates {
+		if total <= e.sizeCap {
+			break
+		}
+		if err := os.Remove(c.path); err != nil {
+			log.Warnw("failed to evict transient", "shard", c.key, "path", c.path, "error", err)
+			continue
+		}
+		total -= uint64(c.size)
+		delete(e.lastAccess, c.key)
+		evicted = append(evicted, TransientEvictionEvent{Shard: c.key, Path: c.path, Size: c.size})
+	}
+
+	if total > e.sizeCap {
+		log.Warnw("transients still exceed the size cap after eviction; remaining transients are in use", "size", total, "cap", e.sizeCap)
+	}
+
+	return evicted, nil
+}
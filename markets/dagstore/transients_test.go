@@ -0,0 +1,96 @@
+// stm: #unit
+package dagstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+func writeTransient(t *testing.T, dir, key string, size int, mtime time.Time) {
+	p := filepath.Join(dir, transientPrefix+key+transientCompleteSuffix)
+	require.NoError(t, os.WriteFile(p, make([]byte, size), 0644))
+	require.NoError(t, os.Chtimes(p, mtime, mtime))
+}
+
+func TestTransientEvictionLRU(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeTransient(t, dir, "a", 100, now.Add(-3*time.Hour))
+	writeTransient(t, dir, "b", 100, now.Add(-2*time.Hour))
+	writeTransient(t, dir, "c", 100, now.Add(-time.Hour))
+
+	e := newTransientEvictor(dir, 100, config.TransientEvictionLRU)
+	require.True(t, e.enabled())
+
+	// c is in use, a was used after it
+	release := e.acquire("c")
+	e.acquire("a")()
+
+	evicted, err := e.sweep()
+	require.NoError(t, err)
+	require.Len(t, evicted, 2)
+	require.Equal(t, "b", evicted[0].Shard)
+	require.EqualValues(t, 100, evicted[0].Size)
+	require.NoFileExists(t, evicted[0].Path)
+	require.Equal(t, "a", evicted[1].Shard)
+
+	// within the cap
+	evicted, err = e.sweep()
+	require.NoError(t, err)
+	require.Empty(t, evicted)
+
+	// once released, c is evicted before the more recently written d
+	release()
+	writeTransient(t, dir, "d", 100, time.Now().Add(time.Second))
+	evicted, err = e.sweep()
+	require.NoError(t, err)
+	require.Len(t, evicted, 1)
+	require.Equal(t, "c", evicted[0].Shard)
+}
+
+func TestTransientEvictionFIFO(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeTransient(t, dir, "a", 100, now.Add(-3*time.Hour))
+	writeTransient(t, dir, "b", 100, now.Add(-2*time.Hour))
+
+	e := newTransientEvictor(dir, 150, config.TransientEvictionFIFO)
+	e.acquire("a")()
+
+	evicted, err := e.sweep()
+	require.NoError(t, err)
+	require.Len(t, evicted, 1)
+	require.Equal(t, "a", evicted[0].Shard)
+}
+
+func TestTransientEvictionDisabled(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		sizeCap uint64
+		policy  string
+	}{
+		{"zero cap", 0, config.TransientEvictionLRU},
+		{"none policy", 1, config.TransientEvictionNone},
+		{"no policy", 1, ""},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTransient(t, dir, "a", 100, time.Now())
+
+			e := newTransientEvictor(dir, tc.sizeCap, tc.policy)
+			require.False(t, e.enabled())
+
+			evicted, err := e.sweep()
+			require.NoError(t, err)
+			require.Empty(t, evicted)
+			require.FileExists(t, filepath.Join(dir, transientPrefix+"a"+transientCompleteSuffix))
+		})
+	}
+}
@@ -17,7 +17,9 @@ import (
 	logging "github.com/ipfs/go-log/v2"
 	carindex "github.com/ipld/go-car/v2/index"
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/syndtr/goleveldb/leveldb"
 	ldbopts "github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/dagstore"
@@ -29,6 +31,7 @@ import (
 	"github.com/filecoin-project/go-fil-markets/stores"
 	"github.com/filecoin-project/go-statemachine/fsm"
 
+	"github.com/filecoin-project/lotus/journal/alerting"
 	"github.com/filecoin-project/lotus/node/config"
 )
 
@@ -49,11 +52,34 @@ type Wrapper struct {
 	minerAPI   MinerAPI
 	failureCh  chan dagstore.ShardResult
 	gcInterval time.Duration
+
+	transients          *transientEvictor
+	transientGCInterval time.Duration
+
+	// leveldb backing the dagstore datastore, which holds the shard index
+	indexDB                 *leveldb.DB
+	indexDir                string
+	indexCompactionInterval time.Duration
+
+	// bounds the number of shards loaded for retrievals at the same time;
+	// nil means unlimited
+	retrievals chan struct{}
+
+	// background recovery of failed shards; recoveryAttempts is only used by
+	// the recovery loop
+	recoveryInterval  time.Duration
+	recoveryAttempts  map[shard.Key]int
+	recoveryLk        sync.Mutex
+	permanentlyFailed []shard.Key
+
+	// optional
+	alerting      *alerting.Alerting
+	recoveryAlert alerting.AlertType
 }
 
 var _ stores.DAGStoreWrapper = (*Wrapper)(nil)
 
-func NewDAGStore(cfg config.DAGStoreConfig, minerApi MinerAPI, h host.Host) (*dagstore.DAGStore, *Wrapper, error) {
+func NewDAGStore(cfg config.DAGStoreConfig, minerApi MinerAPI, h host.Host, al *alerting.Alerting) (*dagstore.DAGStore, *Wrapper, error) {
 	// construct the DAG Store.
 	registry := mount.NewRegistry()
 	if err := registry.Register(lotusScheme, mountTemplate(minerApi)); err != nil {
@@ -68,8 +94,11 @@ func NewDAGStore(cfg config.DAGStoreConfig, minerApi MinerAPI, h host.Host) (*da
 		datastoreDir  = filepath.Join(cfg.RootDir, "datastore")
 		indexDir      = filepath.Join(cfg.RootDir, "index")
 	)
+	if cfg.PieceDirectory != "" {
+		transientsDir = cfg.PieceDirectory
+	}
 
-	dstore, err := newDatastore(datastoreDir)
+	dstore, indexDB, err := newDatastore(datastoreDir)
 	if err != nil {
 		return nil, nil, xerrors.Errorf("failed to create dagstore datastore in %s: %w", datastoreDir, err)
 	}
@@ -105,17 +134,38 @@ func NewDAGStore(cfg config.DAGStoreConfig, minerApi MinerAPI, h host.Host) (*da
 		minerAPI:   minerApi,
 		failureCh:  failureCh,
 		gcInterval: time.Duration(cfg.GCInterval),
+
+		transients:          newTransientEvictor(transientsDir, cfg.TransientStoreSizeCap, cfg.TransientEvictionPolicy),
+		transientGCInterval: time.Duration(cfg.TransientGCInterval),
+
+		indexDB:                 indexDB,
+		indexDir:                datastoreDir,
+		indexCompactionInterval: time.Duration(cfg.IndexCompactionInterval),
+
+		recoveryInterval: time.Duration(cfg.RecoveryInterval),
+		recoveryAttempts: map[shard.Key]int{},
+
+		alerting: al,
+	}
+	if al != nil {
+		w.recoveryAlert = al.AddAlertType("dagstore", "shard-recovery")
+	}
+	if w.transientGCInterval == 0 {
+		w.transientGCInterval = w.gcInterval
+	}
+	if cfg.MaxConcurrentRetrievals > 0 {
+		w.retrievals = make(chan struct{}, cfg.MaxConcurrentRetrievals)
 	}
 
 	return dagst, w, nil
 }
 
 // newDatastore creates a datastore under the given base directory
-// for dagstore metadata.
-func newDatastore(dir string) (ds.Batching, error) {
+// for dagstore metadata, and returns it along with the underlying LevelDB.
+func newDatastore(dir string) (ds.Batching, *leveldb.DB, error) {
 	// Create the datastore directory if it doesn't exist yet.
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, xerrors.Errorf("failed to create directory %s for DAG store datastore: %w", dir, err)
+		return nil, nil, xerrors.Errorf("failed to create directory %s for DAG store datastore: %w", dir, err)
 	}
 
 	// Create a new LevelDB datastore
@@ -126,11 +176,11 @@ func newDatastore(dir string) (ds.Batching, error) {
 		ReadOnly:    false,
 	})
 	if err != nil {
-		return nil, xerrors.Errorf("failed to open datastore for DAG store: %w", err)
+		return nil, nil, xerrors.Errorf("failed to open datastore for DAG store: %w", err)
 	}
 	// Keep statistics about the datastore
 	mds := measure.New("measure.", dstore)
-	return mds, nil
+	return mds, dstore.DB, nil
 }
 
 func (w *Wrapper) Start(ctx context.Context) error {
@@ -140,12 +190,30 @@ func (w *Wrapper) Start(ctx context.Context) error {
 	w.backgroundWg.Add(1)
 	go w.gcLoop()
 
+	// Run a go-routine to periodically compact the shard index.
+	if w.indexCompactionInterval > 0 {
+		w.backgroundWg.Add(1)
+		go w.indexCompactionLoop()
+	}
+
+	// Run a go-routine to keep the transients directory under its size cap.
+	if w.transients.enabled() {
+		w.backgroundWg.Add(1)
+		go w.transientEvictionLoop()
+	}
+
 	// Run a go-routine for shard recovery
 	if dss, ok := w.dagst.(*dagstore.DAGStore); ok {
 		w.backgroundWg.Add(1)
 		go dagstore.RecoverImmediately(w.ctx, dss, w.failureCh, maxRecoverAttempts, w.backgroundWg.Done)
 	}
 
+	// Run a go-routine to retry recovering shards which are still failed.
+	if w.cfg.RecoveryRetries > 0 && w.recoveryInterval > 0 {
+		w.backgroundWg.Add(1)
+		go w.recoveryLoop()
+	}
+
 	return w.dagst.Start(ctx)
 }
 
@@ -168,10 +236,131 @@ func (w *Wrapper) gcLoop() {
 	}
 }
 
+func (w *Wrapper) transientEvictionLoop() {
+	defer w.backgroundWg.Done()
+
+	ticker := time.NewTicker(w.transientGCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			evicted, err := w.transients.sweep()
+			if err != nil {
+				log.Warnw("transient eviction sweep failed", "error", err)
+			}
+			for _, ev := range evicted {
+				log.Infow("evicted transient", "shard", ev.Shard, "size", ev.Size)
+			}
+
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *Wrapper) indexCompactionLoop() {
+	defer w.backgroundWg.Done()
+
+	ticker := time.NewTicker(w.indexCompactionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.compactIndex(); err != nil {
+				log.Warnw("shard index compaction failed", "error", err)
+			}
+
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// compactIndex runs a full compaction of the datastore holding the shard
+// index, dropping deleted entries.
+func (w *Wrapper) compactIndex() error {
+	before, err := dirSize(w.indexDir)
+	if err != nil {
+		return xerrors.Errorf("getting shard index size: %w", err)
+	}
+
+	start := time.Now()
+	if err := w.indexDB.CompactRange(util.Range{}); err != nil {
+		return xerrors.Errorf("compacting shard index: %w", err)
+	}
+	took := time.Since(start)
+
+	after, err := dirSize(w.indexDir)
+	if err != nil {
+		return xerrors.Errorf("getting shard index size: %w", err)
+	}
+
+	log.Infow("compacted shard index", "took", took, "reclaimed", before-after, "size", after)
+	return nil
+}
+
+// dirSize returns the total size of the files in dir.
+func dirSize(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, ent := range entries {
+		fi, err := ent.Info()
+		if err != nil {
+			continue // removed in the meantime
+		}
+		if fi.Mode().IsRegular() {
+			size += fi.Size()
+		}
+	}
+	return size, nil
+}
+
 func (w *Wrapper) LoadShard(ctx context.Context, pieceCid cid.Cid) (stores.ClosableBlockstore, error) {
+	if w.retrievals == nil {
+		return w.loadShard(ctx, pieceCid)
+	}
+
+	// the slot is held until the retrieval closes the blockstore
+	select {
+	case w.retrievals <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	bs, err := w.loadShard(ctx, pieceCid)
+	if err != nil {
+		<-w.retrievals
+		return nil, err
+	}
+
+	bs.Closer = &releaseCloser{Closer: bs.Closer, release: func() { <-w.retrievals }}
+	return bs, nil
+}
+
+func (w *Wrapper) loadShard(ctx context.Context, pieceCid cid.Cid) (*Blockstore, error) {
 	log.Debugf("acquiring shard for piece CID %s", pieceCid)
 
 	key := shard.KeyFromCID(pieceCid)
+
+	// protect the transient from eviction while the shard is in use
+	release := w.transients.acquire(key.String())
+	bs, err := w.acquireShard(ctx, pieceCid, key)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	bs.Closer = &releaseCloser{Closer: bs.Closer, release: release}
+	return bs, nil
+}
+
+func (w *Wrapper) acquireShard(ctx context.Context, pieceCid cid.Cid, key shard.Key) (*Blockstore, error) {
 	resch := make(chan dagstore.ShardResult, 1)
 	err := w.dagst.AcquireShard(ctx, key, resch, dagstore.AcquireOpts{})
 	log.Debugf("sent message to acquire shard for piece CID %s", pieceCid)
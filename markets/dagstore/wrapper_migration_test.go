@@ -102,7 +102,7 @@ func TestShardRegistration(t *testing.T) {
 	require.NoError(t, err)
 
 	mapi := NewMinerAPI(ps, &wrappedSA{sa}, 10, 5)
-	dagst, w, err := NewDAGStore(cfg, mapi, h)
+	dagst, w, err := NewDAGStore(cfg, mapi, h, nil)
 	require.NoError(t, err)
 	require.NotNil(t, dagst)
 	require.NotNil(t, w)
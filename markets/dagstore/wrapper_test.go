@@ -4,7 +4,9 @@ package dagstore
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -35,7 +37,7 @@ func TestWrapperAcquireRecoveryDestroy(t *testing.T) {
 	dagst, w, err := NewDAGStore(config.DAGStoreConfig{
 		RootDir:    t.TempDir(),
 		GCInterval: config.Duration(1 * time.Millisecond),
-	}, mockLotusMount{}, h)
+	}, mockLotusMount{}, h, nil)
 	require.NoError(t, err)
 
 	defer dagst.Close() //nolint:errcheck
@@ -112,7 +114,7 @@ func TestWrapperBackground(t *testing.T) {
 	dagst, w, err := NewDAGStore(config.DAGStoreConfig{
 		RootDir:    t.TempDir(),
 		GCInterval: config.Duration(1 * time.Millisecond),
-	}, mockLotusMount{}, h)
+	}, mockLotusMount{}, h, nil)
 	require.NoError(t, err)
 
 	defer dagst.Close() //nolint:errcheck
@@ -155,6 +157,116 @@ func TestWrapperBackground(t *testing.T) {
 	}
 }
 
+// TestWrapperShardRecovery verifies that failed shards are recovered up to
+// RecoveryRetries times, and then reported as permanently failed
+func TestWrapperShardRecovery(t *testing.T) {
+	h, err := mocknet.New().GenPeer()
+	require.NoError(t, err)
+
+	dagst, w, err := NewDAGStore(config.DAGStoreConfig{
+		RootDir:          t.TempDir(),
+		GCInterval:       config.Duration(1 * time.Minute),
+		RecoveryRetries:  2,
+		RecoveryInterval: config.Duration(1 * time.Hour),
+	}, mockLotusMount{}, h, nil)
+	require.NoError(t, err)
+	defer dagst.Close() //nolint:errcheck
+
+	flaky, broken, ok := shard.KeyFromString("flaky"), shard.KeyFromString("broken"), shard.KeyFromString("ok")
+	mock := &mockDagStore{
+		recover: make(chan shard.Key, 3),
+		shards: dagstore.AllShardsInfo{
+			flaky:  {ShardState: dagstore.ShardStateErrored},
+			broken: {ShardState: dagstore.ShardStateErrored},
+			ok:     {ShardState: dagstore.ShardStateAvailable},
+		},
+		recoverErr: map[shard.Key]error{
+			flaky:  xerrors.New("fetch failed"),
+			broken: xerrors.New("piece not found"),
+		},
+	}
+	w.dagst = mock
+	w.ctx = context.Background()
+
+	recovered := func() []shard.Key {
+		var keys []shard.Key
+		for len(mock.recover) > 0 {
+			keys = append(keys, <-mock.recover)
+		}
+		return keys
+	}
+
+	w.recoverFailedShards()
+	require.ElementsMatch(t, []shard.Key{flaky, broken}, recovered())
+	require.Empty(t, w.PermanentlyFailedShards())
+
+	// the flaky shard recovers on the second attempt
+	delete(mock.recoverErr, flaky)
+	w.recoverFailedShards()
+	require.ElementsMatch(t, []shard.Key{flaky, broken}, recovered())
+	require.Equal(t, []shard.Key{broken}, w.PermanentlyFailedShards())
+
+	// permanently failed shards aren't retried
+	mock.shards[flaky] = dagstore.ShardInfo{ShardState: dagstore.ShardStateAvailable}
+	w.recoverFailedShards()
+	require.Empty(t, recovered())
+	require.Equal(t, []shard.Key{broken}, w.PermanentlyFailedShards())
+
+	// until they're recovered manually
+	mock.shards[broken] = dagstore.ShardInfo{ShardState: dagstore.ShardStateAvailable}
+	w.recoverFailedShards()
+	require.Empty(t, recovered())
+	require.Empty(t, w.PermanentlyFailedShards())
+}
+
+// TestWrapperIndexCompaction verifies that compacting the shard index drops
+// deleted entries
+func TestWrapperIndexCompaction(t *testing.T) {
+	h, err := mocknet.New().GenPeer()
+	require.NoError(t, err)
+
+	dagst, w, err := NewDAGStore(config.DAGStoreConfig{
+		RootDir:                 t.TempDir(),
+		GCInterval:              config.Duration(1 * time.Minute),
+		IndexCompactionInterval: config.Duration(1 * time.Hour),
+	}, mockLotusMount{}, h, nil)
+	require.NoError(t, err)
+	defer dagst.Close() //nolint:errcheck
+
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, w.indexDB.Put([]byte(fmt.Sprintf("key-%d", i)), bytes.Repeat([]byte{1}, 1024), nil))
+	}
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, w.indexDB.Delete([]byte(fmt.Sprintf("key-%d", i)), nil))
+	}
+
+	require.NoError(t, w.compactIndex())
+
+	has, err := w.indexDB.Has([]byte("key-0"), nil)
+	require.NoError(t, err)
+	require.False(t, has)
+}
+
+// TestWrapperPieceDirectory verifies that transients are kept in the piece
+// directory when one is configured
+func TestWrapperPieceDirectory(t *testing.T) {
+	h, err := mocknet.New().GenPeer()
+	require.NoError(t, err)
+
+	rootDir := t.TempDir()
+	pieceDir := filepath.Join(t.TempDir(), "pieces")
+	dagst, _, err := NewDAGStore(config.DAGStoreConfig{
+		RootDir:        rootDir,
+		PieceDirectory: pieceDir,
+		GCInterval:     config.Duration(1 * time.Minute),
+	}, mockLotusMount{}, h, nil)
+	require.NoError(t, err)
+	defer dagst.Close() //nolint:errcheck
+
+	require.DirExists(t, pieceDir)
+	require.NoDirExists(t, filepath.Join(rootDir, "transients"))
+}
+
 type mockDagStore struct {
 	acquireShardErr chan error
 	acquireShardRes dagstore.ShardResult
@@ -164,6 +276,9 @@ type mockDagStore struct {
 	recover chan shard.Key
 	destroy chan shard.Key
 	close   chan struct{}
+
+	shards     dagstore.AllShardsInfo
+	recoverErr map[shard.Key]error
 }
 
 func (m *mockDagStore) GetIterableIndex(key shard.Key) (carindex.IterableIndex, error) {
@@ -189,7 +304,7 @@ func (m *mockDagStore) GetShardInfo(k shard.Key) (dagstore.ShardInfo, error) {
 }
 
 func (m *mockDagStore) AllShardsInfo() dagstore.AllShardsInfo {
-	panic("implement me")
+	return m.shards
 }
 
 func (m *mockDagStore) Start(_ context.Context) error {
@@ -215,6 +330,7 @@ func (m *mockDagStore) AcquireShard(ctx context.Context, key shard.Key, out chan
 
 func (m *mockDagStore) RecoverShard(ctx context.Context, key shard.Key, out chan dagstore.ShardResult, _ dagstore.RecoverOpts) error {
 	m.recover <- key
+	out <- dagstore.ShardResult{Key: key, Error: m.recoverErr[key]}
 	return nil
 }
 
@@ -0,0 +1,356 @@
+package dealfilter
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math/big"
+	"strconv"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+)
+
+// DealFilterContext holds the properties of a storage deal proposal which a
+// filter expression can refer to.
+type DealFilterContext struct {
+	ProposalCid cid.Cid
+	// Client is the ID address of the deal client.
+	Client    address.Address
+	PieceSize abi.PaddedPieceSize
+	Verified  bool
+	// Price is the asking price per epoch, in attoFIL.
+	Price abi.TokenAmount
+}
+
+// DealFilter decides whether a storage deal proposal is accepted.
+type DealFilter interface {
+	Accept(DealFilterContext) (bool, error)
+}
+
+// Identifiers available to filter expressions, and their types.
+var filterIdents = map[string]exprType{
+	"proposalCid": typeString,
+	"client":      typeString,
+	"pieceSize":   typeInt,
+	"verified":    typeBool,
+	"price":       typeInt,
+}
+
+type exprType int
+
+const (
+	typeBool exprType = iota
+	typeInt
+	typeString
+)
+
+func (t exprType) String() string {
+	switch t {
+	case typeBool:
+		return "bool"
+	case typeInt:
+		return "int"
+	default:
+		return "string"
+	}
+}
+
+// CompiledDealFilter is a DealFilter evaluating a boolean expression.
+type CompiledDealFilter struct {
+	expr ast.Expr
+}
+
+var _ DealFilter = (*CompiledDealFilter)(nil)
+
+// CompileDealFilter compiles a deal filter expression. Expressions use Go
+// syntax, and may use the identifiers proposalCid and client (strings; client
+// is the ID address of the deal client, with the address prefix of the current
+// network), pieceSize and price (integers, price in attoFIL per epoch) and
+// verified (bool). Only the operators && || ! and comparisons are supported,
+// ordering comparisons only between integers, along with parentheses and
+// oneOf(x, a, b, ...), which is true when x equals any of the other arguments.
+// For example:
+//
+//	verified && oneOf(client, "f01234", "f05678") || price >= 1000000
+//
+// Unknown identifiers, type errors and non-boolean expressions are rejected.
+func CompileDealFilter(expr string) (DealFilter, error) {
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing deal filter expression: %w", err)
+	}
+
+	t, err := checkExpr(e)
+	if err != nil {
+		return nil, xerrors.Errorf("checking deal filter expression: %w", err)
+	}
+	if t != typeBool {
+		return nil, xerrors.Errorf("deal filter expression must evaluate to bool, got %s", t)
+	}
+
+	return &CompiledDealFilter{expr: e}, nil
+}
+
+// Accept evaluates the filter expression against the deal.
+func (f *CompiledDealFilter) Accept(dc DealFilterContext) (bool, error) {
+	v, err := evalExpr(f.expr, dc)
+	if err != nil {
+		return false, err
+	}
+	return v.(bool), nil
+}
+
+// AddressResolver resolves addresses to their ID form.
+type AddressResolver interface {
+	StateLookupID(context.Context, address.Address, types.TipSetKey) (address.Address, error)
+}
+
+// ExpressionStorageDealFilter returns a storage deal filter which rejects the
+// deals not accepted by f, and passes the others on to next, if non-nil. The
+// client address is resolved to its ID address with r before evaluating f.
+func ExpressionStorageDealFilter(f DealFilter, r AddressResolver, next dtypes.StorageDealFilter) dtypes.StorageDealFilter {
+	return func(ctx context.Context, deal storagemarket.MinerDeal) (bool, string, error) {
+		client := deal.Proposal.Client
+		if client.Protocol() != address.ID {
+			var err error
+			client, err = r.StateLookupID(ctx, client, types.EmptyTSK)
+			if err != nil {
+				return false, "failed to resolve client address", xerrors.Errorf("resolving deal client %s: %w", deal.Proposal.Client, err)
+			}
+		}
+
+		ok, err := f.Accept(DealFilterContext{
+			ProposalCid: deal.ProposalCid,
+			Client:      client,
+			PieceSize:   deal.Proposal.PieceSize,
+			Verified:    deal.Proposal.VerifiedDeal,
+			Price:       deal.Proposal.StoragePricePerEpoch,
+		})
+		if err != nil {
+			return false, "miner error", xerrors.Errorf("evaluating deal filter expression: %w", err)
+		}
+		if !ok {
+			return false, "deal rejected by the miner's deal filter expression", nil
+		}
+
+		if next != nil {
+			return next(ctx, deal)
+		}
+		return true, "", nil
+	}
+}
+
+func checkExpr(e ast.Expr) (exprType, error) {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return checkExpr(e.X)
+
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			return typeInt, nil
+		case token.STRING:
+			return typeString, nil
+		}
+		return 0, xerrors.Errorf("unsupported literal %s", e.Value)
+
+	case *ast.Ident:
+		if e.Name == "true" || e.Name == "false" {
+			return typeBool, nil
+		}
+		t, ok := filterIdents[e.Name]
+		if !ok {
+			return 0, xerrors.Errorf("unknown identifier %q", e.Name)
+		}
+		return t, nil
+
+	case *ast.UnaryExpr:
+		t, err := checkExpr(e.X)
+		if err != nil {
+			return 0, err
+		}
+		if e.Op == token.NOT && t == typeBool {
+			return t, nil
+		}
+		return 0, xerrors.Errorf("operator %s not defined on %s", e.Op, t)
+
+	case *ast.BinaryExpr:
+		lt, err := checkExpr(e.X)
+		if err != nil {
+			return 0, err
+		}
+		rt, err := checkExpr(e.Y)
+		if err != nil {
+			return 0, err
+		}
+		if lt != rt {
+			return 0, xerrors.Errorf("mismatched types %s and %s for operator %s", lt, rt, e.Op)
+		}
+		switch e.Op {
+		case token.LAND, token.LOR:
+			if lt == typeBool {
+				return typeBool, nil
+			}
+		case token.EQL, token.NEQ:
+			return typeBool, nil
+		case token.LSS, token.LEQ, token.GTR, token.GEQ:
+			if lt == typeInt {
+				return typeBool, nil
+			}
+		}
+		return 0, xerrors.Errorf("operator %s not defined on %s", e.Op, lt)
+
+	case *ast.CallExpr:
+		fn, ok := e.Fun.(*ast.Ident)
+		if !ok || fn.Name != "oneOf" {
+			return 0, xerrors.Errorf("unknown function; only oneOf is supported")
+		}
+		if len(e.Args) < 2 {
+			return 0, xerrors.Errorf("oneOf requires at least 2 arguments")
+		}
+		t, err := checkExpr(e.Args[0])
+		if err != nil {
+			return 0, err
+		}
+		for _, a := range e.Args[1:] {
+			at, err := checkExpr(a)
+			if err != nil {
+				return 0, err
+			}
+			if at != t {
+				return 0, xerrors.Errorf("mismatched types %s and %s in oneOf", t, at)
+			}
+		}
+		return typeBool, nil
+	}
+
+	return 0, xerrors.Errorf("unsupported expression %T", e)
+}
+
+// evalExpr evaluates an expression accepted by checkExpr. Values are bool,
+// string or *big.Int.
+func evalExpr(e ast.Expr, dc DealFilterContext) (interface{}, error) {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return evalExpr(e.X, dc)
+
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			return strconv.Unquote(e.Value)
+		}
+		n, ok := new(big.Int).SetString(e.Value, 0)
+		if !ok {
+			return nil, xerrors.Errorf("invalid integer %s", e.Value)
+		}
+		return n, nil
+
+	case *ast.Ident:
+		switch e.Name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "proposalCid":
+			if !dc.ProposalCid.Defined() {
+				return "", nil
+			}
+			return dc.ProposalCid.String(), nil
+		case "client":
+			if dc.Client == address.Undef {
+				return "", nil
+			}
+			return dc.Client.String(), nil
+		case "pieceSize":
+			return new(big.Int).SetUint64(uint64(dc.PieceSize)), nil
+		case "verified":
+			return dc.Verified, nil
+		case "price":
+			if dc.Price.Int == nil {
+				return new(big.Int), nil
+			}
+			return new(big.Int).Set(dc.Price.Int), nil
+		}
+
+	case *ast.UnaryExpr:
+		v, err := evalExpr(e.X, dc)
+		if err != nil {
+			return nil, err
+		}
+		return !v.(bool), nil
+
+	case *ast.BinaryExpr:
+		l, err := evalExpr(e.X, dc)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case token.LAND:
+			if !l.(bool) {
+				return false, nil
+			}
+			return evalExpr(e.Y, dc)
+		case token.LOR:
+			if l.(bool) {
+				return true, nil
+			}
+			return evalExpr(e.Y, dc)
+		}
+
+		r, err := evalExpr(e.Y, dc)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case token.EQL:
+			return valuesEqual(l, r), nil
+		case token.NEQ:
+			return !valuesEqual(l, r), nil
+		}
+
+		cmp := l.(*big.Int).Cmp(r.(*big.Int))
+		switch e.Op {
+		case token.LSS:
+			return cmp < 0, nil
+		case token.LEQ:
+			return cmp <= 0, nil
+		case token.GTR:
+			return cmp > 0, nil
+		case token.GEQ:
+			return cmp >= 0, nil
+		}
+
+	case *ast.CallExpr:
+		x, err := evalExpr(e.Args[0], dc)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range e.Args[1:] {
+			v, err := evalExpr(a, dc)
+			if err != nil {
+				return nil, err
+			}
+			if valuesEqual(x, v) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	return nil, xerrors.Errorf("unsupported expression %T", e)
+}
+
+func valuesEqual(a, b interface{}) bool {
+	if ai, ok := a.(*big.Int); ok {
+		return ai.Cmp(b.(*big.Int)) == 0
+	}
+	return a == b
+}
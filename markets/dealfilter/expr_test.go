@@ -0,0 +1,136 @@
+package dealfilter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin/v9/market"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestCompileDealFilter(t *testing.T) {
+	client, err := address.NewIDAddress(1234)
+	require.NoError(t, err)
+	other, err := address.NewIDAddress(5678)
+	require.NoError(t, err)
+
+	deal := func(client address.Address, price int64, verified bool) DealFilterContext {
+		return DealFilterContext{
+			Client:    client,
+			PieceSize: abi.PaddedPieceSize(32 << 30),
+			Verified:  verified,
+			Price:     big.NewInt(price),
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		expr   string
+		accept []DealFilterContext
+		reject []DealFilterContext
+	}{
+		{
+			name:   "always true",
+			expr:   "true",
+			accept: []DealFilterContext{deal(client, 0, false), {}},
+		},
+		{
+			name:   "client address",
+			expr:   fmt.Sprintf(`verified && oneOf(client, %q, "f09999")`, client),
+			accept: []DealFilterContext{deal(client, 0, true)},
+			reject: []DealFilterContext{deal(client, 0, false), deal(other, 0, true)},
+		},
+		{
+			name:   "price threshold",
+			expr:   "price >= 1000 || (verified && pieceSize <= 34359738368)",
+			accept: []DealFilterContext{deal(other, 1000, false), deal(other, 0, true)},
+			reject: []DealFilterContext{deal(other, 999, false)},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := CompileDealFilter(tc.expr)
+			require.NoError(t, err)
+
+			for _, d := range tc.accept {
+				ok, err := f.Accept(d)
+				require.NoError(t, err)
+				require.True(t, ok, "%+v", d)
+			}
+			for _, d := range tc.reject {
+				ok, err := f.Accept(d)
+				require.NoError(t, err)
+				require.False(t, ok, "%+v", d)
+			}
+		})
+	}
+}
+
+func TestCompileDealFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		"verified &&",            // syntax error
+		`dealer == "f01234"`,     // unknown identifier
+		`price > "1000"`,         // type mismatch
+		"price + 1 > 0",          // arithmetic
+		"-price < 0",             // unary minus
+		`client < "f01234"`,      // string ordering
+		"price",                  // not a bool
+		`len(client) > 0`,        // unknown function
+		"client.Protocol() == 0", // unsupported expression
+	} {
+		_, err := CompileDealFilter(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+type testResolver map[address.Address]address.Address
+
+func (r testResolver) StateLookupID(_ context.Context, a address.Address, _ types.TipSetKey) (address.Address, error) {
+	id, ok := r[a]
+	if !ok {
+		return address.Undef, fmt.Errorf("actor %s not found", a)
+	}
+	return id, nil
+}
+
+func TestExpressionStorageDealFilterResolvesClient(t *testing.T) {
+	id, err := address.NewIDAddress(1234)
+	require.NoError(t, err)
+	robust, err := address.NewSecp256k1Address([]byte("client"))
+	require.NoError(t, err)
+	unknown, err := address.NewSecp256k1Address([]byte("unknown"))
+	require.NoError(t, err)
+
+	f, err := CompileDealFilter(fmt.Sprintf("client == %q", id))
+	require.NoError(t, err)
+
+	filter := ExpressionStorageDealFilter(f, testResolver{robust: id}, nil)
+	deal := func(client address.Address) storagemarket.MinerDeal {
+		return storagemarket.MinerDeal{
+			ClientDealProposal: market.ClientDealProposal{
+				Proposal: market.DealProposal{Client: client, StoragePricePerEpoch: big.Zero()},
+			},
+		}
+	}
+
+	ok, _, err := filter(context.Background(), deal(id))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, _, err = filter(context.Background(), deal(robust))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, _, err = filter(context.Background(), deal(unknown))
+	require.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ipfs/go-cid"
+	"go.opencensus.io/stats"
 	"go.uber.org/fx"
 	"golang.org/x/xerrors"
 
@@ -22,6 +23,7 @@ import (
 	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/actors"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/metrics"
 	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/storage/ctladdr"
 )
@@ -46,6 +48,8 @@ type dealPublisherAPI interface {
 // There is a configurable maximum number of deals that can be included in one
 // message. When the limit is reached the DealPublisher immediately submits a
 // publish message with all deals in the queue.
+// With manual publishing, deals stay in the queue until ForcePublishPendingDeals
+// is called.
 type DealPublisher struct {
 	api dealPublisherAPI
 	as  *ctladdr.AddressSelector
@@ -55,6 +59,7 @@ type DealPublisher struct {
 
 	maxDealsPerPublishMsg uint64
 	publishPeriod         time.Duration
+	manualPublish         bool
 	publishSpec           *api.MessageSendSpec
 
 	lk                      sync.Mutex
@@ -94,6 +99,9 @@ type PublishMsgConfig struct {
 	MaxDealsPerMsg uint64
 	// Minimum start epoch buffer to give time for sealing of sector with deal
 	StartEpochSealingBuffer uint64
+	// Don't publish deals automatically; they are held until publishing is
+	// requested through the API
+	ManualPublish bool
 }
 
 func NewDealPublisher(
@@ -131,6 +139,7 @@ func newDealPublisher(
 		Shutdown:                cancel,
 		maxDealsPerPublishMsg:   publishMsgCfg.MaxDealsPerMsg,
 		publishPeriod:           publishMsgCfg.Period,
+		manualPublish:           publishMsgCfg.ManualPublish,
 		startEpochSealingBuffer: abi.ChainEpoch(publishMsgCfg.StartEpochSealingBuffer),
 		publishSpec:             publishSpec,
 	}
@@ -226,9 +235,15 @@ func (p *DealPublisher) processNewDeal(pdeal *pendingDeal) {
 
 	// Add the new deal to the queue
 	p.pending = append(p.pending, pdeal)
+	p.recordQueueSize()
 	log.Infof("add deal with piece CID %s to publish deals queue - %d deals in queue (max queue size %d)",
 		pdeal.deal.Proposal.PieceCID, len(p.pending), p.maxDealsPerPublishMsg)
 
+	if p.manualPublish {
+		log.Infof("automatic deal publishing is disabled, deals in queue will be published when requested")
+		return
+	}
+
 	// If the maximum number of deals per message has been reached or we're not batching, send a
 	// publish message
 	if uint64(len(p.pending)) >= p.maxDealsPerPublishMsg || p.publishPeriod == 0 {
@@ -288,9 +303,24 @@ func (p *DealPublisher) publishAllDeals() {
 	p.filterCancelledDeals()
 	deals := p.pending
 	p.pending = nil
+	p.recordQueueSize()
 
-	// Send the publish message
-	go p.publishReady(deals)
+	// Send the publish messages, with at most maxDealsPerPublishMsg deals
+	// each; the queue can only grow past that with manual publishing
+	go func() {
+		for len(deals) > 0 {
+			n := len(deals)
+			if p.maxDealsPerPublishMsg > 0 && uint64(n) > p.maxDealsPerPublishMsg {
+				n = int(p.maxDealsPerPublishMsg)
+			}
+			p.publishReady(deals[:n])
+			deals = deals[n:]
+		}
+	}()
+}
+
+func (p *DealPublisher) recordQueueSize() {
+	stats.Record(p.ctx, metrics.DealPublishQueueSize.M(int64(len(p.pending))))
 }
 
 func (p *DealPublisher) publishReady(ready []*pendingDeal) {
@@ -232,6 +232,33 @@ func TestForcePublish(t *testing.T) {
 	checkPublishedDeals(t, dpapi, dealsToPublish, []int{2})
 }
 
+func TestManualPublish(t *testing.T) {
+	dpapi := newDPAPI(t)
+
+	dp := newDealPublisher(dpapi, nil, PublishMsgConfig{
+		MaxDealsPerMsg: 2,
+		ManualPublish:  true,
+	}, &api.MessageSendSpec{MaxFee: abi.NewTokenAmount(1)})
+
+	// Deals are held even past the maximum message size
+	var dealsToPublish []markettypes.ClientDealProposal
+	for i := 0; i < 3; i++ {
+		deal := publishDeal(t, dp, 0, false, false)
+		dealsToPublish = append(dealsToPublish, deal)
+	}
+
+	require.Eventually(t, func() bool {
+		return len(dp.PendingDeals().Deals) == 3
+	}, time.Second, time.Millisecond, "failed to queue deals")
+	require.Empty(t, dpapi.pushedMsgs)
+
+	// Publishing splits the queue into messages of at most MaxDealsPerMsg deals
+	dp.ForcePublishPendingDeals()
+	require.Len(t, dp.PendingDeals().Deals, 0)
+
+	checkPublishedDeals(t, dpapi, dealsToPublish, []int{2, 1})
+}
+
 func publishDeal(t *testing.T, dp *DealPublisher, invalid int, ctxCancelled bool, expired bool) markettypes.ClientDealProposal {
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
@@ -49,6 +49,7 @@ var (
 	WorkerHostname, _ = tag.NewKey("worker_hostname")
 	StorageID, _      = tag.NewKey("storage_id")
 	SectorState, _    = tag.NewKey("sector_state")
+	ShedPolicy, _     = tag.NewKey("shed_policy")
 
 	PathSeal, _    = tag.NewKey("path_seal")
 	PathStorage, _ = tag.NewKey("path_storage")
@@ -72,6 +73,7 @@ var (
 	// common
 	LotusInfo          = stats.Int64("info", "Arbitrary counter to tag lotus info to", stats.UnitDimensionless)
 	PeerCount          = stats.Int64("peer/count", "Current number of FIL peers", stats.UnitDimensionless)
+	BootstrapFailed    = stats.Int64("bootstrap_failed", "Set to 1 when all bootstrap connection attempts have failed", stats.UnitDimensionless)
 	APIRequestDuration = stats.Float64("api/request_duration_ms", "Duration of API requests", stats.UnitMilliseconds)
 
 	// graphsync
@@ -110,6 +112,7 @@ var (
 	BlockReceived                       = stats.Int64("block/received", "Counter for total received blocks", stats.UnitDimensionless)
 	BlockValidationFailure              = stats.Int64("block/failure", "Counter for block validation failures", stats.UnitDimensionless)
 	BlockValidationSuccess              = stats.Int64("block/success", "Counter for block validation successes", stats.UnitDimensionless)
+	BlockValidationTimeout              = stats.Int64("block/validation_timeout", "Counter for gossip blocks dropped because validation timed out", stats.UnitDimensionless)
 	BlockValidationDurationMilliseconds = stats.Float64("block/validation_ms", "Duration for Block Validation in ms", stats.UnitMilliseconds)
 	BlockDelay                          = stats.Int64("block/delay", "Delay of accepted blocks, where delay is >5s", stats.UnitMilliseconds)
 	PubsubPublishMessage                = stats.Int64("pubsub/published", "Counter for total published messages", stats.UnitDimensionless)
@@ -140,6 +143,10 @@ var (
 
 	SectorStates = stats.Int64("sealing/states", "Number of sectors in each state", stats.UnitDimensionless)
 
+	ProofVerifyDuration = stats.Float64("sealing/proof_verify_ms", "Duration of proof verification calls", stats.UnitMilliseconds)
+
+	SectorWriteVerifyErrors = stats.Int64("sealing/write_verify_errors", "Counter of sector data writes which failed read-back verification", stats.UnitDimensionless)
+
 	StorageFSAvailable      = stats.Float64("storage/path_fs_available_frac", "Fraction of filesystem available storage", stats.UnitDimensionless)
 	StorageAvailable        = stats.Float64("storage/path_available_frac", "Fraction of available storage", stats.UnitDimensionless)
 	StorageReserved         = stats.Float64("storage/path_reserved_frac", "Fraction of reserved storage", stats.UnitDimensionless)
@@ -157,6 +164,7 @@ var (
 	SchedAssignerSubmitDuration          = stats.Float64("sched/assigner_cycle_submit_ms", "Duration of scheduler window submit step", stats.UnitMilliseconds)
 	SchedCycleOpenWindows                = stats.Int64("sched/assigner_cycle_open_window", "Number of open windows in scheduling cycles", stats.UnitDimensionless)
 	SchedCycleQueueSize                  = stats.Int64("sched/assigner_cycle_task_queue_entry", "Number of task queue entries in scheduling cycles", stats.UnitDimensionless)
+	SchedTasksShed                       = stats.Int64("sched/tasks_shed", "Number of tasks failed because the scheduler queue was full", stats.UnitDimensionless)
 
 	DagStorePRInitCount      = stats.Int64("dagstore/pr_init_count", "PieceReader init count", stats.UnitDimensionless)
 	DagStorePRBytesRequested = stats.Int64("dagstore/pr_requested_bytes", "PieceReader requested bytes", stats.UnitBytes)
@@ -174,6 +182,8 @@ var (
 	DagStorePRAtReadBytes      = stats.Int64("dagstore/pr_at_read_bytes", "PieceReader ReadAt bytes read from source", stats.UnitBytes)    // PRReadSize tag
 	DagStorePRAtReadCount      = stats.Int64("dagstore/pr_at_read_count", "PieceReader ReadAt reads from source", stats.UnitDimensionless) // PRReadSize tag
 
+	DealPublishQueueSize = stats.Int64("market/deal_publish_queue_size", "Number of deals waiting to be published", stats.UnitDimensionless)
+
 	// splitstore
 	SplitstoreMiss                  = stats.Int64("splitstore/miss", "Number of misses in hotstre access", stats.UnitDimensionless)
 	SplitstoreCompactionTimeSeconds = stats.Float64("splitstore/compaction_time", "Compaction time in seconds", stats.UnitSeconds)
@@ -181,6 +191,12 @@ var (
 	SplitstoreCompactionCold        = stats.Int64("splitstore/cold", "Number of cold blocks in last compaction", stats.UnitDimensionless)
 	SplitstoreCompactionDead        = stats.Int64("splitstore/dead", "Number of dead blocks in last compaction", stats.UnitDimensionless)
 
+	// eth
+	EthTxLookupCacheHit      = stats.Int64("eth/tx_lookup_cache_hit", "Number of eth transaction lookups served by the cache", stats.UnitDimensionless)
+	EthTxLookupCacheMiss     = stats.Int64("eth/tx_lookup_cache_miss", "Number of eth transaction lookups not found in the cache", stats.UnitDimensionless)
+	EthTxLookupCacheHitRatio = stats.Float64("eth/tx_lookup_cache_hit_ratio", "Hit ratio of the eth transaction lookup cache", stats.UnitDimensionless)
+	EthSubscriptionDropped   = stats.Int64("eth/subscription_dropped", "Number of messages dropped because an eth subscriber's send queue was full", stats.UnitDimensionless)
+
 	// rcmgr
 	RcmgrAllowConn      = stats.Int64("rcmgr/allow_conn", "Number of allowed connections", stats.UnitDimensionless)
 	RcmgrBlockConn      = stats.Int64("rcmgr/block_conn", "Number of blocked connections", stats.UnitDimensionless)
@@ -234,6 +250,10 @@ var (
 		Measure:     BlockValidationSuccess,
 		Aggregation: view.Count(),
 	}
+	BlockValidationTimeoutView = &view.View{
+		Measure:     BlockValidationTimeout,
+		Aggregation: view.Count(),
+	}
 	BlockValidationDurationView = &view.View{
 		Measure:     BlockValidationDurationMilliseconds,
 		Aggregation: defaultMillisecondsDistribution,
@@ -311,6 +331,10 @@ var (
 		Measure:     PeerCount,
 		Aggregation: view.LastValue(),
 	}
+	BootstrapFailedView = &view.View{
+		Measure:     BootstrapFailed,
+		Aggregation: view.LastValue(),
+	}
 	PubsubPublishMessageView = &view.View{
 		Measure:     PubsubPublishMessage,
 		Aggregation: view.Count(),
@@ -415,11 +439,19 @@ var (
 		Aggregation: workMillisecondsDistribution,
 		TagKeys:     []tag.Key{TaskType, WorkerHostname},
 	}
+	ProofVerifyDurationView = &view.View{
+		Measure:     ProofVerifyDuration,
+		Aggregation: defaultMillisecondsDistribution,
+	}
 	SectorStatesView = &view.View{
 		Measure:     SectorStates,
 		Aggregation: view.LastValue(),
 		TagKeys:     []tag.Key{SectorState},
 	}
+	SectorWriteVerifyErrorsView = &view.View{
+		Measure:     SectorWriteVerifyErrors,
+		Aggregation: view.Count(),
+	}
 	StorageFSAvailableView = &view.View{
 		Measure:     StorageFSAvailable,
 		Aggregation: view.LastValue(),
@@ -495,6 +527,11 @@ var (
 		Measure:     SchedCycleQueueSize,
 		Aggregation: queueSizeDistribution,
 	}
+	SchedTasksShedView = &view.View{
+		Measure:     SchedTasksShed,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{ShedPolicy, TaskType},
+	}
 
 	DagStorePRInitCountView = &view.View{
 		Measure:     DagStorePRInitCount,
@@ -553,6 +590,11 @@ var (
 		TagKeys:     []tag.Key{PRReadSize},
 	}
 
+	DealPublishQueueSizeView = &view.View{
+		Measure:     DealPublishQueueSize,
+		Aggregation: view.LastValue(),
+	}
+
 	// splitstore
 	SplitstoreMissView = &view.View{
 		Measure:     SplitstoreMiss,
@@ -575,6 +617,24 @@ var (
 		Aggregation: view.Sum(),
 	}
 
+	// eth
+	EthTxLookupCacheHitView = &view.View{
+		Measure:     EthTxLookupCacheHit,
+		Aggregation: view.Count(),
+	}
+	EthTxLookupCacheMissView = &view.View{
+		Measure:     EthTxLookupCacheMiss,
+		Aggregation: view.Count(),
+	}
+	EthTxLookupCacheHitRatioView = &view.View{
+		Measure:     EthTxLookupCacheHitRatio,
+		Aggregation: view.LastValue(),
+	}
+	EthSubscriptionDroppedView = &view.View{
+		Measure:     EthSubscriptionDropped,
+		Aggregation: view.Count(),
+	}
+
 	// graphsync
 	GraphsyncReceivingPeersCountView = &view.View{
 		Measure:     GraphsyncReceivingPeersCount,
@@ -705,6 +765,7 @@ var DefaultViews = func() []*view.View {
 	views := []*view.View{
 		InfoView,
 		PeerCountView,
+		BootstrapFailedView,
 		APIRequestDurationView,
 
 		GraphsyncReceivingPeersCountView,
@@ -747,6 +808,7 @@ var ChainNodeViews = append([]*view.View{
 	BlockReceivedView,
 	BlockValidationFailureView,
 	BlockValidationSuccessView,
+	BlockValidationTimeoutView,
 	BlockValidationDurationView,
 	BlockDelayView,
 	IndexerMessageValidationFailureView,
@@ -776,6 +838,10 @@ var ChainNodeViews = append([]*view.View{
 	SplitstoreCompactionHotView,
 	SplitstoreCompactionColdView,
 	SplitstoreCompactionDeadView,
+	EthTxLookupCacheHitView,
+	EthTxLookupCacheMissView,
+	EthTxLookupCacheHitRatioView,
+	EthSubscriptionDroppedView,
 	VMApplyBlocksTotalView,
 	VMApplyMessagesView,
 	VMApplyEarlyView,
@@ -785,6 +851,7 @@ var ChainNodeViews = append([]*view.View{
 	VMAppliedView,
 	VMExecutionWaitingView,
 	VMExecutionRunningView,
+	ProofVerifyDurationView,
 }, DefaultViews...)
 
 var MinerNodeViews = append([]*view.View{
@@ -792,8 +859,10 @@ var MinerNodeViews = append([]*view.View{
 	WorkerCallsReturnedCountView,
 	WorkerUntrackedCallsReturnedView,
 	WorkerCallsReturnedDurationView,
+	ProofVerifyDurationView,
 
 	SectorStatesView,
+	SectorWriteVerifyErrorsView,
 	StorageFSAvailableView,
 	StorageAvailableView,
 	StorageReservedView,
@@ -811,6 +880,7 @@ var MinerNodeViews = append([]*view.View{
 	SchedAssignerSubmitDurationView,
 	SchedCycleOpenWindowsView,
 	SchedCycleQueueSizeView,
+	SchedTasksShedView,
 
 	DagStorePRInitCountView,
 	DagStorePRBytesRequestedView,
@@ -825,6 +895,8 @@ var MinerNodeViews = append([]*view.View{
 	DagStorePRAtCacheFillCountView,
 	DagStorePRAtReadBytesView,
 	DagStorePRAtReadCountView,
+
+	DealPublishQueueSizeView,
 }, DefaultViews...)
 
 var GatewayNodeViews = append([]*view.View{
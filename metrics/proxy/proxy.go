@@ -2,23 +2,84 @@ package proxy
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"reflect"
+	"time"
 
+	logging "github.com/ipfs/go-log/v2"
 	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/lib/requestid"
 	"github.com/filecoin-project/lotus/metrics"
 )
 
-func MetricedStorMinerAPI(a api.StorageMiner) api.StorageMiner {
+var log = logging.Logger("api")
+
+// Option configures the calls made through an API proxy, in addition to the metrics it records.
+type Option func(*options)
+
+type options struct {
+	permitted func(method string) bool
+	tracer    trace.Tracer
+	logCalls  bool
+}
+
+// WithMethodFilter rejects calls to the methods, given by name without the Filecoin. prefix, for which
+// permitted returns false. A nil permitted allows all methods.
+func WithMethodFilter(permitted func(method string) bool) Option {
+	return func(o *options) {
+		o.permitted = permitted
+	}
+}
+
+// WithTracer records a span with tracer for each call, named after the method and carrying the method
+// and the duration of the call in milliseconds. The span is passed down in the call context, so spans
+// recorded while serving the call are nested in it.
+func WithTracer(tracer trace.Tracer) Option {
+	return func(o *options) {
+		o.tracer = tracer
+	}
+}
+
+// WithCallLogging logs each call at debug level with the method, a SHA256 hash of the JSON encoded
+// params, the caller IP attached with WithCallerIP, the duration of the call in milliseconds and the
+// error returned, if any. The params themselves aren't logged, as they may hold sensitive data.
+func WithCallLogging() Option {
+	return func(o *options) {
+		o.logCalls = true
+	}
+}
+
+type callerIPKey struct{}
+
+// WithCallerIP returns a copy of ctx carrying the IP address of the client making API calls with it.
+func WithCallerIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, callerIPKey{}, ip)
+}
+
+// CallerIP returns the client IP address attached to ctx with WithCallerIP, if any.
+func CallerIP(ctx context.Context) string {
+	ip, _ := ctx.Value(callerIPKey{}).(string)
+	return ip
+}
+
+func MetricedStorMinerAPI(a api.StorageMiner, opts ...Option) api.StorageMiner {
 	var out api.StorageMinerStruct
-	proxy(a, &out)
+	proxy(a, &out, opts...)
 	return &out
 }
 
-func MetricedFullAPI(a api.FullNode) api.FullNode {
+func MetricedFullAPI(a api.FullNode, opts ...Option) api.FullNode {
 	var out api.FullNodeStruct
-	proxy(a, &out)
+	proxy(a, &out, opts...)
 	return &out
 }
 
@@ -40,7 +101,12 @@ func MetricedGatewayAPI(a api.Gateway) api.Gateway {
 	return &out
 }
 
-func proxy(in interface{}, outstr interface{}) {
+func proxy(in interface{}, outstr interface{}, opts ...Option) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	outs := api.GetInternalStructs(outstr)
 	for _, out := range outs {
 		rint := reflect.ValueOf(out).Elem()
@@ -52,14 +118,89 @@ func proxy(in interface{}, outstr interface{}) {
 
 			rint.Field(f).Set(reflect.MakeFunc(field.Type, func(args []reflect.Value) (results []reflect.Value) {
 				ctx := args[0].Interface().(context.Context)
+				if o.permitted != nil && !o.permitted(field.Name) {
+					log.Warnw("rejected call to disallowed API method", "method", field.Name, "remote", CallerIP(ctx))
+					return errorResults(field.Type, xerrors.Errorf("method %s is not allowed on this endpoint", field.Name))
+				}
+
+				var span trace.Span
+				if o.tracer != nil {
+					ctx, span = o.tracer.Start(ctx, field.Name, trace.WithSpanKind(trace.SpanKindServer))
+					defer span.End()
+				}
+
 				// upsert function name into context
 				ctx, _ = tag.New(ctx, tag.Upsert(metrics.Endpoint, field.Name))
 				stop := metrics.Timer(ctx, metrics.APIRequestDuration)
 				defer stop()
 				// pass tagged ctx back into function call
 				args[0] = reflect.ValueOf(ctx)
-				return fn.Call(args)
+				start := time.Now()
+				results = fn.Call(args)
+				err := resultError(results)
+				if _, ok := requestid.FromContext(ctx); ok && err != nil {
+					requestid.Logger(ctx, log).Debugw("api call failed", "method", field.Name, "error", err)
+				}
+				if span != nil {
+					span.SetAttributes(
+						attribute.String("method", field.Name),
+						attribute.Int64("duration_ms", time.Since(start).Milliseconds()),
+					)
+					if err != nil {
+						span.SetStatus(codes.Error, err.Error())
+					}
+				}
+				// hashing the params is costly, only do it when the call is logged
+				if o.logCalls && log.Desugar().Core().Enabled(zapcore.DebugLevel) {
+					var errMsg string
+					if err != nil {
+						errMsg = err.Error()
+					}
+					requestid.Logger(ctx, log).Debugw("api call",
+						"method", field.Name,
+						"params_hash", paramsHash(args[1:]),
+						"caller_ip", CallerIP(ctx),
+						"duration_ms", time.Since(start).Milliseconds(),
+						"error", errMsg)
+				}
+				return results
 			}))
 		}
 	}
 }
+
+// paramsHash returns the hex encoded SHA256 hash of the JSON encoding of the params of a call, or an
+// empty string if they can't be encoded.
+func paramsHash(params []reflect.Value) string {
+	values := make([]interface{}, len(params))
+	for i, p := range params {
+		values[i] = p.Interface()
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// resultError returns the error returned by a call, if any.
+func resultError(results []reflect.Value) error {
+	if len(results) == 0 {
+		return nil
+	}
+	err, _ := results[len(results)-1].Interface().(error)
+	return err
+}
+
+// errorResults returns the results of a call to a function of type ft failing with err.
+func errorResults(ft reflect.Type, err error) []reflect.Value {
+	results := make([]reflect.Value, ft.NumOut())
+	for i := range results {
+		results[i] = reflect.Zero(ft.Out(i))
+	}
+	if len(results) > 0 {
+		results[len(results)-1] = reflect.ValueOf(&err).Elem()
+	}
+	return results
+}
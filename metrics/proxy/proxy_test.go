@@ -0,0 +1,100 @@
+package proxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+type testFullAPI struct {
+	api.FullNodeStub
+}
+
+func (a *testFullAPI) ChainHead(context.Context) (*types.TipSet, error) {
+	return nil, nil
+}
+
+func TestWithMethodFilter(t *testing.T) {
+	ctx := context.Background()
+	a := MetricedFullAPI(&testFullAPI{}, WithMethodFilter(func(method string) bool {
+		return method == "ChainHead"
+	}))
+
+	_, err := a.ChainHead(ctx)
+	require.NoError(t, err)
+
+	_, err = a.ChainGetGenesis(ctx)
+	require.ErrorContains(t, err, "method ChainGetGenesis is not allowed")
+
+	// without a filter, calls go through to the api
+	_, err = MetricedFullAPI(&testFullAPI{}).ChainGetGenesis(ctx)
+	require.ErrorIs(t, err, api.ErrNotSupported)
+}
+
+func TestCallerIP(t *testing.T) {
+	require.Equal(t, "", CallerIP(context.Background()))
+	require.Equal(t, "10.0.0.1", CallerIP(WithCallerIP(context.Background(), "10.0.0.1")))
+}
+
+func TestWithTracer(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tracer := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(rec)).Tracer("test")
+
+	ctx := context.Background()
+	a := MetricedFullAPI(&testFullAPI{}, WithTracer(tracer))
+	_, err := a.ChainHead(ctx)
+	require.NoError(t, err)
+	_, err = a.ChainGetGenesis(ctx)
+	require.Error(t, err)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, "ChainHead", spans[0].Name())
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Equal(t, "ChainGetGenesis", spans[1].Name())
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	require.Equal(t, "ChainHead", attrs["method"].AsString())
+	require.Contains(t, attrs, attribute.Key("duration_ms"))
+}
+
+func TestParamsHash(t *testing.T) {
+	hash := func(s string) string {
+		h := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+
+	require.Equal(t, hash(`["f01000",true]`), paramsHash([]reflect.Value{reflect.ValueOf("f01000"), reflect.ValueOf(true)}))
+	require.Equal(t, hash(`[]`), paramsHash(nil))
+
+	// params which can't be encoded aren't hashed
+	require.Equal(t, "", paramsHash([]reflect.Value{reflect.ValueOf(make(chan struct{}))}))
+}
+
+func TestWithCallLogging(t *testing.T) {
+	_ = logging.SetLogLevel("api", "DEBUG")
+	defer logging.SetLogLevel("api", "INFO") //nolint:errcheck
+
+	ctx := WithCallerIP(context.Background(), "10.0.0.1")
+	a := MetricedFullAPI(&testFullAPI{}, WithCallLogging())
+	_, err := a.ChainHead(ctx)
+	require.NoError(t, err)
+	_, err = a.ChainGetGenesis(ctx)
+	require.ErrorIs(t, err, api.ErrNotSupported)
+}
@@ -72,6 +72,8 @@ var (
 	DAGStoreKey          = special{13} // constructor returns multiple values
 	ResourceManagerKey   = special{14} // Libp2p option
 	UserAgentKey         = special{15} // Libp2p option
+
+	ObservedAddrActivationKey = special{16} // Libp2p option
 )
 
 type invoke int
@@ -96,6 +98,7 @@ const (
 	PstoreAddSelfKeysKey
 	StartListeningKey
 	BootstrapKey
+	RegisterHostMetricsKey
 
 	// filecoin
 	SetGenesisKey
@@ -117,6 +120,9 @@ const (
 	GetParamsKey
 	HandleMigrateProviderFundsKey
 	HandleDealsKey
+	HandleStoragePriceOracleKey
+	HandleSectorWatcherKey
+	GPUTemperatureGuardKey
 	HandleRetrievalKey
 	RunSectorServiceKey
 
@@ -229,12 +235,12 @@ var LibP2P = Options(
 	}),
 
 	// Services (connection management)
-	Override(ConnectionManagerKey, lp2p.ConnectionManager(50, 200, 20*time.Second, nil)),
+	Override(ConnectionManagerKey, lp2p.ConnectionManager(50, 200, 20*time.Second, 0, 0, nil)),
 	Override(new(*conngater.BasicConnectionGater), lp2p.ConnGater),
 	Override(ConnGaterKey, lp2p.ConnGaterOption),
 
 	// Services (resource management)
-	Override(new(network.ResourceManager), lp2p.ResourceManager(200)),
+	Override(new(network.ResourceManager), lp2p.ResourceManager(200, nil)),
 	Override(ResourceManagerKey, lp2p.ResourceManagerOption),
 )
 
@@ -269,6 +275,11 @@ func ConfigCommon(cfg *config.Common, enableLibp2pNode bool) Option {
 	// setup logging early
 	lotuslog.SetLevelsFromConfig(cfg.Logging.SubsystemLevels)
 
+	listenAddrs := cfg.Libp2p.ListenAddresses
+	if !cfg.Libp2p.WebTransportEnabled {
+		listenAddrs = lp2p.WithoutWebTransport(listenAddrs)
+	}
+
 	return Options(
 		func(s *Settings) error { s.Config = true; return nil },
 		Override(new(dtypes.APIEndpoint), func() (dtypes.APIEndpoint, error) {
@@ -292,13 +303,15 @@ func ConfigCommon(cfg *config.Common, enableLibp2pNode bool) Option {
 		If(enableLibp2pNode,
 			Override(new(api.Net), From(new(net.NetAPI))),
 			Override(new(api.Common), From(new(common.CommonAPI))),
-			Override(StartListeningKey, lp2p.StartListening(cfg.Libp2p.ListenAddresses)),
+			Override(StartListeningKey, lp2p.StartListening(listenAddrs)),
 			Override(ConnectionManagerKey, lp2p.ConnectionManager(
 				cfg.Libp2p.ConnMgrLow,
 				cfg.Libp2p.ConnMgrHigh,
 				time.Duration(cfg.Libp2p.ConnMgrGrace),
+				time.Duration(cfg.Libp2p.ConnMgrEmergencyGracePeriod),
+				time.Duration(cfg.Libp2p.ConnMgrSilencePeriod),
 				cfg.Libp2p.ProtectedPeers)),
-			Override(new(network.ResourceManager), lp2p.ResourceManager(cfg.Libp2p.ConnMgrHigh)),
+			Override(new(network.ResourceManager), lp2p.ResourceManager(cfg.Libp2p.ConnMgrHigh, cfg.Libp2p.ProtocolPeerLimits)),
 			Override(new(*pubsub.PubSub), lp2p.GossipSub),
 			Override(new(*config.Pubsub), &cfg.Pubsub),
 
@@ -310,6 +323,12 @@ func ConfigCommon(cfg *config.Common, enableLibp2pNode bool) Option {
 				cfg.Libp2p.AnnounceAddresses,
 				cfg.Libp2p.NoAnnounceAddresses)),
 
+			Override(ObservedAddrActivationKey, lp2p.ObservedAddrActivationThreshold(cfg.Libp2p.ObservedAddressActivationThreshold)),
+
+			If(cfg.Libp2p.MetricsReporter,
+				Override(RegisterHostMetricsKey, lp2p.RegisterHostMetrics),
+			),
+
 			If(!cfg.Libp2p.DisableNatPortMap, Override(NatPortMapKey, lp2p.NatPortMap)),
 		),
 		Override(new(dtypes.MetadataDS), modules.Datastore(cfg.Backup.DisableMetadataLog)),
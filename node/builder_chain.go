@@ -2,6 +2,7 @@ package node
 
 import (
 	"os"
+	"time"
 
 	gorpc "github.com/libp2p/go-libp2p-gorpc"
 	"go.uber.org/fx"
@@ -95,14 +96,14 @@ var ChainNode = Options(
 	// Chain networking
 	Override(new(*hello.Service), hello.NewHelloService),
 	Override(new(exchange.Server), exchange.NewServer),
-	Override(new(*peermgr.PeerMgr), peermgr.NewPeerMgr),
+	Override(new(*peermgr.PeerMgr), peermgr.NewPeerMgr(config.DefaultFullNode().Libp2p.BootstrapRetryMax, time.Duration(config.DefaultFullNode().Libp2p.BootstrapRetryDelay))),
 
 	// Chain mining API dependencies
 	Override(new(*slashfilter.SlashFilter), modules.NewSlashFilter),
 
 	// Service: Message Pool
 	Override(new(dtypes.DefaultMaxFeeFunc), modules.NewDefaultMaxFeeFunc),
-	Override(new(*messagepool.MessagePool), modules.MessagePool),
+	Override(new(*messagepool.MessagePool), modules.MessagePool(messagepool.RepublishInterval, 0, messagepool.MsgSelectPolicyFee, 0, 0)),
 	Override(new(*dtypes.MpoolLocker), new(dtypes.MpoolLocker)),
 
 	// Shared graphsync (markets, serving chain)
@@ -155,6 +156,7 @@ var ChainNode = Options(
 		Override(new(stmgr.StateManagerAPI), rpcstmgr.NewRPCStateManager),
 		Override(new(full.EthModuleAPI), From(new(api.Gateway))),
 		Override(new(full.EthEventAPI), From(new(api.Gateway))),
+		Override(new(full.EthWalletAPI), &full.EthModuleDummy{}),
 	),
 
 	// Full node API / service startup
@@ -171,7 +173,7 @@ var ChainNode = Options(
 		Override(RunChainExchangeKey, modules.RunChainExchange),
 		Override(RunPeerMgrKey, modules.RunPeerMgr),
 		Override(HandleIncomingMessagesKey, modules.HandleIncomingMessages),
-		Override(HandleIncomingBlocksKey, modules.HandleIncomingBlocks),
+		Override(HandleIncomingBlocksKey, modules.HandleIncomingBlocks(0, 0, 0)),
 	),
 )
 
@@ -184,6 +186,12 @@ func ConfigFullNode(c interface{}) Option {
 	enableLibp2pNode := true // always enable libp2p for full nodes
 
 	ipfsMaddr := cfg.Client.IpfsMAddr
+
+	validatorCacheSize := 0
+	if cfg.Chainstore.ValidatorCacheEnabled {
+		validatorCacheSize = cfg.Chainstore.ValidatorCacheSize
+	}
+
 	return Options(
 		ConfigCommon(&cfg.Common, enableLibp2pNode),
 
@@ -195,7 +203,7 @@ func ConfigFullNode(c interface{}) Option {
 			If(cfg.Chainstore.Splitstore.ColdStoreType == "discard",
 				Override(new(dtypes.ColdBlockstore), modules.DiscardColdBlockstore)),
 			If(cfg.Chainstore.Splitstore.HotStoreType == "badger",
-				Override(new(dtypes.HotBlockstore), modules.BadgerHotBlockstore)),
+				Override(new(dtypes.HotBlockstore), modules.BadgerHotBlockstore(&cfg.Chainstore))),
 			Override(new(dtypes.SplitBlockstore), modules.SplitBlockstore(&cfg.Chainstore)),
 			Override(new(dtypes.BasicChainBlockstore), modules.ChainSplitBlockstore),
 			Override(new(dtypes.BasicStateBlockstore), modules.StateSplitBlockstore),
@@ -211,9 +219,35 @@ func ConfigFullNode(c interface{}) Option {
 			Override(new(dtypes.GCReferenceProtector), modules.NoopGCReferenceProtector),
 		),
 
-		Override(new(dtypes.ChainBlockstore), From(new(dtypes.BasicChainBlockstore))),
+		If(cfg.Chainstore.BlockCacheSizeBytes == 0 || cfg.Chainstore.EnableSplitstore,
+			Override(new(dtypes.ChainBlockstore), From(new(dtypes.BasicChainBlockstore))),
+		),
+		If(cfg.Chainstore.BlockCacheSizeBytes > 0 && !cfg.Chainstore.EnableSplitstore,
+			Override(new(dtypes.ChainBlockstore), modules.CachedChainBlockstore(cfg.Chainstore.BlockCacheSizeBytes)),
+		),
 		Override(new(dtypes.StateBlockstore), From(new(dtypes.BasicStateBlockstore))),
 
+		Override(new(*messagepool.MessagePool), modules.MessagePool(time.Duration(cfg.Chainstore.MsgPoolRepublishInterval), time.Duration(cfg.Fevm.EthPendingTransactionTimeout), cfg.Chainstore.MsgSelectPolicy, cfg.Chainstore.MsgMaxQueueSizePerSender, cfg.Chainstore.MsgRepublishMaxAttempts)),
+		If(!cfg.Chainstore.StateManagerCacheEnabled,
+			Override(new(*stmgr.StateManager), modules.StateManagerWithoutCache),
+		),
+		If(cfg.Chainstore.TipsetWeightAlgorithm == "flat",
+			Override(new(store.WeightFunc), filcns.FlatWeight),
+		),
+		If(cfg.Chainstore.NetworkVersionOverride != 0,
+			Override(new(dtypes.NetworkVersionOverride), dtypes.NetworkVersionOverride(cfg.Chainstore.NetworkVersionOverride))),
+		If(len(cfg.Chainstore.BeaconEndpoints) > 0,
+			Override(new(dtypes.DrandSchedule), modules.DrandConfigWithServers(cfg.Chainstore.BeaconEndpoints)),
+		),
+		Override(new(*peermgr.PeerMgr), peermgr.NewPeerMgr(cfg.Libp2p.BootstrapRetryMax, time.Duration(cfg.Libp2p.BootstrapRetryDelay))),
+		Override(new(exchange.Client), modules.ExchangeClient(cfg.Chainstore.SyncPeerScoreMinimum)),
+		Override(new(chain.SyncManagerCtor), func() chain.SyncManagerCtor {
+			return chain.SyncManagerWithLimits(cfg.Chainstore.MinSyncWorkers, cfg.Chainstore.MaxSyncWorkers, cfg.Chainstore.BootstrapSyncMinPeers)
+		}),
+		ApplyIf(isFullNode,
+			Override(HandleIncomingBlocksKey, modules.HandleIncomingBlocks(time.Duration(cfg.Chainstore.GossipBlockValidationTimeout), validatorCacheSize, cfg.Chainstore.SimultaneousBlockFetchLimit)),
+		),
+
 		If(os.Getenv("LOTUS_ENABLE_CHAINSTORE_FALLBACK") == "1",
 			Override(new(dtypes.ChainBlockstore), modules.FallbackChainBlockstore),
 			Override(new(dtypes.StateBlockstore), modules.FallbackStateBlockstore),
@@ -268,12 +302,15 @@ func ConfigFullNode(c interface{}) Option {
 		// in lite-mode Eth api is provided by gateway
 		ApplyIf(isFullNode,
 			If(cfg.Fevm.EnableEthRPC,
-				Override(new(full.EthModuleAPI), modules.EthModuleAPI(cfg.Fevm)),
+				Override(new(*full.EthModule), modules.EthModuleAPI(cfg.Fevm)),
+				Override(new(full.EthModuleAPI), From(new(*full.EthModule))),
+				Override(new(full.EthWalletAPI), From(new(*full.EthModule))),
 				Override(new(full.EthEventAPI), modules.EthEventAPI(cfg.Fevm)),
 			),
 			If(!cfg.Fevm.EnableEthRPC,
 				Override(new(full.EthModuleAPI), &full.EthModuleDummy{}),
 				Override(new(full.EthEventAPI), &full.EthModuleDummy{}),
+				Override(new(full.EthWalletAPI), &full.EthModuleDummy{}),
 			),
 		),
 
@@ -76,6 +76,19 @@ func ConfigStorageMiner(c interface{}) Option {
 
 	enableLibp2pNode := cfg.Subsystems.EnableMarkets // we enable libp2p nodes if the storage market subsystem is enabled, otherwise we don't
 
+	var storageDealFilter dtypes.StorageDealFilter
+	if cfg.Dealmaking.Filter != "" {
+		storageDealFilter = dealfilter.CliStorageDealFilter(cfg.Dealmaking.Filter)
+	}
+	var storageDealFilterExpr dealfilter.DealFilter
+	if cfg.Dealmaking.FilterExpression != "" {
+		f, err := dealfilter.CompileDealFilter(cfg.Dealmaking.FilterExpression)
+		if err != nil {
+			return Error(xerrors.Errorf("invalid Dealmaking.FilterExpression: %w", err))
+		}
+		storageDealFilterExpr = f
+	}
+
 	return Options(
 
 		Override(new(v1api.FullNode), modules.MakeUuidWrapper),
@@ -120,6 +133,11 @@ func ConfigStorageMiner(c interface{}) Option {
 			Override(new(gen.WinningPoStProver), storage.NewWinningPoStProver),
 			Override(PreflightChecksKey, modules.PreflightChecks),
 			Override(new(*sealing.Sealing), modules.SealingPipeline(cfg.Fees)),
+			If(cfg.Sealing.SectorBuildWatcher,
+				If(!enableLibp2pNode, Error(xerrors.Errorf("the sector build watcher publishes over pubsub and requires the markets subsystem to be enabled"))),
+				Override(new(dtypes.SectorWatcherTopic), dtypes.SectorWatcherTopic(cfg.Sealing.SectorWatcherTopicName)),
+				Override(HandleSectorWatcherKey, modules.HandleSectorWatcher),
+			),
 
 			Override(new(*wdpost.WindowPoStScheduler), modules.WindowPostScheduler(cfg.Fees, cfg.Proving)),
 			Override(new(sectorblocks.SectorBuilder), From(new(*sealing.Sealing))),
@@ -133,6 +151,9 @@ func ConfigStorageMiner(c interface{}) Option {
 			Override(new(sectorstorage.Unsealer), From(new(*sectorstorage.Manager))),
 			Override(new(sectorstorage.SectorManager), From(new(*sectorstorage.Manager))),
 			Override(new(storiface.WorkerReturn), From(new(sectorstorage.SectorManager))),
+			If(cfg.Storage.GPUTemperatureLimit > 0,
+				Override(GPUTemperatureGuardKey, modules.GPUTemperatureGuard(cfg.Storage.GPUTemperatureLimit, time.Duration(cfg.Storage.GPUTemperaturePollInterval))),
+			),
 		),
 
 		If(!cfg.Subsystems.EnableSectorStorage,
@@ -154,7 +175,7 @@ func ConfigStorageMiner(c interface{}) Option {
 			// Markets
 			Override(new(dtypes.StagingBlockstore), modules.StagingBlockstore),
 			Override(new(dtypes.StagingGraphsync), modules.StagingGraphsync(cfg.Dealmaking.SimultaneousTransfersForStorage, cfg.Dealmaking.SimultaneousTransfersForStoragePerClient, cfg.Dealmaking.SimultaneousTransfersForRetrieval)),
-			Override(new(dtypes.ProviderPieceStore), modules.NewProviderPieceStore),
+			Override(new(dtypes.ProviderPieceStore), modules.NewProviderPieceStore(cfg.DAGStore)),
 			Override(new(*sectorblocks.SectorBlocks), sectorblocks.NewSectorBlocks),
 
 			// Markets (retrieval deps)
@@ -168,7 +189,7 @@ func ConfigStorageMiner(c interface{}) Option {
 			Override(new(dtypes.RetrievalPricingFunc), modules.RetrievalPricingFunc(cfg.Dealmaking)),
 
 			// DAG Store
-			Override(new(dagstore.MinerAPI), modules.NewMinerAPI(cfg.DAGStore)),
+			Override(new(dagstore.MinerAPI), modules.NewMinerAPI(cfg.DAGStore, cfg.Dealmaking.PieceRetrievalTransfer)),
 			Override(DAGStoreKey, modules.DAGStore(cfg.DAGStore)),
 
 			// Markets (retrieval)
@@ -187,11 +208,14 @@ func ConfigStorageMiner(c interface{}) Option {
 			Override(new(idxprov.MeshCreator), idxprov.NewMeshCreator),
 			Override(new(provider.Interface), modules.IndexProvider(cfg.IndexProvider)),
 			Override(new(*storedask.StoredAsk), modules.NewStorageAsk),
-			Override(new(dtypes.StorageDealFilter), modules.BasicDealFilter(cfg.Dealmaking, nil)),
+			Override(new(dtypes.StorageDealFilter), modules.BasicDealFilter(cfg.Dealmaking, nil, nil)),
 			Override(new(storagemarket.StorageProvider), modules.StorageProvider),
 			Override(new(*storageadapter.DealPublisher), storageadapter.NewDealPublisher(nil, storageadapter.PublishMsgConfig{})),
 			Override(HandleMigrateProviderFundsKey, modules.HandleMigrateProviderFunds),
 			Override(HandleDealsKey, modules.HandleDeals),
+			If(cfg.Dealmaking.StoragePriceOracle != "",
+				Override(HandleStoragePriceOracleKey, modules.HandleStoragePriceOracle(cfg.Dealmaking)),
+			),
 
 			// Config (todo: get a real property system)
 			Override(new(dtypes.ConsiderOnlineStorageDealsConfigFunc), modules.NewConsiderOnlineStorageDealsConfigFunc),
@@ -213,8 +237,8 @@ func ConfigStorageMiner(c interface{}) Option {
 			Override(new(dtypes.SetMaxDealStartDelayFunc), modules.NewSetMaxDealStartDelayFunc),
 			Override(new(dtypes.GetMaxDealStartDelayFunc), modules.NewGetMaxDealStartDelayFunc),
 
-			If(cfg.Dealmaking.Filter != "",
-				Override(new(dtypes.StorageDealFilter), modules.BasicDealFilter(cfg.Dealmaking, dealfilter.CliStorageDealFilter(cfg.Dealmaking.Filter))),
+			If(storageDealFilter != nil || storageDealFilterExpr != nil,
+				Override(new(dtypes.StorageDealFilter), modules.BasicDealFilter(cfg.Dealmaking, storageDealFilter, storageDealFilterExpr)),
 			),
 
 			If(cfg.Dealmaking.RetrievalFilter != "",
@@ -224,6 +248,7 @@ func ConfigStorageMiner(c interface{}) Option {
 				Period:                  time.Duration(cfg.Dealmaking.PublishMsgPeriod),
 				MaxDealsPerMsg:          cfg.Dealmaking.MaxDealsPerPublishMsg,
 				StartEpochSealingBuffer: cfg.Dealmaking.StartEpochSealingBuffer,
+				ManualPublish:           !cfg.Dealmaking.AutoPublishEnabled,
 			})),
 			Override(new(storagemarket.StorageProviderNode), storageadapter.NewProviderNodeAdapter(&cfg.Fees, &cfg.Dealmaking)),
 		),
@@ -2,11 +2,14 @@ package config
 
 import (
 	"encoding"
+	"encoding/json"
+	stdbig "math/big"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
@@ -26,6 +29,34 @@ const (
 	RetrievalPricingExternalMode = "external"
 )
 
+const (
+	// EthSyncStatusModeFilecoin makes eth_syncing report the state of the Filecoin sync workers.
+	EthSyncStatusModeFilecoin = "filecoin"
+	// EthSyncStatusModeEthereum makes eth_syncing report the node as syncing until its head
+	// catches up with the current epoch, as Ethereum clients do.
+	EthSyncStatusModeEthereum = "ethereum"
+	// EthSyncStatusModeAuto selects the recommended mode, currently EthSyncStatusModeEthereum.
+	EthSyncStatusModeAuto = "auto"
+)
+
+const (
+	// EthAddressMappingDelegatedFirst presents actors by their delegated address when they have one.
+	EthAddressMappingDelegatedFirst = "delegated-first"
+	// EthAddressMappingIDBased presents actors by their masked ID address.
+	EthAddressMappingIDBased = "id-based"
+	// EthAddressMappingStrict only accepts Ethereum addresses in Eth API requests.
+	EthAddressMappingStrict = "strict"
+)
+
+const (
+	// SubscriptionBackpressureDropOldest discards the oldest queued message when a subscriber's buffer is full.
+	SubscriptionBackpressureDropOldest = "drop-oldest"
+	// SubscriptionBackpressureDropNewest discards the new message when a subscriber's buffer is full.
+	SubscriptionBackpressureDropNewest = "drop-newest"
+	// SubscriptionBackpressureBlock waits for room in a subscriber's buffer, up to the send timeout.
+	SubscriptionBackpressureBlock = "block"
+)
+
 // MaxTraversalLinks configures the maximum number of links to traverse in a DAG while calculating
 // CommP and traversing a DAG with graphsync; invokes a budget on DAG depth and density.
 var MaxTraversalLinks uint64 = 32 * (1 << 20)
@@ -37,7 +68,63 @@ func init() {
 }
 
 func (b *BatchFeeConfig) FeeForSectors(nSectors int) abi.TokenAmount {
-	return big.Add(big.Int(b.Base), big.Mul(big.NewInt(int64(nSectors)), big.Int(b.PerSector)))
+	fee, _ := b.EffectiveFeeForSectors(nSectors)
+	return fee
+}
+
+// EffectiveFeeForSectors returns the max fee for a batch of nSectors, limited
+// to MaxTotalFee when it is set, and whether that cap was applied.
+func (b *BatchFeeConfig) EffectiveFeeForSectors(nSectors int) (fee abi.TokenAmount, capped bool) {
+	fee = big.Add(big.Int(b.Base), big.Mul(big.NewInt(int64(nSectors)), big.Int(b.PerSector)))
+
+	maxTotal := big.Int(b.MaxTotalFee)
+	if maxTotal.Nil() || maxTotal.IsZero() || fee.LessThanEqual(maxTotal) {
+		return fee, false
+	}
+	return maxTotal, true
+}
+
+// maxFeeScale bounds the factor accepted by MinerFeeConfig.Scale.
+const maxFeeScale = 1000
+
+// Scale returns a copy of the batch fee config with all fees multiplied by
+// factor, rounded down to the nearest attoFIL.
+func (b *BatchFeeConfig) Scale(factor float64) BatchFeeConfig {
+	r := new(stdbig.Rat).SetFloat64(factor)
+	return BatchFeeConfig{
+		Base:        scaleFIL(b.Base, r),
+		PerSector:   scaleFIL(b.PerSector, r),
+		MaxTotalFee: scaleFIL(b.MaxTotalFee, r),
+	}
+}
+
+// Scale returns a copy of the fee config with all fee caps multiplied by
+// factor, e.g. to follow a base fee spike. The factor must be in (0, 1000].
+func (c *MinerFeeConfig) Scale(factor float64) (MinerFeeConfig, error) {
+	if !(factor > 0 && factor <= maxFeeScale) {
+		return MinerFeeConfig{}, xerrors.Errorf("fee scale factor must be in (0, %d], got %v", maxFeeScale, factor)
+	}
+
+	r := new(stdbig.Rat).SetFloat64(factor)
+	return MinerFeeConfig{
+		MaxPreCommitGasFee:       scaleFIL(c.MaxPreCommitGasFee, r),
+		MaxCommitGasFee:          scaleFIL(c.MaxCommitGasFee, r),
+		MaxPreCommitBatchGasFee:  c.MaxPreCommitBatchGasFee.Scale(factor),
+		MaxCommitBatchGasFee:     c.MaxCommitBatchGasFee.Scale(factor),
+		MaxTerminateGasFee:       scaleFIL(c.MaxTerminateGasFee, r),
+		MaxWindowPoStGasFee:      scaleFIL(c.MaxWindowPoStGasFee, r),
+		MaxPublishDealsFee:       scaleFIL(c.MaxPublishDealsFee, r),
+		MaxMarketBalanceAddFee:   scaleFIL(c.MaxMarketBalanceAddFee, r),
+		MaximizeWindowPoStFeeCap: c.MaximizeWindowPoStFeeCap,
+	}, nil
+}
+
+func scaleFIL(f types.FIL, r *stdbig.Rat) types.FIL {
+	if f.Int == nil {
+		return f
+	}
+	n := new(stdbig.Int).Mul(f.Int, r.Num())
+	return types.FIL{Int: n.Quo(n, r.Denom())}
 }
 
 func defCommon() Common {
@@ -45,6 +132,10 @@ func defCommon() Common {
 		API: API{
 			ListenAddress: "/ip4/127.0.0.1/tcp/1234/http",
 			Timeout:       Duration(30 * time.Second),
+
+			RequestIDHeader: "X-Request-ID",
+
+			OpenTelemetryServiceName: "lotus",
 		},
 		Logging: Logging{
 			SubsystemLevels: map[string]string{
@@ -63,12 +154,22 @@ func defCommon() Common {
 				"/ip4/0.0.0.0/udp/0/quic-v1/webtransport",
 				"/ip6/::/udp/0/quic-v1/webtransport",
 			},
+			WebTransportEnabled: true,
 			AnnounceAddresses:   []string{},
 			NoAnnounceAddresses: []string{},
 
 			ConnMgrLow:   150,
 			ConnMgrHigh:  180,
 			ConnMgrGrace: Duration(20 * time.Second),
+
+			ConnMgrEmergencyGracePeriod: Duration(0),
+			ConnMgrSilencePeriod:        Duration(0),
+
+			BootstrapRetryMax: 5,
+
+			ObservedAddressActivationThreshold: 4,
+
+			MetricsReporter: false,
 		},
 		Pubsub: Pubsub{
 			Bootstrapper: false,
@@ -104,12 +205,56 @@ func DefaultFullNode() *FullNode {
 				HotStoreMaxSpaceTarget:       650_000_000_000,
 				HotStoreMaxSpaceThreshold:    150_000_000_000,
 				HotstoreMaxSpaceSafetyBuffer: 50_000_000_000,
+				MovingGCThresholdRatio:       0.9,
 			},
+			GCLockTimeout: Duration(5 * time.Minute),
+
+			MsgSelectPolicy:              "fee",
+			MsgMaxQueueSizePerSender:     100,
+			MsgRepublishMaxAttempts:      0,
+			TipsetWeightAlgorithm:        "filecoin-ec",
+			GossipBlockValidationTimeout: Duration(30 * time.Second),
+			ValidatorCacheEnabled:        true,
+			ValidatorCacheSize:           2048,
+			BeaconEndpoints:              []string{},
+			SimultaneousBlockFetchLimit:  64,
+			MinSyncWorkers:               1,
+			MaxSyncWorkers:               5,
+			BlockCompressionEnabled:      false,
+			SyncPeerScoreMinimum:         -100,
+			StateManagerCacheEnabled:     true,
+			NetworkVersionOverride:       0,
+			ActorMigrationParallelism:    0,
 		},
 		Cluster: *DefaultUserRaftConfig(),
 		Fevm: FevmConfig{
 			EnableEthRPC:                 false,
 			EthTxHashMappingLifetimeDays: 0,
+
+			EthGetBlockTransactionCountMax: 0,
+
+			EnableEthBatchRequests: false,
+			EthBatchRequestMaxSize: 20,
+
+			EthGetLogsMaxResults: 10000,
+
+			EthPendingTransactionTimeout: Duration(time.Hour),
+			EthEventBatch:                false,
+			EthEventBatchInterval:        Duration(100 * time.Millisecond),
+			EthSyncStatusMode:            EthSyncStatusModeAuto,
+			EthAddressMapping:            EthAddressMappingDelegatedFirst,
+			EthMaxCodeSize:               24576,
+			EnableEIP2718Transactions:    true,
+
+			EthGetTransactionByHashCacheSize: 4096,
+			EthBlockPropagationDelay:         Duration(0),
+			EthCallCacheEnabled:              false,
+			EthCallCacheTTL:                  Duration(0),
+			EthCallCacheSize:                 512,
+			EthCallAddressWhitelist:          []string{},
+			EthGetBlockByHashFullTx:          false,
+			ChainNotificationBufferSize:      64,
+
 			Events: Events{
 				DisableRealTimeFilterAPI: false,
 				DisableHistoricFilterAPI: false,
@@ -117,6 +262,10 @@ func DefaultFullNode() *FullNode {
 				MaxFilters:               100,
 				MaxFilterResults:         10000,
 				MaxFilterHeightRange:     2880, // conservative limit of one day
+
+				MaxSubscriptionBufferSize:    1024,
+				SubscriptionBackpressureMode: SubscriptionBackpressureDropOldest,
+				SubscriptionSendTimeout:      Duration(30 * time.Second),
 			},
 		},
 	}
@@ -135,16 +284,26 @@ func DefaultStorageMiner() *StorageMiner {
 			WaitDealsDelay:            Duration(time.Hour * 6),
 			AlwaysKeepUnsealedCopy:    true,
 			FinalizeEarly:             false,
+			SectorSealRetryPolicy:     "exponential",
 			MakeNewSectorForDeals:     true,
 
 			CollateralFromMinerBalance: false,
 			AvailableBalanceBuffer:     types.FIL(big.Zero()),
 			DisableCollateralFallback:  false,
+			PledgeCollateralBuffer:     types.MustParseFIL("0.01"),
 
-			MaxPreCommitBatch:  miner5.PreCommitSectorBatchMaxSize, // up to 256 sectors
-			PreCommitBatchWait: Duration(24 * time.Hour),           // this should be less than 31.5 hours, which is the expiration of a precommit ticket
+			MaxPreCommitBatch:         miner5.PreCommitSectorBatchMaxSize, // up to 256 sectors
+			MaxPreCommitBatchByValue:  false,
+			MaxPreCommitBatchFeeValue: types.MustParseFIL("0"),
+			PreCommitBatchWait:        Duration(24 * time.Hour), // this should be less than 31.5 hours, which is the expiration of a precommit ticket
 			// XXX snap deals wait deals slack if first
-			PreCommitBatchSlack: Duration(3 * time.Hour), // time buffer for forceful batch submission before sectors/deals in batch would start expiring, higher value will lower the chances for message fail due to expiration
+			PreCommitBatchSlack:         Duration(3 * time.Hour), // time buffer for forceful batch submission before sectors/deals in batch would start expiring, higher value will lower the chances for message fail due to expiration
+			PreCommitBatchAbortOnExpiry: false,
+
+			PreCommitGasMultiplier: 1.05,
+			CommitGasMultiplier:    1.05,
+
+			ReplicaUpdateGasMultiplier: 1.1,
 
 			CommittedCapacitySectorLifetime: Duration(builtin.EpochDurationSeconds * uint64(maxSectorExtentsion) * uint64(time.Second)),
 
@@ -154,20 +313,42 @@ func DefaultStorageMiner() *StorageMiner {
 			CommitBatchWait:  Duration(24 * time.Hour),    // this can be up to 30 days
 			CommitBatchSlack: Duration(1 * time.Hour),     // time buffer for forceful batch submission before sectors/deals in batch would start expiring, higher value will lower the chances for message fail due to expiration
 
+			CommitBatchOverlapWindow: Duration(0),
+
 			BatchPreCommitAboveBaseFee: types.FIL(types.BigMul(types.PicoFil, types.NewInt(320))), // 0.32 nFIL
 			AggregateAboveBaseFee:      types.FIL(types.BigMul(types.PicoFil, types.NewInt(320))), // 0.32 nFIL
 
+			AggregateMinBaseFeeDecayRatio: 0,
+
+			BatchPreCommitAboveBaseFeeDynamic: false,
+			SectorPreCommitBatchGasTarget:     0,
+
 			TerminateBatchMin:                      1,
 			TerminateBatchMax:                      100,
 			TerminateBatchWait:                     Duration(5 * time.Minute),
 			MaxSectorProveCommitsSubmittedPerEpoch: 20,
+			MaxConcurrentProveCommits:              0,
+			MaxPreCommitsInFlight:                  0,
 			UseSyntheticPoRep:                      false,
+			TicketLookaheadEpochs:                  0,
+			FinalizeSectorTimeout:                  Duration(time.Hour),
+
+			SectorBuildWatcher:     false,
+			SectorWatcherTopicName: "/lotus/sector-states/v0",
 		},
 
 		Proving: ProvingConfig{
-			ParallelCheckLimit:    32,
-			PartitionCheckTimeout: Duration(20 * time.Minute),
-			SingleCheckTimeout:    Duration(10 * time.Minute),
+			ParallelCheckLimit:         32,
+			AdaptiveParallelCheckLimit: false,
+			PartitionCheckTimeout:      Duration(20 * time.Minute),
+			SingleCheckTimeout:         Duration(10 * time.Minute),
+
+			FaultDeclarationGasMultiplier: 1.1,
+			PoStMessageConfirmDepth:       1,
+			WdPoStMessageGasBuffer:        1_000_000,
+			WindowPoStNonceStrategy:       WindowPoStNonceSequential,
+			WindowPoStWorkerType:          WindowPoStWorkerLocal,
+			FallbackProverTimeout:         Duration(time.Hour),
 		},
 
 		Storage: SealerConfig{
@@ -183,12 +364,20 @@ func DefaultStorageMiner() *StorageMiner {
 
 			// Default to 10 - tcp should still be able to figure this out, and
 			// it's the ratio between 10gbit / 1gbit
-			ParallelFetchLimit: 10,
+			ParallelFetchLimit:        10,
+			NetworkBandwidthLimitMBps: 0,
+			ParallelSectorMoveLimit:   0,
+
+			TaskSheddingPolicy: TaskSheddingBlock,
 
-			Assigner: "utilization",
+			Assigner:                 "utilization",
+			WorkerSelectionHeuristic: WorkerSelectionUtilization,
 
 			// By default use the hardware resource filtering strategy.
 			ResourceFiltering: ResourceFilteringHardware,
+
+			GPUTemperatureLimit:        0,
+			GPUTemperaturePollInterval: Duration(30 * time.Second),
 		},
 
 		Dealmaking: DealmakingConfig{
@@ -204,7 +393,9 @@ func DefaultStorageMiner() *StorageMiner {
 			ExpectedSealDuration:            Duration(time.Hour * 24),
 			PublishMsgPeriod:                Duration(time.Hour),
 			MaxDealsPerPublishMsg:           8,
+			AutoPublishEnabled:              true,
 			MaxProviderCollateralMultiplier: 2,
+			StoragePriceCap:                 types.MustParseFIL("0"),
 
 			SimultaneousTransfersForStorage:          DefaultSimultaneousTransfers,
 			SimultaneousTransfersForStoragePerClient: 0,
@@ -212,15 +403,22 @@ func DefaultStorageMiner() *StorageMiner {
 
 			StartEpochSealingBuffer: 480, // 480 epochs buffer == 4 hours from adding deal to sector to sector being sealed
 
+			MaxDealClientAddressCount: 0,
+
+			StoragePriceOraclePollInterval: Duration(time.Hour),
+
 			RetrievalPricing: &RetrievalPricing{
 				Strategy: RetrievalPricingDefaultMode,
 				Default: &RetrievalPricingDefault{
-					VerifiedDealsFreeTransfer: true,
+					VerifiedDealsFreeTransfer:        true,
+					RetrievalPricingDefaultBytePrice: types.MustParseFIL("0"),
 				},
 				External: &RetrievalPricingExternal{
 					Path: "",
 				},
 			},
+
+			PieceRetrievalTransfer: true,
 		},
 
 		IndexProvider: IndexProviderConfig{
@@ -231,6 +429,8 @@ func DefaultStorageMiner() *StorageMiner {
 			// format: "/indexer/ingest/<network-name>"
 			TopicName:         "",
 			PurgeCacheOnStart: false,
+
+			GossipSubHeartbeatInterval: Duration(0),
 		},
 
 		Subsystems: MinerSubsystemConfig{
@@ -245,12 +445,14 @@ func DefaultStorageMiner() *StorageMiner {
 			MaxCommitGasFee:    types.MustParseFIL("0.05"),
 
 			MaxPreCommitBatchGasFee: BatchFeeConfig{
-				Base:      types.MustParseFIL("0"),
-				PerSector: types.MustParseFIL("0.02"),
+				Base:        types.MustParseFIL("0"),
+				PerSector:   types.MustParseFIL("0.02"),
+				MaxTotalFee: types.MustParseFIL("0"),
 			},
 			MaxCommitBatchGasFee: BatchFeeConfig{
-				Base:      types.MustParseFIL("0"),
-				PerSector: types.MustParseFIL("0.03"), // enough for 6 agg and 1nFIL base fee
+				Base:        types.MustParseFIL("0"),
+				PerSector:   types.MustParseFIL("0.03"), // enough for 6 agg and 1nFIL base fee
+				MaxTotalFee: types.MustParseFIL("0"),
 			},
 
 			MaxTerminateGasFee:     types.MustParseFIL("0.5"),
@@ -271,8 +473,12 @@ func DefaultStorageMiner() *StorageMiner {
 		DAGStore: DAGStoreConfig{
 			MaxConcurrentIndex:         5,
 			MaxConcurrencyStorageCalls: 100,
+			MaxConcurrentRetrievals:    100,
 			MaxConcurrentUnseals:       5,
 			GCInterval:                 Duration(1 * time.Minute),
+			TransientEvictionPolicy:    TransientEvictionNone,
+			RecoveryRetries:            3,
+			RecoveryInterval:           Duration(10 * time.Minute),
 		},
 	}
 
@@ -284,6 +490,8 @@ func DefaultStorageMiner() *StorageMiner {
 var (
 	_ encoding.TextMarshaler   = (*Duration)(nil)
 	_ encoding.TextUnmarshaler = (*Duration)(nil)
+	_ json.Marshaler           = (*Duration)(nil)
+	_ json.Unmarshaler         = (*Duration)(nil)
 )
 
 // Duration is a wrapper type for time.Duration
@@ -305,6 +513,100 @@ func (dur Duration) MarshalText() ([]byte, error) {
 	return []byte(d.String()), nil
 }
 
+// MarshalJSON encodes the duration as a quoted human-readable string, e.g. "30s"
+func (dur Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(dur).String())
+}
+
+// UnmarshalJSON accepts both the quoted string form and, for backwards
+// compatibility, a raw integer number of nanoseconds
+func (dur *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		return dur.UnmarshalText([]byte(s))
+	}
+
+	var ns int64
+	if err := json.Unmarshal(b, &ns); err != nil {
+		return xerrors.Errorf("duration must be a string or an integer number of nanoseconds, got %s", string(b))
+	}
+	*dur = Duration(ns)
+	return nil
+}
+
+const (
+	// TransientEvictionLRU evicts the least recently used DAG store
+	// transients first.
+	TransientEvictionLRU = "lru"
+	// TransientEvictionFIFO evicts the oldest DAG store transients first.
+	TransientEvictionFIFO = "fifo"
+	// TransientEvictionNone disables DAG store transient eviction.
+	TransientEvictionNone = "none"
+)
+
+const (
+	// WindowPoStNonceSequential sends WindowPoSt messages one after another
+	// from the first suitable PoSt control address.
+	WindowPoStNonceSequential = "sequential"
+	// WindowPoStNonceParallelGap pushes the WindowPoSt messages of a deadline
+	// concurrently, within the message pool nonce gap.
+	WindowPoStNonceParallelGap = "parallel-nonce-gap"
+	// WindowPoStNonceRoundRobin rotates WindowPoSt messages across the PoSt
+	// control addresses.
+	WindowPoStNonceRoundRobin = "round-robin"
+)
+
+const (
+	// WorkerSelectionUtilization assigns tasks to the workers with the lowest
+	// utilization.
+	WorkerSelectionUtilization = "utilization"
+	// WorkerSelectionLocality prefers workers which already have files of the
+	// task sector in their local storage.
+	WorkerSelectionLocality = "locality"
+	// WorkerSelectionCapacity assigns tasks to the workers with the most free
+	// memory.
+	WorkerSelectionCapacity = "capacity"
+)
+
+const (
+	// TaskSheddingReject fails tasks scheduled while the scheduler queue is
+	// full.
+	TaskSheddingReject = "reject"
+	// TaskSheddingBlock makes tasks scheduled while the scheduler queue is
+	// full wait for room.
+	TaskSheddingBlock = "block"
+	// TaskSheddingOldestFirst fails the oldest queued task to make room for
+	// new ones.
+	TaskSheddingOldestFirst = "oldest-first"
+)
+
+const (
+	// WindowPoStWorkerLocal computes window PoSt on PoSt workers or on the
+	// lotus-miner process.
+	WindowPoStWorkerLocal = "local"
+	// WindowPoStWorkerRemote computes window PoSt on a remote proving service.
+	WindowPoStWorkerRemote = "remote"
+)
+
+// RecommendedParallelCheckLimit returns the number of sector checks to run in
+// parallel when checking sectorCount sectors on a machine with cpuCount CPUs:
+// min(sectorCount, 2*cpuCount), clamped to [1, 512].
+func RecommendedParallelCheckLimit(sectorCount, cpuCount int) int {
+	const minLimit, maxLimit = 1, 512
+
+	limit := 2 * cpuCount
+	if sectorCount < limit {
+		limit = sectorCount
+	}
+	if limit < minLimit {
+		return minLimit
+	}
+	if limit > maxLimit {
+		return maxLimit
+	}
+	return limit
+}
+
 // ResourceFilteringStrategy is an enum indicating the kinds of resource
 // filtering strategies that can be configured for workers.
 type ResourceFilteringStrategy string
@@ -317,8 +619,40 @@ const (
 	// ResourceFilteringDisabled disables resource filtering against this
 	// worker. The scheduler may assign any task to this worker.
 	ResourceFilteringDisabled = ResourceFilteringStrategy("disabled")
+
+	// ResourceFilteringSchedule selects the hardware or disabled strategy
+	// depending on the time of day, see ActiveResourceFilteringStrategy.
+	ResourceFilteringSchedule = ResourceFilteringStrategy("schedule")
 )
 
+// ActiveResourceFilteringStrategy returns the strategy of the first schedule
+// window containing now, or ResourceFilteringHardware if there is none.
+// Windows with an unknown time zone never match.
+func ActiveResourceFilteringStrategy(schedule []ResourceFilteringWindow, now time.Time) ResourceFilteringStrategy {
+	for _, w := range schedule {
+		loc, err := time.LoadLocation(w.Timezone)
+		if err != nil {
+			continue
+		}
+
+		h := now.In(loc).Hour()
+		var match bool
+		switch {
+		case w.StartHour < w.EndHour:
+			match = h >= w.StartHour && h < w.EndHour
+		case w.StartHour > w.EndHour: // spans midnight
+			match = h >= w.StartHour || h < w.EndHour
+		default:
+			match = true
+		}
+		if match {
+			return w.Strategy
+		}
+	}
+
+	return ResourceFilteringHardware
+}
+
 var (
 	DefaultDataSubFolder        = "raft"
 	DefaultWaitForLeaderTimeout = 15 * time.Second
@@ -326,6 +660,10 @@ var (
 	DefaultNetworkTimeout       = 100 * time.Second
 	DefaultCommitRetryDelay     = 200 * time.Millisecond
 	DefaultBackupsRotate        = 6
+
+	DefaultPeerDiscoveryRetries  = 3
+	DefaultPeerDiscoveryInterval = 5 * time.Second
+	DefaultLeaderElectionTimeout = 10 * time.Second
 )
 
 func DefaultUserRaftConfig() *UserRaftConfig {
@@ -337,6 +675,9 @@ func DefaultUserRaftConfig() *UserRaftConfig {
 	cfg.CommitRetries = DefaultCommitRetries
 	cfg.CommitRetryDelay = Duration(DefaultCommitRetryDelay)
 	cfg.BackupsRotate = DefaultBackupsRotate
+	cfg.PeerDiscoveryRetries = DefaultPeerDiscoveryRetries
+	cfg.PeerDiscoveryInterval = Duration(DefaultPeerDiscoveryInterval)
+	cfg.LeaderElectionTimeout = Duration(DefaultLeaderElectionTimeout)
 
 	return &cfg
 }
@@ -3,13 +3,21 @@ package config
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/chain/types"
 )
 
 func TestDefaultFullNodeRoundtrip(t *testing.T) {
@@ -31,6 +39,7 @@ func TestDefaultFullNodeRoundtrip(t *testing.T) {
 
 	fmt.Println(s)
 
+	c.Version = CurrentConfigVersion
 	require.True(t, reflect.DeepEqual(c, c2))
 }
 
@@ -49,6 +58,7 @@ func TestDefaultFullNodeCommentRoundtrip(t *testing.T) {
 
 	fmt.Println(s)
 
+	c.Version = CurrentConfigVersion
 	require.True(t, reflect.DeepEqual(c, c2))
 }
 
@@ -71,6 +81,7 @@ func TestDefaultMinerRoundtrip(t *testing.T) {
 
 	fmt.Println(s)
 
+	c.Version = CurrentConfigVersion
 	require.True(t, reflect.DeepEqual(c, c2))
 }
 
@@ -79,3 +90,201 @@ func TestDefaultStorageMiner_IsEmpty(t *testing.T) {
 	require.True(t, subject.IndexProvider.Enable)
 	require.Equal(t, "", subject.IndexProvider.TopicName)
 }
+
+func TestDurationJSONRoundtrip(t *testing.T) {
+	for _, d := range []Duration{
+		0,
+		Duration(1500 * time.Microsecond),
+		Duration(250 * time.Millisecond),
+		Duration(30 * time.Second),
+		Duration(30 * time.Hour),
+	} {
+		b, err := json.Marshal(d)
+		require.NoError(t, err)
+		require.Equal(t, strconv.Quote(time.Duration(d).String()), string(b))
+
+		var out Duration
+		require.NoError(t, json.Unmarshal(b, &out))
+		require.Equal(t, d, out)
+
+		// raw nanosecond integers are still accepted
+		var raw Duration
+		require.NoError(t, json.Unmarshal([]byte(strconv.FormatInt(int64(d), 10)), &raw))
+		require.Equal(t, d, raw)
+	}
+
+	var out Duration
+	require.Error(t, json.Unmarshal([]byte(`"thirty seconds"`), &out))
+	require.Error(t, json.Unmarshal([]byte(`true`), &out))
+}
+
+func TestDurationConfigJSONRoundtrip(t *testing.T) {
+	c := DefaultStorageMiner()
+
+	b, err := json.Marshal(c)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"CommitBatchWait":"24h0m0s"`)
+
+	c2 := DefaultStorageMiner()
+	c2.Sealing.CommitBatchWait = 0
+	c2.Dealmaking.PublishMsgPeriod = 0
+	require.NoError(t, json.Unmarshal(b, c2))
+	require.Equal(t, c.Sealing.CommitBatchWait, c2.Sealing.CommitBatchWait)
+	require.Equal(t, c.Dealmaking.PublishMsgPeriod, c2.Dealmaking.PublishMsgPeriod)
+}
+
+func TestDurationTOMLUnchanged(t *testing.T) {
+	type wrapper struct {
+		D Duration
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, toml.NewEncoder(buf).Encode(wrapper{D: Duration(90 * time.Minute)}))
+	require.Equal(t, "D = \"1h30m0s\"\n", buf.String())
+
+	var out wrapper
+	_, err := toml.Decode(buf.String(), &out)
+	require.NoError(t, err)
+	require.Equal(t, Duration(90*time.Minute), out.D)
+}
+
+func TestBatchFeeMaxTotalFee(t *testing.T) {
+	for name, getCfg := range map[string]func(c *StorageMiner) *BatchFeeConfig{
+		"PreCommitBatchGasFee": func(c *StorageMiner) *BatchFeeConfig { return &c.Fees.MaxPreCommitBatchGasFee },
+		"CommitBatchGasFee":    func(c *StorageMiner) *BatchFeeConfig { return &c.Fees.MaxCommitBatchGasFee },
+	} {
+		getCfg := getCfg
+		t.Run(name, func(t *testing.T) {
+			c := DefaultStorageMiner()
+			bf := getCfg(c)
+			bf.Base = types.MustParseFIL("0.1")
+
+			const n = 256
+			uncapped := big.Add(big.Int(bf.Base), big.Mul(big.NewInt(n), big.Int(bf.PerSector)))
+
+			// no cap
+			fee, capped := bf.EffectiveFeeForSectors(n)
+			require.False(t, capped)
+			require.Equal(t, uncapped, fee)
+			require.Equal(t, uncapped, bf.FeeForSectors(n))
+
+			// exactly at the cap
+			bf.MaxTotalFee = types.FIL(uncapped)
+			fee, capped = bf.EffectiveFeeForSectors(n)
+			require.False(t, capped)
+			require.Equal(t, uncapped, fee)
+			require.Equal(t, uncapped, bf.FeeForSectors(n))
+
+			// over the cap
+			bf.MaxTotalFee = types.MustParseFIL("1")
+			fee, capped = bf.EffectiveFeeForSectors(n)
+			require.True(t, capped)
+			require.Equal(t, big.Int(types.MustParseFIL("1")), fee)
+			require.Equal(t, big.Int(types.MustParseFIL("1")), bf.FeeForSectors(n))
+		})
+	}
+}
+
+func TestActiveResourceFilteringStrategy(t *testing.T) {
+	at := func(hour int, loc *time.Location) time.Time {
+		return time.Date(2023, 3, 1, hour, 30, 0, 0, loc)
+	}
+
+	daytime := []ResourceFilteringWindow{
+		{StartHour: 8, EndHour: 18, Timezone: "UTC", Strategy: ResourceFilteringHardware},
+		{StartHour: 18, EndHour: 8, Timezone: "UTC", Strategy: ResourceFilteringDisabled},
+	}
+	require.Equal(t, ResourceFilteringHardware, ActiveResourceFilteringStrategy(daytime, at(8, time.UTC)))
+	require.Equal(t, ResourceFilteringHardware, ActiveResourceFilteringStrategy(daytime, at(17, time.UTC)))
+	require.Equal(t, ResourceFilteringDisabled, ActiveResourceFilteringStrategy(daytime, at(18, time.UTC)))
+
+	// midnight rollover
+	require.Equal(t, ResourceFilteringDisabled, ActiveResourceFilteringStrategy(daytime, at(23, time.UTC)))
+	require.Equal(t, ResourceFilteringDisabled, ActiveResourceFilteringStrategy(daytime, at(0, time.UTC)))
+	require.Equal(t, ResourceFilteringDisabled, ActiveResourceFilteringStrategy(daytime, at(7, time.UTC)))
+
+	// hours are evaluated in the window time zone
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	night := []ResourceFilteringWindow{{StartHour: 22, EndHour: 6, Timezone: "Asia/Tokyo", Strategy: ResourceFilteringDisabled}}
+	require.Equal(t, ResourceFilteringDisabled, ActiveResourceFilteringStrategy(night, at(23, tokyo)))
+	require.Equal(t, ResourceFilteringDisabled, ActiveResourceFilteringStrategy(night, at(14, time.UTC))) // 23:30 in Tokyo
+	require.Equal(t, ResourceFilteringHardware, ActiveResourceFilteringStrategy(night, at(23, time.UTC))) // 08:30 in Tokyo
+
+	// whole day window
+	allDay := []ResourceFilteringWindow{{StartHour: 0, EndHour: 0, Timezone: "UTC", Strategy: ResourceFilteringDisabled}}
+	require.Equal(t, ResourceFilteringDisabled, ActiveResourceFilteringStrategy(allDay, at(12, time.UTC)))
+
+	// fallback
+	require.Equal(t, ResourceFilteringHardware, ActiveResourceFilteringStrategy(nil, at(12, time.UTC)))
+	office := []ResourceFilteringWindow{{StartHour: 9, EndHour: 17, Timezone: "UTC", Strategy: ResourceFilteringDisabled}}
+	require.Equal(t, ResourceFilteringHardware, ActiveResourceFilteringStrategy(office, at(20, time.UTC)))
+	badTz := []ResourceFilteringWindow{{StartHour: 0, EndHour: 24, Timezone: "Nowhere/Nothing", Strategy: ResourceFilteringDisabled}}
+	require.Equal(t, ResourceFilteringHardware, ActiveResourceFilteringStrategy(badTz, at(12, time.UTC)))
+}
+
+func TestRecommendedParallelCheckLimit(t *testing.T) {
+	// sector count dominates
+	require.Equal(t, 10, RecommendedParallelCheckLimit(10, 16))
+	// cpu count dominates
+	require.Equal(t, 32, RecommendedParallelCheckLimit(2000, 16))
+	// lower clamp
+	require.Equal(t, 1, RecommendedParallelCheckLimit(0, 16))
+	require.Equal(t, 1, RecommendedParallelCheckLimit(100, 0))
+	// upper clamp
+	require.Equal(t, 512, RecommendedParallelCheckLimit(10000, 1024))
+
+	require.Zero(t, testing.AllocsPerRun(100, func() {
+		_ = RecommendedParallelCheckLimit(2349, 64)
+	}))
+}
+
+func TestMinerFeeConfigScale(t *testing.T) {
+	c := DefaultStorageMiner()
+	orig := c.Fees.MaxPreCommitGasFee.String()
+
+	doubled, err := c.Fees.Scale(2.0)
+	require.NoError(t, err)
+	require.Equal(t, big.Mul(big.Int(c.Fees.MaxPreCommitGasFee), big.NewInt(2)), big.Int(doubled.MaxPreCommitGasFee))
+	require.Equal(t, big.Mul(big.Int(c.Fees.MaxWindowPoStGasFee), big.NewInt(2)), big.Int(doubled.MaxWindowPoStGasFee))
+	require.Equal(t, big.Mul(big.Int(c.Fees.MaxCommitBatchGasFee.Base), big.NewInt(2)), big.Int(doubled.MaxCommitBatchGasFee.Base))
+	require.Equal(t, big.Mul(big.Int(c.Fees.MaxCommitBatchGasFee.PerSector), big.NewInt(2)), big.Int(doubled.MaxCommitBatchGasFee.PerSector))
+	require.Equal(t, c.Fees.MaximizeWindowPoStFeeCap, doubled.MaximizeWindowPoStFeeCap)
+
+	halved, err := c.Fees.Scale(0.5)
+	require.NoError(t, err)
+	require.Equal(t, big.Div(big.Int(c.Fees.MaxPublishDealsFee), big.NewInt(2)), big.Int(halved.MaxPublishDealsFee))
+	require.Equal(t, big.Div(big.Int(c.Fees.MaxPreCommitBatchGasFee.PerSector), big.NewInt(2)), big.Int(halved.MaxPreCommitBatchGasFee.PerSector))
+
+	// the receiver is never modified
+	require.Equal(t, orig, c.Fees.MaxPreCommitGasFee.String())
+
+	for _, f := range []float64{0, -1, 1000.5, math.NaN(), math.Inf(1)} {
+		_, err := c.Fees.Scale(f)
+		require.Error(t, err, "factor %v", f)
+	}
+
+	c.Fees = doubled
+	require.NoError(t, c.Validate())
+	c.Fees = halved
+	require.NoError(t, c.Validate())
+}
+
+func TestUserRaftConfigTOMLRoundtrip(t *testing.T) {
+	c := DefaultFullNode()
+	c.Cluster.PeerDiscoveryRetries = 7
+	c.Cluster.PeerDiscoveryInterval = Duration(30 * time.Second)
+	c.Cluster.LeaderElectionTimeout = Duration(time.Minute)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, toml.NewEncoder(buf).Encode(c))
+
+	c2, err := FromReader(buf, DefaultFullNode())
+	require.NoError(t, err)
+	require.Equal(t, c.Cluster, c2.(*FullNode).Cluster)
+
+	def := DefaultUserRaftConfig()
+	require.Equal(t, 3, def.PeerDiscoveryRetries)
+	require.Equal(t, Duration(5*time.Second), def.PeerDiscoveryInterval)
+	require.Equal(t, Duration(10*time.Second), def.LeaderElectionTimeout)
+}
@@ -0,0 +1,394 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// FieldChange describes a single field which differs between two configs.
+type FieldChange struct {
+	// Path is the dot-separated path of the field, e.g. "Sealing.CommitBatchWait".
+	// Slice elements and map entries are addressed as "Field[index]" and "Field[key]".
+	Path string
+
+	// Old and New hold the field values. Fields with a text form (Duration, FIL,
+	// Cid) are represented by that string; slice elements and map entries which
+	// only exist on one side are represented by nil on the other.
+	Old interface{}
+	New interface{}
+
+	// DefaultOverride is set when New differs from the value in the default config.
+	DefaultOverride bool
+}
+
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	filType             = reflect.TypeOf(types.FIL{})
+)
+
+// DiffConfigs returns the list of fields which differ between configs a and b,
+// which must be of the same struct type (or pointers to it). Embedded structs
+// are flattened, so FullNode's "Common.API.Timeout" is reported as "API.Timeout",
+// matching the TOML layout.
+func DiffConfigs(a, b interface{}) ([]FieldChange, error) {
+	va, vb := derefValue(reflect.ValueOf(a)), derefValue(reflect.ValueOf(b))
+	if !va.IsValid() || !vb.IsValid() {
+		return nil, xerrors.Errorf("cannot diff nil configs")
+	}
+	if va.Type() != vb.Type() {
+		return nil, xerrors.Errorf("cannot diff configs of different types: %s and %s", va.Type(), vb.Type())
+	}
+	if va.Kind() != reflect.Struct {
+		return nil, xerrors.Errorf("config must be a struct, got %s", va.Type())
+	}
+
+	var changes []FieldChange
+	if err := diffValues("", va, vb, &changes); err != nil {
+		return nil, err
+	}
+
+	var overrides map[string]struct{}
+	if def := defaultConfigFor(va.Type()); def != nil {
+		var defChanges []FieldChange
+		if err := diffValues("", derefValue(reflect.ValueOf(def)), vb, &defChanges); err != nil {
+			return nil, xerrors.Errorf("diffing against default config: %w", err)
+		}
+		overrides = make(map[string]struct{}, len(defChanges))
+		for _, c := range defChanges {
+			overrides[c.Path] = struct{}{}
+		}
+	}
+	for i := range changes {
+		_, changes[i].DefaultOverride = overrides[changes[i].Path]
+	}
+
+	return changes, nil
+}
+
+// ApplyDiff replays changes recorded by DiffConfigs on top of base, which must
+// be a non-nil pointer to a config struct. Changes are applied in order.
+func ApplyDiff(base interface{}, changes []FieldChange) error {
+	v := reflect.ValueOf(base)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return xerrors.Errorf("base config must be a non-nil pointer, got %T", base)
+	}
+
+	for _, c := range changes {
+		path, err := parseFieldPath(c.Path)
+		if err != nil {
+			return xerrors.Errorf("parsing path %q: %w", c.Path, err)
+		}
+		if err := setFieldPath(v, path, c.New); err != nil {
+			return xerrors.Errorf("applying change to %s: %w", c.Path, err)
+		}
+	}
+
+	return nil
+}
+
+func defaultConfigFor(t reflect.Type) interface{} {
+	switch t {
+	case reflect.TypeOf(FullNode{}):
+		return DefaultFullNode()
+	case reflect.TypeOf(StorageMiner{}):
+		return DefaultStorageMiner()
+	}
+	return nil
+}
+
+func derefValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isTextLeaf(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) && reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// diffValue returns the representation of v used in FieldChange.
+func diffValue(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.Type() == filType && v.Interface().(types.FIL).Int == nil {
+		return types.FIL(types.NewInt(0)).String(), nil
+	}
+	if isTextLeaf(v.Type()) {
+		txt, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(txt), nil
+	}
+	return v.Interface(), nil
+}
+
+func appendChange(path string, a, b reflect.Value, changes *[]FieldChange) error {
+	av, err := diffValue(a)
+	if err != nil {
+		return xerrors.Errorf("%s: %w", path, err)
+	}
+	bv, err := diffValue(b)
+	if err != nil {
+		return xerrors.Errorf("%s: %w", path, err)
+	}
+	if reflect.DeepEqual(av, bv) {
+		return nil
+	}
+	*changes = append(*changes, FieldChange{Path: path, Old: av, New: bv})
+	return nil
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func diffValues(path string, a, b reflect.Value, changes *[]FieldChange) error {
+	if isTextLeaf(a.Type()) {
+		return appendChange(path, a, b, changes)
+	}
+
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			f := a.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			fpath := joinPath(path, f.Name)
+			if f.Anonymous {
+				fpath = path
+			}
+			if err := diffValues(fpath, a.Field(i), b.Field(i), changes); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Ptr:
+		switch {
+		case a.IsNil() && b.IsNil():
+			return nil
+		case a.IsNil():
+			return diffValues(path, reflect.Zero(a.Type().Elem()), b.Elem(), changes)
+		case b.IsNil():
+			return diffValues(path, a.Elem(), reflect.Zero(b.Type().Elem()), changes)
+		}
+		return diffValues(path, a.Elem(), b.Elem(), changes)
+
+	case reflect.Slice:
+		n := a.Len()
+		if b.Len() > n {
+			n = b.Len()
+		}
+		for i := 0; i < n; i++ {
+			ipath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= a.Len():
+				if err := appendChange(ipath, reflect.Value{}, b.Index(i), changes); err != nil {
+					return err
+				}
+			case i >= b.Len():
+				if err := appendChange(ipath, a.Index(i), reflect.Value{}, changes); err != nil {
+					return err
+				}
+			default:
+				if err := diffValues(ipath, a.Index(i), b.Index(i), changes); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		keys := map[string]reflect.Value{}
+		for _, k := range a.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		for _, k := range b.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			k := keys[name]
+			if err := appendChange(path+"["+name+"]", a.MapIndex(k), b.MapIndex(k), changes); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return xerrors.Errorf("%s: unsupported field kind %s", path, a.Kind())
+	}
+
+	return appendChange(path, a, b, changes)
+}
+
+type pathElem struct {
+	name    string
+	bracket bool
+}
+
+func parseFieldPath(path string) ([]pathElem, error) {
+	var elems []pathElem
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, xerrors.Errorf("unterminated '['")
+			}
+			elems = append(elems, pathElem{name: path[1:end], bracket: true})
+			path = path[end+1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			elems = append(elems, pathElem{name: path[:end]})
+			path = path[end:]
+		}
+	}
+	if len(elems) == 0 {
+		return nil, xerrors.Errorf("empty path")
+	}
+	return elems, nil
+}
+
+func setFieldPath(v reflect.Value, path []pathElem, val interface{}) error {
+	for v.Kind() == reflect.Ptr && (len(path) > 0 || val != nil) {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if len(path) == 0 {
+		return assignValue(v, val)
+	}
+
+	elem, rest := path[0], path[1:]
+	switch {
+	case !elem.bracket:
+		if v.Kind() != reflect.Struct {
+			return xerrors.Errorf("cannot access field %s on %s", elem.name, v.Type())
+		}
+		f := v.FieldByName(elem.name)
+		if !f.IsValid() {
+			return xerrors.Errorf("unknown field %s in %s", elem.name, v.Type())
+		}
+		return setFieldPath(f, rest, val)
+
+	case v.Kind() == reflect.Slice:
+		idx, err := strconv.Atoi(elem.name)
+		if err != nil || idx < 0 {
+			return xerrors.Errorf("invalid slice index %q", elem.name)
+		}
+		if len(rest) == 0 && val == nil {
+			// element removed; removals are always at the tail
+			if idx < v.Len() {
+				v.Set(v.Slice(0, idx))
+			}
+			return nil
+		}
+		if idx > v.Len() {
+			return xerrors.Errorf("slice index %d out of range (length %d)", idx, v.Len())
+		}
+		if idx == v.Len() {
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		}
+		return setFieldPath(v.Index(idx), rest, val)
+
+	case v.Kind() == reflect.Map:
+		if len(rest) != 0 {
+			return xerrors.Errorf("map values can only be replaced as a whole")
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return xerrors.Errorf("unsupported map key type %s", v.Type().Key())
+		}
+		key := reflect.ValueOf(elem.name).Convert(v.Type().Key())
+		if val == nil {
+			if !v.IsNil() {
+				v.SetMapIndex(key, reflect.Value{})
+			}
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		mv := reflect.New(v.Type().Elem()).Elem()
+		if err := assignValue(mv, val); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, mv)
+		return nil
+	}
+
+	return xerrors.Errorf("cannot index %s", v.Type())
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func assignValue(v reflect.Value, val interface{}) error {
+	if val == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if s, ok := val.(string); ok && isTextLeaf(v.Type()) {
+		nv := reflect.New(v.Type())
+		if err := nv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return err
+		}
+		v.Set(nv.Elem())
+		return nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch {
+	case rv.Type().AssignableTo(v.Type()):
+		v.Set(rv)
+	case rv.Kind() == v.Kind() && rv.Type().ConvertibleTo(v.Type()),
+		isNumericKind(rv.Kind()) && isNumericKind(v.Kind()):
+		v.Set(rv.Convert(v.Type()))
+	default:
+		return xerrors.Errorf("cannot assign %T to %s", val, v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// stm: #unit
+package config
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func findChange(changes []FieldChange, path string) (FieldChange, bool) {
+	for _, c := range changes {
+		if c.Path == path {
+			return c, true
+		}
+	}
+	return FieldChange{}, false
+}
+
+func TestDiffConfigsIdentical(t *testing.T) {
+	changes, err := DiffConfigs(DefaultFullNode(), DefaultFullNode())
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	changes, err = DiffConfigs(DefaultStorageMiner(), DefaultStorageMiner())
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiffConfigsFullNode(t *testing.T) {
+	a := DefaultFullNode()
+	b := DefaultFullNode()
+	b.API.Timeout = Duration(time.Minute)
+	b.Libp2p.ListenAddresses = append(b.Libp2p.ListenAddresses, "/ip4/127.0.0.1/tcp/1234")
+	b.Logging.SubsystemLevels["chain"] = "debug"
+	b.Fees.DefaultMaxFee = types.MustParseFIL("0.1")
+	b.Chainstore.EnableSplitstore = false
+
+	changes, err := DiffConfigs(a, b)
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+
+	c, ok := findChange(changes, "API.Timeout")
+	require.True(t, ok, "embedded Common fields are flattened")
+	require.Equal(t, "30s", c.Old)
+	require.Equal(t, "1m0s", c.New)
+	require.True(t, c.DefaultOverride)
+
+	c, ok = findChange(changes, fmt.Sprintf("Libp2p.ListenAddresses[%d]", len(a.Libp2p.ListenAddresses)))
+	require.True(t, ok)
+	require.Nil(t, c.Old)
+	require.Equal(t, "/ip4/127.0.0.1/tcp/1234", c.New)
+
+	c, ok = findChange(changes, "Logging.SubsystemLevels[chain]")
+	require.True(t, ok)
+	require.Nil(t, c.Old)
+	require.Equal(t, "debug", c.New)
+
+	c, ok = findChange(changes, "Fees.DefaultMaxFee")
+	require.True(t, ok)
+	require.Equal(t, "0.07 FIL", c.Old)
+	require.Equal(t, "0.1 FIL", c.New)
+
+	c, ok = findChange(changes, "Chainstore.EnableSplitstore")
+	require.True(t, ok)
+	require.Equal(t, true, c.Old)
+	require.Equal(t, false, c.New)
+
+	applied := DefaultFullNode()
+	require.NoError(t, ApplyDiff(applied, changes))
+	require.Equal(t, b, applied)
+
+	// reverting to the defaults is not a default override
+	revert, err := DiffConfigs(b, a)
+	require.NoError(t, err)
+	require.Len(t, revert, 5)
+	for _, c := range revert {
+		require.False(t, c.DefaultOverride, c.Path)
+	}
+
+	require.NoError(t, ApplyDiff(applied, revert))
+	require.Equal(t, a, applied)
+}
+
+func TestDiffConfigsStorageMiner(t *testing.T) {
+	a := DefaultStorageMiner()
+	b := DefaultStorageMiner()
+	b.Sealing.CommitBatchWait = Duration(12 * time.Hour)
+	b.Sealing.MaxCommitBatch = 100
+	b.Proving.FaultDeclarationGasMultiplier = 1.5
+	b.Addresses.PreCommitControl = []string{"f01234"}
+	b.Dealmaking.RetrievalPricing.Strategy = RetrievalPricingExternalMode
+	b.Dealmaking.RetrievalPricing.External.Path = "/usr/local/bin/price"
+	b.Fees.MaxCommitBatchGasFee.PerSector = types.MustParseFIL("0.01")
+
+	changes, err := DiffConfigs(a, b)
+	require.NoError(t, err)
+	require.Len(t, changes, 7)
+
+	c, ok := findChange(changes, "Sealing.CommitBatchWait")
+	require.True(t, ok)
+	require.Equal(t, "24h0m0s", c.Old)
+	require.Equal(t, "12h0m0s", c.New)
+	require.True(t, c.DefaultOverride)
+	require.Equal(t, "Sealing.CommitBatchWait: 24h0m0s -> 12h0m0s", c.String())
+
+	_, ok = findChange(changes, "Dealmaking.RetrievalPricing.External.Path")
+	require.True(t, ok)
+	_, ok = findChange(changes, "Addresses.PreCommitControl[0]")
+	require.True(t, ok)
+	_, ok = findChange(changes, "Fees.MaxCommitBatchGasFee.PerSector")
+	require.True(t, ok)
+
+	applied := DefaultStorageMiner()
+	require.NoError(t, ApplyDiff(applied, changes))
+	require.Equal(t, b, applied)
+
+	// only fields which differ from the default are overrides
+	c2 := DefaultStorageMiner()
+	c2.Sealing.MaxCommitBatch = 100
+	c3 := DefaultStorageMiner()
+	c3.Sealing.MaxCommitBatch = 100
+	c3.Sealing.MinCommitBatch = 2
+	changes, err = DiffConfigs(c2, c3)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.True(t, changes[0].DefaultOverride)
+}
+
+func TestDiffConfigsSliceShrink(t *testing.T) {
+	a := DefaultStorageMiner()
+	a.Addresses.CommitControl = []string{"f01", "f02", "f03"}
+	b := DefaultStorageMiner()
+	b.Addresses.CommitControl = []string{"f04"}
+
+	changes, err := DiffConfigs(a, b)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	require.NoError(t, ApplyDiff(a, changes))
+	require.Equal(t, []string{"f04"}, a.Addresses.CommitControl)
+}
+
+func TestDiffConfigsErrors(t *testing.T) {
+	_, err := DiffConfigs(DefaultFullNode(), DefaultStorageMiner())
+	require.Error(t, err)
+
+	_, err = DiffConfigs(nil, DefaultStorageMiner())
+	require.Error(t, err)
+
+	require.Error(t, ApplyDiff(*DefaultStorageMiner(), nil))
+	require.Error(t, ApplyDiff(DefaultStorageMiner(), []FieldChange{{Path: "Sealing.NoSuchField", New: 1}}))
+	require.Error(t, ApplyDiff(DefaultStorageMiner(), []FieldChange{{Path: "Sealing.CommitBatchWait", New: "soon"}}))
+	require.Error(t, ApplyDiff(DefaultStorageMiner(), []FieldChange{{Path: "Sealing.MaxCommitBatch", New: "ten"}}))
+}
+
+func TestApplyDiffNumericConversion(t *testing.T) {
+	// diffs which went through JSON carry numbers as float64
+	c := DefaultStorageMiner()
+	require.NoError(t, ApplyDiff(c, []FieldChange{{Path: "Sealing.MaxCommitBatch", New: float64(42)}}))
+	require.Equal(t, 42, c.Sealing.MaxCommitBatch)
+}
@@ -2,7 +2,6 @@ package config
 
 import (
 	"bytes"
-	"fmt"
 	"io"
 	"os"
 	"reflect"
@@ -59,22 +58,54 @@ func FromFile(path string, opts ...LoadCfgOpt) (interface{}, error) {
 	return FromReader(buf, def)
 }
 
-// FromReader loads config from a reader instance.
+// FromReader loads config from a reader instance. Full node and miner configs written for an
+// older config version are migrated to CurrentConfigVersion first.
 func FromReader(reader io.Reader, def interface{}) (interface{}, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	switch def.(type) {
+	case *FullNode, *StorageMiner:
+		data, err = migrateToCurrent(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return decodeConfig(bytes.NewReader(data), def)
+}
+
+// decodeConfig decodes the config in reader onto def, and applies env var overrides.
+func decodeConfig(reader io.Reader, def interface{}) (interface{}, error) {
 	cfg := def
 	_, err := toml.NewDecoder(reader).Decode(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	err = envconfig.Process("LOTUS", cfg)
-	if err != nil {
-		return nil, fmt.Errorf("processing env vars overrides: %s", err)
+	if err := ApplyEnvOverrides(cfg); err != nil {
+		return nil, err
 	}
 
 	return cfg, nil
 }
 
+// ApplyEnvOverrides overrides fields of cfg, which must be a pointer to a config
+// struct (*FullNode or *StorageMiner), with values from LOTUS_-prefixed environment
+// variables. The variable name is built from the upper-cased TOML path of the field,
+// e.g. Sealing.CommitBatchWait is set by LOTUS_SEALING_COMMITBATCHWAIT; these names
+// are listed in the generated default config. Values are parsed like TOML values, so
+// Duration and FIL fields accept "1h30m" and "0.5 FIL". Fields without a matching
+// variable are left unchanged; a variable which fails to parse returns an error.
+func ApplyEnvOverrides(cfg interface{}) error {
+	if err := envconfig.Process("LOTUS", cfg); err != nil {
+		return xerrors.Errorf("processing env vars overrides: %w", err)
+	}
+	return nil
+}
+
 type cfgLoadOpts struct {
 	defaultCfg           func() (interface{}, error)
 	canFallbackOnDefault func() error
@@ -108,6 +139,13 @@ func NoDefaultForSplitstoreTransition() error {
 	return xerrors.Errorf("FullNode config not found and fallback to default disallowed while we transition to splitstore discard default.  Use `lotus config default` to set this repo up with a default config.  Be sure to set `EnableSplitstore` to `false` if you are running a full archive node")
 }
 
+// MatchVersionField matches a config Version field set to a version other than 0, which must stay
+// uncommented for the config to be loaded with that version.
+func MatchVersionField(s string) bool {
+	versionRx := regexp.MustCompile(`(?m)^\s*Version\s*=\s*[1-9]`)
+	return versionRx.MatchString(s)
+}
+
 // Match the EnableSplitstore field
 func MatchEnableSplitstoreField(s string) bool {
 	enableSplitstoreRx := regexp.MustCompile(`(?m)^\s*EnableSplitstore\s*=`)
@@ -146,7 +184,9 @@ func Commented(commented bool) UpdateCfgOpt {
 }
 
 func DefaultKeepUncommented() UpdateCfgOpt {
-	return KeepUncommented(MatchEnableSplitstoreField)
+	return KeepUncommented(func(s string) bool {
+		return MatchEnableSplitstoreField(s) || MatchVersionField(s)
+	})
 }
 
 // ConfigUpdate takes in a config and a default config and optionally comments out default values
@@ -236,7 +276,11 @@ func ConfigUpdate(cfgCur, cfgDef interface{}, opts ...UpdateCfgOpt) ([]byte, err
 						outLines = append(outLines, pad+"# type: "+doc.Type)
 					}
 
-					outLines = append(outLines, pad+"# env var: LOTUS_"+strings.ToUpper(strings.ReplaceAll(section, ".", "_"))+"_"+strings.ToUpper(lf[0]))
+					envPrefix := "LOTUS_"
+					if section != "" {
+						envPrefix += strings.ToUpper(strings.ReplaceAll(section, ".", "_")) + "_"
+					}
+					outLines = append(outLines, pad+"# env var: "+envPrefix+strings.ToUpper(lf[0]))
 				}
 			}
 
@@ -257,7 +301,7 @@ func ConfigUpdate(cfgCur, cfgDef interface{}, opts ...UpdateCfgOpt) ([]byte, err
 
 	// sanity-check that the updated config parses the same way as the current one
 	if cfgDef != nil {
-		cfgUpdated, err := FromReader(strings.NewReader(nodeStr), cfgDef)
+		cfgUpdated, err := decodeConfig(strings.NewReader(nodeStr), cfgDef)
 		if err != nil {
 			return nil, xerrors.Errorf("parsing updated config: %w", err)
 		}
@@ -270,6 +314,16 @@ func ConfigUpdate(cfgCur, cfgDef interface{}, opts ...UpdateCfgOpt) ([]byte, err
 	return []byte(nodeStr), nil
 }
 
+// GenerateDocumentedTemplate encodes cfg as a TOML config template. Every field is
+// preceded by its documentation (taken from the config struct doc comments, see
+// doc_gen.go), type and env var name, and all settings except EnableSplitstore,
+// which full nodes require to be set explicitly, are commented out. Uncommenting
+// all settings reproduces cfg exactly when the template is decoded.
+func GenerateDocumentedTemplate(cfg interface{}) ([]byte, error) {
+	return ConfigUpdate(cfg, nil, Commented(true), DefaultKeepUncommented())
+}
+
+// ConfigComment is the same as GenerateDocumentedTemplate.
 func ConfigComment(t interface{}) ([]byte, error) {
-	return ConfigUpdate(t, nil, Commented(true), DefaultKeepUncommented())
+	return GenerateDocumentedTemplate(t)
 }
@@ -4,10 +4,15 @@ package config
 import (
 	"bytes"
 	"os"
+	"reflect"
+	"regexp"
 	"testing"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/lotus/chain/types"
 )
 
 func fullNodeDefault() (interface{}, error) { return DefaultFullNode(), nil }
@@ -17,9 +22,10 @@ func TestDecodeNothing(t *testing.T) {
 	assert := assert.New(t)
 
 	{
+		// an empty file has no Version, so it is migrated to the current one
 		cfg, err := FromFile(os.DevNull, SetDefault(fullNodeDefault))
 		assert.Nil(err, "error should be nil")
-		assert.Equal(DefaultFullNode(), cfg,
+		assert.Equal(WithCurrentVersion(DefaultFullNode()), cfg,
 			"config from empty file should be the same as default")
 	}
 
@@ -39,6 +45,7 @@ func TestParitalConfig(t *testing.T) {
 		Timeout = "10s"
 		`
 	expected := DefaultFullNode()
+	expected.Version = CurrentConfigVersion
 	expected.API.Timeout = Duration(10 * time.Second)
 
 	{
@@ -66,6 +73,32 @@ func TestParitalConfig(t *testing.T) {
 	}
 }
 
+func TestLibp2pConnMgrRoundtrip(t *testing.T) {
+	cfg := DefaultFullNode()
+	cfg.Libp2p.ConnMgrEmergencyGracePeriod = Duration(time.Minute)
+	cfg.Libp2p.ConnMgrSilencePeriod = Duration(30 * time.Second)
+	cfg.Libp2p.ProtocolPeerLimits = map[string]int{
+		"/fil/hello/1.0.0":    4,
+		"/fil/sync/blk/0.0.1": 16,
+	}
+
+	b, err := ConfigUpdate(cfg, DefaultFullNode())
+	assert.NoError(t, err)
+
+	loaded, err := FromReader(bytes.NewReader(b), DefaultFullNode())
+	assert.NoError(t, err)
+	cfg.Version = CurrentConfigVersion
+	assert.Equal(t, cfg, loaded)
+
+	// leaving the new fields out keeps the existing connection manager behaviour
+	loaded, err = FromReader(bytes.NewReader([]byte("[Libp2p]\nConnMgrLow = 100\n")), DefaultFullNode())
+	assert.NoError(t, err)
+	lc := loaded.(*FullNode).Libp2p
+	assert.Zero(t, lc.ConnMgrEmergencyGracePeriod)
+	assert.Zero(t, lc.ConnMgrSilencePeriod)
+	assert.Nil(t, lc.ProtocolPeerLimits)
+}
+
 func TestValidateSplitstoreSet(t *testing.T) {
 	cfgSet := ` 
 		EnableSplitstore = false
@@ -138,3 +171,60 @@ func TestFailToFallbackToDefault(t *testing.T) {
 	_, err = FromFile(nonExistantFileName, SetDefault(fullNodeDefault), SetCanFallbackOnDefault(NoDefaultForSplitstoreTransition))
 	assert.Error(t, err)
 }
+
+func TestApplyEnvOverrides(t *testing.T) {
+	t.Setenv("LOTUS_API_LISTENADDRESS", "/ip4/0.0.0.0/tcp/2345/http")
+	t.Setenv("LOTUS_SEALING_COMMITBATCHWAIT", "2h30m")
+	t.Setenv("LOTUS_SEALING_MAXCOMMITBATCH", "100")
+	t.Setenv("LOTUS_SEALING_AGGREGATECOMMITS", "false")
+	t.Setenv("LOTUS_SEALING_PRECOMMITGASMULTIPLIER", "1.2")
+	t.Setenv("LOTUS_FEES_MAXPRECOMMITGASFEE", "0.05 FIL")
+
+	sm := DefaultStorageMiner()
+	assert.NoError(t, ApplyEnvOverrides(sm))
+
+	expected := DefaultStorageMiner()
+	expected.API.ListenAddress = "/ip4/0.0.0.0/tcp/2345/http"
+	expected.Sealing.CommitBatchWait = Duration(2*time.Hour + 30*time.Minute)
+	expected.Sealing.MaxCommitBatch = 100
+	expected.Sealing.AggregateCommits = false
+	expected.Sealing.PreCommitGasMultiplier = 1.2
+	expected.Fees.MaxPreCommitGasFee = types.MustParseFIL("0.05")
+	assert.Equal(t, expected, sm)
+
+	t.Setenv("LOTUS_CHAINSTORE_ENABLESPLITSTORE", "false")
+	t.Setenv("LOTUS_LIBP2P_CONNMGRLOW", "20")
+
+	fn := DefaultFullNode()
+	assert.NoError(t, ApplyEnvOverrides(fn))
+	assert.Equal(t, "/ip4/0.0.0.0/tcp/2345/http", fn.API.ListenAddress)
+	assert.False(t, fn.Chainstore.EnableSplitstore)
+	assert.EqualValues(t, 20, fn.Libp2p.ConnMgrLow)
+	assert.Equal(t, DefaultFullNode().Fees, fn.Fees, "fields without env vars are unchanged")
+
+	t.Setenv("LOTUS_SEALING_COMMITBATCHWAIT", "soon")
+	assert.Error(t, ApplyEnvOverrides(DefaultStorageMiner()))
+}
+
+// commentedSettingRx matches settings commented out by GenerateDocumentedTemplate;
+// documentation lines have a space after the '#'.
+var commentedSettingRx = regexp.MustCompile(`(?m)^(\s*)#(\S)`)
+
+func TestGenerateDocumentedTemplateRoundtrip(t *testing.T) {
+	for name, def := range map[string]func() interface{}{
+		"FullNode":     func() interface{} { return DefaultFullNode() },
+		"StorageMiner": func() interface{} { return DefaultStorageMiner() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := GenerateDocumentedTemplate(def())
+			assert.NoError(t, err)
+
+			uncommented := commentedSettingRx.ReplaceAllString(string(tmpl), "$1$2")
+
+			decoded := reflect.New(reflect.TypeOf(def()).Elem()).Interface()
+			_, err = toml.Decode(uncommented, decoded)
+			assert.NoError(t, err)
+			assert.Equal(t, def(), decoded)
+		})
+	}
+}
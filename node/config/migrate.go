@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bytes"
+
+	"github.com/BurntSushi/toml"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("config")
+
+// CurrentConfigVersion is the config layout version of this release. It must be
+// bumped, with a migration appended to configMigrations, whenever a config change
+// requires existing config files to be rewritten.
+const CurrentConfigVersion = 2
+
+// WithCurrentVersion sets the Version of cfg, when it is a *FullNode or *StorageMiner, to
+// CurrentConfigVersion, for writing a new config file. It returns cfg.
+func WithCurrentVersion(cfg interface{}) interface{} {
+	switch c := cfg.(type) {
+	case *FullNode:
+		c.Version = CurrentConfigVersion
+	case *StorageMiner:
+		c.Version = CurrentConfigVersion
+	}
+	return cfg
+}
+
+// configMigration upgrades a parsed TOML config tree by one version, in place.
+type configMigration func(cfg map[string]interface{}) error
+
+// configMigrations[v] migrates a config from version v to version v+1.
+var configMigrations = []configMigration{
+	migrateV0ToV1,
+	migrateV1ToV2,
+}
+
+// MigrateConfig upgrades the TOML config in data from fromVersion to
+// CurrentConfigVersion, applying one migration per version bump, and returns the
+// re-encoded config with Version set. Configs without a Version field are version 0.
+// Works for both full node and miner configs. Comments in data are not preserved.
+func MigrateConfig(fromVersion int, data []byte) ([]byte, error) {
+	if fromVersion < 0 || fromVersion > CurrentConfigVersion {
+		return nil, xerrors.Errorf("can't migrate config from version %d, supported versions are 0 to %d", fromVersion, CurrentConfigVersion)
+	}
+
+	cfg := map[string]interface{}{}
+	if _, err := toml.Decode(string(data), &cfg); err != nil {
+		return nil, xerrors.Errorf("decoding config: %w", err)
+	}
+
+	for v := fromVersion; v < CurrentConfigVersion; v++ {
+		if err := configMigrations[v](cfg); err != nil {
+			return nil, xerrors.Errorf("migrating config from version %d to %d: %w", v, v+1, err)
+		}
+	}
+	cfg["Version"] = int64(CurrentConfigVersion)
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+		return nil, xerrors.Errorf("encoding migrated config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// migrateToCurrent migrates the TOML config in data to CurrentConfigVersion if it was written for an
+// older version. Configs of newer versions are returned as-is, for validation to reject.
+func migrateToCurrent(data []byte) ([]byte, error) {
+	var hdr struct{ Version int }
+	if _, err := toml.Decode(string(data), &hdr); err != nil {
+		return nil, err
+	}
+	if hdr.Version >= CurrentConfigVersion {
+		return data, nil
+	}
+
+	log.Warnf("config file is for config version %d, migrating it to version %d; print the migrated config with the config updated command", hdr.Version, CurrentConfigVersion)
+	return MigrateConfig(hdr.Version, data)
+}
+
+// minerOnlySections are top-level tables which only exist in StorageMiner configs.
+var minerOnlySections = []string{"Subsystems", "Dealmaking", "IndexProvider", "Proving", "Sealing", "Storage", "Addresses", "DAGStore"}
+
+func isMinerConfig(cfg map[string]interface{}) bool {
+	for _, s := range minerOnlySections {
+		if _, ok := cfg[s]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// configTable returns the table stored under key in cfg, creating it when missing.
+func configTable(cfg map[string]interface{}, key string) (map[string]interface{}, error) {
+	v, ok := cfg[key]
+	if !ok {
+		t := map[string]interface{}{}
+		cfg[key] = t
+		return t, nil
+	}
+	t, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, xerrors.Errorf("%s is a %T, not a table", key, v)
+	}
+	return t, nil
+}
+
+// migrateV0ToV1 writes out Fevm.EnableEthRPC in full node configs which don't set
+// it, so that the Eth RPC stays in the state the node was running with even if the
+// default changes in a later release.
+func migrateV0ToV1(cfg map[string]interface{}) error {
+	if isMinerConfig(cfg) {
+		return nil
+	}
+
+	fevm, err := configTable(cfg, "Fevm")
+	if err != nil {
+		return err
+	}
+	if _, ok := fevm["EnableEthRPC"]; !ok {
+		fevm["EnableEthRPC"] = DefaultFullNode().Fevm.EnableEthRPC
+	}
+	return nil
+}
+
+// migrateV1ToV2 renames Sealing.CommittedCapacityDefaultLifetime to
+// Sealing.CommittedCapacitySectorLifetime. When both are set the new name wins.
+func migrateV1ToV2(cfg map[string]interface{}) error {
+	v, ok := cfg["Sealing"]
+	if !ok {
+		return nil
+	}
+	sealing, ok := v.(map[string]interface{})
+	if !ok {
+		return xerrors.Errorf("Sealing is a %T, not a table", v)
+	}
+
+	old, ok := sealing["CommittedCapacityDefaultLifetime"]
+	if !ok {
+		return nil
+	}
+	delete(sealing, "CommittedCapacityDefaultLifetime")
+	if _, ok := sealing["CommittedCapacitySectorLifetime"]; !ok {
+		sealing["CommittedCapacitySectorLifetime"] = old
+	}
+	return nil
+}
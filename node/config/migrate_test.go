@@ -0,0 +1,159 @@
+// stm: #unit
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigMigrationsCoverAllVersions(t *testing.T) {
+	require.Len(t, configMigrations, CurrentConfigVersion)
+
+	// configs without a Version are from before versioning
+	require.Zero(t, DefaultFullNode().Version)
+	require.Zero(t, DefaultStorageMiner().Version)
+	require.Equal(t, CurrentConfigVersion, WithCurrentVersion(DefaultFullNode()).(*FullNode).Version)
+	require.Equal(t, CurrentConfigVersion, WithCurrentVersion(DefaultStorageMiner()).(*StorageMiner).Version)
+}
+
+func TestMigrateConfigV0ToV1(t *testing.T) {
+	cfg := map[string]interface{}{
+		"API": map[string]interface{}{"ListenAddress": "/ip4/127.0.0.1/tcp/1234/http"},
+	}
+	require.NoError(t, migrateV0ToV1(cfg))
+	require.Equal(t, map[string]interface{}{"EnableEthRPC": false}, cfg["Fevm"])
+
+	// an explicit setting is kept
+	cfg = map[string]interface{}{"Fevm": map[string]interface{}{"EnableEthRPC": true}}
+	require.NoError(t, migrateV0ToV1(cfg))
+	require.Equal(t, true, cfg["Fevm"].(map[string]interface{})["EnableEthRPC"])
+
+	// miner configs have no Fevm section
+	cfg = map[string]interface{}{"Sealing": map[string]interface{}{}}
+	require.NoError(t, migrateV0ToV1(cfg))
+	require.NotContains(t, cfg, "Fevm")
+
+	require.Error(t, migrateV0ToV1(map[string]interface{}{"Fevm": "yes"}))
+}
+
+func TestMigrateConfigV1ToV2(t *testing.T) {
+	cfg := map[string]interface{}{
+		"Sealing": map[string]interface{}{"CommittedCapacityDefaultLifetime": "720h0m0s"},
+	}
+	require.NoError(t, migrateV1ToV2(cfg))
+	require.Equal(t, map[string]interface{}{"CommittedCapacitySectorLifetime": "720h0m0s"}, cfg["Sealing"])
+
+	// the new name wins when both are set
+	cfg = map[string]interface{}{
+		"Sealing": map[string]interface{}{
+			"CommittedCapacityDefaultLifetime": "720h0m0s",
+			"CommittedCapacitySectorLifetime":  "1440h0m0s",
+		},
+	}
+	require.NoError(t, migrateV1ToV2(cfg))
+	require.Equal(t, map[string]interface{}{"CommittedCapacitySectorLifetime": "1440h0m0s"}, cfg["Sealing"])
+
+	cfg = map[string]interface{}{}
+	require.NoError(t, migrateV1ToV2(cfg))
+	require.Empty(t, cfg)
+}
+
+func TestMigrateConfigFromV0(t *testing.T) {
+	v0FullNode := `
+[API]
+  ListenAddress = "/ip4/127.0.0.1/tcp/1234/http"
+
+[Chainstore]
+  EnableSplitstore = false
+`
+	migrated, err := MigrateConfig(0, []byte(v0FullNode))
+	require.NoError(t, err)
+
+	var raw map[string]interface{}
+	_, err = toml.Decode(string(migrated), &raw)
+	require.NoError(t, err)
+	require.EqualValues(t, CurrentConfigVersion, raw["Version"])
+	require.Contains(t, raw["Fevm"], "EnableEthRPC")
+
+	// the migrated config loads over a zero version, so Version comes from the file
+	def := DefaultFullNode()
+	def.Version = 0
+	loaded, err := FromReader(bytes.NewReader(migrated), def)
+	require.NoError(t, err)
+	fn := loaded.(*FullNode)
+	require.NoError(t, fn.Validate())
+	require.Equal(t, CurrentConfigVersion, fn.Version)
+	require.Equal(t, "/ip4/127.0.0.1/tcp/1234/http", fn.API.ListenAddress)
+	require.False(t, fn.Chainstore.EnableSplitstore)
+
+	v0Miner := `
+[Sealing]
+  MaxWaitDealsSectors = 4
+  CommittedCapacityDefaultLifetime = "720h0m0s"
+`
+	migrated, err = MigrateConfig(0, []byte(v0Miner))
+	require.NoError(t, err)
+
+	sm := DefaultStorageMiner()
+	sm.Version = 0
+	loaded, err = FromReader(bytes.NewReader(migrated), sm)
+	require.NoError(t, err)
+	sm = loaded.(*StorageMiner)
+	require.NoError(t, sm.Validate())
+	require.Equal(t, CurrentConfigVersion, sm.Version)
+	require.EqualValues(t, 4, sm.Sealing.MaxWaitDealsSectors)
+	require.Equal(t, Duration(720*time.Hour), sm.Sealing.CommittedCapacitySectorLifetime)
+	require.NotContains(t, string(migrated), "Fevm")
+}
+
+func TestMigrateConfigCurrentIsNoop(t *testing.T) {
+	data := []byte(fmt.Sprintf("Version = %d\n\n[API]\n  Timeout = \"10s\"\n", CurrentConfigVersion))
+	migrated, err := MigrateConfig(CurrentConfigVersion, data)
+	require.NoError(t, err)
+
+	loaded, err := FromReader(bytes.NewReader(migrated), DefaultFullNode())
+	require.NoError(t, err)
+	expected := DefaultFullNode()
+	expected.Version = CurrentConfigVersion
+	expected.API.Timeout = Duration(10 * time.Second)
+	require.Equal(t, expected, loaded)
+}
+
+func TestFromReaderMigrates(t *testing.T) {
+	// an unversioned miner config using the setting renamed in version 2
+	data := "[Sealing]\n  CommittedCapacityDefaultLifetime = \"720h0m0s\"\n"
+	loaded, err := FromReader(strings.NewReader(data), DefaultStorageMiner())
+	require.NoError(t, err)
+	sm := loaded.(*StorageMiner)
+	require.Equal(t, CurrentConfigVersion, sm.Version)
+	require.Equal(t, Duration(720*time.Hour), sm.Sealing.CommittedCapacitySectorLifetime)
+
+	// current configs are loaded as-is
+	data = fmt.Sprintf("Version = %d\n[Sealing]\n  CommittedCapacityDefaultLifetime = \"720h0m0s\"\n", CurrentConfigVersion)
+	loaded, err = FromReader(strings.NewReader(data), DefaultStorageMiner())
+	require.NoError(t, err)
+	require.Equal(t, DefaultStorageMiner().Sealing.CommittedCapacitySectorLifetime, loaded.(*StorageMiner).Sealing.CommittedCapacitySectorLifetime)
+
+	// new config files keep their version
+	tmpl, err := GenerateDocumentedTemplate(WithCurrentVersion(DefaultFullNode()))
+	require.NoError(t, err)
+	require.Contains(t, string(tmpl), fmt.Sprintf("\nVersion = %d\n", CurrentConfigVersion))
+	tmpl, err = GenerateDocumentedTemplate(DefaultFullNode())
+	require.NoError(t, err)
+	require.Contains(t, string(tmpl), "\n#Version = 0\n")
+}
+
+func TestMigrateConfigErrors(t *testing.T) {
+	_, err := MigrateConfig(-1, nil)
+	require.Error(t, err)
+	_, err = MigrateConfig(CurrentConfigVersion+1, nil)
+	require.Error(t, err)
+	_, err = MigrateConfig(0, []byte("[API"))
+	require.Error(t, err)
+}
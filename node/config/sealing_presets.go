@@ -0,0 +1,74 @@
+package config
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-state-types/big"
+	miner5 "github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+
+	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// SealingConfigHighThroughput returns the default SealingConfig tuned to seal
+// as many sectors as possible per unit of chain fees.
+//
+// Deal sectors are allowed to accumulate for longer and in larger numbers,
+// concurrent sealing is not capped, and PreCommit / ProveCommit messages are
+// always batched and aggregated up to the protocol maximums, regardless of the
+// current base fee.
+//
+// The trade-off is latency: the time from a deal being handed to the miner to
+// its sector becoming active can grow to days, many sectors sit in the
+// pipeline at once (which requires a lot of scratch space and collateral), and
+// a failed batch message affects many sectors at once.
+func SealingConfigHighThroughput() SealingConfig {
+	sc := DefaultStorageMiner().Sealing
+
+	sc.MaxWaitDealsSectors = 16
+	sc.MaxSealingSectors = 0 // no limit
+	sc.MaxSealingSectorsForDeals = 0
+	sc.WaitDealsDelay = Duration(12 * time.Hour)
+
+	sc.MaxPreCommitBatch = miner5.PreCommitSectorBatchMaxSize
+	sc.PreCommitBatchWait = Duration(24 * time.Hour) // must stay below the 31.5 hour precommit ticket expiration
+	sc.PreCommitBatchSlack = Duration(3 * time.Hour)
+	sc.BatchPreCommitAboveBaseFee = types.FIL(big.Zero()) // always batch
+
+	sc.AggregateCommits = true
+	sc.MinCommitBatch = miner5.MinAggregatedSectors
+	sc.MaxCommitBatch = miner5.MaxAggregatedSectors
+	sc.CommitBatchWait = Duration(72 * time.Hour)
+	sc.CommitBatchSlack = Duration(6 * time.Hour)
+	sc.AggregateAboveBaseFee = types.FIL(big.Zero()) // always aggregate
+
+	return sc
+}
+
+// SealingConfigLowLatency returns the default SealingConfig tuned to minimise
+// the time between a deal (or pledge) entering the pipeline and its sector
+// being committed on chain.
+//
+// Each deal sector starts sealing as soon as it receives its first deal,
+// PreCommit messages are flushed after a single epoch instead of being held
+// for a full batch, and ProveCommits are sent individually without aggregation.
+//
+// The trade-off is cost: deal sectors are sealed mostly empty, and sending one
+// message per sector forgoes the gas savings of batching and aggregation, which
+// becomes expensive when the base fee is high.
+func SealingConfigLowLatency() SealingConfig {
+	sc := DefaultStorageMiner().Sealing
+
+	sc.MaxWaitDealsSectors = 1
+	sc.WaitDealsDelay = Duration(0)
+
+	// waiting less than one epoch can't get a message on chain any sooner
+	sc.PreCommitBatchWait = Duration(builtin.EpochDurationSeconds * time.Second)
+	sc.PreCommitBatchSlack = Duration(0)
+
+	sc.AggregateCommits = false
+	sc.CommitBatchWait = Duration(builtin.EpochDurationSeconds * time.Second)
+	sc.CommitBatchSlack = Duration(0)
+
+	return sc
+}
@@ -0,0 +1,30 @@
+// stm: #unit
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealingPresetsValidate(t *testing.T) {
+	for name, preset := range map[string]func() SealingConfig{
+		"HighThroughput": SealingConfigHighThroughput,
+		"LowLatency":     SealingConfigLowLatency,
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := DefaultStorageMiner()
+			c.Sealing = preset()
+			require.NoError(t, c.Validate())
+		})
+	}
+
+	ht := SealingConfigHighThroughput()
+	require.True(t, ht.AggregateCommits)
+	require.Greater(t, ht.MaxWaitDealsSectors, DefaultStorageMiner().Sealing.MaxWaitDealsSectors)
+
+	ll := SealingConfigLowLatency()
+	require.False(t, ll.AggregateCommits)
+	require.Equal(t, uint64(1), ll.MaxWaitDealsSectors)
+	require.Zero(t, ll.WaitDealsDelay)
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"github.com/ipfs/go-cid"
 
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+
 	"github.com/filecoin-project/lotus/chain/types"
 )
 
@@ -21,6 +24,12 @@ type Common struct {
 
 // FullNode is a full node config
 type FullNode struct {
+	// Version is the layout version of this config file. New config files are written with the
+	// current version. Files without a Version, or with a lower one, were written by older releases
+	// and are migrated to the current layout when loaded; the config updated command prints the
+	// migrated config.
+	Version int
+
 	Common
 	Client        Client
 	Wallet        Wallet
@@ -51,6 +60,12 @@ type Logging struct {
 
 // StorageMiner is a miner config
 type StorageMiner struct {
+	// Version is the layout version of this config file. New config files are written with the
+	// current version. Files without a Version, or with a lower one, were written by older releases
+	// and are migrated to the current layout when loaded; the config updated command prints the
+	// migrated config.
+	Version int
+
 	Common
 
 	Subsystems    MinerSubsystemConfig
@@ -77,6 +92,13 @@ type DAGStoreConfig struct {
 	// <LOTUS_MINER_PATH>/dagstore (monolith deployment)
 	RootDir string
 
+	// Path to the directory holding the transient copies of unsealed pieces,
+	// e.g. to keep them on a different disk than the shard indices. Existing
+	// transients aren't moved when this is changed; pieces are fetched again
+	// from the storage subsystem as needed.
+	// Default value: empty (<RootDir>/transients).
+	PieceDirectory string
+
 	// The maximum amount of indexing jobs that can run simultaneously.
 	// 0 means unlimited.
 	// Default value: 5.
@@ -97,10 +119,61 @@ type DAGStoreConfig struct {
 	// Default value: 100.
 	MaxConcurrencyStorageCalls int
 
+	// The maximum number of shards that can be loaded simultaneously to
+	// serve retrievals. Unlike MaxConcurrencyStorageCalls, this doesn't
+	// affect indexing. 0 means unlimited.
+	// Default value: 100.
+	MaxConcurrentRetrievals int
+
 	// The time between calls to periodic dagstore GC, in time.Duration string
 	// representation, e.g. 1m, 5m, 1h.
 	// Default value: 1 minute.
 	GCInterval Duration
+
+	// The maximum total size, in bytes, of the transient copies of unsealed
+	// pieces kept in the transients directory. When exceeded, the transients
+	// of shards not currently serving retrievals are evicted according to
+	// TransientEvictionPolicy. 0 means unlimited.
+	// Default value: 0 (unlimited).
+	TransientStoreSizeCap uint64
+
+	// The order in which transients are evicted when TransientStoreSizeCap
+	// is exceeded: "lru" evicts the least recently used transients first,
+	// "fifo" the oldest ones, and "none" disables eviction.
+	// Default value: "none".
+	TransientEvictionPolicy string
+
+	// The time between transient eviction sweeps, in time.Duration string
+	// representation, e.g. 1m, 5m, 1h. 0 means GCInterval is used.
+	// Default value: 0 (GCInterval).
+	TransientGCInterval Duration
+
+	// The time between full compactions of the datastore holding the shard
+	// index, which reclaims the space of deleted entries, in time.Duration
+	// string representation, e.g. 1h, 24h. 0 disables periodic compaction.
+	// Default value: 0 (disabled).
+	IndexCompactionInterval Duration
+
+	// The number of times a failed shard is recovered in the background,
+	// every RecoveryInterval, before it's considered permanently failed.
+	// Permanently failed shards raise an alert, and are only recovered
+	// manually, e.g. with lotus-miner dagstore recover-shard. 0 disables
+	// background recovery.
+	// Default value: 3.
+	RecoveryRetries int
+
+	// The time between background recoveries of failed shards, in
+	// time.Duration string representation, e.g. 10m, 1h.
+	// Default value: 10 minutes.
+	RecoveryInterval Duration
+
+	// The time between flushes of piece metadata writes, in time.Duration
+	// string representation, e.g. 1s, 10s. When set, writes to the piece
+	// store are buffered in memory and written as a single batch, followed
+	// by a sync, at this interval; writes buffered since the last flush are
+	// lost if the miner crashes. 0 writes piece metadata synchronously.
+	// Default value: 0 (synchronous).
+	PieceStoreFlushInterval Duration
 }
 
 type MinerSubsystemConfig struct {
@@ -140,9 +213,17 @@ type DealmakingConfig struct {
 	// The maximum number of deals to include in a single PublishStorageDeals
 	// message
 	MaxDealsPerPublishMsg uint64
+	// Whether deals are published automatically, as configured by PublishMsgPeriod and
+	// MaxDealsPerPublishMsg. When disabled, accepted deals wait in the publish queue until
+	// they are published with lotus-miner storage-deals publish
+	AutoPublishEnabled bool
 	// The maximum collateral that the provider will put up against a deal,
 	// as a multiplier of the minimum collateral bound
 	MaxProviderCollateralMultiplier uint64
+	// The maximum storage price per epoch of a deal, for the whole piece rather than per GiB. Deals proposing a
+	// higher price are rejected, which protects against accepting deals whose price was inflated by mistake,
+	// e.g. a misplaced decimal point in the client wallet. 0 is no cap.
+	StoragePriceCap types.FIL
 	// The maximum allowed disk usage size in bytes of staging deals not yet
 	// passed to the sealing node by the markets service. 0 is unlimited.
 	MaxStagingDealsBytes int64
@@ -159,15 +240,48 @@ type DealmakingConfig struct {
 	SimultaneousTransfersForRetrieval uint64
 	// Minimum start epoch buffer to give time for sealing of sector with deal.
 	StartEpochSealingBuffer uint64
+	// The maximum number of distinct client addresses with deals in a single sector. Once a sector holds deals
+	// from this many clients, deals from other clients are packed into other sectors. 0 is unlimited.
+	MaxDealClientAddressCount int
+	// When enabled, an unsealed copy of a deal's data is kept only if the deal requested fast retrieval, so that
+	// individual deals can opt out of fast retrieval to save disk space; Sealing.AlwaysKeepUnsealedCopy is ignored
+	// for deal data. When disabled, unsealed copies are kept for deals requesting fast retrieval, and for all deals
+	// if Sealing.AlwaysKeepUnsealedCopy is set.
+	FastRetrieval bool
 
 	// A command used for fine-grained evaluation of storage deals
 	// see https://lotus.filecoin.io/storage-providers/advanced-configurations/market/#using-filters-for-fine-grained-storage-and-retrieval-deal-acceptance for more details
 	Filter string
+	// A boolean expression evaluated for every storage deal proposal, which is rejected when the expression is false.
+	// The expression uses Go syntax and may refer to proposalCid, client (the client's ID address), pieceSize,
+	// price (attoFIL per epoch) and verified, combined with && || !, comparisons and oneOf, e.g.
+	// verified && oneOf(client, "f01234", "f05678") || price >= 1000000. Arithmetic is not supported. It is checked
+	// when the node starts, and runs before Filter when both are set. Empty disables it
+	FilterExpression string
 	// A command used for fine-grained evaluation of retrieval deals
 	// see https://lotus.filecoin.io/storage-providers/advanced-configurations/market/#using-filters-for-fine-grained-storage-and-retrieval-deal-acceptance for more details
 	RetrievalFilter string
 
+	// URL (http or https) or file path of an oracle providing storage prices. When set, the oracle is polled
+	// and the storage ask is updated whenever its prices change. The oracle must return a JSON object in the
+	// form {"price": "123456789", "verifiedPrice": "0"}, with prices in attoFIL/GiB/Epoch. Responses which
+	// take longer than 30 seconds, or with prices above 1 FIL/GiB/Epoch, are rejected and the ask is left unchanged
+	StoragePriceOracle string
+	// How often the storage price oracle is polled
+	StoragePriceOraclePollInterval Duration
+
 	RetrievalPricing *RetrievalPricing
+
+	// When enabled (default), the DAGStore fetches pieces it needs to serve retrievals
+	// from sector storage, unsealing them if necessary. Disable this when pieces are
+	// stored and served externally (e.g. over a separate HTTP endpoint); the retrieval
+	// market will then only serve data which is already held by the DAGStore.
+	//
+	// The DAGStore also reads pieces to initialize and index shards, so while this is
+	// disabled shards can't be initialized: new deals aren't indexed, their multihashes
+	// aren't announced to network indexers, and shard recovery (lotus-miner dagstore
+	// recover-shard / initialize-all) fails. Existing, initialized shards keep working.
+	PieceRetrievalTransfer bool
 }
 
 type IndexProviderConfig struct {
@@ -198,6 +312,12 @@ type IndexProviderConfig struct {
 	// starts. By default, the cache is rehydrated from previously cached entries stored in
 	// datastore if any is present.
 	PurgeCacheOnStart bool
+
+	// GossipSubHeartbeatInterval sets how often the latest advertisement is re-announced on the indexer topic, for
+	// indexers which missed the original announcement. A head which was just announced, either because it was
+	// published or by an earlier heartbeat, isn't announced again until a full interval has passed.
+	// Only applies when Enable is set. 0 disables re-announcements.
+	GossipSubHeartbeatInterval Duration
 }
 
 type RetrievalPricing struct {
@@ -219,6 +339,13 @@ type RetrievalPricingDefault struct {
 	// This parameter is ONLY applicable if the retrieval pricing policy strategy has been configured to "default".
 	// default value is true
 	VerifiedDealsFreeTransfer bool
+
+	// RetrievalPricingDefaultBytePrice overrides the per-byte price from the retrieval ask, which is
+	// useful when the ask is shared with the storage market. Transfer of verified deal data stays
+	// free if VerifiedDealsFreeTransfer is set.
+	// This parameter is ONLY applicable if the retrieval pricing policy strategy has been configured to "default".
+	// default value is 0, which uses the price from the ask
+	RetrievalPricingDefaultBytePrice types.FIL
 }
 
 type ProvingConfig struct {
@@ -232,6 +359,11 @@ type ProvingConfig struct {
 	// 'lotus-miner proving compute window-post 0'
 	ParallelCheckLimit int
 
+	// When enabled, the number of sector checks run in parallel is derived from the number of sectors being
+	// checked and the number of CPUs (twice the CPU count, at most one per sector, between 1 and 512), and
+	// ParallelCheckLimit is ignored.
+	AdaptiveParallelCheckLimit bool
+
 	// Maximum amount of time a proving pre-check can take for a sector. If the check times out the sector will be skipped
 	//
 	// WARNING: Setting this value too low risks in sectors being skipped even though they are accessible, just reading the
@@ -249,6 +381,16 @@ type ProvingConfig struct {
 	// blocked or slow
 	PartitionCheckTimeout Duration
 
+	// When enabled, window PoSt computed on the lotus-miner process takes priority over sealing tasks of the
+	// miner's local worker: all of the worker's GPUs are reserved for the proof as soon as it starts, and sealing
+	// tasks needing a GPU which are assigned to the worker don't start until the proof is done.
+	//
+	// NOTE: the proofs library can't interrupt a running computation, so a sealing task which was already computing
+	// on the GPU keeps running and shares it with the proof. Preempted tasks are held back and restarted by the
+	// scheduler once the proof is done, which delays sealing. This has no effect on window PoSt workers, which
+	// don't run sealing tasks.
+	HighPriorityWindowPoSt bool
+
 	// Disable Window PoSt computation on the lotus-miner process even if no window PoSt workers are present.
 	//
 	// WARNING: If no windowPoSt workers are connected, window PoSt WILL FAIL resulting in faulty sectors which will need
@@ -309,6 +451,14 @@ type ProvingConfig struct {
 	// resulting in more total gas use (but each message will have lower gas limit)
 	MaxPartitionsPerRecoveryMessage int
 
+	// Maximum number of DeclareFaultsRecovered messages to spread the recoveries of a single deadline over. 0 = no limit.
+	//
+	// When MaxPartitionsPerRecoveryMessage would produce more messages than this, the partitions are spread evenly
+	// over this many messages instead. Independently of this setting, a message which doesn't fit in the block gas
+	// limit is split in half until every part fits, so all recoveries are always declared; with very large
+	// recoveries the number of messages sent can therefore exceed this value
+	MaxFaultRecoveryMessages int
+
 	// Enable single partition per PoSt Message for partitions containing recovery sectors
 	//
 	// In cases when submitting PoSt messages which contain recovering sectors, the default network limit may still be
@@ -318,6 +468,64 @@ type ProvingConfig struct {
 	// Note that setting this value lower may result in less efficient gas use - more messages will be sent,
 	// to prove each deadline, resulting in more total gas use (but each message will have lower gas limit)
 	SingleRecoveringPartitionPerPostMessage bool
+
+	// Factor by which the estimated gas limit of DeclareFaultsRecovered messages is multiplied before the message is
+	// submitted. The gas estimator can underestimate fault recovery declarations covering many partitions, resulting
+	// in messages running out of gas. Must be in the range [1.0, 3.0]; 0 leaves the estimate unchanged
+	FaultDeclarationGasMultiplier float64
+
+	// Number of epochs a WindowPoSt message must be buried under before the submission is counted as confirmed.
+	//
+	// Raising this value protects against shallow reorgs which undo a PoSt that has just landed on chain. Values
+	// below the build message confidence (5 epochs on mainnet) have no effect
+	PoStMessageConfirmDepth int
+
+	// Flat amount of gas added to the estimated gas limit of all WindowPoSt messages (SubmitWindowedPoSt, fault
+	// and recovery declarations) after FaultDeclarationGasMultiplier is applied.
+	//
+	// Messages which barely pass gas estimation can run out of gas when execution costs slightly more than
+	// estimated. The limit is never raised above the block gas limit
+	WdPoStMessageGasBuffer uint64
+
+	// WindowPoStNonceStrategy sets how WindowPoSt messages are spread across the PoSt control addresses of the
+	// miner, and so how their nonces are assigned.
+	// "sequential" (default) - messages are sent one after another from the first control address, in a stable
+	// order, which has enough funds.
+	// "parallel-nonce-gap" - like sequential, but the messages of a deadline are pushed concurrently, as many at a
+	// time as the message pool nonce gap allows.
+	// "round-robin" - each message is sent from the next control address in turn, so that no single address
+	// accumulates pending nonces.
+	WindowPoStNonceStrategy string
+
+	// WindowPoStDispatchDeadlineBuffer delays submitting the WindowPoSt messages of a deadline by this many epochs
+	// past the usual confidence delay after the deadline opens. Miners which share workers or control addresses can
+	// each be given a different value so that their proofs don't all reach the message pool at the same time.
+	// The delay is capped at half of the deadline challenge window (30 epochs on mainnet). 0 (default) submits
+	// proofs as soon as they are ready
+	WindowPoStDispatchDeadlineBuffer int
+
+	// WindowPoStWorkerType selects where window PoSt SNARKs are computed.
+	// "local" (default) - on window PoSt workers, or on the lotus-miner process when there are none.
+	// "remote" - on the remote proving service at RemotePoStEndpoint. Vanilla proofs are still read from the
+	// sectors by lotus-miner, and are sent to the service with the sector challenges of each partition.
+	WindowPoStWorkerType string
+
+	// RemotePoStEndpoint is the HTTPS URL of the remote proving service used when WindowPoStWorkerType is "remote".
+	// Requests carry the same authorization header as requests to workers.
+	RemotePoStEndpoint string
+
+	// UseFallbackProver enables retrying window PoSt partitions which fail on PoSt workers, or on the lotus-miner
+	// process, with FallbackProverCommand. Partitions with sectors whose vanilla proofs can't be read are not retried.
+	UseFallbackProver bool
+
+	// FallbackProverCommand is the shell command computing window PoSt partition proofs when UseFallbackProver is
+	// enabled. It is run with a JSON object on stdin holding the proof type, miner ID, partition index, randomness,
+	// sector challenges and the vanilla proof of each sector, and must write the proof bytes to stdout.
+	FallbackProverCommand string
+
+	// FallbackProverTimeout is the maximum time FallbackProverCommand can run for a partition before it is killed.
+	// 0 means no timeout.
+	FallbackProverTimeout Duration
 }
 
 type SealingConfig struct {
@@ -353,6 +561,11 @@ type SealingConfig struct {
 	// DEPRECATED: Target expiration is no longer used
 	MinTargetUpgradeSectorExpiration uint64
 
+	// SnapDealSectorReuseWindow is the minimum number of epochs a CC sector must have remaining in its lifetime to
+	// be considered for snap-deal upgrades, so that sectors close to expiry aren't filled with deals.
+	// 0 allows sectors of any remaining lifetime. Must not exceed the maximum sector expiration extension.
+	SnapDealSectorReuseWindow abi.ChainEpoch
+
 	// CommittedCapacitySectorLifetime is the duration a Committed Capacity (CC) sector will
 	// live before it must be extended or converted into sector containing deals before it is
 	// terminated. Value must be between 180-1278 days (1278 in nv21, 540 before nv21).
@@ -369,6 +582,12 @@ type SealingConfig struct {
 	// Run sector finalization before submitting sector proof to the chain
 	FinalizeEarly bool
 
+	// How long to wait before automatically retrying a failed PreCommit1, PreCommit2 or proof computation.
+	// "exponential" doubles the wait, starting at 1 minute, with each consecutive failure, "linear" waits one more
+	// minute with each consecutive failure; both wait at most 1 hour. "none" doesn't retry automatically, leaving
+	// the sector in the failed state until it's moved manually with lotus-miner sectors update-state
+	SectorSealRetryPolicy string
+
 	// Whether new sectors are created to pack incoming deals
 	// When this is set to false no new sectors will be created for sealing incoming deals
 	// This is useful for forcing all deals to be assigned as snap deals to sectors marked for upgrade
@@ -383,13 +602,49 @@ type SealingConfig struct {
 	AvailableBalanceBuffer types.FIL
 	// Don't send collateral with messages even if there is no available balance in the miner actor
 	DisableCollateralFallback bool
+	// Extra amount of FIL to add on top of the estimated pre-commit deposit attached to pre-commit messages. This acts
+	// as a safety margin in case the required deposit changes between estimation and message execution; any excess
+	// stays in the miner actor as available balance
+	PledgeCollateralBuffer types.FIL
 
 	// maximum precommit batch size - batches will be sent immediately above this size
 	MaxPreCommitBatch int
+	// When enabled, batches are sent immediately once their estimated total fee reaches MaxPreCommitBatchFeeValue,
+	// instead of once they reach MaxPreCommitBatch sectors. The estimated fee is the batch network fee at the current
+	// BaseFee plus the gas fee allowed for the batch by Fees.MaxPreCommitBatchGasFee
+	MaxPreCommitBatchByValue bool
+	// estimated total fee at which a precommit batch is sent when MaxPreCommitBatchByValue is enabled
+	MaxPreCommitBatchFeeValue types.FIL
 	// how long to wait before submitting a batch after crossing the minimum batch size
 	PreCommitBatchWait Duration
 	// time buffer for forceful batch submission before sectors/deal in batch would start expiring
 	PreCommitBatchSlack Duration
+	// When enabled, sectors reaching PreCommitBatchSlack are removed from the batch instead of forcing the whole
+	// batch out, possibly at a high BaseFee. Removed sectors are restarted with a new ticket, redoing PC1 and PC2,
+	// while the remaining sectors keep batching
+	PreCommitBatchAbortOnExpiry bool
+	// Fraction of the time a sector ticket can be pre-committed with, minus PreCommitBatchSlack, after which PC1
+	// isn't started with the ticket any more; the sector gets a new ticket instead, so that it isn't pre-committed
+	// too late. PC1 runs which already started aren't interrupted. 0 never stops PC1
+	P1StopFraction float64
+	// Maximum number of pre-commit messages which may be waiting to land on chain at the same time. Pending
+	// pre-commits are held back until earlier messages land, which limits the nonce gaps left in the message
+	// pool when some of the messages are dropped. Messages sent before a restart aren't counted; 0 = unlimited
+	MaxPreCommitsInFlight int
+	// Factor by which the estimated gas limit of pre-commit messages is multiplied before sending. Over-estimating
+	// gas protects against out-of-gas failures when state changes between estimation and execution, especially near
+	// epoch boundaries. Must be in the range [1.0, 2.0]
+	PreCommitGasMultiplier float64
+
+	// Factor by which the estimated gas limit of prove-commit messages, both single and aggregated, is multiplied
+	// before sending. Over-estimating gas protects against out-of-gas failures when state changes between
+	// estimation and execution, especially near epoch boundaries. Must be in the range [1.0, 2.0]
+	CommitGasMultiplier float64
+
+	// Factor by which the estimated gas limit of ProveReplicaUpdates messages, sent for snap deals, is multiplied
+	// before sending. Replica updates are estimated separately from prove-commits, as their execution cost
+	// differs. Must be in the range [1.0, 2.0]
+	ReplicaUpdateGasMultiplier float64
 
 	// enable / disable commit aggregation (takes effect after nv13)
 	AggregateCommits bool
@@ -401,15 +656,33 @@ type SealingConfig struct {
 	CommitBatchWait Duration
 	// time buffer for forceful batch submission before sectors/deals in batch would start expiring
 	CommitBatchSlack Duration
+	// how long to keep a commit batch open for more sectors once it's due to be sent, when it isn't full. Sectors
+	// becoming ready within the window join the batch, which is sent right away if it reaches MaxCommitBatch.
+	// The window is taken out of CommitBatchSlack, and must be shorter than it. 0 sends batches when they are due
+	CommitBatchOverlapWindow Duration
 
 	// network BaseFee below which to stop doing precommit batching, instead
 	// sending precommit messages to the chain individually. When the basefee is
 	// below this threshold, precommit messages will get sent out immediately.
 	BatchPreCommitAboveBaseFee types.FIL
+	// When enabled, pending precommits are re-evaluated against the current chain head's
+	// BaseFee every epoch, instead of only when new sectors are added to the batch. This
+	// sends the batch out as soon as the BaseFee drops below BatchPreCommitAboveBaseFee.
+	BatchPreCommitAboveBaseFeeDynamic bool
+	// SectorPreCommitBatchGasTarget lowers the BaseFee at which pending precommits are sent out early to this
+	// fraction of BatchPreCommitAboveBaseFee. While the BaseFee is falling, precommits then wait for the lower
+	// tier, re-evaluated every epoch, until it is reached or PreCommitBatchWait expires. Must be in the range
+	// [0.0, 1.0]; 0 means no target.
+	SectorPreCommitBatchGasTarget float64
 
 	// network BaseFee below which to stop doing commit aggregation, instead
 	// submitting proofs to the chain individually
 	AggregateAboveBaseFee types.FIL
+	// When set, a commit aggregate is held back while the BaseFee is falling, in the hope that it falls below
+	// AggregateAboveBaseFee: if the BaseFee dropped below this fraction of its value CommitBatchWait / 2 ago,
+	// the batch is re-evaluated after another epoch instead of being sent. Batches are still sent before
+	// CommitBatchSlack is reached. Must be in the range [0.0, 1.0]; 0 disables the check
+	AggregateMinBaseFeeDecayRatio float64
 
 	// When submitting several sector prove commit messages simultaneously, this option allows you to
 	// stagger the number of prove commits submitted per epoch
@@ -418,6 +691,11 @@ type SealingConfig struct {
 	// Submitting a smaller number of prove commits per epoch would reduce the possibility of failed msgs
 	MaxSectorProveCommitsSubmittedPerEpoch uint64
 
+	// Maximum number of ProveCommitSector messages which may be waiting to land on chain at the same time.
+	// Sectors which would exceed the limit wait in SubmitCommit until earlier messages land.
+	// Only applies when AggregateCommits is disabled; 0 = unlimited
+	MaxConcurrentProveCommits int
+
 	TerminateBatchMax  uint64
 	TerminateBatchMin  uint64
 	TerminateBatchWait Duration
@@ -429,10 +707,81 @@ type SealingConfig struct {
 
 	// UseSyntheticPoRep, when set to true, will reduce the amount of cache data held on disk after the completion of PreCommit 2 to 11GiB.
 	UseSyntheticPoRep bool
+
+	// TicketLookaheadEpochs lets sectors reuse the sealing ticket most recently fetched for another sector, if it's
+	// at most this many epochs older than the ticket they would get otherwise, instead of querying the chain for
+	// each sector. Sectors sealed within the window then share a ticket epoch. The ticket of a sector is older by
+	// up to this many epochs, leaving less time to seal and precommit it before the ticket expires. Can be at
+	// most 900 (one finality). 0 fetches a ticket for every sector.
+	TicketLookaheadEpochs int
+
+	// FinalizeSectorTimeout is how long finalizing a sector, which moves its files to long-term storage, may take,
+	// including the time spent waiting for a worker. Finalization taking longer is cancelled, and the sector moves
+	// to the FinalizeFailed state, from which it's retried. 0 means no timeout.
+	FinalizeSectorTimeout Duration
+
+	// When enabled, every sector state transition is published as a JSON message to the
+	// SectorWatcherTopicName pubsub topic, so that external monitoring tools can react to
+	// sector failures without polling. Requires the markets subsystem, which runs the libp2p node.
+	SectorBuildWatcher bool
+	// Pubsub topic that sector state change events are published to when SectorBuildWatcher is enabled.
+	SectorWatcherTopicName string
+}
+
+// ResourceFilteringWindow applies a resource filtering strategy during a daily
+// time window.
+type ResourceFilteringWindow struct {
+	// StartHour is the hour of the day (0-23) the window starts at, inclusive.
+	StartHour int
+	// EndHour is the hour of the day (0-24) the window ends at, exclusive. Windows with
+	// EndHour lower than StartHour span midnight; windows with EndHour equal to StartHour
+	// span the whole day.
+	EndHour int
+	// Timezone is the IANA name of the time zone the hours are expressed in, e.g.
+	// "Europe/Berlin". An empty value means the local time zone of the miner.
+	Timezone string
+	// Strategy is the resource filtering strategy applied during the window, either
+	// "hardware" or "disabled".
+	Strategy ResourceFilteringStrategy
 }
 
 type SealerConfig struct {
 	ParallelFetchLimit int
+	// NetworkBandwidthLimitMBps limits the combined throughput, in MiB/s, of the sector data fetched
+	// from and read on other storage paths by the miner, so that transfers don't saturate a shared
+	// network. The limit applies to the sum of all concurrent transfers. 0 means unlimited
+	NetworkBandwidthLimitMBps float64
+	// ParallelSectorMoveLimit is the maximum number of sectors the miner moves from sealing paths to long-term
+	// storage at the same time when finalizing them. Further moves are queued until a running move completes,
+	// which keeps many sectors finishing at once from saturating disk I/O. 0 means unlimited
+	ParallelSectorMoveLimit int
+	// MaxPendingStorageRequests is the maximum number of sealing and storage tasks, e.g. fetches and
+	// finalizations, which can wait to start on a worker at the same time. Further tasks are handled
+	// according to TaskSheddingPolicy. 0 means unlimited
+	MaxPendingStorageRequests int
+	// TaskSheddingPolicy sets what happens to a task scheduled while MaxPendingStorageRequests tasks are
+	// already waiting to start:
+	// "block" (default) makes the new task wait until a pending task starts on a worker;
+	// "reject" fails the new task right away, letting the sealing pipeline retry it later;
+	// "oldest-first" fails the task which has been waiting in the scheduler queue the longest to make room
+	// for the new one, and waits like "block" if no queued task can be removed.
+	// Shed tasks are counted in the sched/tasks_shed metric
+	TaskSheddingPolicy string
+	// StorageWriteVerify makes the builtin worker read back and hash every chunk of sector data it writes when
+	// adding pieces, right after writing it. Writes whose data doesn't read back as written fail, and are
+	// counted in the sealing/write_verify_errors metric. Note that data read back may come from the OS page
+	// cache rather than the disk
+	StorageWriteVerify bool
+	// MaxOpenSectorsPerWorker is the maximum number of sectors a worker can hold in its sealing paths at the same
+	// time. Sectors count towards the limit from the time their files are declared in a sealing path until they
+	// are moved to long-term storage or removed; paths which can also store sectors aren't counted. AddPiece tasks for new sectors are only assigned to workers
+	// below the limit, and stay queued while all workers are at it. 0 means unlimited
+	MaxOpenSectorsPerWorker int
+	// StorageSealerStaging is the absolute path of an attached storage path with CanSeal set, which the builtin
+	// worker uses for the files of sectors being sealed regardless of storage path weights, e.g. to keep PC1/PC2
+	// files on fast NVMe while sealed sectors are stored on HDD. If empty (default), or if the path can't hold a
+	// file, the attached sealing paths are used in weight order as before
+	StorageSealerStaging string
 
 	AllowSectorDownload      bool
 	AllowAddPiece            bool
@@ -444,8 +793,10 @@ type SealerConfig struct {
 	AllowProveReplicaUpdate2 bool
 	AllowRegenSectorKey      bool
 
-	// LocalWorkerName specifies a custom name for the builtin worker.
-	// If set to an empty string (default) os hostname will be used
+	// LocalWorkerName specifies a custom name for the builtin worker, shown by the scheduler
+	// and in 'lotus-miner sealing workers'.
+	// If set to an empty string (default) the name is generated from the os hostname and the
+	// miner process ID, which keeps builtin workers of multiple miners on the same host apart.
 	LocalWorkerName string
 
 	// Assigner specifies the worker assigner to use when scheduling tasks.
@@ -453,6 +804,14 @@ type SealerConfig struct {
 	// "spread" - assign tasks to as many distinct workers as possible.
 	Assigner string
 
+	// WorkerSelectionHeuristic tunes which worker the "utilization" assigner picks for a task.
+	// "utilization" (default) - the worker with the lowest utilization.
+	// "locality" - a worker which already has files of the task sector in its local storage paths, so
+	// they don't have to be fetched, falling back to the lowest utilization worker when none can take the task.
+	// "capacity" - the worker with the most physical memory not reserved by its tasks.
+	// Other assigners don't support heuristics, so this must be "utilization" when Assigner is set to one.
+	WorkerSelectionHeuristic string
+
 	// DisallowRemoteFinalize when set to true will force all Finalize tasks to
 	// run on workers with local access to both long-term storage and the sealing
 	// path containing the sector.
@@ -468,13 +827,29 @@ type SealerConfig struct {
 
 	// ResourceFiltering instructs the system which resource filtering strategy
 	// to use when evaluating tasks against this worker. An empty value defaults
-	// to "hardware".
+	// to "hardware". When set to "schedule", the strategy is taken from the
+	// ResourceFilteringSchedule window matching the current time.
 	ResourceFiltering ResourceFilteringStrategy
+
+	// ResourceFilteringSchedule lists the time-of-day windows used when ResourceFiltering
+	// is set to "schedule". The first window matching the current time applies; outside
+	// of all windows the "hardware" strategy is used.
+	ResourceFilteringSchedule []ResourceFilteringWindow
+
+	// GPUTemperatureLimit is the temperature, in degrees Celsius, above which new tasks needing a GPU are paused on
+	// the local worker of lotus-miner, e.g. 90. Running tasks aren't interrupted. An alert is raised while tasks are
+	// paused, and they're resumed once all GPUs cool down below GPUTemperatureLimit - 5. Temperatures are read
+	// through NVML, so only NVIDIA GPUs are monitored. 0 disables the monitoring
+	GPUTemperatureLimit int
+	// GPUTemperaturePollInterval is how often GPU temperatures are read when GPUTemperatureLimit is set
+	GPUTemperaturePollInterval Duration
 }
 
 type BatchFeeConfig struct {
 	Base      types.FIL
 	PerSector types.FIL
+	// Upper bound on the total fee of a batch, regardless of its size. 0 means no cap
+	MaxTotalFee types.FIL
 }
 
 type MinerFeeConfig struct {
@@ -501,6 +876,10 @@ type MinerAddressConfig struct {
 	CommitControl      []string
 	TerminateControl   []string
 	DealPublishControl []string
+	// DealPublishWorker pins deal publish messages to one of DealPublishControl. When set, PublishStorageDeals
+	// messages are only sent from this address, without falling back to other control addresses, or to the worker
+	// and owner addresses. Empty selects among DealPublishControl as usual.
+	DealPublishWorker string
 
 	// DisableOwnerFallback disables usage of the owner address for messages
 	// sent automatically
@@ -518,6 +897,72 @@ type API struct {
 	ListenAddress       string
 	RemoteListenAddress string
 	Timeout             Duration
+
+	// WebSocketHeartbeat is the interval at which the API server pings WebSocket
+	// clients; connections which stop responding are closed. 0 uses the RPC
+	// library default of 5s
+	WebSocketHeartbeat Duration
+	// WebSocketMaxMessageSize is the maximum size, in bytes, of a single request
+	// accepted by the API server. The limit applies to WebSocket messages as well
+	// as plain HTTP requests. 0 uses the RPC library default of 100 MiB
+	WebSocketMaxMessageSize int64
+	// MaxResponseBodySize is the maximum size, in bytes, of a response to a JSON-RPC request sent over plain
+	// HTTP, e.g. to keep a ChainGetPath call over a long fork from returning megabytes of data. Larger responses
+	// are replaced with a JSON-RPC error carrying the request ID and HTTP 413 (Request Entity Too Large). Responses
+	// are buffered in memory up to this size before they are sent. WebSocket connections aren't limited. 0 means unlimited; 104857600
+	// (100 MiB) is a reasonable limit for public endpoints
+	MaxResponseBodySize int64
+
+	// RequestIDHeader is the HTTP header from which the API server reads a request ID supplied by the client.
+	// The ID is attached to the request context, logged with failed API calls and echoed back in the response.
+	// Empty disables request IDs
+	RequestIDHeader string
+
+	// HTTPProxyHeaders lists HTTP headers, e.g. X-Forwarded-For or X-Real-IP, from which the real client IP is
+	// read when the API is behind a proxy or load balancer. The headers are only trusted on requests coming
+	// from TrustedSubnets; the first listed header holding a valid address is used in place of the proxy
+	// address, e.g. in logs. Empty uses the address of the direct peer
+	HTTPProxyHeaders []string
+	// TrustedSubnets lists the subnets, in CIDR notation, of the proxies whose HTTPProxyHeaders are trusted
+	TrustedSubnets []string
+
+	// SseEnabled serves chain head changes as Server-Sent Events on the /sse endpoint of the full node API, as
+	// an alternative to ChainNotify over WebSocket for clients behind proxies which terminate WebSocket
+	// connections. Each event is named after the head change type (current, apply or revert), with the tipset
+	// as JSON data. The API token may be passed in the token query parameter. Ignored by the miner
+	SseEnabled bool
+
+	// UnixSocketPath is the path of a Unix domain socket the API is served on in addition to ListenAddress,
+	// e.g. for local tooling. The socket file is created with 0600 permissions, so only the user running the
+	// node can connect; API tokens are still required. Empty disables the socket
+	UnixSocketPath string
+
+	// AllowedMethods restricts the JSON-RPC methods which can be called, over HTTP or WebSocket, to this list,
+	// e.g. to keep expensive methods off a public endpoint. Methods are given by name, with or without the
+	// Filecoin. prefix; Ethereum methods may also be given by their eth_ alias. Calls to other methods fail with
+	// an error. Empty allows all methods
+	AllowedMethods []string
+	// DeniedMethods lists JSON-RPC methods which can't be called, in the same format as AllowedMethods. It
+	// applies on top of AllowedMethods
+	DeniedMethods []string
+
+	// OpenTelemetryEndpoint is the host:port of an OTLP gRPC collector to export traces to. Each API call,
+	// over HTTP or WebSocket, is traced, with its method, duration and error; spans recorded while serving it
+	// are exported as well. The connection uses TLS unless the endpoint is prefixed with
+	// http://. Empty disables the export
+	OpenTelemetryEndpoint string
+	// OpenTelemetryServiceName is the service name the exported traces are reported under
+	OpenTelemetryServiceName string
+
+	// TraceAllMethods logs every API call, over HTTP or WebSocket, at debug level on the api logger, with the
+	// method, a SHA256 hash of the JSON encoded params, the caller IP, the duration in milliseconds and the error
+	// returned, if any. The params themselves aren't logged, as they may hold sensitive data
+	TraceAllMethods bool
+
+	// PrometheusBasicAuthUser and PrometheusBasicAuthPass, when both set, protect the /debug/metrics endpoint
+	// with HTTP Basic Authentication using these credentials. The endpoint is open when they aren't set
+	PrometheusBasicAuthUser string
+	PrometheusBasicAuthPass string
 }
 
 // Libp2p contains configs for libp2p
@@ -525,6 +970,10 @@ type Libp2p struct {
 	// Binding address for the libp2p host - 0 means random port.
 	// Format: multiaddress; see https://multiformats.io/multiaddr/
 	ListenAddresses []string
+	// When disabled, QUIC WebTransport addresses are left out of ListenAddresses, e.g. on networks whose
+	// firewalls block QUIC, so that the default addresses don't need to be edited. Plain QUIC addresses
+	// are still listened on
+	WebTransportEnabled bool
 	// Addresses to explicitally announce to other peers. If not specified,
 	// all interface addresses are announced
 	// Format: multiaddress
@@ -551,6 +1000,46 @@ type Libp2p struct {
 	// ConnMgrGrace is a time duration that new connections are immune from being
 	// closed by the connection manager.
 	ConnMgrGrace Duration
+	// ConnMgrEmergencyGracePeriod, when non-zero, enables emergency trimming, where
+	// the connection manager closes connections when the node runs low on memory.
+	// Emergency trims ignore ConnMgrGrace, so connections newer than this period are
+	// protected from them instead, unless there aren't enough older connections to close.
+	// The protection also applies to regular trims, so values larger than ConnMgrGrace
+	// extend the grace period of new connections.
+	// The default of 0 leaves emergency trimming disabled.
+	ConnMgrEmergencyGracePeriod Duration
+	// ConnMgrSilencePeriod is the minimum time between two connection GC operations.
+	// Raising it makes the connection manager trim less often during connection spikes.
+	// The default of 0 uses the libp2p default of 10s.
+	ConnMgrSilencePeriod Duration
+	// ProtocolPeerLimits caps the number of concurrent streams a single peer can
+	// have open for a protocol, keyed by protocol ID, e.g. "/fil/hello/1.0.0" = 4.
+	// libp2p connections aren't bound to a protocol, so the limits are enforced by the
+	// libp2p resource manager on streams, and only apply when it is enabled (the
+	// default on full nodes, see LOTUS_RCMGR). Protocols which aren't listed use the
+	// resource manager defaults.
+	ProtocolPeerLimits map[string]int
+
+	// BootstrapRetryMax is the number of times connecting to the bootstrap peers
+	// is retried when no peers are connected before an alert is raised and the
+	// bootstrap_failed metric is set. Retries continue after that, at the delay
+	// of the last retry.
+	BootstrapRetryMax int
+	// BootstrapRetryDelay enables backoff between bootstrap retries: it is the delay
+	// before the first retry, doubling with each subsequent retry. 0 retries every
+	// 5 seconds, when the connected peers are checked.
+	BootstrapRetryDelay Duration
+
+	// ObservedAddressActivationThreshold is the number of distinct peers which must report the same observed
+	// address of the node before it is considered an external address of the node and announced. Raising it
+	// reduces the chance of announcing a wrong external address behind NATs, at the cost of taking longer to
+	// discover the right one. The threshold is shared by all libp2p hosts in the process.
+	ObservedAddressActivationThreshold int
+
+	// MetricsReporter exports the bandwidth used by each libp2p protocol, and the number of open libp2p
+	// connections and streams, on the Prometheus metrics endpoint. The metrics of the libp2p subsystems
+	// themselves are exported regardless
+	MetricsReporter bool
 }
 
 type Pubsub struct {
@@ -583,6 +1072,108 @@ type Pubsub struct {
 type Chainstore struct {
 	EnableSplitstore bool
 	Splitstore       Splitstore
+	// GCLockTimeout limits how long splitstore compaction may hold the transaction lock, which blocks blockstore
+	// access, while protecting the references created during the compaction before it starts purging. If it takes
+	// longer, the compaction is aborted before anything is purged and retried on the next head change. Purging
+	// itself takes the lock in small batches. 0 disables the timeout.
+	GCLockTimeout Duration
+
+	// MsgPoolRepublishInterval specifies how often the message pool republishes pending local messages.
+	// Values shorter than the pubsub timecache duration plus the propagation delay are raised to that minimum.
+	// 0 keeps the default of 10 epochs plus the propagation delay.
+	MsgPoolRepublishInterval Duration
+	// MsgSelectPolicy is how the message pool picks the messages for the blocks mined by this node:
+	// "fee" picks the messages paying the most per unit of gas, "time" picks the messages which entered the
+	// pool first, and "fair" picks one message from each sender in turn, so that senders with many pending
+	// messages can't fill the block by themselves. Priority messages always go first.
+	MsgSelectPolicy string
+	// MsgMaxQueueSizePerSender is the maximum number of pending messages a sender can have in the message pool
+	// for new messages pushed through the MpoolPush API to be accepted; messages replacing a pending message by
+	// fee are still accepted. Messages received from the network are subject to the message pool's own limits.
+	// 0 means no limit.
+	MsgMaxQueueSizePerSender int
+	// MsgRepublishMaxAttempts is the number of times a pending local message is republished before it is dropped
+	// from the message pool, if it still hasn't been included in a block. Dropped messages are logged with their
+	// CID and sender. 0 means messages are republished until they are included.
+	MsgRepublishMaxAttempts int
+
+	// TipsetWeightAlgorithm is the function computing the weight of tipsets, which decides the heaviest chain and
+	// is checked when validating blocks. "filecoin-ec" (default) is the Expected Consensus weight used by Filecoin
+	// networks. "flat" gives every block a weight of 1, for experiments on test networks; all nodes of the network
+	// must use it, and it can't be used with mainnet builds.
+	TipsetWeightAlgorithm string
+
+	// GossipBlockValidationTimeout limits how long validation of a block received over gossip may take.
+	// Blocks whose validation doesn't complete in time are dropped without penalizing the sending peer.
+	// 0 disables the timeout.
+	GossipBlockValidationTimeout Duration
+
+	// ValidatorCacheEnabled enables caching the results of validating blocks received over gossip, so that
+	// a block seen multiple times, e.g. during fork resolution, is only fully validated once. Only acceptances
+	// and rejections which depend on the block header alone are cached; the epoch of cached blocks is still
+	// checked.
+	ValidatorCacheEnabled bool
+	// ValidatorCacheSize is the maximum number of block validation results to cache.
+	ValidatorCacheSize int
+
+	// BeaconEndpoints overrides the builtin list of Drand HTTP endpoints, e.g. for networks using a custom Drand
+	// chain. Endpoints must serve all Drand chains used by the network. When more than one endpoint is given,
+	// requests fail over between them, preferring the fastest. Empty uses the builtin list.
+	BeaconEndpoints []string
+
+	// SimultaneousBlockFetchLimit is the maximum number of blocks received over gossip whose messages are
+	// fetched from the network at the same time. 0 means no limit.
+	SimultaneousBlockFetchLimit int
+
+	// MinSyncWorkers is the minimum number of chain sync workers kept busy once the initial sync is done, as long
+	// as there are sync targets waiting. Raising it prevents syncing falling back to a single worker when many
+	// peers report unrelated heads, e.g. during sustained peer disconnects.
+	MinSyncWorkers int
+	// MaxSyncWorkers is the maximum number of chain sync workers running at the same time.
+	MaxSyncWorkers int
+	// BootstrapSyncMinPeers is the number of peers whose chain heads must be known before the initial chain sync
+	// starts, so that the sync target isn't picked from a single, possibly forked, peer. A warning is logged every
+	// 30 seconds while waiting. 0 uses the network default, e.g. 4 on mainnet, which can also be set with the
+	// LOTUS_SYNC_BOOTSTRAP_PEERS environment variable.
+	BootstrapSyncMinPeers int
+
+	// BlockCompressionEnabled enables zstd compression of all DAG-CBOR blocks (actor state, but also block
+	// headers and messages) as they are written to the chain and splitstore hot blockstores. Blocks are
+	// compressed individually, so every read of a compressed block pays for decompressing it, and getting the
+	// size of a compressed block reads its stored value.
+	//
+	// There is no downgrade path: blocks which were already compressed remain readable by this release after
+	// disabling this option, but they are not rewritten, so once enabled the blockstore can't be read by older
+	// releases or by external tools opening the badger store directly.
+	BlockCompressionEnabled bool
+
+	// SyncPeerScoreMinimum is the minimum gossipsub peer score a peer must have for chain sync to fetch blocks
+	// from it. Requests are sent to other peers instead. Peers without a score are not filtered.
+	SyncPeerScoreMinimum float64
+
+	// BlockCacheSizeBytes is the maximum size, in bytes, of the in-memory LRU cache of raw blocks read from or
+	// written to the chain blockstore. The cache reports its hit and miss counts under the "chain_block" cache
+	// name. It can't be used with the splitstore, which moves and deletes blocks on its own; enable it only
+	// with EnableSplitstore disabled. 0 disables the cache.
+	BlockCacheSizeBytes uint64
+
+	// StateManagerCacheEnabled enables the in-memory caches of the state manager: the last state tree loaded to
+	// resolve addresses, and the execution traces of recent tipsets. Disabling it lowers memory usage, at the cost
+	// of reading the state tree from the blockstore, and executing tipsets again for traces, on every call.
+	StateManagerCacheEnabled bool
+
+	// NetworkVersionOverride makes the StateNetworkVersion API report this network version for every tipset
+	// instead of the one from the upgrade schedule, so that clients can be tested against features of a network
+	// version before its upgrade. Chain validation, message execution and the node's own API methods still follow
+	// the upgrade schedule, but API clients act on the reported version: miners connected to this node pick seal
+	// proofs, batching and message formats for it, and may send messages the chain rejects. It can't be set on
+	// mainnet. 0 reports the network version of the upgrade schedule
+	NetworkVersionOverride network.Version
+
+	// ActorMigrationParallelism is the maximum number of workers migrating actor states concurrently during network
+	// upgrades and their pre-migrations. Some migrations use fewer workers than this, to leave CPUs for syncing.
+	// 0 uses the LOTUS_MIGRATION_MAX_WORKER_COUNT environment variable, or the number of CPUs if it isn't set
+	ActorMigrationParallelism int
 }
 
 type Splitstore struct {
@@ -622,6 +1213,11 @@ type Splitstore struct {
 	// is set.  Moving GC will not occur when total moving size exceeds
 	// HotstoreMaxSpaceTarget - HotstoreMaxSpaceSafetyBuffer
 	HotstoreMaxSpaceSafetyBuffer uint64
+
+	// When HotStoreMaxSpaceTarget is set Moving GC will also be triggered when total moving size
+	// exceeds HotStoreMaxSpaceTarget * MovingGCThresholdRatio, if that comes before the
+	// HotStoreMaxSpaceThreshold trigger. Must be in the range [0.5, 1.0]
+	MovingGCThresholdRatio float64
 }
 
 // // Full Node
@@ -663,7 +1259,8 @@ type UserRaftConfig struct {
 	// initialized or when starting in staging mode.
 	InitPeersetMultiAddr []string
 	// LeaderTimeout specifies how long to wait for a leader before
-	// failing an operation.
+	// failing an operation. On bootstrap, it also bounds each attempt
+	// at reaching a quorum of the initial peerset.
 	WaitForLeaderTimeout Duration
 	// NetworkTimeout specifies how long before a Raft network
 	// operation is timed out
@@ -676,6 +1273,21 @@ type UserRaftConfig struct {
 	// BackupsRotate specifies the maximum number of Raft's DataFolder
 	// copies that we keep as backups (renaming) after cleanup.
 	BackupsRotate int
+	// SnapshotCompression enables zstd compression of Raft snapshots. Compressed
+	// snapshots can't be read by older Lotus versions, so it should only be enabled
+	// once all cluster members are upgraded. Uncompressed snapshots remain readable
+	// either way.
+	SnapshotCompression bool
+	// PeerDiscoveryRetries specifies how many times to retry reaching a
+	// quorum of the initial peerset on bootstrap before giving up. 0 means
+	// a single attempt.
+	PeerDiscoveryRetries int
+	// PeerDiscoveryInterval specifies how long to wait between peer
+	// discovery attempts.
+	PeerDiscoveryInterval Duration
+	// LeaderElectionTimeout specifies how long to wait for a leader to be
+	// elected once a quorum of peers has been reached.
+	LeaderElectionTimeout Duration
 	// Tracing enables propagation of contexts across binary boundaries.
 	Tracing bool
 }
@@ -689,6 +1301,107 @@ type FevmConfig struct {
 	// Set to 0 to keep all mappings
 	EthTxHashMappingLifetimeDays int
 
+	// EthGetBlockTransactionCountMax caps the transaction count returned by eth_getBlockTransactionCountByHash
+	// and eth_getBlockTransactionCountByNumber. Set to 0 to return the full count
+	EthGetBlockTransactionCountMax int
+
+	// EnableEthBatchRequests executes the requests of JSON-RPC batches sent to the API concurrently instead of
+	// one after another, at most 16 at a time across all batches. This applies to all methods served on the
+	// endpoint, not only eth_ ones.
+	EnableEthBatchRequests bool
+
+	// EthBatchRequestMaxSize is the maximum number of requests in a batch when EnableEthBatchRequests is set.
+	// Larger batches are rejected. Set to 0 for no limit
+	EthBatchRequestMaxSize int
+
+	// EthGetLogsMaxResults is the maximum number of logs eth_getLogs returns. Queries matching more logs fail with
+	// error code -32005, asking to narrow the block range. It applies instead of Events.MaxFilterResults, which
+	// still bounds the logs collected by filters installed with eth_newFilter. Set to 0 for no limit
+	EthGetLogsMaxResults int
+
+	// EthPendingTransactionTimeout is how long a message sent from an Ethereum (f4) address may stay in the
+	// message pool without being mined before it's evicted. Set to 0 to keep pending messages indefinitely
+	EthPendingTransactionTimeout Duration
+
+	// EthEventBatch buffers the log events delivered to eth_subscribe("logs") subscriptions and sends them as a
+	// single JSON array every EthEventBatchInterval instead of one WebSocket message per event. Subscribers must
+	// accept an array of logs as the subscription result
+	EthEventBatch bool
+
+	// EthEventBatchInterval is how long log events are buffered before being sent when EthEventBatch is set
+	EthEventBatchInterval Duration
+
+	// EthSyncStatusMode selects how eth_syncing determines whether the node is syncing:
+	// "filecoin" - report the progress of the Filecoin sync workers, which go idle between sync targets
+	//   and can report the node as synced while it is still catching up with the chain.
+	// "ethereum" - report the node as syncing until its head is less than an epoch behind the current
+	//   time, with the {startingBlock, currentBlock, highestBlock} progress object expected by ETH tooling.
+	// "auto" (default) - currently the same as "ethereum".
+	EthSyncStatusMode string
+
+	// EthAddressMapping selects how Filecoin actors are given Ethereum addresses by the Eth API:
+	// "delegated-first" (default) - actors with a delegated (f410) address use it, other actors use the masked
+	//   ID address (0xff0000000000000000000000 followed by the actor ID).
+	// "id-based" - all actors with an ID use the masked ID address, including Ethereum accounts and contracts.
+	// "strict" - addresses are presented as with "delegated-first", but only Ethereum addresses are accepted in
+	//   requests: masked ID addresses, and eth_call/eth_estimateGas calls without a sender, are rejected. Useful
+	//   for EVM-only private networks.
+	EthAddressMapping string
+
+	// EthMaxCodeSize is the maximum contract bytecode size, in bytes. The node rejects contract creation
+	// transactions whose init code exceeds twice this size (EIP-3860) before they enter the message pool.
+	// --
+	// NOTE: the size of deployed contracts is enforced by the EVM actor as part of consensus, and raising
+	// this value won't allow larger contracts on a network unless the network upgrades the actor limit too.
+	EthMaxCodeSize int
+
+	// EnableEIP2718Transactions enables EIP-2718 typed transaction envelopes (type 1 and type 2) at
+	// eth_sendRawTransaction. When disabled, typed transactions are rejected with an invalid params error.
+	// --
+	// NOTE: legacy (untyped) transactions aren't supported, so disabling this effectively rejects all raw
+	// transactions submitted through this node.
+	EnableEIP2718Transactions bool
+
+	// EthGetTransactionByHashCacheSize is the number of transaction locations (including tipset and index
+	// in it) kept in memory to answer eth_getTransactionByHash without searching the chain. Locations are
+	// cached when tipsets are applied and after successful lookups. 0 disables the cache
+	EthGetTransactionByHashCacheSize int
+
+	// EthBlockPropagationDelay lags the block number reported by eth_blockNumber behind the latest executed
+	// tipset by this long, rounded up to whole epochs, so that clients polling it don't race the propagation
+	// of the newest blocks. Other methods resolve "latest" as before. 0 reports the latest executed tipset
+	EthBlockPropagationDelay Duration
+
+	// EthCallCacheEnabled caches the results of eth_call, keyed by the call and the tipset it's executed on, so
+	// that repeated calls, e.g. to read-only contract methods, don't execute again. Calls against "latest"
+	// resolve to a new tipset every epoch, so they're only served from the cache within an epoch
+	EthCallCacheEnabled bool
+	// EthCallCacheTTL is how long a cached eth_call result is kept. 0 keeps results until they're evicted
+	EthCallCacheTTL Duration
+	// EthCallCacheSize is the number of eth_call results kept in the cache, evicting the least recently used
+	EthCallCacheSize int
+	// EthCallAddressWhitelist restricts eth_call to calls to these contract addresses, given as 0x prefixed
+	// Ethereum addresses, e.g. on nodes serving specific dApps. Other calls, including contract deployments, are
+	// rejected with a method not found (-32601) error. Addresses are matched as given, so contracts called by
+	// their masked ID address must be listed in that form too. Empty allows calls to any contract
+	EthCallAddressWhitelist []string
+
+	// EthGetBlockByHashFullTx accepts eth_getBlockByHash calls which only pass the block hash, as some clients do,
+	// returning full transaction objects for them. When disabled, such calls are rejected for missing the full
+	// transactions flag. Only calls made over HTTP are affected
+	EthGetBlockByHashFullTx bool
+
+	// EnableEthWalletMethods makes eth_accounts return the Ethereum (f4) addresses in the node wallet, and enables
+	// eth_sign and eth_signTypedData_v4 to sign with them. The signing methods require an admin token, and
+	// eth_accounts keeps returning an empty list to callers without one
+	EnableEthWalletMethods bool
+
+	// ChainNotificationBufferSize is the number of head changes buffered for the chain notification
+	// subscription which feeds the Ethereum event and block filters. If the feed falls further behind than this,
+	// e.g. while processing the events of a large tipset, the subscription is closed and reopened, so a larger
+	// buffer helps with bursts of chain activity. Other chain notification subscribers are not affected
+	ChainNotificationBufferSize int
+
 	Events Events
 }
 
@@ -723,6 +1436,21 @@ type Events struct {
 	// relative to the CWD (current working directory).
 	DatabasePath string
 
+	// MaxSubscriptionBufferSize is the number of messages queued for each websocket subscriber before
+	// SubscriptionBackpressureMode applies.
+	MaxSubscriptionBufferSize int
+
+	// SubscriptionBackpressureMode selects what happens when a subscriber's buffer is full: "drop-oldest"
+	// discards the oldest queued message, "drop-newest" discards the new message, and "block" makes the
+	// subscription wait for room, up to SubscriptionSendTimeout, before closing it. Dropped messages are
+	// counted by the eth/subscription_dropped metric. In "block" mode a slow subscriber stalls event delivery
+	// to all subscribers of the node while it waits, so use it only when every subscriber is trusted to keep up.
+	SubscriptionBackpressureMode string
+
+	// SubscriptionSendTimeout is how long sending a message to a subscriber may take before the subscriber
+	// is considered dead and its subscription is closed.
+	SubscriptionSendTimeout Duration
+
 	// Others, not implemented yet:
 	// Set a limit on the number of active websocket subscriptions (may be zero)
 	// Set a timeout for subscription clients
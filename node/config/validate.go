@@ -0,0 +1,488 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/network"
+	miner5 "github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+// FieldError describes a single config invariant violation.
+type FieldError struct {
+	// Field is the dot-separated path of the offending field, e.g. "Sealing.CommitBatchSlack".
+	Field string
+	Msg   string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Msg
+}
+
+// ValidationError collects every violation found while validating a config.
+// Callers can inspect the individual *FieldError values through Unwrap.
+type ValidationError struct {
+	Errs []error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid config (%d errors): %s", len(e.Errs), strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errs
+}
+
+// Validate checks the invariants of the full node config that can be verified
+// statically. It returns a *ValidationError listing all violations, or nil.
+func (c *FullNode) Validate() error {
+	var v validator
+	v.version(c.Version)
+	v.common(&c.Common)
+
+	v.nonNegativeFIL("Fees.DefaultMaxFee", c.Fees.DefaultMaxFee)
+
+	cs := &c.Chainstore
+	if cs.EnableSplitstore {
+		ss := &cs.Splitstore
+		v.oneOf("Chainstore.Splitstore.ColdStoreType", ss.ColdStoreType, "discard", "messages", "universal")
+		v.oneOf("Chainstore.Splitstore.HotStoreType", ss.HotStoreType, "badger")
+		v.oneOf("Chainstore.Splitstore.MarkSetType", ss.MarkSetType, "map", "badger")
+		if r := ss.MovingGCThresholdRatio; !(r >= 0.5 && r <= 1) {
+			v.errorf("Chainstore.Splitstore.MovingGCThresholdRatio", "must be in the range [0.5, 1.0], got %f", r)
+		}
+		if ss.HotStoreMaxSpaceTarget != 0 {
+			if ss.HotStoreMaxSpaceThreshold > ss.HotStoreMaxSpaceTarget {
+				v.errorf("Chainstore.Splitstore.HotStoreMaxSpaceThreshold", "must not exceed HotStoreMaxSpaceTarget (%d > %d)", ss.HotStoreMaxSpaceThreshold, ss.HotStoreMaxSpaceTarget)
+			}
+			if ss.HotstoreMaxSpaceSafetyBuffer > ss.HotStoreMaxSpaceTarget {
+				v.errorf("Chainstore.Splitstore.HotstoreMaxSpaceSafetyBuffer", "must not exceed HotStoreMaxSpaceTarget (%d > %d)", ss.HotstoreMaxSpaceSafetyBuffer, ss.HotStoreMaxSpaceTarget)
+			}
+		}
+	}
+	if cs.EnableSplitstore && cs.BlockCacheSizeBytes > 0 {
+		v.errorf("Chainstore.BlockCacheSizeBytes", "can't be used with the splitstore enabled")
+	}
+	v.nonNegativeDuration("Chainstore.GCLockTimeout", cs.GCLockTimeout)
+	v.nonNegativeDuration("Chainstore.MsgPoolRepublishInterval", cs.MsgPoolRepublishInterval)
+	v.oneOf("Chainstore.MsgSelectPolicy", cs.MsgSelectPolicy, "fee", "time", "fair")
+	v.nonNegative("Chainstore.MsgMaxQueueSizePerSender", int64(cs.MsgMaxQueueSizePerSender))
+	v.nonNegative("Chainstore.MsgRepublishMaxAttempts", int64(cs.MsgRepublishMaxAttempts))
+	v.oneOf("Chainstore.TipsetWeightAlgorithm", cs.TipsetWeightAlgorithm, "filecoin-ec", "flat")
+	if cs.NetworkVersionOverride != 0 {
+		if build.BuildType == build.BuildMainnet {
+			v.errorf("Chainstore.NetworkVersionOverride", "can't be set on mainnet")
+		} else if cs.NetworkVersionOverride > build.TestNetworkVersion {
+			v.errorf("Chainstore.NetworkVersionOverride", "must not exceed the newest supported network version %d, got %d", build.TestNetworkVersion, cs.NetworkVersionOverride)
+		}
+	}
+	if cs.TipsetWeightAlgorithm == "flat" && build.BuildType == build.BuildMainnet {
+		v.errorf("Chainstore.TipsetWeightAlgorithm", "flat weights can't be used on mainnet")
+	}
+	v.nonNegativeDuration("Chainstore.GossipBlockValidationTimeout", cs.GossipBlockValidationTimeout)
+	if cs.ValidatorCacheEnabled && cs.ValidatorCacheSize <= 0 {
+		v.errorf("Chainstore.ValidatorCacheSize", "must be positive when ValidatorCacheEnabled is set, got %d", cs.ValidatorCacheSize)
+	}
+	v.nonNegative("Chainstore.SimultaneousBlockFetchLimit", int64(cs.SimultaneousBlockFetchLimit))
+	if cs.MinSyncWorkers < 1 {
+		v.errorf("Chainstore.MinSyncWorkers", "must be at least 1, got %d", cs.MinSyncWorkers)
+	}
+	v.nonNegative("Chainstore.BootstrapSyncMinPeers", int64(cs.BootstrapSyncMinPeers))
+	v.nonNegative("Chainstore.ActorMigrationParallelism", int64(cs.ActorMigrationParallelism))
+	if cs.MaxSyncWorkers < cs.MinSyncWorkers {
+		v.errorf("Chainstore.MaxSyncWorkers", "must not be less than MinSyncWorkers (%d < %d)", cs.MaxSyncWorkers, cs.MinSyncWorkers)
+	}
+
+	fevm := &c.Fevm
+	v.nonNegative("Fevm.EthTxHashMappingLifetimeDays", int64(fevm.EthTxHashMappingLifetimeDays))
+	v.nonNegative("Fevm.EthGetBlockTransactionCountMax", int64(fevm.EthGetBlockTransactionCountMax))
+	v.nonNegative("Fevm.EthBatchRequestMaxSize", int64(fevm.EthBatchRequestMaxSize))
+	v.nonNegative("Fevm.EthGetLogsMaxResults", int64(fevm.EthGetLogsMaxResults))
+	v.nonNegativeDuration("Fevm.EthPendingTransactionTimeout", fevm.EthPendingTransactionTimeout)
+	if fevm.EthEventBatch && fevm.EthEventBatchInterval <= 0 {
+		v.errorf("Fevm.EthEventBatchInterval", "must be positive when EthEventBatch is set, got %s", time.Duration(fevm.EthEventBatchInterval))
+	}
+	v.oneOf("Fevm.EthSyncStatusMode", fevm.EthSyncStatusMode, EthSyncStatusModeAuto, EthSyncStatusModeFilecoin, EthSyncStatusModeEthereum)
+	v.oneOf("Fevm.EthAddressMapping", fevm.EthAddressMapping, EthAddressMappingDelegatedFirst, EthAddressMappingIDBased, EthAddressMappingStrict)
+	if fevm.EthMaxCodeSize < 1024 {
+		v.errorf("Fevm.EthMaxCodeSize", "must be at least 1024, got %d", fevm.EthMaxCodeSize)
+	}
+	v.nonNegative("Fevm.EthGetTransactionByHashCacheSize", int64(fevm.EthGetTransactionByHashCacheSize))
+	v.nonNegativeDuration("Fevm.EthCallCacheTTL", fevm.EthCallCacheTTL)
+	if fevm.EthCallCacheEnabled && fevm.EthCallCacheSize <= 0 {
+		v.errorf("Fevm.EthCallCacheSize", "must be positive when EthCallCacheEnabled is set, got %d", fevm.EthCallCacheSize)
+	}
+	v.nonNegativeDuration("Fevm.EthBlockPropagationDelay", fevm.EthBlockPropagationDelay)
+	for _, addr := range fevm.EthCallAddressWhitelist {
+		if _, err := ethtypes.ParseEthAddress(addr); err != nil {
+			v.errorf("Fevm.EthCallAddressWhitelist", "invalid Ethereum address %q: %s", addr, err)
+		}
+	}
+	if fevm.ChainNotificationBufferSize < 1 {
+		v.errorf("Fevm.ChainNotificationBufferSize", "must be at least 1, got %d", fevm.ChainNotificationBufferSize)
+	}
+	v.nonNegativeDuration("Fevm.Events.FilterTTL", fevm.Events.FilterTTL)
+	v.nonNegative("Fevm.Events.MaxFilters", int64(fevm.Events.MaxFilters))
+	v.nonNegative("Fevm.Events.MaxFilterResults", int64(fevm.Events.MaxFilterResults))
+	if fevm.Events.MaxSubscriptionBufferSize < 1 {
+		v.errorf("Fevm.Events.MaxSubscriptionBufferSize", "must be at least 1, got %d", fevm.Events.MaxSubscriptionBufferSize)
+	}
+	v.oneOf("Fevm.Events.SubscriptionBackpressureMode", fevm.Events.SubscriptionBackpressureMode, SubscriptionBackpressureDropOldest, SubscriptionBackpressureDropNewest, SubscriptionBackpressureBlock)
+	if fevm.Events.SubscriptionSendTimeout <= 0 {
+		v.errorf("Fevm.Events.SubscriptionSendTimeout", "must be positive, got %s", time.Duration(fevm.Events.SubscriptionSendTimeout))
+	}
+
+	return v.err()
+}
+
+// Validate checks the invariants of the storage miner config that can be
+// verified statically. It returns a *ValidationError listing all violations,
+// or nil.
+func (c *StorageMiner) Validate() error {
+	var v validator
+	v.version(c.Version)
+	v.common(&c.Common)
+
+	dm := &c.Dealmaking
+	v.nonNegativeDuration("Dealmaking.ExpectedSealDuration", dm.ExpectedSealDuration)
+	v.nonNegativeDuration("Dealmaking.MaxDealStartDelay", dm.MaxDealStartDelay)
+	v.nonNegativeDuration("Dealmaking.PublishMsgPeriod", dm.PublishMsgPeriod)
+	v.nonNegative("Dealmaking.MaxStagingDealsBytes", dm.MaxStagingDealsBytes)
+	v.nonNegativeFIL("Dealmaking.StoragePriceCap", dm.StoragePriceCap)
+	v.nonNegative("Dealmaking.MaxDealClientAddressCount", int64(dm.MaxDealClientAddressCount))
+	if dm.StoragePriceOracle != "" && dm.StoragePriceOraclePollInterval <= 0 {
+		v.errorf("Dealmaking.StoragePriceOraclePollInterval", "must be positive when StoragePriceOracle is set, got %s", time.Duration(dm.StoragePriceOraclePollInterval))
+	}
+	if rp := dm.RetrievalPricing; rp != nil {
+		v.oneOf("Dealmaking.RetrievalPricing.Strategy", rp.Strategy, RetrievalPricingDefaultMode, RetrievalPricingExternalMode)
+		if rp.Strategy == RetrievalPricingExternalMode && (rp.External == nil || rp.External.Path == "") {
+			v.errorf("Dealmaking.RetrievalPricing.External.Path", "must be set when the external pricing strategy is used")
+		}
+		if rp.Default != nil {
+			v.nonNegativeFIL("Dealmaking.RetrievalPricing.Default.RetrievalPricingDefaultBytePrice", rp.Default.RetrievalPricingDefaultBytePrice)
+		}
+	}
+
+	v.nonNegativeDuration("IndexProvider.GossipSubHeartbeatInterval", c.IndexProvider.GossipSubHeartbeatInterval)
+
+	pv := &c.Proving
+	v.nonNegative("Proving.ParallelCheckLimit", int64(pv.ParallelCheckLimit))
+	v.nonNegativeDuration("Proving.SingleCheckTimeout", pv.SingleCheckTimeout)
+	v.nonNegativeDuration("Proving.PartitionCheckTimeout", pv.PartitionCheckTimeout)
+	v.nonNegative("Proving.MaxPartitionsPerPoStMessage", int64(pv.MaxPartitionsPerPoStMessage))
+	v.nonNegative("Proving.MaxPartitionsPerRecoveryMessage", int64(pv.MaxPartitionsPerRecoveryMessage))
+	v.nonNegative("Proving.MaxFaultRecoveryMessages", int64(pv.MaxFaultRecoveryMessages))
+	v.nonNegative("Proving.PoStMessageConfirmDepth", int64(pv.PoStMessageConfirmDepth))
+	v.nonNegative("Proving.WindowPoStDispatchDeadlineBuffer", int64(pv.WindowPoStDispatchDeadlineBuffer))
+	if pv.WindowPoStNonceStrategy != "" {
+		v.oneOf("Proving.WindowPoStNonceStrategy", pv.WindowPoStNonceStrategy, WindowPoStNonceSequential, WindowPoStNonceParallelGap, WindowPoStNonceRoundRobin)
+	}
+	if pv.WindowPoStWorkerType != "" {
+		v.oneOf("Proving.WindowPoStWorkerType", pv.WindowPoStWorkerType, WindowPoStWorkerLocal, WindowPoStWorkerRemote)
+	}
+	if pv.WindowPoStWorkerType == WindowPoStWorkerRemote {
+		if u, err := url.Parse(pv.RemotePoStEndpoint); err != nil || u.Scheme != "https" || u.Host == "" {
+			v.errorf("Proving.RemotePoStEndpoint", "must be an https URL when WindowPoStWorkerType is remote, got %q", pv.RemotePoStEndpoint)
+		}
+	}
+	if pv.UseFallbackProver {
+		if strings.TrimSpace(pv.FallbackProverCommand) == "" {
+			v.errorf("Proving.FallbackProverCommand", "must be set when UseFallbackProver is enabled")
+		}
+		if pv.WindowPoStWorkerType == WindowPoStWorkerRemote {
+			v.errorf("Proving.UseFallbackProver", "can't be enabled when WindowPoStWorkerType is remote")
+		}
+	}
+	v.nonNegativeDuration("Proving.FallbackProverTimeout", pv.FallbackProverTimeout)
+	if m := pv.FaultDeclarationGasMultiplier; m != 0 && (m < 1.0 || m > 3.0) {
+		v.errorf("Proving.FaultDeclarationGasMultiplier", "must be 0 or in the range [1.0, 3.0], got %f", m)
+	}
+
+	sc := &c.Sealing
+	v.nonNegativeDuration("Sealing.CommittedCapacitySectorLifetime", sc.CommittedCapacitySectorLifetime)
+	v.nonNegativeDuration("Sealing.WaitDealsDelay", sc.WaitDealsDelay)
+	v.nonNegativeFIL("Sealing.AvailableBalanceBuffer", sc.AvailableBalanceBuffer)
+	v.nonNegativeFIL("Sealing.PledgeCollateralBuffer", sc.PledgeCollateralBuffer)
+	if sc.MaxPreCommitBatch < 1 || sc.MaxPreCommitBatch > miner5.PreCommitSectorBatchMaxSize {
+		v.errorf("Sealing.MaxPreCommitBatch", "must be in the range [1, %d], got %d", miner5.PreCommitSectorBatchMaxSize, sc.MaxPreCommitBatch)
+	}
+	v.nonNegativeFIL("Sealing.MaxPreCommitBatchFeeValue", sc.MaxPreCommitBatchFeeValue)
+	if sc.MaxPreCommitBatchByValue && (sc.MaxPreCommitBatchFeeValue.Int == nil || sc.MaxPreCommitBatchFeeValue.Sign() <= 0) {
+		v.errorf("Sealing.MaxPreCommitBatchFeeValue", "must be positive when MaxPreCommitBatchByValue is set, got %s", sc.MaxPreCommitBatchFeeValue)
+	}
+	v.oneOf("Sealing.SectorSealRetryPolicy", sc.SectorSealRetryPolicy, "exponential", "linear", "none")
+	if f := sc.P1StopFraction; f < 0 || f >= 1 || math.IsNaN(f) {
+		v.errorf("Sealing.P1StopFraction", "must be in the range [0, 1), got %f", f)
+	}
+	if sc.PreCommitBatchSlack >= sc.PreCommitBatchWait {
+		v.errorf("Sealing.PreCommitBatchSlack", "must be less than PreCommitBatchWait (%s >= %s)", time.Duration(sc.PreCommitBatchSlack), time.Duration(sc.PreCommitBatchWait))
+	}
+	if m := sc.PreCommitGasMultiplier; m < 1.0 || m > 2.0 {
+		v.errorf("Sealing.PreCommitGasMultiplier", "must be in the range [1.0, 2.0], got %f", m)
+	}
+	if m := sc.CommitGasMultiplier; m < 1.0 || m > 2.0 {
+		v.errorf("Sealing.CommitGasMultiplier", "must be in the range [1.0, 2.0], got %f", m)
+	}
+	if m := sc.ReplicaUpdateGasMultiplier; m < 1.0 || m > 2.0 {
+		v.errorf("Sealing.ReplicaUpdateGasMultiplier", "must be in the range [1.0, 2.0], got %f", m)
+	}
+	if sc.MaxCommitBatch < 1 || sc.MaxCommitBatch > miner5.MaxAggregatedSectors {
+		v.errorf("Sealing.MaxCommitBatch", "must be in the range [1, %d], got %d", miner5.MaxAggregatedSectors, sc.MaxCommitBatch)
+	}
+	if sc.MinCommitBatch > sc.MaxCommitBatch {
+		v.errorf("Sealing.MinCommitBatch", "must not exceed MaxCommitBatch (%d > %d)", sc.MinCommitBatch, sc.MaxCommitBatch)
+	}
+	if sc.CommitBatchSlack >= sc.CommitBatchWait {
+		v.errorf("Sealing.CommitBatchSlack", "must be less than CommitBatchWait (%s >= %s)", time.Duration(sc.CommitBatchSlack), time.Duration(sc.CommitBatchWait))
+	}
+	v.nonNegativeDuration("Sealing.CommitBatchOverlapWindow", sc.CommitBatchOverlapWindow)
+	if sc.CommitBatchOverlapWindow > 0 && sc.CommitBatchOverlapWindow >= sc.CommitBatchSlack {
+		v.errorf("Sealing.CommitBatchOverlapWindow", "must be less than CommitBatchSlack (%s >= %s)", time.Duration(sc.CommitBatchOverlapWindow), time.Duration(sc.CommitBatchSlack))
+	}
+	v.nonNegativeFIL("Sealing.BatchPreCommitAboveBaseFee", sc.BatchPreCommitAboveBaseFee)
+	if t := sc.SectorPreCommitBatchGasTarget; t < 0 || t > 1 || math.IsNaN(t) {
+		v.errorf("Sealing.SectorPreCommitBatchGasTarget", "must be in the range [0.0, 1.0], got %f", t)
+	}
+	if r := sc.AggregateMinBaseFeeDecayRatio; r < 0 || r > 1 || math.IsNaN(r) {
+		v.errorf("Sealing.AggregateMinBaseFeeDecayRatio", "must be in the range [0.0, 1.0], got %f", r)
+	}
+	v.nonNegativeFIL("Sealing.AggregateAboveBaseFee", sc.AggregateAboveBaseFee)
+	v.nonNegative("Sealing.MaxConcurrentProveCommits", int64(sc.MaxConcurrentProveCommits))
+	v.nonNegative("Sealing.MaxPreCommitsInFlight", int64(sc.MaxPreCommitsInFlight))
+	if l := sc.TicketLookaheadEpochs; l < 0 || l > int(policy.ChainFinality) {
+		v.errorf("Sealing.TicketLookaheadEpochs", "must be in the range [0, %d], got %d", policy.ChainFinality, l)
+	}
+	v.nonNegativeDuration("Sealing.FinalizeSectorTimeout", sc.FinalizeSectorTimeout)
+	if sc.TerminateBatchMin > sc.TerminateBatchMax {
+		v.errorf("Sealing.TerminateBatchMin", "must not exceed TerminateBatchMax (%d > %d)", sc.TerminateBatchMin, sc.TerminateBatchMax)
+	}
+	v.nonNegativeDuration("Sealing.TerminateBatchWait", sc.TerminateBatchWait)
+	v.nonNegative("Sealing.SnapDealSectorReuseWindow", int64(sc.SnapDealSectorReuseWindow))
+	if maxExt, err := policy.GetMaxSectorExpirationExtension(network.Version21); err == nil && sc.SnapDealSectorReuseWindow > maxExt {
+		v.errorf("Sealing.SnapDealSectorReuseWindow", "must not exceed the maximum sector expiration extension (%d > %d)", sc.SnapDealSectorReuseWindow, maxExt)
+	}
+	if sc.SectorBuildWatcher && sc.SectorWatcherTopicName == "" {
+		v.errorf("Sealing.SectorWatcherTopicName", "must be set when SectorBuildWatcher is enabled")
+	}
+
+	v.nonNegative("Storage.ParallelFetchLimit", int64(c.Storage.ParallelFetchLimit))
+	v.nonNegative("Storage.ParallelSectorMoveLimit", int64(c.Storage.ParallelSectorMoveLimit))
+	v.nonNegative("Storage.MaxPendingStorageRequests", int64(c.Storage.MaxPendingStorageRequests))
+	if p := c.Storage.TaskSheddingPolicy; p != "" {
+		v.oneOf("Storage.TaskSheddingPolicy", p, TaskSheddingReject, TaskSheddingBlock, TaskSheddingOldestFirst)
+	}
+	v.nonNegative("Storage.MaxOpenSectorsPerWorker", int64(c.Storage.MaxOpenSectorsPerWorker))
+	if h := c.Storage.WorkerSelectionHeuristic; h != "" {
+		v.oneOf("Storage.WorkerSelectionHeuristic", h, WorkerSelectionUtilization, WorkerSelectionLocality, WorkerSelectionCapacity)
+		if a := c.Storage.Assigner; h != WorkerSelectionUtilization && a != "" && a != "utilization" {
+			v.errorf("Storage.WorkerSelectionHeuristic", "%q requires the utilization assigner, got assigner %q", h, a)
+		}
+	}
+	if p := c.Storage.StorageSealerStaging; p != "" && !filepath.IsAbs(p) {
+		v.errorf("Storage.StorageSealerStaging", "must be an absolute path, got %q", p)
+	}
+	if bw := c.Storage.NetworkBandwidthLimitMBps; bw < 0 || math.IsNaN(bw) {
+		v.errorf("Storage.NetworkBandwidthLimitMBps", "must not be negative, got %f", bw)
+	}
+	v.nonNegative("Storage.GPUTemperatureLimit", int64(c.Storage.GPUTemperatureLimit))
+	if c.Storage.GPUTemperatureLimit > 0 && c.Storage.GPUTemperaturePollInterval <= 0 {
+		v.errorf("Storage.GPUTemperaturePollInterval", "must be positive when GPUTemperatureLimit is set, got %s", time.Duration(c.Storage.GPUTemperaturePollInterval))
+	}
+	if c.Storage.ResourceFiltering != "" {
+		v.oneOf("Storage.ResourceFiltering", string(c.Storage.ResourceFiltering),
+			string(ResourceFilteringHardware), string(ResourceFilteringDisabled), string(ResourceFilteringSchedule))
+	}
+	for i, w := range c.Storage.ResourceFilteringSchedule {
+		field := fmt.Sprintf("Storage.ResourceFilteringSchedule[%d]", i)
+		if w.StartHour < 0 || w.StartHour > 23 {
+			v.errorf(field+".StartHour", "must be between 0 and 23, got %d", w.StartHour)
+		}
+		if w.EndHour < 0 || w.EndHour > 24 {
+			v.errorf(field+".EndHour", "must be between 0 and 24, got %d", w.EndHour)
+		}
+		if _, err := time.LoadLocation(w.Timezone); err != nil {
+			v.errorf(field+".Timezone", "unknown time zone %q", w.Timezone)
+		}
+		v.oneOf(field+".Strategy", string(w.Strategy), string(ResourceFilteringHardware), string(ResourceFilteringDisabled))
+	}
+
+	fees := &c.Fees
+	v.nonNegativeFIL("Fees.MaxPreCommitGasFee", fees.MaxPreCommitGasFee)
+	v.nonNegativeFIL("Fees.MaxCommitGasFee", fees.MaxCommitGasFee)
+	v.nonNegativeFIL("Fees.MaxPreCommitBatchGasFee.Base", fees.MaxPreCommitBatchGasFee.Base)
+	v.nonNegativeFIL("Fees.MaxPreCommitBatchGasFee.PerSector", fees.MaxPreCommitBatchGasFee.PerSector)
+	v.nonNegativeFIL("Fees.MaxPreCommitBatchGasFee.MaxTotalFee", fees.MaxPreCommitBatchGasFee.MaxTotalFee)
+	v.nonNegativeFIL("Fees.MaxCommitBatchGasFee.Base", fees.MaxCommitBatchGasFee.Base)
+	v.nonNegativeFIL("Fees.MaxCommitBatchGasFee.PerSector", fees.MaxCommitBatchGasFee.PerSector)
+	v.nonNegativeFIL("Fees.MaxCommitBatchGasFee.MaxTotalFee", fees.MaxCommitBatchGasFee.MaxTotalFee)
+	v.nonNegativeFIL("Fees.MaxTerminateGasFee", fees.MaxTerminateGasFee)
+	v.nonNegativeFIL("Fees.MaxWindowPoStGasFee", fees.MaxWindowPoStGasFee)
+	v.nonNegativeFIL("Fees.MaxPublishDealsFee", fees.MaxPublishDealsFee)
+	v.nonNegativeFIL("Fees.MaxMarketBalanceAddFee", fees.MaxMarketBalanceAddFee)
+
+	if dpw := c.Addresses.DealPublishWorker; dpw != "" {
+		v.dealPublishWorker(dpw, c.Addresses.DealPublishControl)
+	}
+
+	ds := &c.DAGStore
+	v.nonNegative("DAGStore.MaxConcurrentIndex", int64(ds.MaxConcurrentIndex))
+	v.nonNegative("DAGStore.MaxConcurrentReadyFetches", int64(ds.MaxConcurrentReadyFetches))
+	v.nonNegative("DAGStore.MaxConcurrentUnseals", int64(ds.MaxConcurrentUnseals))
+	v.nonNegative("DAGStore.MaxConcurrencyStorageCalls", int64(ds.MaxConcurrencyStorageCalls))
+	v.nonNegative("DAGStore.MaxConcurrentRetrievals", int64(ds.MaxConcurrentRetrievals))
+	v.nonNegativeDuration("DAGStore.GCInterval", ds.GCInterval)
+	v.oneOf("DAGStore.TransientEvictionPolicy", ds.TransientEvictionPolicy, TransientEvictionLRU, TransientEvictionFIFO, TransientEvictionNone)
+	v.nonNegativeDuration("DAGStore.TransientGCInterval", ds.TransientGCInterval)
+	v.nonNegativeDuration("DAGStore.IndexCompactionInterval", ds.IndexCompactionInterval)
+	v.nonNegativeDuration("DAGStore.PieceStoreFlushInterval", ds.PieceStoreFlushInterval)
+	v.nonNegative("DAGStore.RecoveryRetries", int64(ds.RecoveryRetries))
+	if ds.RecoveryRetries > 0 && ds.RecoveryInterval <= 0 {
+		v.errorf("DAGStore.RecoveryInterval", "must be positive when RecoveryRetries is set, got %s", time.Duration(ds.RecoveryInterval))
+	}
+
+	return v.err()
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) errorf(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, &FieldError{Field: field, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errs: v.errs}
+}
+
+func (v *validator) version(ver int) {
+	if ver < 0 || ver > CurrentConfigVersion {
+		v.errorf("Version", "must be between 0 and %d, got %d; configs written by newer releases can't be loaded", CurrentConfigVersion, ver)
+	}
+}
+
+func (v *validator) common(c *Common) {
+	v.nonNegativeDuration("API.Timeout", c.API.Timeout)
+	v.nonNegativeDuration("API.WebSocketHeartbeat", c.API.WebSocketHeartbeat)
+	v.nonNegative("API.WebSocketMaxMessageSize", c.API.WebSocketMaxMessageSize)
+	v.nonNegative("API.MaxResponseBodySize", c.API.MaxResponseBodySize)
+	for i, m := range c.API.AllowedMethods {
+		if strings.TrimSpace(m) == "" {
+			v.errorf(fmt.Sprintf("API.AllowedMethods[%d]", i), "method name must not be empty")
+		}
+	}
+	for i, m := range c.API.DeniedMethods {
+		if strings.TrimSpace(m) == "" {
+			v.errorf(fmt.Sprintf("API.DeniedMethods[%d]", i), "method name must not be empty")
+		}
+	}
+	for i, h := range c.API.HTTPProxyHeaders {
+		if strings.TrimSpace(h) == "" {
+			v.errorf(fmt.Sprintf("API.HTTPProxyHeaders[%d]", i), "header name must not be empty")
+		}
+	}
+	for i, subnet := range c.API.TrustedSubnets {
+		if _, _, err := net.ParseCIDR(subnet); err != nil {
+			v.errorf(fmt.Sprintf("API.TrustedSubnets[%d]", i), "invalid subnet %q: %s", subnet, err)
+		}
+	}
+	if len(c.API.HTTPProxyHeaders) > 0 && len(c.API.TrustedSubnets) == 0 {
+		v.errorf("API.TrustedSubnets", "must be set when HTTPProxyHeaders is set")
+	}
+	if c.API.OpenTelemetryEndpoint != "" && c.API.OpenTelemetryServiceName == "" {
+		v.errorf("API.OpenTelemetryServiceName", "must be set when OpenTelemetryEndpoint is set")
+	}
+	if c.API.PrometheusBasicAuthUser != "" && c.API.PrometheusBasicAuthPass == "" {
+		v.errorf("API.PrometheusBasicAuthPass", "must be set when PrometheusBasicAuthUser is set")
+	}
+	if c.API.PrometheusBasicAuthPass != "" && c.API.PrometheusBasicAuthUser == "" {
+		v.errorf("API.PrometheusBasicAuthUser", "must be set when PrometheusBasicAuthPass is set")
+	}
+
+	if c.Libp2p.ConnMgrLow >= c.Libp2p.ConnMgrHigh {
+		v.errorf("Libp2p.ConnMgrLow", "must be less than ConnMgrHigh (%d >= %d)", c.Libp2p.ConnMgrLow, c.Libp2p.ConnMgrHigh)
+	}
+	v.nonNegativeDuration("Libp2p.ConnMgrGrace", c.Libp2p.ConnMgrGrace)
+	v.nonNegativeDuration("Libp2p.ConnMgrEmergencyGracePeriod", c.Libp2p.ConnMgrEmergencyGracePeriod)
+	v.nonNegativeDuration("Libp2p.ConnMgrSilencePeriod", c.Libp2p.ConnMgrSilencePeriod)
+	protos := make([]string, 0, len(c.Libp2p.ProtocolPeerLimits))
+	for proto := range c.Libp2p.ProtocolPeerLimits {
+		protos = append(protos, proto)
+	}
+	sort.Strings(protos)
+	for _, proto := range protos {
+		field := "Libp2p.ProtocolPeerLimits[" + proto + "]"
+		if proto == "" {
+			v.errorf(field, "protocol ID must not be empty")
+		}
+		if n := c.Libp2p.ProtocolPeerLimits[proto]; n <= 0 {
+			v.errorf(field, "must be positive, got %d", n)
+		}
+	}
+	v.nonNegative("Libp2p.BootstrapRetryMax", int64(c.Libp2p.BootstrapRetryMax))
+	v.nonNegativeDuration("Libp2p.BootstrapRetryDelay", c.Libp2p.BootstrapRetryDelay)
+	if c.Libp2p.ObservedAddressActivationThreshold < 1 {
+		v.errorf("Libp2p.ObservedAddressActivationThreshold", "must be at least 1, got %d", c.Libp2p.ObservedAddressActivationThreshold)
+	}
+}
+
+func (v *validator) nonNegative(field string, n int64) {
+	if n < 0 {
+		v.errorf(field, "must not be negative, got %d", n)
+	}
+}
+
+func (v *validator) nonNegativeDuration(field string, d Duration) {
+	if d < 0 {
+		v.errorf(field, "must not be negative, got %s", time.Duration(d))
+	}
+}
+
+func (v *validator) nonNegativeFIL(field string, f types.FIL) {
+	bi := types.BigInt(f)
+	if !bi.Nil() && bi.Sign() < 0 {
+		v.errorf(field, "must not be negative, got %s", f)
+	}
+}
+
+func (v *validator) dealPublishWorker(worker string, control []string) {
+	addr, err := address.NewFromString(worker)
+	if err != nil {
+		v.errorf("Addresses.DealPublishWorker", "invalid address %q: %s", worker, err)
+		return
+	}
+	for _, s := range control {
+		if ctl, err := address.NewFromString(s); err == nil && ctl == addr {
+			return
+		}
+	}
+	v.errorf("Addresses.DealPublishWorker", "must be one of DealPublishControl, got %q", worker)
+}
+
+func (v *validator) oneOf(field, val string, allowed ...string) {
+	for _, a := range allowed {
+		if val == a {
+			return
+		}
+	}
+	v.errorf(field, "must be one of %q, got %q", allowed, val)
+}
@@ -0,0 +1,400 @@
+// stm: #unit
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/builtin"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestValidateDefaults(t *testing.T) {
+	require.NoError(t, DefaultFullNode().Validate())
+	require.NoError(t, DefaultStorageMiner().Validate())
+}
+
+func requireFieldErrors(t *testing.T, err error, fields ...string) {
+	t.Helper()
+
+	if len(fields) == 0 {
+		require.NoError(t, err)
+		return
+	}
+
+	require.Error(t, err)
+	verr, ok := err.(interface{ Unwrap() []error })
+	require.True(t, ok, "error should expose individual violations")
+
+	var got []string
+	for _, e := range verr.Unwrap() {
+		ferr, ok := e.(*FieldError)
+		require.True(t, ok)
+		got = append(got, ferr.Field)
+	}
+	require.ElementsMatch(t, fields, got)
+}
+
+func TestValidateFullNode(t *testing.T) {
+	negFIL := types.MustParseFIL("-1")
+
+	testCases := []struct {
+		name   string
+		modify func(c *FullNode)
+		fields []string
+	}{
+		{"config from a newer release", func(c *FullNode) { c.Version = CurrentConfigVersion + 1 }, []string{"Version"}},
+		{"negative api timeout", func(c *FullNode) { c.API.Timeout = Duration(-time.Second) }, []string{"API.Timeout"}},
+		{"negative websocket heartbeat", func(c *FullNode) { c.API.WebSocketHeartbeat = Duration(-time.Second) }, []string{"API.WebSocketHeartbeat"}},
+		{"negative websocket message size", func(c *FullNode) { c.API.WebSocketMaxMessageSize = -1 }, []string{"API.WebSocketMaxMessageSize"}},
+		{"negative response body size", func(c *FullNode) { c.API.MaxResponseBodySize = -1 }, []string{"API.MaxResponseBodySize"}},
+		{"empty allowed method", func(c *FullNode) { c.API.AllowedMethods = []string{"ChainHead", ""} }, []string{"API.AllowedMethods[1]"}},
+		{"empty denied method", func(c *FullNode) { c.API.DeniedMethods = []string{" "} }, []string{"API.DeniedMethods[0]"}},
+		{"otel endpoint without service name", func(c *FullNode) {
+			c.API.OpenTelemetryEndpoint = "localhost:4317"
+			c.API.OpenTelemetryServiceName = ""
+		}, []string{"API.OpenTelemetryServiceName"}},
+		{"negative migration parallelism", func(c *FullNode) { c.Chainstore.ActorMigrationParallelism = -1 }, []string{"Chainstore.ActorMigrationParallelism"}},
+		{"network version override on mainnet", func(c *FullNode) { c.Chainstore.NetworkVersionOverride = 21 }, []string{"Chainstore.NetworkVersionOverride"}},
+		{"proxy headers without trusted subnets", func(c *FullNode) { c.API.HTTPProxyHeaders = []string{"X-Forwarded-For"} }, []string{"API.TrustedSubnets"}},
+		{"invalid trusted subnet", func(c *FullNode) { c.API.TrustedSubnets = []string{"10.0.0.1"} }, []string{"API.TrustedSubnets[0]"}},
+		{"proxy headers", func(c *FullNode) {
+			c.API.HTTPProxyHeaders = []string{"X-Forwarded-For"}
+			c.API.TrustedSubnets = []string{"10.0.0.0/8", "::1/128"}
+		}, nil},
+		{"metrics user without password", func(c *FullNode) { c.API.PrometheusBasicAuthUser = "prom" }, []string{"API.PrometheusBasicAuthPass"}},
+		{"metrics password without user", func(c *FullNode) { c.API.PrometheusBasicAuthPass = "secret" }, []string{"API.PrometheusBasicAuthUser"}},
+		{"connmgr low equals high", func(c *FullNode) { c.Libp2p.ConnMgrLow = c.Libp2p.ConnMgrHigh }, []string{"Libp2p.ConnMgrLow"}},
+		{"connmgr low above high", func(c *FullNode) { c.Libp2p.ConnMgrLow = c.Libp2p.ConnMgrHigh + 1 }, []string{"Libp2p.ConnMgrLow"}},
+		{"negative connmgr grace", func(c *FullNode) { c.Libp2p.ConnMgrGrace = Duration(-time.Second) }, []string{"Libp2p.ConnMgrGrace"}},
+		{"negative connmgr emergency grace", func(c *FullNode) { c.Libp2p.ConnMgrEmergencyGracePeriod = Duration(-time.Second) }, []string{"Libp2p.ConnMgrEmergencyGracePeriod"}},
+		{"negative connmgr silence", func(c *FullNode) { c.Libp2p.ConnMgrSilencePeriod = Duration(-time.Second) }, []string{"Libp2p.ConnMgrSilencePeriod"}},
+		{"protocol peer limits", func(c *FullNode) {
+			c.Libp2p.ProtocolPeerLimits = map[string]int{"/fil/hello/1.0.0": 4, "/fil/sync/blk/0.0.1": 0, "": 1}
+		}, []string{"Libp2p.ProtocolPeerLimits[/fil/sync/blk/0.0.1]", "Libp2p.ProtocolPeerLimits[]"}},
+		{"negative bootstrap retries", func(c *FullNode) { c.Libp2p.BootstrapRetryMax = -1 }, []string{"Libp2p.BootstrapRetryMax"}},
+		{"zero observed address activation threshold", func(c *FullNode) { c.Libp2p.ObservedAddressActivationThreshold = 0 }, []string{"Libp2p.ObservedAddressActivationThreshold"}},
+		{"negative bootstrap delay", func(c *FullNode) { c.Libp2p.BootstrapRetryDelay = Duration(-time.Second) }, []string{"Libp2p.BootstrapRetryDelay"}},
+		{"negative default max fee", func(c *FullNode) { c.Fees.DefaultMaxFee = negFIL }, []string{"Fees.DefaultMaxFee"}},
+		{"unknown coldstore type", func(c *FullNode) { c.Chainstore.Splitstore.ColdStoreType = "tape" }, []string{"Chainstore.Splitstore.ColdStoreType"}},
+		{"unknown hotstore type", func(c *FullNode) { c.Chainstore.Splitstore.HotStoreType = "map" }, []string{"Chainstore.Splitstore.HotStoreType"}},
+		{"unknown markset type", func(c *FullNode) { c.Chainstore.Splitstore.MarkSetType = "bloom" }, []string{"Chainstore.Splitstore.MarkSetType"}},
+		{"negative gc lock timeout", func(c *FullNode) { c.Chainstore.GCLockTimeout = Duration(-time.Minute) }, []string{"Chainstore.GCLockTimeout"}},
+		{"splitstore types ignored when disabled", func(c *FullNode) {
+			c.Chainstore.EnableSplitstore = false
+			c.Chainstore.Splitstore.ColdStoreType = "tape"
+		}, nil},
+		{"block cache with splitstore", func(c *FullNode) { c.Chainstore.BlockCacheSizeBytes = 1 << 20 }, []string{"Chainstore.BlockCacheSizeBytes"}},
+		{"block cache without splitstore", func(c *FullNode) {
+			c.Chainstore.EnableSplitstore = false
+			c.Chainstore.BlockCacheSizeBytes = 1 << 20
+		}, nil},
+		{"hotstore threshold above target", func(c *FullNode) {
+			c.Chainstore.Splitstore.HotStoreMaxSpaceThreshold = c.Chainstore.Splitstore.HotStoreMaxSpaceTarget + 1
+		}, []string{"Chainstore.Splitstore.HotStoreMaxSpaceThreshold"}},
+		{"hotstore safety buffer above target", func(c *FullNode) {
+			c.Chainstore.Splitstore.HotstoreMaxSpaceSafetyBuffer = c.Chainstore.Splitstore.HotStoreMaxSpaceTarget + 1
+		}, []string{"Chainstore.Splitstore.HotstoreMaxSpaceSafetyBuffer"}},
+		{"moving gc ratio too low", func(c *FullNode) { c.Chainstore.Splitstore.MovingGCThresholdRatio = 0.4 }, []string{"Chainstore.Splitstore.MovingGCThresholdRatio"}},
+		{"moving gc ratio above one", func(c *FullNode) { c.Chainstore.Splitstore.MovingGCThresholdRatio = 1.1 }, []string{"Chainstore.Splitstore.MovingGCThresholdRatio"}},
+		{"hotstore ordering ignored without target", func(c *FullNode) {
+			c.Chainstore.Splitstore.HotStoreMaxSpaceTarget = 0
+		}, nil},
+		{"negative republish interval", func(c *FullNode) { c.Chainstore.MsgPoolRepublishInterval = Duration(-time.Second) }, []string{"Chainstore.MsgPoolRepublishInterval"}},
+		{"negative per sender queue size", func(c *FullNode) { c.Chainstore.MsgMaxQueueSizePerSender = -1 }, []string{"Chainstore.MsgMaxQueueSizePerSender"}},
+		{"negative republish attempts", func(c *FullNode) { c.Chainstore.MsgRepublishMaxAttempts = -1 }, []string{"Chainstore.MsgRepublishMaxAttempts"}},
+		{"unknown tipset weight algorithm", func(c *FullNode) { c.Chainstore.TipsetWeightAlgorithm = "longest" }, []string{"Chainstore.TipsetWeightAlgorithm"}},
+		{"unknown message selection policy", func(c *FullNode) { c.Chainstore.MsgSelectPolicy = "random" }, []string{"Chainstore.MsgSelectPolicy"}},
+		{"negative block validation timeout", func(c *FullNode) { c.Chainstore.GossipBlockValidationTimeout = Duration(-time.Second) }, []string{"Chainstore.GossipBlockValidationTimeout"}},
+		{"empty validator cache", func(c *FullNode) { c.Chainstore.ValidatorCacheSize = 0 }, []string{"Chainstore.ValidatorCacheSize"}},
+		{"validator cache size ignored when disabled", func(c *FullNode) {
+			c.Chainstore.ValidatorCacheEnabled = false
+			c.Chainstore.ValidatorCacheSize = 0
+		}, nil},
+		{"negative block fetch limit", func(c *FullNode) { c.Chainstore.SimultaneousBlockFetchLimit = -1 }, []string{"Chainstore.SimultaneousBlockFetchLimit"}},
+		{"no sync workers", func(c *FullNode) { c.Chainstore.MinSyncWorkers = 0 }, []string{"Chainstore.MinSyncWorkers"}},
+		{"negative bootstrap sync peers", func(c *FullNode) { c.Chainstore.BootstrapSyncMinPeers = -1 }, []string{"Chainstore.BootstrapSyncMinPeers"}},
+		{"max sync workers below min", func(c *FullNode) {
+			c.Chainstore.MinSyncWorkers = 3
+			c.Chainstore.MaxSyncWorkers = 2
+		}, []string{"Chainstore.MaxSyncWorkers"}},
+		{"negative tx hash lifetime", func(c *FullNode) { c.Fevm.EthTxHashMappingLifetimeDays = -1 }, []string{"Fevm.EthTxHashMappingLifetimeDays"}},
+		{"small max code size", func(c *FullNode) { c.Fevm.EthMaxCodeSize = 1000 }, []string{"Fevm.EthMaxCodeSize"}},
+		{"negative block tx count max", func(c *FullNode) { c.Fevm.EthGetBlockTransactionCountMax = -1 }, []string{"Fevm.EthGetBlockTransactionCountMax"}},
+		{"negative eth call cache ttl", func(c *FullNode) { c.Fevm.EthCallCacheTTL = Duration(-time.Second) }, []string{"Fevm.EthCallCacheTTL"}},
+		{"empty eth call cache", func(c *FullNode) {
+			c.Fevm.EthCallCacheEnabled = true
+			c.Fevm.EthCallCacheSize = 0
+		}, []string{"Fevm.EthCallCacheSize"}},
+		{"negative tx lookup cache size", func(c *FullNode) { c.Fevm.EthGetTransactionByHashCacheSize = -1 }, []string{"Fevm.EthGetTransactionByHashCacheSize"}},
+		{"negative block propagation delay", func(c *FullNode) { c.Fevm.EthBlockPropagationDelay = Duration(-time.Second) }, []string{"Fevm.EthBlockPropagationDelay"}},
+		{"eth call whitelist", func(c *FullNode) {
+			c.Fevm.EthCallAddressWhitelist = []string{"0xd4c5fb16488Aa48081296299d54b0c648C9333dA"}
+		}, nil},
+		{"invalid eth call whitelist address", func(c *FullNode) { c.Fevm.EthCallAddressWhitelist = []string{"f01234"} }, []string{"Fevm.EthCallAddressWhitelist"}},
+		{"zero chain notification buffer", func(c *FullNode) { c.Fevm.ChainNotificationBufferSize = 0 }, []string{"Fevm.ChainNotificationBufferSize"}},
+		{"unlimited eth batch", func(c *FullNode) {
+			c.Fevm.EnableEthBatchRequests = true
+			c.Fevm.EthBatchRequestMaxSize = 0
+		}, nil},
+		{"negative eth batch size", func(c *FullNode) { c.Fevm.EthBatchRequestMaxSize = -1 }, []string{"Fevm.EthBatchRequestMaxSize"}},
+		{"negative eth_getLogs max results", func(c *FullNode) { c.Fevm.EthGetLogsMaxResults = -1 }, []string{"Fevm.EthGetLogsMaxResults"}},
+		{"negative pending tx timeout", func(c *FullNode) { c.Fevm.EthPendingTransactionTimeout = Duration(-time.Second) }, []string{"Fevm.EthPendingTransactionTimeout"}},
+		{"unknown eth sync status mode", func(c *FullNode) { c.Fevm.EthSyncStatusMode = "bitcoin" }, []string{"Fevm.EthSyncStatusMode"}},
+		{"unknown eth address mapping", func(c *FullNode) { c.Fevm.EthAddressMapping = "hash-based" }, []string{"Fevm.EthAddressMapping"}},
+		{"strict eth address mapping", func(c *FullNode) { c.Fevm.EthAddressMapping = "strict" }, nil},
+		{"empty eth event batch interval", func(c *FullNode) {
+			c.Fevm.EthEventBatch = true
+			c.Fevm.EthEventBatchInterval = 0
+		}, []string{"Fevm.EthEventBatchInterval"}},
+		{"negative filter ttl", func(c *FullNode) { c.Fevm.Events.FilterTTL = Duration(-time.Second) }, []string{"Fevm.Events.FilterTTL"}},
+		{"negative max filters", func(c *FullNode) { c.Fevm.Events.MaxFilters = -1 }, []string{"Fevm.Events.MaxFilters"}},
+		{"negative max filter results", func(c *FullNode) { c.Fevm.Events.MaxFilterResults = -1 }, []string{"Fevm.Events.MaxFilterResults"}},
+		{"drop-oldest backpressure", func(c *FullNode) { c.Fevm.Events.SubscriptionBackpressureMode = SubscriptionBackpressureDropOldest }, nil},
+		{"drop-newest backpressure", func(c *FullNode) { c.Fevm.Events.SubscriptionBackpressureMode = SubscriptionBackpressureDropNewest }, nil},
+		{"block backpressure", func(c *FullNode) { c.Fevm.Events.SubscriptionBackpressureMode = SubscriptionBackpressureBlock }, nil},
+		{"unknown backpressure mode", func(c *FullNode) { c.Fevm.Events.SubscriptionBackpressureMode = "drop-all" }, []string{"Fevm.Events.SubscriptionBackpressureMode"}},
+		{"empty subscription buffer", func(c *FullNode) { c.Fevm.Events.MaxSubscriptionBufferSize = 0 }, []string{"Fevm.Events.MaxSubscriptionBufferSize"}},
+		{"empty subscription send timeout", func(c *FullNode) { c.Fevm.Events.SubscriptionSendTimeout = 0 }, []string{"Fevm.Events.SubscriptionSendTimeout"}},
+		{"multiple violations", func(c *FullNode) {
+			c.Libp2p.ConnMgrLow = c.Libp2p.ConnMgrHigh
+			c.Fees.DefaultMaxFee = negFIL
+		}, []string{"Libp2p.ConnMgrLow", "Fees.DefaultMaxFee"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := DefaultFullNode()
+			tc.modify(c)
+			requireFieldErrors(t, c.Validate(), tc.fields...)
+		})
+	}
+}
+
+func TestValidateStorageMiner(t *testing.T) {
+	negFIL := types.MustParseFIL("-1")
+
+	testCases := []struct {
+		name   string
+		modify func(c *StorageMiner)
+		fields []string
+	}{
+		{"connmgr low above high", func(c *StorageMiner) { c.Libp2p.ConnMgrLow = c.Libp2p.ConnMgrHigh + 1 }, []string{"Libp2p.ConnMgrLow"}},
+		{"negative expected seal duration", func(c *StorageMiner) { c.Dealmaking.ExpectedSealDuration = Duration(-time.Second) }, []string{"Dealmaking.ExpectedSealDuration"}},
+		{"negative max deal start delay", func(c *StorageMiner) { c.Dealmaking.MaxDealStartDelay = Duration(-time.Second) }, []string{"Dealmaking.MaxDealStartDelay"}},
+		{"negative publish period", func(c *StorageMiner) { c.Dealmaking.PublishMsgPeriod = Duration(-time.Second) }, []string{"Dealmaking.PublishMsgPeriod"}},
+		{"negative staging bytes", func(c *StorageMiner) { c.Dealmaking.MaxStagingDealsBytes = -1 }, []string{"Dealmaking.MaxStagingDealsBytes"}},
+		{"negative storage price cap", func(c *StorageMiner) { c.Dealmaking.StoragePriceCap = negFIL }, []string{"Dealmaking.StoragePriceCap"}},
+		{"negative deal client count", func(c *StorageMiner) { c.Dealmaking.MaxDealClientAddressCount = -1 }, []string{"Dealmaking.MaxDealClientAddressCount"}},
+		{"oracle without poll interval", func(c *StorageMiner) {
+			c.Dealmaking.StoragePriceOracle = "https://example.com/price"
+			c.Dealmaking.StoragePriceOraclePollInterval = 0
+		}, []string{"Dealmaking.StoragePriceOraclePollInterval"}},
+		{"unknown retrieval pricing strategy", func(c *StorageMiner) { c.Dealmaking.RetrievalPricing.Strategy = "auction" }, []string{"Dealmaking.RetrievalPricing.Strategy"}},
+		{"external retrieval pricing without path", func(c *StorageMiner) {
+			c.Dealmaking.RetrievalPricing.Strategy = RetrievalPricingExternalMode
+		}, []string{"Dealmaking.RetrievalPricing.External.Path"}},
+		{"negative retrieval byte price", func(c *StorageMiner) {
+			c.Dealmaking.RetrievalPricing.Default.RetrievalPricingDefaultBytePrice = negFIL
+		}, []string{"Dealmaking.RetrievalPricing.Default.RetrievalPricingDefaultBytePrice"}},
+		{"unknown window post worker type", func(c *StorageMiner) { c.Proving.WindowPoStWorkerType = "gpu" }, []string{"Proving.WindowPoStWorkerType"}},
+		{"remote window post without endpoint", func(c *StorageMiner) { c.Proving.WindowPoStWorkerType = "remote" }, []string{"Proving.RemotePoStEndpoint"}},
+		{"remote window post over http", func(c *StorageMiner) {
+			c.Proving.WindowPoStWorkerType = "remote"
+			c.Proving.RemotePoStEndpoint = "http://prover:8080/post"
+		}, []string{"Proving.RemotePoStEndpoint"}},
+		{"remote window post", func(c *StorageMiner) {
+			c.Proving.WindowPoStWorkerType = "remote"
+			c.Proving.RemotePoStEndpoint = "https://prover:8443/post"
+		}, nil},
+		{"fallback prover without command", func(c *StorageMiner) { c.Proving.UseFallbackProver = true }, []string{"Proving.FallbackProverCommand"}},
+		{"fallback prover with remote window post", func(c *StorageMiner) {
+			c.Proving.WindowPoStWorkerType = "remote"
+			c.Proving.RemotePoStEndpoint = "https://prover:8443/post"
+			c.Proving.UseFallbackProver = true
+			c.Proving.FallbackProverCommand = "/usr/local/bin/post-prover"
+		}, []string{"Proving.UseFallbackProver"}},
+		{"fallback prover", func(c *StorageMiner) {
+			c.Proving.UseFallbackProver = true
+			c.Proving.FallbackProverCommand = "/usr/local/bin/post-prover"
+		}, nil},
+		{"invalid deal publish worker", func(c *StorageMiner) { c.Addresses.DealPublishWorker = "f0abc" }, []string{"Addresses.DealPublishWorker"}},
+		{"deal publish worker not a deal publish control", func(c *StorageMiner) {
+			c.Addresses.DealPublishControl = []string{"f01000"}
+			c.Addresses.DealPublishWorker = "f01001"
+		}, []string{"Addresses.DealPublishWorker"}},
+		{"deal publish worker", func(c *StorageMiner) {
+			c.Addresses.DealPublishControl = []string{"f01000", "f01001"}
+			c.Addresses.DealPublishWorker = "f01001"
+		}, nil},
+		{"negative fallback prover timeout", func(c *StorageMiner) { c.Proving.FallbackProverTimeout = Duration(-time.Second) }, []string{"Proving.FallbackProverTimeout"}},
+		{"negative parallel check limit", func(c *StorageMiner) { c.Proving.ParallelCheckLimit = -1 }, []string{"Proving.ParallelCheckLimit"}},
+		{"negative single check timeout", func(c *StorageMiner) { c.Proving.SingleCheckTimeout = Duration(-time.Second) }, []string{"Proving.SingleCheckTimeout"}},
+		{"negative partition check timeout", func(c *StorageMiner) { c.Proving.PartitionCheckTimeout = Duration(-time.Second) }, []string{"Proving.PartitionCheckTimeout"}},
+		{"negative partitions per post", func(c *StorageMiner) { c.Proving.MaxPartitionsPerPoStMessage = -1 }, []string{"Proving.MaxPartitionsPerPoStMessage"}},
+		{"negative partitions per recovery", func(c *StorageMiner) { c.Proving.MaxPartitionsPerRecoveryMessage = -1 }, []string{"Proving.MaxPartitionsPerRecoveryMessage"}},
+		{"negative recovery messages", func(c *StorageMiner) { c.Proving.MaxFaultRecoveryMessages = -1 }, []string{"Proving.MaxFaultRecoveryMessages"}},
+		{"fault gas multiplier below range", func(c *StorageMiner) { c.Proving.FaultDeclarationGasMultiplier = 0.5 }, []string{"Proving.FaultDeclarationGasMultiplier"}},
+		{"fault gas multiplier above range", func(c *StorageMiner) { c.Proving.FaultDeclarationGasMultiplier = 3.5 }, []string{"Proving.FaultDeclarationGasMultiplier"}},
+		{"fault gas multiplier disabled", func(c *StorageMiner) { c.Proving.FaultDeclarationGasMultiplier = 0 }, nil},
+		{"negative post confirm depth", func(c *StorageMiner) { c.Proving.PoStMessageConfirmDepth = -1 }, []string{"Proving.PoStMessageConfirmDepth"}},
+		{"negative post dispatch buffer", func(c *StorageMiner) { c.Proving.WindowPoStDispatchDeadlineBuffer = -1 }, []string{"Proving.WindowPoStDispatchDeadlineBuffer"}},
+		{"unknown post nonce strategy", func(c *StorageMiner) { c.Proving.WindowPoStNonceStrategy = "random" }, []string{"Proving.WindowPoStNonceStrategy"}},
+		{"negative cc lifetime", func(c *StorageMiner) { c.Sealing.CommittedCapacitySectorLifetime = Duration(-time.Second) }, []string{"Sealing.CommittedCapacitySectorLifetime"}},
+		{"negative wait deals delay", func(c *StorageMiner) { c.Sealing.WaitDealsDelay = Duration(-time.Second) }, []string{"Sealing.WaitDealsDelay"}},
+		{"negative available balance buffer", func(c *StorageMiner) { c.Sealing.AvailableBalanceBuffer = negFIL }, []string{"Sealing.AvailableBalanceBuffer"}},
+		{"negative pledge collateral buffer", func(c *StorageMiner) { c.Sealing.PledgeCollateralBuffer = negFIL }, []string{"Sealing.PledgeCollateralBuffer"}},
+		{"zero precommit batch", func(c *StorageMiner) { c.Sealing.MaxPreCommitBatch = 0 }, []string{"Sealing.MaxPreCommitBatch"}},
+		{"precommit batch above actor limit", func(c *StorageMiner) { c.Sealing.MaxPreCommitBatch = 257 }, []string{"Sealing.MaxPreCommitBatch"}},
+		{"precommit slack equals wait", func(c *StorageMiner) { c.Sealing.PreCommitBatchSlack = c.Sealing.PreCommitBatchWait }, []string{"Sealing.PreCommitBatchSlack"}},
+		{"unknown seal retry policy", func(c *StorageMiner) { c.Sealing.SectorSealRetryPolicy = "fibonacci" }, []string{"Sealing.SectorSealRetryPolicy"}},
+		{"no seal retry", func(c *StorageMiner) { c.Sealing.SectorSealRetryPolicy = "none" }, nil},
+		{"negative p1 stop fraction", func(c *StorageMiner) { c.Sealing.P1StopFraction = -0.1 }, []string{"Sealing.P1StopFraction"}},
+		{"p1 stop fraction of 1", func(c *StorageMiner) { c.Sealing.P1StopFraction = 1 }, []string{"Sealing.P1StopFraction"}},
+		{"p1 stop fraction", func(c *StorageMiner) { c.Sealing.P1StopFraction = 0.8 }, nil},
+		{"negative bandwidth limit", func(c *StorageMiner) { c.Storage.NetworkBandwidthLimitMBps = -1 }, []string{"Storage.NetworkBandwidthLimitMBps"}},
+		{"negative sector move limit", func(c *StorageMiner) { c.Storage.ParallelSectorMoveLimit = -1 }, []string{"Storage.ParallelSectorMoveLimit"}},
+		{"negative pending storage requests", func(c *StorageMiner) { c.Storage.MaxPendingStorageRequests = -1 }, []string{"Storage.MaxPendingStorageRequests"}},
+		{"unknown task shedding policy", func(c *StorageMiner) { c.Storage.TaskSheddingPolicy = "newest-first" }, []string{"Storage.TaskSheddingPolicy"}},
+		{"negative open sectors per worker", func(c *StorageMiner) { c.Storage.MaxOpenSectorsPerWorker = -1 }, []string{"Storage.MaxOpenSectorsPerWorker"}},
+		{"unknown worker selection heuristic", func(c *StorageMiner) { c.Storage.WorkerSelectionHeuristic = "random" }, []string{"Storage.WorkerSelectionHeuristic"}},
+		{"locality heuristic", func(c *StorageMiner) { c.Storage.WorkerSelectionHeuristic = WorkerSelectionLocality }, nil},
+		{"capacity heuristic with spread assigner", func(c *StorageMiner) {
+			c.Storage.Assigner = "spread"
+			c.Storage.WorkerSelectionHeuristic = WorkerSelectionCapacity
+		}, []string{"Storage.WorkerSelectionHeuristic"}},
+		{"relative sealer staging path", func(c *StorageMiner) { c.Storage.StorageSealerStaging = "nvme/staging" }, []string{"Storage.StorageSealerStaging"}},
+		{"absolute sealer staging path", func(c *StorageMiner) { c.Storage.StorageSealerStaging = "/mnt/nvme/staging" }, nil},
+		{"unknown resource filtering", func(c *StorageMiner) { c.Storage.ResourceFiltering = "sometimes" }, []string{"Storage.ResourceFiltering"}},
+		{"resource filtering schedule", func(c *StorageMiner) {
+			c.Storage.ResourceFiltering = ResourceFilteringSchedule
+			c.Storage.ResourceFilteringSchedule = []ResourceFilteringWindow{{StartHour: 22, EndHour: 6, Timezone: "Europe/Berlin", Strategy: ResourceFilteringDisabled}}
+		}, nil},
+		{"invalid resource filtering window", func(c *StorageMiner) {
+			c.Storage.ResourceFilteringSchedule = []ResourceFilteringWindow{{StartHour: 24, EndHour: 25, Timezone: "Nowhere/Nothing", Strategy: ResourceFilteringSchedule}}
+		}, []string{
+			"Storage.ResourceFilteringSchedule[0].StartHour",
+			"Storage.ResourceFilteringSchedule[0].EndHour",
+			"Storage.ResourceFilteringSchedule[0].Timezone",
+			"Storage.ResourceFilteringSchedule[0].Strategy",
+		}},
+		{"snap deal reuse window", func(c *StorageMiner) { c.Sealing.SnapDealSectorReuseWindow = 180 * builtin.EpochsInDay }, nil},
+		{"negative snap deal reuse window", func(c *StorageMiner) { c.Sealing.SnapDealSectorReuseWindow = -1 }, []string{"Sealing.SnapDealSectorReuseWindow"}},
+		{"snap deal reuse window above max extension", func(c *StorageMiner) { c.Sealing.SnapDealSectorReuseWindow = 10 * 365 * builtin.EpochsInDay }, []string{"Sealing.SnapDealSectorReuseWindow"}},
+		{"precommit batch by value without value", func(c *StorageMiner) { c.Sealing.MaxPreCommitBatchByValue = true }, []string{"Sealing.MaxPreCommitBatchFeeValue"}},
+		{"precommit batch by value", func(c *StorageMiner) {
+			c.Sealing.MaxPreCommitBatchByValue = true
+			c.Sealing.MaxPreCommitBatchFeeValue = types.MustParseFIL("0.5")
+		}, nil},
+		{"negative precommit batch fee value", func(c *StorageMiner) { c.Sealing.MaxPreCommitBatchFeeValue = negFIL }, []string{"Sealing.MaxPreCommitBatchFeeValue"}},
+		{"precommit gas multiplier below range", func(c *StorageMiner) { c.Sealing.PreCommitGasMultiplier = 0.9 }, []string{"Sealing.PreCommitGasMultiplier"}},
+		{"precommit gas multiplier above range", func(c *StorageMiner) { c.Sealing.PreCommitGasMultiplier = 2.1 }, []string{"Sealing.PreCommitGasMultiplier"}},
+		{"commit gas multiplier below range", func(c *StorageMiner) { c.Sealing.CommitGasMultiplier = 0.9 }, []string{"Sealing.CommitGasMultiplier"}},
+		{"commit gas multiplier above range", func(c *StorageMiner) { c.Sealing.CommitGasMultiplier = 2.1 }, []string{"Sealing.CommitGasMultiplier"}},
+		{"replica update gas multiplier above range", func(c *StorageMiner) { c.Sealing.ReplicaUpdateGasMultiplier = 2.1 }, []string{"Sealing.ReplicaUpdateGasMultiplier"}},
+		{"zero commit batch", func(c *StorageMiner) {
+			c.Sealing.MinCommitBatch = 0
+			c.Sealing.MaxCommitBatch = 0
+		}, []string{"Sealing.MaxCommitBatch"}},
+		{"commit batch above actor limit", func(c *StorageMiner) { c.Sealing.MaxCommitBatch = 820 }, []string{"Sealing.MaxCommitBatch"}},
+		{"min commit batch above max", func(c *StorageMiner) { c.Sealing.MinCommitBatch = c.Sealing.MaxCommitBatch + 1 }, []string{"Sealing.MinCommitBatch"}},
+		{"commit slack above wait", func(c *StorageMiner) {
+			c.Sealing.CommitBatchSlack = c.Sealing.CommitBatchWait + Duration(time.Second)
+		}, []string{"Sealing.CommitBatchSlack"}},
+		{"negative precommit above base fee", func(c *StorageMiner) { c.Sealing.BatchPreCommitAboveBaseFee = negFIL }, []string{"Sealing.BatchPreCommitAboveBaseFee"}},
+		{"precommit gas target above one", func(c *StorageMiner) { c.Sealing.SectorPreCommitBatchGasTarget = 1.5 }, []string{"Sealing.SectorPreCommitBatchGasTarget"}},
+		{"negative precommit gas target", func(c *StorageMiner) { c.Sealing.SectorPreCommitBatchGasTarget = -0.1 }, []string{"Sealing.SectorPreCommitBatchGasTarget"}},
+		{"aggregate basefee decay ratio above one", func(c *StorageMiner) { c.Sealing.AggregateMinBaseFeeDecayRatio = 1.1 }, []string{"Sealing.AggregateMinBaseFeeDecayRatio"}},
+		{"negative aggregate above base fee", func(c *StorageMiner) { c.Sealing.AggregateAboveBaseFee = negFIL }, []string{"Sealing.AggregateAboveBaseFee"}},
+		{"negative concurrent prove commits", func(c *StorageMiner) { c.Sealing.MaxConcurrentProveCommits = -1 }, []string{"Sealing.MaxConcurrentProveCommits"}},
+		{"negative pre-commits in flight", func(c *StorageMiner) { c.Sealing.MaxPreCommitsInFlight = -1 }, []string{"Sealing.MaxPreCommitsInFlight"}},
+		{"ticket lookahead over finality", func(c *StorageMiner) { c.Sealing.TicketLookaheadEpochs = 901 }, []string{"Sealing.TicketLookaheadEpochs"}},
+		{"commit batch overlap window not below slack", func(c *StorageMiner) { c.Sealing.CommitBatchOverlapWindow = c.Sealing.CommitBatchSlack }, []string{"Sealing.CommitBatchOverlapWindow"}},
+		{"negative finalize sector timeout", func(c *StorageMiner) { c.Sealing.FinalizeSectorTimeout = Duration(-time.Minute) }, []string{"Sealing.FinalizeSectorTimeout"}},
+		{"terminate batch min above max", func(c *StorageMiner) { c.Sealing.TerminateBatchMin = c.Sealing.TerminateBatchMax + 1 }, []string{"Sealing.TerminateBatchMin"}},
+		{"negative terminate batch wait", func(c *StorageMiner) { c.Sealing.TerminateBatchWait = Duration(-time.Second) }, []string{"Sealing.TerminateBatchWait"}},
+		{"sector watcher without topic", func(c *StorageMiner) {
+			c.Sealing.SectorBuildWatcher = true
+			c.Sealing.SectorWatcherTopicName = ""
+		}, []string{"Sealing.SectorWatcherTopicName"}},
+		{"negative fetch limit", func(c *StorageMiner) { c.Storage.ParallelFetchLimit = -1 }, []string{"Storage.ParallelFetchLimit"}},
+		{"negative gpu temperature limit", func(c *StorageMiner) { c.Storage.GPUTemperatureLimit = -1 }, []string{"Storage.GPUTemperatureLimit"}},
+		{"gpu temperature limit without poll interval", func(c *StorageMiner) {
+			c.Storage.GPUTemperatureLimit = 90
+			c.Storage.GPUTemperaturePollInterval = 0
+		}, []string{"Storage.GPUTemperaturePollInterval"}},
+		{"negative fees", func(c *StorageMiner) {
+			c.Fees.MaxPreCommitGasFee = negFIL
+			c.Fees.MaxCommitGasFee = negFIL
+			c.Fees.MaxPreCommitBatchGasFee.Base = negFIL
+			c.Fees.MaxPreCommitBatchGasFee.PerSector = negFIL
+			c.Fees.MaxCommitBatchGasFee.Base = negFIL
+			c.Fees.MaxCommitBatchGasFee.PerSector = negFIL
+			c.Fees.MaxPreCommitBatchGasFee.MaxTotalFee = negFIL
+			c.Fees.MaxCommitBatchGasFee.MaxTotalFee = negFIL
+			c.Fees.MaxTerminateGasFee = negFIL
+			c.Fees.MaxWindowPoStGasFee = negFIL
+			c.Fees.MaxPublishDealsFee = negFIL
+			c.Fees.MaxMarketBalanceAddFee = negFIL
+		}, []string{
+			"Fees.MaxPreCommitGasFee",
+			"Fees.MaxCommitGasFee",
+			"Fees.MaxPreCommitBatchGasFee.Base",
+			"Fees.MaxPreCommitBatchGasFee.PerSector",
+			"Fees.MaxCommitBatchGasFee.Base",
+			"Fees.MaxCommitBatchGasFee.PerSector",
+			"Fees.MaxPreCommitBatchGasFee.MaxTotalFee",
+			"Fees.MaxCommitBatchGasFee.MaxTotalFee",
+			"Fees.MaxTerminateGasFee",
+			"Fees.MaxWindowPoStGasFee",
+			"Fees.MaxPublishDealsFee",
+			"Fees.MaxMarketBalanceAddFee",
+		}},
+		{"negative dagstore limits", func(c *StorageMiner) {
+			c.DAGStore.MaxConcurrentIndex = -1
+			c.DAGStore.MaxConcurrentReadyFetches = -1
+			c.DAGStore.MaxConcurrentUnseals = -1
+			c.DAGStore.MaxConcurrencyStorageCalls = -1
+			c.DAGStore.MaxConcurrentRetrievals = -1
+			c.DAGStore.GCInterval = Duration(-time.Second)
+			c.DAGStore.TransientGCInterval = Duration(-time.Second)
+			c.DAGStore.IndexCompactionInterval = Duration(-time.Second)
+			c.DAGStore.PieceStoreFlushInterval = Duration(-time.Second)
+		}, []string{
+			"DAGStore.MaxConcurrentIndex",
+			"DAGStore.MaxConcurrentReadyFetches",
+			"DAGStore.MaxConcurrentUnseals",
+			"DAGStore.MaxConcurrencyStorageCalls",
+			"DAGStore.MaxConcurrentRetrievals",
+			"DAGStore.GCInterval",
+			"DAGStore.TransientGCInterval",
+			"DAGStore.IndexCompactionInterval",
+			"DAGStore.PieceStoreFlushInterval",
+		}},
+		{"unknown transient eviction policy", func(c *StorageMiner) { c.DAGStore.TransientEvictionPolicy = "random" }, []string{"DAGStore.TransientEvictionPolicy"}},
+		{"negative index provider heartbeat", func(c *StorageMiner) { c.IndexProvider.GossipSubHeartbeatInterval = Duration(-time.Second) }, []string{"IndexProvider.GossipSubHeartbeatInterval"}},
+		{"lru transient eviction", func(c *StorageMiner) {
+			c.DAGStore.TransientStoreSizeCap = 1 << 40
+			c.DAGStore.TransientEvictionPolicy = TransientEvictionLRU
+		}, nil},
+		{"negative shard recovery retries", func(c *StorageMiner) { c.DAGStore.RecoveryRetries = -1 }, []string{"DAGStore.RecoveryRetries"}},
+		{"shard recovery without interval", func(c *StorageMiner) { c.DAGStore.RecoveryInterval = 0 }, []string{"DAGStore.RecoveryInterval"}},
+		{"shard recovery disabled", func(c *StorageMiner) {
+			c.DAGStore.RecoveryRetries = 0
+			c.DAGStore.RecoveryInterval = 0
+		}, nil},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := DefaultStorageMiner()
+			tc.modify(c)
+			requireFieldErrors(t, c.Validate(), tc.fields...)
+		})
+	}
+}
@@ -192,10 +192,8 @@ func (a *API) dealStarter(ctx context.Context, params *api.StartDealParams, isSt
 		dealStart = ts.Height() + abi.ChainEpoch(dealStartBufferHours*blocksPerHour) // TODO: Get this from storage ask
 	}
 
-	networkVersion, err := a.StateNetworkVersion(ctx, types.EmptyTSK)
-	if err != nil {
-		return nil, xerrors.Errorf("failed to get network version: %w", err)
-	}
+	// the version of the upgrade schedule, which NetworkVersionOverride doesn't change
+	networkVersion := a.StateAPI.StateManager.GetNetworkVersion(ctx, a.Chain.GetHeaviestTipSet().Height())
 
 	st, err := miner.PreferredSealProofTypeFromWindowPoStType(networkVersion, mi.WindowPoStProofType, false)
 	if err != nil {
@@ -38,6 +38,14 @@ func (e *EthModuleDummy) EthAccounts(ctx context.Context) ([]ethtypes.EthAddress
 	return nil, ErrModuleDisabled
 }
 
+func (e *EthModuleDummy) EthSign(ctx context.Context, sender ethtypes.EthAddress, data ethtypes.EthBytes) (ethtypes.EthBytes, error) {
+	return nil, ErrModuleDisabled
+}
+
+func (e *EthModuleDummy) EthSignTypedData(ctx context.Context, sender ethtypes.EthAddress, data ethtypes.EthTypedData) (ethtypes.EthBytes, error) {
+	return nil, ErrModuleDisabled
+}
+
 func (e *EthModuleDummy) EthGetBlockTransactionCountByNumber(ctx context.Context, blkNum ethtypes.EthUint64) (ethtypes.EthUint64, error) {
 	return 0, ErrModuleDisabled
 }
@@ -188,3 +196,4 @@ func (e *EthModuleDummy) EthTraceReplayBlockTransactions(ctx context.Context, bl
 
 var _ EthModuleAPI = &EthModuleDummy{}
 var _ EthEventAPI = &EthModuleDummy{}
+var _ EthWalletAPI = &EthModuleDummy{}
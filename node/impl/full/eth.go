@@ -18,11 +18,13 @@ import (
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/builtin"
 	builtintypes "github.com/filecoin-project/go-state-types/builtin"
 	"github.com/filecoin-project/go-state-types/builtin/v10/evm"
+	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/go-state-types/exitcode"
 
 	"github.com/filecoin-project/lotus/api"
@@ -87,9 +89,16 @@ type EthEventAPI interface {
 	EthUnsubscribe(ctx context.Context, id ethtypes.EthSubscriptionID) (bool, error)
 }
 
+// EthWalletAPI signs with the Ethereum accounts in the node wallet. It's not served by the gateway.
+type EthWalletAPI interface {
+	EthSign(ctx context.Context, sender ethtypes.EthAddress, data ethtypes.EthBytes) (ethtypes.EthBytes, error)
+	EthSignTypedData(ctx context.Context, sender ethtypes.EthAddress, data ethtypes.EthTypedData) (ethtypes.EthBytes, error)
+}
+
 var (
 	_ EthModuleAPI = *new(api.FullNode)
 	_ EthEventAPI  = *new(api.FullNode)
+	_ EthWalletAPI = *new(api.FullNode)
 
 	_ EthModuleAPI = *new(api.Gateway)
 )
@@ -127,6 +136,30 @@ type EthModule struct {
 	StateManager     *stmgr.StateManager
 	EthTxHashManager *EthTxHashManager
 
+	// BlockTransactionCountMax caps the counts returned by eth_getBlockTransactionCountBy*; 0 = no cap
+	BlockTransactionCountMax int
+	// FilecoinSyncStatus makes eth_syncing report the state of the sync workers instead of
+	// comparing the chain head with the current epoch
+	FilecoinSyncStatus bool
+	// MaxCodeSize is the maximum contract bytecode size; contract creation transactions with
+	// init code larger than twice this size are rejected. 0 = no limit
+	MaxCodeSize int
+	// EnableTypedTransactions accepts EIP-2718 typed transaction envelopes at eth_sendRawTransaction
+	EnableTypedTransactions bool
+	// BlockNumberLag is the number of epochs eth_blockNumber lags behind the latest executed tipset
+	BlockNumberLag abi.ChainEpoch
+	// CallCache caches eth_call results; nil disables caching
+	CallCache *EthCallCache
+	// AddressMapping selects how actor addresses are presented, and whether calls from actors
+	// without an Ethereum address are accepted
+	AddressMapping EthAddressMapping
+	// Wallet serves eth_accounts and signs for eth_sign and eth_signTypedData_v4; nil disables
+	// the wallet methods
+	Wallet api.Wallet
+	// CallWhitelist restricts eth_call to calls to these contract addresses; empty allows
+	// calls to any address
+	CallWhitelist map[ethtypes.EthAddress]struct{}
+
 	ChainAPI
 	MpoolAPI
 	StateAPI
@@ -134,6 +167,7 @@ type EthModule struct {
 }
 
 var _ EthModuleAPI = (*EthModule)(nil)
+var _ EthWalletAPI = (*EthModule)(nil)
 
 type EthEvent struct {
 	Chain                *store.ChainStore
@@ -143,6 +177,7 @@ type EthEvent struct {
 	FilterStore          filter.FilterStore
 	SubManager           *EthSubscriptionManager
 	MaxFilterHeightRange abi.ChainEpoch
+	GetLogsMaxResults    int // maximum number of logs returned by eth_getLogs, 0 is unlimited
 	SubscribtionCtx      context.Context
 }
 
@@ -155,10 +190,13 @@ type EthAPI struct {
 
 	EthModuleAPI
 	EthEventAPI
+	EthWalletAPI
 }
 
 var ErrNullRound = errors.New("requested epoch was a null round")
 
+var ErrEthWalletDisabled = errors.New("eth wallet methods disabled, enable with Fevm.EnableEthWalletMethods")
+
 func (a *EthModule) StateNetworkName(ctx context.Context) (dtypes.NetworkName, error) {
 	return stmgr.GetNetworkName(ctx, a.StateManager, a.Chain.GetHeaviestTipSet().ParentState())
 }
@@ -180,12 +218,99 @@ func (a *EthModule) EthBlockNumber(ctx context.Context) (ethtypes.EthUint64, err
 	if err != nil {
 		return 0, err
 	}
+
+	if a.BlockNumberLag > 0 {
+		height := parent.Height() - a.BlockNumberLag
+		if height < 0 {
+			height = 0
+		}
+		// report the last non-null tipset at or below the lagged height
+		lagged, err := a.Chain.GetTipsetByHeight(ctx, height, parent, true)
+		if err != nil {
+			return 0, err
+		}
+		parent = lagged
+	}
+
 	return ethtypes.EthUint64(parent.Height()), nil
 }
 
-func (a *EthModule) EthAccounts(context.Context) ([]ethtypes.EthAddress, error) {
-	// The lotus node is not expected to hold manage accounts, so we'll always return an empty array
-	return []ethtypes.EthAddress{}, nil
+func (a *EthModule) EthAccounts(ctx context.Context) ([]ethtypes.EthAddress, error) {
+	// The lotus node is not expected to manage accounts, so unless the wallet methods are enabled,
+	// and the caller could use them, we'll always return an empty array
+	if a.Wallet == nil || !auth.HasPerm(ctx, nil, api.PermAdmin) {
+		return []ethtypes.EthAddress{}, nil
+	}
+
+	addrs, err := a.Wallet.WalletList(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("listing wallet addresses: %w", err)
+	}
+
+	accounts := []ethtypes.EthAddress{}
+	for _, addr := range addrs {
+		if addr.Protocol() != address.Delegated {
+			continue
+		}
+		ea, err := ethtypes.EthAddressFromFilecoinAddress(addr)
+		if err != nil {
+			continue
+		}
+		accounts = append(accounts, ea)
+	}
+	return accounts, nil
+}
+
+func (a *EthModule) EthSign(ctx context.Context, sender ethtypes.EthAddress, data ethtypes.EthBytes) (ethtypes.EthBytes, error) {
+	// EIP-191 personal message
+	msg := append([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(data))), data...)
+	return a.ethWalletSign(ctx, sender, msg)
+}
+
+func (a *EthModule) EthSignTypedData(ctx context.Context, sender ethtypes.EthAddress, data ethtypes.EthTypedData) (ethtypes.EthBytes, error) {
+	msg, err := data.SigningPayload()
+	if err != nil {
+		return nil, xerrors.Errorf("encoding typed data: %w", err)
+	}
+	return a.ethWalletSign(ctx, sender, msg)
+}
+
+// ethWalletSign signs the keccak256 hash of msg with the wallet key of sender, and returns the
+// signature in the Ethereum [r || s || v] format with v = 27 or 28.
+func (a *EthModule) ethWalletSign(ctx context.Context, sender ethtypes.EthAddress, msg []byte) (ethtypes.EthBytes, error) {
+	if a.Wallet == nil {
+		return nil, ErrEthWalletDisabled
+	}
+
+	addr, err := sender.ToFilecoinAddress()
+	if err != nil {
+		return nil, xerrors.Errorf("converting sender address: %w", err)
+	}
+	if addr.Protocol() != address.Delegated {
+		return nil, xerrors.Errorf("sender %s is not an Ethereum account", sender)
+	}
+
+	has, err := a.Wallet.WalletHas(ctx, addr)
+	if err != nil {
+		return nil, xerrors.Errorf("checking wallet for %s: %w", addr, err)
+	}
+	if !has {
+		return nil, xerrors.Errorf("sender %s is not in the wallet", sender)
+	}
+
+	// the delegated signer hashes the message with keccak256 before signing
+	sig, err := a.Wallet.WalletSign(ctx, addr, msg, api.MsgMeta{Type: api.MTUnknown})
+	if err != nil {
+		return nil, xerrors.Errorf("signing: %w", err)
+	}
+	if sig.Type != crypto.SigTypeDelegated || len(sig.Data) != 65 {
+		return nil, xerrors.Errorf("unexpected signature type %d with length %d", sig.Type, len(sig.Data))
+	}
+
+	out := make(ethtypes.EthBytes, 65)
+	copy(out, sig.Data)
+	out[64] += 27
+	return out, nil
 }
 
 func (a *EthAPI) EthAddressToFilecoinAddress(ctx context.Context, ethAddress ethtypes.EthAddress) (address.Address, error) {
@@ -207,6 +332,11 @@ func (a *EthModule) countTipsetMsgs(ctx context.Context, ts *types.TipSet) (int,
 		// TODO: may need to run canonical ordering and deduplication here
 		count += len(blkMsg.BlsMessages) + len(blkMsg.SecpkMessages)
 	}
+
+	if a.BlockTransactionCountMax > 0 && count > a.BlockTransactionCountMax {
+		log.Debugw("capping block transaction count", "tipset", ts.Key(), "count", count, "max", a.BlockTransactionCountMax)
+		count = a.BlockTransactionCountMax
+	}
 	return count, nil
 }
 
@@ -234,7 +364,7 @@ func (a *EthModule) EthGetBlockByHash(ctx context.Context, blkHash ethtypes.EthH
 	if err != nil {
 		return ethtypes.EthBlock{}, xerrors.Errorf("error loading tipset %s: %w", ts, err)
 	}
-	return newEthBlockFromFilecoinTipSet(ctx, ts, fullTxInfo, a.Chain, a.StateAPI)
+	return newEthBlockFromFilecoinTipSet(ctx, ts, fullTxInfo, a.Chain, a.StateAPI, a.AddressMapping)
 }
 
 func (a *EthModule) EthGetBlockByNumber(ctx context.Context, blkParam string, fullTxInfo bool) (ethtypes.EthBlock, error) {
@@ -242,7 +372,7 @@ func (a *EthModule) EthGetBlockByNumber(ctx context.Context, blkParam string, fu
 	if err != nil {
 		return ethtypes.EthBlock{}, err
 	}
-	return newEthBlockFromFilecoinTipSet(ctx, ts, fullTxInfo, a.Chain, a.StateAPI)
+	return newEthBlockFromFilecoinTipSet(ctx, ts, fullTxInfo, a.Chain, a.StateAPI, a.AddressMapping)
 }
 
 func (a *EthModule) EthGetTransactionByHash(ctx context.Context, txHash *ethtypes.EthHash) (*ethtypes.EthTx, error) {
@@ -256,6 +386,11 @@ func (a *EthModule) EthGetTransactionByHashLimited(ctx context.Context, txHash *
 		return nil, nil
 	}
 
+	cache := a.EthTxHashManager.TxLookupCache
+	if tx, ok := cache.lookup(ctx, *txHash, limit, a.Chain, a.StateAPI, a.AddressMapping); ok {
+		return tx, nil
+	}
+
 	c, err := a.EthTxHashManager.TransactionHashLookup.GetCidFromHash(*txHash)
 	if err != nil {
 		log.Debug("could not find transaction hash %s in lookup table", txHash.String())
@@ -269,8 +404,11 @@ func (a *EthModule) EthGetTransactionByHashLimited(ctx context.Context, txHash *
 	// first, try to get the cid from mined transactions
 	msgLookup, err := a.StateAPI.StateSearchMsg(ctx, types.EmptyTSK, c, limit, true)
 	if err == nil && msgLookup != nil {
-		tx, err := newEthTxFromMessageLookup(ctx, msgLookup, -1, a.Chain, a.StateAPI)
+		tx, err := newEthTxFromMessageLookup(ctx, msgLookup, -1, a.Chain, a.StateAPI, a.AddressMapping)
 		if err == nil {
+			if ts, err := a.Chain.LoadTipSet(ctx, msgLookup.TipSet); err == nil && tx.TransactionIndex != nil {
+				cache.Add(*txHash, ts.Parents(), msgLookup.Message, int(*tx.TransactionIndex))
+			}
 			return &tx, nil
 		}
 	}
@@ -285,7 +423,7 @@ func (a *EthModule) EthGetTransactionByHashLimited(ctx context.Context, txHash *
 
 	for _, p := range pending {
 		if p.Cid() == c {
-			tx, err := newEthTxFromSignedMessage(ctx, p, a.StateAPI)
+			tx, err := newEthTxFromSignedMessage(ctx, p, a.StateAPI, a.AddressMapping)
 			if err != nil {
 				return nil, fmt.Errorf("could not convert Filecoin message into tx: %s", err)
 			}
@@ -344,6 +482,10 @@ func (a *EthModule) EthGetTransactionHashByCid(ctx context.Context, cid cid.Cid)
 }
 
 func (a *EthModule) EthGetTransactionCount(ctx context.Context, sender ethtypes.EthAddress, blkParam ethtypes.EthBlockNumberOrHash) (ethtypes.EthUint64, error) {
+	if err := a.checkEthAddress(sender); err != nil {
+		return ethtypes.EthUint64(0), err
+	}
+
 	addr, err := sender.ToFilecoinAddress()
 	if err != nil {
 		return ethtypes.EthUint64(0), nil
@@ -406,7 +548,7 @@ func (a *EthModule) EthGetTransactionReceiptLimited(ctx context.Context, txHash
 		return nil, nil
 	}
 
-	tx, err := newEthTxFromMessageLookup(ctx, msgLookup, -1, a.Chain, a.StateAPI)
+	tx, err := newEthTxFromMessageLookup(ctx, msgLookup, -1, a.Chain, a.StateAPI, a.AddressMapping)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to convert %s into an Eth Txn: %w", txHash, err)
 	}
@@ -419,7 +561,7 @@ func (a *EthModule) EthGetTransactionReceiptLimited(ctx context.Context, txHash
 		}
 	}
 
-	receipt, err := newEthTxReceipt(ctx, tx, msgLookup, events, a.Chain, a.StateAPI)
+	receipt, err := newEthTxReceipt(ctx, tx, msgLookup, events, a.Chain, a.StateAPI, a.AddressMapping)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to convert %s into an Eth Receipt: %w", txHash, err)
 	}
@@ -437,6 +579,10 @@ func (a *EthAPI) EthGetTransactionByBlockNumberAndIndex(context.Context, ethtype
 
 // EthGetCode returns string value of the compiled bytecode
 func (a *EthModule) EthGetCode(ctx context.Context, ethAddr ethtypes.EthAddress, blkParam ethtypes.EthBlockNumberOrHash) (ethtypes.EthBytes, error) {
+	if err := a.checkEthAddress(ethAddr); err != nil {
+		return nil, err
+	}
+
 	to, err := ethAddr.ToFilecoinAddress()
 	if err != nil {
 		return nil, xerrors.Errorf("cannot get Filecoin address: %w", err)
@@ -521,6 +667,10 @@ func (a *EthModule) EthGetCode(ctx context.Context, ethAddr ethtypes.EthAddress,
 }
 
 func (a *EthModule) EthGetStorageAt(ctx context.Context, ethAddr ethtypes.EthAddress, position ethtypes.EthBytes, blkParam ethtypes.EthBlockNumberOrHash) (ethtypes.EthBytes, error) {
+	if err := a.checkEthAddress(ethAddr); err != nil {
+		return nil, err
+	}
+
 	ts, err := getTipsetByEthBlockNumberOrHash(ctx, a.Chain, blkParam)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to process block param: %v; %w", blkParam, err)
@@ -557,6 +707,11 @@ func (a *EthModule) EthGetStorageAt(ctx context.Context, ethAddr ethtypes.EthAdd
 		return ethtypes.EthBytes(make([]byte, 32)), nil
 	}
 
+	// The EVM actor keeps contract storage in a KAMT rooted at ContractState in its state.
+	// KAMT keys are the 32-byte big-endian encoding of the EVM storage slot (the zero-padded
+	// position above), used as-is without hashing, and values are the slot contents with
+	// leading zero bytes trimmed. Rather than walking the KAMT here, we ask the actor to
+	// resolve the slot through its GetStorageAt method, which reads the same structure.
 	params, err := actors.SerializeParams(&evm.GetStorageAtParams{
 		StorageKey: *(*[32]byte)(position),
 	})
@@ -612,6 +767,10 @@ func (a *EthModule) EthGetStorageAt(ctx context.Context, ethAddr ethtypes.EthAdd
 }
 
 func (a *EthModule) EthGetBalance(ctx context.Context, address ethtypes.EthAddress, blkParam ethtypes.EthBlockNumberOrHash) (ethtypes.EthBigInt, error) {
+	if err := a.checkEthAddress(address); err != nil {
+		return ethtypes.EthBigInt{}, err
+	}
+
 	filAddr, err := address.ToFilecoinAddress()
 	if err != nil {
 		return ethtypes.EthBigInt{}, err
@@ -647,6 +806,14 @@ func (a *EthModule) EthSyncing(ctx context.Context) (ethtypes.EthSyncingResult,
 		return ethtypes.EthSyncingResult{}, fmt.Errorf("failed calling SyncState: %w", err)
 	}
 
+	if a.FilecoinSyncStatus {
+		return filecoinSyncStatus(state)
+	}
+	return ethereumSyncStatus(state, a.Chain.GetHeaviestTipSet(), build.Clock.Now()), nil
+}
+
+// filecoinSyncStatus reports the progress of the most recent non-idle sync worker.
+func filecoinSyncStatus(state *api.SyncState) (ethtypes.EthSyncingResult, error) {
 	if len(state.ActiveSyncs) == 0 {
 		return ethtypes.EthSyncingResult{}, errors.New("no active syncs, try again")
 	}
@@ -677,6 +844,35 @@ func (a *EthModule) EthSyncing(ctx context.Context) (ethtypes.EthSyncingResult,
 	return res, nil
 }
 
+// ethereumSyncStatus reports the node as synced once its head is less than an epoch
+// old, like `lotus sync wait`. While catching up, the highest block is the epoch
+// expected at the current time, or the highest sync target if that is further.
+func ethereumSyncStatus(state *api.SyncState, head *types.TipSet, now time.Time) ethtypes.EthSyncingResult {
+	lag := now.Unix() - int64(head.MinTimestamp())
+	if lag < int64(build.BlockDelaySecs) {
+		return ethtypes.EthSyncingResult{DoneSync: true}
+	}
+
+	res := ethtypes.EthSyncingResult{
+		StartingBlock: ethtypes.EthUint64(head.Height()),
+		CurrentBlock:  ethtypes.EthUint64(head.Height()),
+		HighestBlock:  ethtypes.EthUint64(head.Height()) + ethtypes.EthUint64(lag/int64(build.BlockDelaySecs)),
+	}
+	for _, ss := range state.ActiveSyncs {
+		if ss.Stage == api.StageIdle || ss.Base == nil || ss.Target == nil {
+			continue
+		}
+		if h := ethtypes.EthUint64(ss.Base.Height()); h < res.StartingBlock {
+			res.StartingBlock = h
+		}
+		if h := ethtypes.EthUint64(ss.Target.Height()); h > res.HighestBlock {
+			res.HighestBlock = h
+		}
+	}
+
+	return res
+}
+
 func (a *EthModule) EthFeeHistory(ctx context.Context, p jsonrpc.RawParams) (ethtypes.EthFeeHistory, error) {
 	params, err := jsonrpc.DecodeParams[ethtypes.EthFeeHistoryParams](p)
 	if err != nil {
@@ -808,11 +1004,19 @@ func (a *EthModule) EthGasPrice(ctx context.Context) (ethtypes.EthBigInt, error)
 }
 
 func (a *EthModule) EthSendRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (ethtypes.EthHash, error) {
+	if err := checkTxType(rawTx, a.EnableTypedTransactions); err != nil {
+		return ethtypes.EmptyEthHash, err
+	}
+
 	txArgs, err := ethtypes.ParseEthTxArgs(rawTx)
 	if err != nil {
 		return ethtypes.EmptyEthHash, err
 	}
 
+	if err := checkInitCodeSize(txArgs, a.MaxCodeSize); err != nil {
+		return ethtypes.EmptyEthHash, err
+	}
+
 	smsg, err := txArgs.ToSignedMessage()
 	if err != nil {
 		return ethtypes.EmptyEthHash, err
@@ -826,6 +1030,31 @@ func (a *EthModule) EthSendRawTransaction(ctx context.Context, rawTx ethtypes.Et
 	return ethtypes.EthHashFromTxBytes(rawTx), nil
 }
 
+// checkTxType rejects EIP-2718 typed transaction envelopes (EIP-2930 and
+// EIP-1559) unless they are enabled. The error is returned unwrapped so that
+// RPC clients receive the invalid params code.
+func checkTxType(rawTx []byte, enableTyped bool) error {
+	if enableTyped || len(rawTx) == 0 {
+		return nil
+	}
+	if rawTx[0] == 0x01 || rawTx[0] == ethtypes.Eip1559TxType {
+		return &api.ErrInvalidParams{Message: fmt.Sprintf("typed transactions (type %d) are disabled on this node", rawTx[0])}
+	}
+	return nil
+}
+
+// checkInitCodeSize rejects contract creation transactions whose init code
+// exceeds the EIP-3860 limit of twice the maximum code size.
+func checkInitCodeSize(tx *ethtypes.EthTxArgs, maxCodeSize int) error {
+	if tx.To != nil || maxCodeSize <= 0 {
+		return nil
+	}
+	if len(tx.Input) > 2*maxCodeSize {
+		return xerrors.Errorf("contract init code size %d exceeds the limit of %d bytes", len(tx.Input), 2*maxCodeSize)
+	}
+	return nil
+}
+
 func (a *EthModule) Web3ClientVersion(ctx context.Context) (string, error) {
 	return build.UserVersion(), nil
 }
@@ -886,7 +1115,7 @@ func (a *EthModule) EthTraceBlock(ctx context.Context, blkNum string) ([]*ethtyp
 		}
 
 		traces := []*ethtypes.EthTrace{}
-		err = buildTraces(ctx, &traces, nil, []int{}, ir.ExecutionTrace, int64(ts.Height()), a.StateAPI)
+		err = buildTraces(ctx, &traces, nil, []int{}, ir.ExecutionTrace, int64(ts.Height()), a.StateAPI, a.AddressMapping)
 		if err != nil {
 			return nil, xerrors.Errorf("failed building traces: %w", err)
 		}
@@ -960,7 +1189,7 @@ func (a *EthModule) EthTraceReplayBlockTransactions(ctx context.Context, blkNum
 			VmTrace:         nil,
 		}
 
-		err = buildTraces(ctx, &t.Trace, nil, []int{}, ir.ExecutionTrace, int64(ts.Height()), a.StateAPI)
+		err = buildTraces(ctx, &t.Trace, nil, []int{}, ir.ExecutionTrace, int64(ts.Height()), a.StateAPI, a.AddressMapping)
 		if err != nil {
 			return nil, xerrors.Errorf("failed building traces: %w", err)
 		}
@@ -1004,6 +1233,10 @@ func (a *EthModule) applyMessage(ctx context.Context, msg *types.Message, tsk ty
 }
 
 func (a *EthModule) EthEstimateGas(ctx context.Context, tx ethtypes.EthCall) (ethtypes.EthUint64, error) {
+	if err := a.checkEthCall(tx); err != nil {
+		return ethtypes.EthUint64(0), err
+	}
+
 	msg, err := ethCallToFilecoinMessage(ctx, tx)
 	if err != nil {
 		return ethtypes.EthUint64(0), err
@@ -1162,6 +1395,13 @@ func ethGasSearch(
 }
 
 func (a *EthModule) EthCall(ctx context.Context, tx ethtypes.EthCall, blkParam ethtypes.EthBlockNumberOrHash) (ethtypes.EthBytes, error) {
+	if err := a.checkCallWhitelist(tx); err != nil {
+		return nil, err
+	}
+	if err := a.checkEthCall(tx); err != nil {
+		return nil, err
+	}
+
 	msg, err := ethCallToFilecoinMessage(ctx, tx)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to convert ethcall to filecoin message: %w", err)
@@ -1172,19 +1412,26 @@ func (a *EthModule) EthCall(ctx context.Context, tx ethtypes.EthCall, blkParam e
 		return nil, xerrors.Errorf("failed to process block param: %v; %w", blkParam, err)
 	}
 
+	if res, ok := a.CallCache.get(ts.Key(), tx); ok {
+		return res, nil
+	}
+
 	invokeResult, err := a.applyMessage(ctx, msg, ts.Key())
 	if err != nil {
 		return nil, err
 	}
 
-	if msg.To == builtintypes.EthereumAddressManagerActorAddr {
-		// As far as I can tell, the Eth API always returns empty on contract deployment
-		return ethtypes.EthBytes{}, nil
-	} else if len(invokeResult.MsgRct.Return) > 0 {
-		return cbg.ReadByteArray(bytes.NewReader(invokeResult.MsgRct.Return), uint64(len(invokeResult.MsgRct.Return)))
+	res := ethtypes.EthBytes{}
+	// As far as I can tell, the Eth API always returns empty on contract deployment
+	if msg.To != builtintypes.EthereumAddressManagerActorAddr && len(invokeResult.MsgRct.Return) > 0 {
+		res, err = cbg.ReadByteArray(bytes.NewReader(invokeResult.MsgRct.Return), uint64(len(invokeResult.MsgRct.Return)))
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return ethtypes.EthBytes{}, nil
+	a.CallCache.add(ts.Key(), tx, res)
+	return res, nil
 }
 
 func (e *EthEvent) EthGetLogs(ctx context.Context, filterSpec *ethtypes.EthFilterSpec) (*ethtypes.EthFilterResult, error) {
@@ -1192,8 +1439,13 @@ func (e *EthEvent) EthGetLogs(ctx context.Context, filterSpec *ethtypes.EthFilte
 		return nil, api.ErrNotSupported
 	}
 
-	// Create a temporary filter
-	f, err := e.installEthFilterSpec(ctx, filterSpec)
+	// Create a temporary filter, collecting one more event than the limit to
+	// tell whether the limit was exceeded
+	maxResults := e.GetLogsMaxResults
+	if maxResults > 0 {
+		maxResults++
+	}
+	f, err := e.installEthFilterSpec(ctx, filterSpec, maxResults)
 	if err != nil {
 		return nil, err
 	}
@@ -1201,9 +1453,23 @@ func (e *EthEvent) EthGetLogs(ctx context.Context, filterSpec *ethtypes.EthFilte
 
 	_ = e.uninstallFilter(ctx, f)
 
+	if err := checkGetLogsResults(len(ces), e.GetLogsMaxResults); err != nil {
+		return nil, err
+	}
+
 	return ethFilterResultFromEvents(ces, e.SubManager.StateAPI)
 }
 
+// checkGetLogsResults rejects eth_getLogs calls matching more than maxResults
+// events. The error is returned unwrapped so that RPC clients receive the
+// limit exceeded code.
+func checkGetLogsResults(n, maxResults int) error {
+	if maxResults > 0 && n > maxResults {
+		return &api.ErrLimitExceeded{Message: fmt.Sprintf("query returned more than %d results, narrow the block range", maxResults)}
+	}
+	return nil
+}
+
 func (e *EthEvent) EthGetFilterChanges(ctx context.Context, id ethtypes.EthFilterID) (*ethtypes.EthFilterResult, error) {
 	if e.FilterStore == nil {
 		return nil, api.ErrNotSupported
@@ -1244,7 +1510,9 @@ func (e *EthEvent) EthGetFilterLogs(ctx context.Context, id ethtypes.EthFilterID
 	return nil, xerrors.Errorf("wrong filter type")
 }
 
-func (e *EthEvent) installEthFilterSpec(ctx context.Context, filterSpec *ethtypes.EthFilterSpec) (*filter.EventFilter, error) {
+// installEthFilterSpec installs an event filter collecting at most maxResults
+// events, 0 is unlimited.
+func (e *EthEvent) installEthFilterSpec(ctx context.Context, filterSpec *ethtypes.EthFilterSpec, maxResults int) (*filter.EventFilter, error) {
 	var (
 		minHeight abi.ChainEpoch
 		maxHeight abi.ChainEpoch
@@ -1334,7 +1602,7 @@ func (e *EthEvent) installEthFilterSpec(ctx context.Context, filterSpec *ethtype
 		return nil, err
 	}
 
-	return e.EventFilterManager.Install(ctx, minHeight, maxHeight, tipsetCid, addresses, keys)
+	return e.EventFilterManager.InstallWithMaxResults(ctx, maxResults, minHeight, maxHeight, tipsetCid, addresses, keys)
 }
 
 func (e *EthEvent) EthNewFilter(ctx context.Context, filterSpec *ethtypes.EthFilterSpec) (ethtypes.EthFilterID, error) {
@@ -1342,7 +1610,7 @@ func (e *EthEvent) EthNewFilter(ctx context.Context, filterSpec *ethtypes.EthFil
 		return ethtypes.EthFilterID{}, api.ErrNotSupported
 	}
 
-	f, err := e.installEthFilterSpec(ctx, filterSpec)
+	f, err := e.installEthFilterSpec(ctx, filterSpec, e.EventFilterManager.MaxFilterResults)
 	if err != nil {
 		return ethtypes.EthFilterID{}, err
 	}
@@ -1614,3 +1882,41 @@ func (g gasRewardSorter) Swap(i, j int) {
 func (g gasRewardSorter) Less(i, j int) bool {
 	return g[i].premium.Int.Cmp(g[j].premium.Int) == -1
 }
+
+// checkEthAddress rejects masked ID addresses, which don't belong to Ethereum
+// accounts or contracts, with EthAddressStrict.
+func (a *EthModule) checkEthAddress(addr ethtypes.EthAddress) error {
+	if a.AddressMapping == EthAddressStrict && addr.IsMaskedID() {
+		return xerrors.Errorf("%s is an ID address; only Ethereum addresses are accepted", addr)
+	}
+	return nil
+}
+
+// checkCallWhitelist rejects calls to contracts which aren't on the eth_call whitelist, as well as
+// contract deployments, when a whitelist is set.
+func (a *EthModule) checkCallWhitelist(tx ethtypes.EthCall) error {
+	if len(a.CallWhitelist) == 0 {
+		return nil
+	}
+	if tx.To != nil {
+		if _, ok := a.CallWhitelist[*tx.To]; ok {
+			return nil
+		}
+	}
+	return &api.ErrMethodNotFound{Message: "eth_call is only served for whitelisted contracts on this node"}
+}
+
+// checkEthCall rejects calls without a sender, which are sent from the system
+// actor, and calls to masked ID addresses with EthAddressStrict.
+func (a *EthModule) checkEthCall(tx ethtypes.EthCall) error {
+	if a.AddressMapping != EthAddressStrict {
+		return nil
+	}
+	if tx.From == nil || *tx.From == (ethtypes.EthAddress{}) {
+		return xerrors.Errorf("calls without a sender aren't accepted; only Ethereum addresses are accepted")
+	}
+	if tx.To != nil {
+		return a.checkEthAddress(*tx.To)
+	}
+	return nil
+}
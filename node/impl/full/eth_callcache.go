@@ -0,0 +1,71 @@
+package full
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+// ethCallKey identifies an eth_call by its arguments and the tipset it was
+// executed on.
+type ethCallKey struct {
+	TipSet types.TipSetKey
+	// Call is the JSON encoding of the call
+	Call string
+}
+
+// EthCallCache is an LRU cache of eth_call results. Executing a call with the
+// same arguments on the same tipset always returns the same result, so results
+// are keyed by both. Failed calls aren't cached. A nil *EthCallCache caches
+// nothing.
+type EthCallCache struct {
+	cache *expirable.LRU[ethCallKey, *ethtypes.EthBytes]
+}
+
+// NewEthCallCache returns a cache holding up to size results, each for at most
+// ttl, or nil if size is not positive. Results don't expire if ttl is 0.
+func NewEthCallCache(size int, ttl time.Duration) *EthCallCache {
+	if size <= 0 {
+		return nil
+	}
+	return &EthCallCache{cache: expirable.NewLRU[ethCallKey, *ethtypes.EthBytes](size, nil, ttl)}
+}
+
+func ethCallCacheKey(ts types.TipSetKey, tx ethtypes.EthCall) (ethCallKey, bool) {
+	call, err := json.Marshal(tx)
+	if err != nil {
+		return ethCallKey{}, false
+	}
+	return ethCallKey{TipSet: ts, Call: string(call)}, true
+}
+
+func (c *EthCallCache) get(ts types.TipSetKey, tx ethtypes.EthCall) (ethtypes.EthBytes, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	key, ok := ethCallCacheKey(ts, tx)
+	if !ok {
+		return nil, false
+	}
+	res, ok := c.cache.Get(key)
+	// expired results are returned as found, with a nil value
+	if !ok || res == nil {
+		return nil, false
+	}
+	return *res, true
+}
+
+func (c *EthCallCache) add(ts types.TipSetKey, tx ethtypes.EthCall, res ethtypes.EthBytes) {
+	if c == nil {
+		return
+	}
+
+	if key, ok := ethCallCacheKey(ts, tx); ok {
+		c.cache.Add(key, &res)
+	}
+}
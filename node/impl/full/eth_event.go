@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ipfs/go-cid"
 	"github.com/zyedidia/generic/queue"
+	"go.opencensus.io/stats"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-jsonrpc"
@@ -16,6 +18,7 @@ import (
 	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/metrics"
 )
 
 type filterEventCollector interface {
@@ -170,12 +173,44 @@ func ethFilterResultFromMessages(cs []*types.SignedMessage, sa StateAPI) (*ethty
 	return res, nil
 }
 
+// BackpressureMode selects what a subscription does with new messages while
+// its send queue is full.
+type BackpressureMode int
+
+const (
+	// BackpressureDropOldest discards the oldest queued message.
+	BackpressureDropOldest BackpressureMode = iota
+	// BackpressureDropNewest discards the new message.
+	BackpressureDropNewest
+	// BackpressureBlock waits for the subscriber to make room, up to the send
+	// timeout, and closes the subscription if it doesn't. Events are dispatched
+	// to all subscriptions in turn, so while a subscription waits, event
+	// delivery to every other subscriber stalls too.
+	BackpressureBlock
+)
+
 type EthSubscriptionManager struct {
 	Chain    *store.ChainStore
 	StateAPI StateAPI
 	ChainAPI ChainAPI
-	mu       sync.Mutex
-	subs     map[ethtypes.EthSubscriptionID]*ethSubscription
+
+	// AddressMapping selects how actor addresses in new block headers are presented
+	AddressMapping EthAddressMapping
+
+	// EventBatchInterval, when non-zero, buffers log events for this long and
+	// sends them to subscribers as a single array.
+	EventBatchInterval time.Duration
+
+	// SendQueueSize is the number of messages queued for each subscriber
+	// before Backpressure applies. 0 uses defaultSendQueue.
+	SendQueueSize int
+	Backpressure  BackpressureMode
+	// SendTimeout, when non-zero, is how long delivering a single message may
+	// take before the subscriber is considered dead.
+	SendTimeout time.Duration
+
+	mu   sync.Mutex
+	subs map[ethtypes.EthSubscriptionID]*ethSubscription
 }
 
 func (e *EthSubscriptionManager) StartSubscription(ctx context.Context, out ethSubscriptionCallback, dropFilter func(context.Context, filter.Filter) error) (*ethSubscription, error) { // nolint
@@ -192,14 +227,24 @@ func (e *EthSubscriptionManager) StartSubscription(ctx context.Context, out ethS
 		Chain:           e.Chain,
 		StateAPI:        e.StateAPI,
 		ChainAPI:        e.ChainAPI,
+		addressMapping:  e.AddressMapping,
 		uninstallFilter: dropFilter,
 		id:              id,
 		in:              make(chan interface{}, 200),
 		out:             out,
 		quit:            quit,
 
-		toSend:   queue.New[[]byte](),
-		sendCond: make(chan struct{}, 1),
+		eventBatchInterval: e.EventBatchInterval,
+
+		sendQueueSize: e.SendQueueSize,
+		backpressure:  e.Backpressure,
+		sendTimeout:   e.SendTimeout,
+		toSend:        queue.New[[]byte](),
+		sendCond:      make(chan struct{}, 1),
+		sendSpace:     make(chan struct{}, 1),
+	}
+	if sub.sendQueueSize <= 0 {
+		sub.sendQueueSize = defaultSendQueue
 	}
 
 	e.mu.Lock()
@@ -231,25 +276,34 @@ func (e *EthSubscriptionManager) StopSubscription(ctx context.Context, id ethtyp
 
 type ethSubscriptionCallback func(context.Context, jsonrpc.RawParams) error
 
-const maxSendQueue = 20000
+const defaultSendQueue = 20000
 
 type ethSubscription struct {
 	Chain           *store.ChainStore
 	StateAPI        StateAPI
 	ChainAPI        ChainAPI
+	addressMapping  EthAddressMapping
 	uninstallFilter func(context.Context, filter.Filter) error
 	id              ethtypes.EthSubscriptionID
 	in              chan interface{}
 	out             ethSubscriptionCallback
 
+	eventBatchInterval time.Duration
+
 	mu      sync.Mutex
 	filters []filter.Filter
 	quit    func()
 
+	sendQueueSize int
+	backpressure  BackpressureMode
+	sendTimeout   time.Duration
+
 	sendLk       sync.Mutex
 	sendQueueLen int
+	dropping     bool // whether messages have been dropped since the queue was last not full
 	toSend       *queue.Queue[[]byte]
 	sendCond     chan struct{}
+	sendSpace    chan struct{}
 }
 
 func (e *ethSubscription) addFilter(ctx context.Context, f filter.Filter) {
@@ -276,7 +330,12 @@ func (e *ethSubscription) startOut(ctx context.Context) {
 
 				e.sendLk.Unlock()
 
-				if err := e.out(ctx, front); err != nil {
+				select {
+				case e.sendSpace <- struct{}{}:
+				default:
+				}
+
+				if err := e.sendOut(ctx, front); err != nil {
 					log.Warnw("error sending subscription response, killing subscription", "sub", e.id, "error", err)
 					e.stop()
 					return
@@ -290,6 +349,41 @@ func (e *ethSubscription) startOut(ctx context.Context) {
 	}
 }
 
+func (e *ethSubscription) sendOut(ctx context.Context, msg []byte) error {
+	if e.sendTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.sendTimeout)
+		defer cancel()
+	}
+	return e.out(ctx, msg)
+}
+
+// waitSendSpace waits until the send queue has room, releasing sendLk while
+// waiting. It returns false if the send timeout expired first.
+func (e *ethSubscription) waitSendSpace(ctx context.Context) bool {
+	var timeout <-chan time.Time
+	if e.sendTimeout > 0 {
+		t := time.NewTimer(e.sendTimeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	for e.sendQueueLen >= e.sendQueueSize {
+		e.sendLk.Unlock()
+		select {
+		case <-e.sendSpace:
+		case <-timeout:
+			e.sendLk.Lock()
+			return false
+		case <-ctx.Done():
+			e.sendLk.Lock()
+			return false
+		}
+		e.sendLk.Lock()
+	}
+	return true
+}
+
 func (e *ethSubscription) send(ctx context.Context, v interface{}) {
 	resp := ethtypes.EthSubscriptionResponse{
 		SubscriptionID: e.id,
@@ -305,14 +399,28 @@ func (e *ethSubscription) send(ctx context.Context, v interface{}) {
 	e.sendLk.Lock()
 	defer e.sendLk.Unlock()
 
-	e.toSend.Enqueue(outParam)
+	if e.sendQueueLen >= e.sendQueueSize {
+		switch e.backpressure {
+		case BackpressureDropNewest:
+			e.dropped(ctx, "newest")
+			return
+		case BackpressureBlock:
+			if !e.waitSendSpace(ctx) {
+				log.Warnw("subscription send queue full, killing subscription", "sub", e.id)
+				e.stop()
+				return
+			}
+		default:
+			e.dropped(ctx, "oldest")
+			e.toSend.Dequeue()
+			e.sendQueueLen--
+		}
+	} else {
+		e.dropping = false
+	}
 
+	e.toSend.Enqueue(outParam)
 	e.sendQueueLen++
-	if e.sendQueueLen > maxSendQueue {
-		log.Warnw("subscription send queue full, killing subscription", "sub", e.id)
-		e.stop()
-		return
-	}
 
 	select {
 	case e.sendCond <- struct{}{}:
@@ -320,11 +428,30 @@ func (e *ethSubscription) send(ctx context.Context, v interface{}) {
 	}
 }
 
+// dropped records a message dropped from the full send queue, warning once until the queue has room
+// again. sendLk must be held.
+func (e *ethSubscription) dropped(ctx context.Context, which string) {
+	stats.Record(ctx, metrics.EthSubscriptionDropped.M(1))
+	if !e.dropping {
+		log.Warnw("subscription send queue full, dropping messages", "sub", e.id, "drop", which, "queueSize", e.sendQueueSize)
+		e.dropping = true
+	}
+}
+
 func (e *ethSubscription) start(ctx context.Context) {
+	// log events buffered when event batching is enabled
+	var (
+		pendingLogs []interface{}
+		flushLogs   <-chan time.Time
+	)
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-flushLogs:
+			e.send(ctx, pendingLogs)
+			pendingLogs, flushLogs = nil, nil
 		case v := <-e.in:
 			switch vt := v.(type) {
 			case *filter.CollectedEvent:
@@ -333,11 +460,19 @@ func (e *ethSubscription) start(ctx context.Context) {
 					continue
 				}
 
+				if e.eventBatchInterval > 0 {
+					pendingLogs = append(pendingLogs, evs.Results...)
+					if flushLogs == nil && len(pendingLogs) > 0 {
+						flushLogs = time.After(e.eventBatchInterval)
+					}
+					continue
+				}
+
 				for _, r := range evs.Results {
 					e.send(ctx, r)
 				}
 			case *types.TipSet:
-				ev, err := newEthBlockFromFilecoinTipSet(ctx, vt, true, e.Chain, e.StateAPI)
+				ev, err := newEthBlockFromFilecoinTipSet(ctx, vt, true, e.Chain, e.StateAPI, e.addressMapping)
 				if err != nil {
 					break
 				}
@@ -1,15 +1,24 @@
 package full
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/require"
+	"github.com/zyedidia/generic/queue"
 
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
 	"github.com/filecoin-project/go-state-types/big"
 
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/chain/types/mock"
 )
 
 func TestEthLogFromEvent(t *testing.T) {
@@ -162,3 +171,253 @@ func TestRewardPercentiles(t *testing.T) {
 		require.Equal(t, ans, rewards)
 	}
 }
+
+func TestEthereumSyncStatus(t *testing.T) {
+	blk := mock.MkBlock(nil, 1, 1)
+	blk.Height = 100
+	blk.Timestamp = 1_000_000
+	head := mock.TipSet(blk)
+	headTime := time.Unix(int64(blk.Timestamp), 0)
+	delay := time.Duration(build.BlockDelaySecs) * time.Second
+
+	// head is recent, the node is synced even with idle sync workers
+	res := ethereumSyncStatus(&api.SyncState{}, head, headTime.Add(delay/2))
+	require.True(t, res.DoneSync)
+	b, err := json.Marshal(res)
+	require.NoError(t, err)
+	require.Equal(t, "false", string(b))
+
+	// head is 10 epochs old, report the expected epoch as the highest block
+	res = ethereumSyncStatus(&api.SyncState{}, head, headTime.Add(10*delay))
+	require.False(t, res.DoneSync)
+	require.EqualValues(t, 100, res.StartingBlock)
+	require.EqualValues(t, 100, res.CurrentBlock)
+	require.EqualValues(t, 110, res.HighestBlock)
+
+	b, err = json.Marshal(res)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"startingBlock":"0x64","currentBlock":"0x64","highestBlock":"0x6e"}`, string(b))
+
+	// active sync workers extend the range
+	base, target := mock.MkBlock(nil, 1, 2), mock.MkBlock(nil, 1, 3)
+	base.Height, target.Height = 50, 200
+	state := &api.SyncState{ActiveSyncs: []api.ActiveSync{
+		{Stage: api.StageIdle},
+		{Stage: api.StageMessages, Base: mock.TipSet(base), Target: mock.TipSet(target), Height: 100},
+	}}
+	res = ethereumSyncStatus(state, head, headTime.Add(10*delay))
+	require.False(t, res.DoneSync)
+	require.EqualValues(t, 50, res.StartingBlock)
+	require.EqualValues(t, 100, res.CurrentBlock)
+	require.EqualValues(t, 200, res.HighestBlock)
+}
+
+func TestEthSubscriptionBackpressure(t *testing.T) {
+	newSub := func(mode BackpressureMode) *ethSubscription {
+		ctx, quit := context.WithCancel(context.Background())
+		t.Cleanup(quit)
+		return &ethSubscription{
+			quit:          quit,
+			sendQueueSize: 2,
+			backpressure:  mode,
+			sendTimeout:   10 * time.Millisecond,
+			toSend:        queue.New[[]byte](),
+			sendCond:      make(chan struct{}, 1),
+			sendSpace:     make(chan struct{}, 1),
+			out: func(context.Context, jsonrpc.RawParams) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+	}
+	queued := func(sub *ethSubscription) []int {
+		var out []int
+		for !sub.toSend.Empty() {
+			var resp struct{ Result int }
+			require.NoError(t, json.Unmarshal(sub.toSend.Dequeue(), &resp))
+			out = append(out, resp.Result)
+		}
+		return out
+	}
+
+	ctx := context.Background()
+
+	sub := newSub(BackpressureDropOldest)
+	for i := 1; i <= 3; i++ {
+		sub.send(ctx, i)
+	}
+	require.True(t, sub.dropping)
+	require.Equal(t, []int{2, 3}, queued(sub))
+
+	// the queue has room again
+	sub.sendQueueLen = 0
+	sub.send(ctx, 4)
+	require.False(t, sub.dropping)
+	require.Equal(t, []int{4}, queued(sub))
+
+	sub = newSub(BackpressureDropNewest)
+	for i := 1; i <= 3; i++ {
+		sub.send(ctx, i)
+	}
+	require.Equal(t, []int{1, 2}, queued(sub))
+
+	// nothing drains the queue, so the blocked send times out and the subscription is closed
+	sub = newSub(BackpressureBlock)
+	for i := 1; i <= 3; i++ {
+		sub.send(ctx, i)
+	}
+	require.Nil(t, sub.quit)
+	require.Equal(t, []int{1, 2}, queued(sub))
+}
+
+func TestCheckInitCodeSize(t *testing.T) {
+	to := ethtypes.EthAddress{1}
+
+	require.NoError(t, checkInitCodeSize(&ethtypes.EthTxArgs{Input: make([]byte, 2048)}, 1024))
+	require.Error(t, checkInitCodeSize(&ethtypes.EthTxArgs{Input: make([]byte, 2049)}, 1024))
+
+	// calls and disabled limits aren't checked
+	require.NoError(t, checkInitCodeSize(&ethtypes.EthTxArgs{To: &to, Input: make([]byte, 4096)}, 1024))
+	require.NoError(t, checkInitCodeSize(&ethtypes.EthTxArgs{Input: make([]byte, 4096)}, 0))
+}
+
+func TestCheckTxType(t *testing.T) {
+	require.NoError(t, checkTxType([]byte{0x02, 0xc0}, true))
+	require.NoError(t, checkTxType([]byte{0x01, 0xc0}, true))
+
+	for _, typ := range []byte{0x01, 0x02} {
+		err := checkTxType([]byte{typ, 0xc0}, false)
+		var invalid *api.ErrInvalidParams
+		require.ErrorAs(t, err, &invalid)
+	}
+
+	// legacy envelopes are left to the parser
+	require.NoError(t, checkTxType([]byte{0xf8, 0x00}, false))
+}
+
+func TestCheckStrictEthAddress(t *testing.T) {
+	ethAddr, err := ethtypes.ParseEthAddress("0xd4c5fb16488Aa48081296299d54b0c648C9333dA")
+	require.NoError(t, err)
+	id, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	idAddr, err := ethtypes.EthAddressFromFilecoinAddress(id)
+	require.NoError(t, err)
+
+	a := &EthModule{AddressMapping: EthAddressStrict}
+	require.NoError(t, a.checkEthAddress(ethAddr))
+	require.Error(t, a.checkEthAddress(idAddr))
+
+	require.NoError(t, a.checkEthCall(ethtypes.EthCall{From: &ethAddr, To: &ethAddr}))
+	require.Error(t, a.checkEthCall(ethtypes.EthCall{To: &ethAddr}))
+	require.Error(t, a.checkEthCall(ethtypes.EthCall{From: &ethAddr, To: &idAddr}))
+
+	// other mappings accept any address
+	a.AddressMapping = EthAddressDelegatedFirst
+	require.NoError(t, a.checkEthAddress(idAddr))
+	require.NoError(t, a.checkEthCall(ethtypes.EthCall{To: &idAddr}))
+}
+
+func TestCheckCallWhitelist(t *testing.T) {
+	allowed, err := ethtypes.ParseEthAddress("0xd4c5fb16488Aa48081296299d54b0c648C9333dA")
+	require.NoError(t, err)
+	other, err := ethtypes.ParseEthAddress("0x0000000000000000000000000000000000000001")
+	require.NoError(t, err)
+
+	// no whitelist allows any call
+	a := &EthModule{}
+	require.NoError(t, a.checkCallWhitelist(ethtypes.EthCall{To: &other}))
+	require.NoError(t, a.checkCallWhitelist(ethtypes.EthCall{}))
+
+	a.CallWhitelist = map[ethtypes.EthAddress]struct{}{allowed: {}}
+	require.NoError(t, a.checkCallWhitelist(ethtypes.EthCall{To: &allowed}))
+
+	for _, tx := range []ethtypes.EthCall{{To: &other}, {}} {
+		var notFound *api.ErrMethodNotFound
+		require.ErrorAs(t, a.checkCallWhitelist(tx), &notFound)
+	}
+}
+
+func TestCheckGetLogsResults(t *testing.T) {
+	require.NoError(t, checkGetLogsResults(100, 0))
+	require.NoError(t, checkGetLogsResults(10, 10))
+
+	var limitErr *api.ErrLimitExceeded
+	require.ErrorAs(t, checkGetLogsResults(11, 10), &limitErr)
+}
+
+func TestEthTxLookupCache(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := NewEthTxLookupCache(0)
+	require.NoError(t, err)
+	require.Nil(t, c)
+
+	// a disabled cache doesn't cache anything
+	c.Add(ethtypes.EthHash{1}, types.EmptyTSK, cid.Undef, 0)
+	_, ok := c.get(ctx, ethtypes.EthHash{1})
+	require.False(t, ok)
+
+	c, err = NewEthTxLookupCache(1)
+	require.NoError(t, err)
+
+	msg, err := cid.Decode("bafy2bzacecjiwyyrbg4l3yc3ibpmy2qqwqckguicdxpyibcsojwzdbldf5lty")
+	require.NoError(t, err)
+	c.Add(ethtypes.EthHash{1}, types.EmptyTSK, msg, 3)
+	loc, ok := c.get(ctx, ethtypes.EthHash{1})
+	require.True(t, ok)
+	require.Equal(t, ethTxLocation{TipSet: types.EmptyTSK, Message: msg, TxIndex: 3}, loc)
+
+	// the least recently used location is evicted
+	c.Add(ethtypes.EthHash{2}, types.EmptyTSK, msg, 4)
+	_, ok = c.get(ctx, ethtypes.EthHash{1})
+	require.False(t, ok)
+
+	c.Remove(ethtypes.EthHash{2})
+	_, ok = c.get(ctx, ethtypes.EthHash{2})
+	require.False(t, ok)
+
+	require.EqualValues(t, 1, c.hits.Load())
+	require.EqualValues(t, 2, c.misses.Load())
+}
+
+func TestEthCallCache(t *testing.T) {
+	c := NewEthCallCache(0, 0)
+	require.Nil(t, c)
+
+	to := ethtypes.EthAddress{1}
+	call := ethtypes.EthCall{To: &to, Data: ethtypes.EthBytes{0xde, 0xad}}
+
+	// a disabled cache doesn't cache anything
+	c.add(types.EmptyTSK, call, ethtypes.EthBytes{1})
+	_, ok := c.get(types.EmptyTSK, call)
+	require.False(t, ok)
+
+	c = NewEthCallCache(2, 0)
+	c.add(types.EmptyTSK, call, ethtypes.EthBytes{1})
+	res, ok := c.get(types.EmptyTSK, call)
+	require.True(t, ok)
+	require.Equal(t, ethtypes.EthBytes{1}, res)
+
+	// other call data, or another tipset, miss
+	other := call
+	other.Data = ethtypes.EthBytes{0xbe, 0xef}
+	_, ok = c.get(types.EmptyTSK, other)
+	require.False(t, ok)
+
+	otherTs := types.NewTipSetKey(cid.MustParse("bafy2bzacecjiwyyrbg4l3yc3ibpmy2qqwqckguicdxpyibcsojwzdbldf5lty"))
+	_, ok = c.get(otherTs, call)
+	require.False(t, ok)
+
+	// the least recently used result is evicted
+	c.add(types.EmptyTSK, other, ethtypes.EthBytes{2})
+	c.add(otherTs, call, ethtypes.EthBytes{3})
+	_, ok = c.get(types.EmptyTSK, call)
+	require.False(t, ok)
+
+	// results expire after the ttl
+	c = NewEthCallCache(2, 10*time.Millisecond)
+	c.add(types.EmptyTSK, call, ethtypes.EthBytes{1})
+	time.Sleep(50 * time.Millisecond)
+	_, ok = c.get(types.EmptyTSK, call)
+	require.False(t, ok)
+}
@@ -0,0 +1,135 @@
+package full
+
+import (
+	"context"
+	"sync/atomic"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/ipfs/go-cid"
+	"go.opencensus.io/stats"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/metrics"
+)
+
+// ethTxLocation is where a transaction was included on chain.
+type ethTxLocation struct {
+	// TipSet is the tipset including the message
+	TipSet  types.TipSetKey
+	Message cid.Cid
+	TxIndex int
+}
+
+// EthTxLookupCache is an LRU cache mapping transaction hashes to the location
+// of the transaction on chain, sparing eth_getTransactionByHash a search of the
+// chain. Cached locations are checked against the current chain before use, so
+// locations which were reverted are never served. A nil *EthTxLookupCache
+// caches nothing.
+type EthTxLookupCache struct {
+	cache *lru.Cache[ethtypes.EthHash, ethTxLocation]
+
+	hits, misses atomic.Int64
+}
+
+// NewEthTxLookupCache returns a cache holding up to size locations, or nil if
+// size is not positive.
+func NewEthTxLookupCache(size int) (*EthTxLookupCache, error) {
+	if size <= 0 {
+		return nil, nil
+	}
+
+	cache, err := lru.New[ethtypes.EthHash, ethTxLocation](size)
+	if err != nil {
+		return nil, xerrors.Errorf("creating eth tx lookup cache: %w", err)
+	}
+	return &EthTxLookupCache{cache: cache}, nil
+}
+
+// Add records that the transaction with the given hash was included in ts, as
+// the message msg at index txIdx of its messages.
+func (c *EthTxLookupCache) Add(hash ethtypes.EthHash, ts types.TipSetKey, msg cid.Cid, txIdx int) {
+	if c == nil {
+		return
+	}
+	c.cache.Add(hash, ethTxLocation{TipSet: ts, Message: msg, TxIndex: txIdx})
+}
+
+// Remove evicts the location of the transaction with the given hash.
+func (c *EthTxLookupCache) Remove(hash ethtypes.EthHash) {
+	if c == nil {
+		return
+	}
+	c.cache.Remove(hash)
+}
+
+func (c *EthTxLookupCache) get(ctx context.Context, hash ethtypes.EthHash) (ethTxLocation, bool) {
+	if c == nil {
+		return ethTxLocation{}, false
+	}
+
+	loc, ok := c.cache.Get(hash)
+	if ok {
+		c.hits.Add(1)
+		stats.Record(ctx, metrics.EthTxLookupCacheHit.M(1))
+	} else {
+		c.misses.Add(1)
+		stats.Record(ctx, metrics.EthTxLookupCacheMiss.M(1))
+	}
+
+	hits, misses := c.hits.Load(), c.misses.Load()
+	stats.Record(ctx, metrics.EthTxLookupCacheHitRatio.M(float64(hits)/float64(hits+misses)))
+
+	return loc, ok
+}
+
+// lookup returns the transaction with the given hash from its cached location,
+// if that location is still on the current chain, the message has been
+// executed, and it's within limit epochs of the head.
+func (c *EthTxLookupCache) lookup(ctx context.Context, hash ethtypes.EthHash, limit abi.ChainEpoch, cs *store.ChainStore, sa StateAPI, mapping EthAddressMapping) (*ethtypes.EthTx, bool) {
+	loc, ok := c.get(ctx, hash)
+	if !ok {
+		return nil, false
+	}
+
+	tx, err := func() (*ethtypes.EthTx, error) {
+		ts, err := cs.LoadTipSet(ctx, loc.TipSet)
+		if err != nil {
+			return nil, err
+		}
+
+		head := cs.GetHeaviestTipSet()
+		if head.Height() <= ts.Height() {
+			return nil, xerrors.Errorf("message not executed yet")
+		}
+		if limit != api.LookbackNoLimit && head.Height()-ts.Height() > limit {
+			return nil, xerrors.Errorf("message beyond the lookback limit")
+		}
+
+		canonical, err := cs.GetTipsetByHeight(ctx, ts.Height(), head, false)
+		if err != nil {
+			return nil, err
+		}
+		if canonical.Key() != ts.Key() {
+			c.Remove(hash)
+			return nil, xerrors.Errorf("tipset reverted")
+		}
+
+		tx, err := newEthTxFromIncludedMessage(ctx, ts, loc.Message, loc.TxIndex, cs, sa, mapping)
+		if err != nil {
+			return nil, err
+		}
+		return &tx, nil
+	}()
+	if err != nil {
+		log.Debugw("cached eth tx location unusable", "hash", hash, "error", err)
+		return nil, false
+	}
+
+	return tx, true
+}
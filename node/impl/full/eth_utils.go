@@ -176,7 +176,7 @@ func ethCallToFilecoinMessage(ctx context.Context, tx ethtypes.EthCall) (*types.
 	}, nil
 }
 
-func newEthBlockFromFilecoinTipSet(ctx context.Context, ts *types.TipSet, fullTxInfo bool, cs *store.ChainStore, sa StateAPI) (ethtypes.EthBlock, error) {
+func newEthBlockFromFilecoinTipSet(ctx context.Context, ts *types.TipSet, fullTxInfo bool, cs *store.ChainStore, sa StateAPI, mapping EthAddressMapping) (ethtypes.EthBlock, error) {
 	parentKeyCid, err := ts.Parents().Cid()
 	if err != nil {
 		return ethtypes.EthBlock{}, err
@@ -223,7 +223,7 @@ func newEthBlockFromFilecoinTipSet(ctx context.Context, ts *types.TipSet, fullTx
 		default:
 			return ethtypes.EthBlock{}, xerrors.Errorf("failed to get signed msg %s: %w", msg.Cid(), err)
 		}
-		tx, err := newEthTxFromSignedMessage(ctx, smsg, sa)
+		tx, err := newEthTxFromSignedMessage(ctx, smsg, sa, mapping)
 		if err != nil {
 			return ethtypes.EthBlock{}, xerrors.Errorf("failed to convert msg to ethTx: %w", err)
 		}
@@ -351,6 +351,21 @@ func parseEthRevert(ret []byte) string {
 	return ethtypes.EthBytes(cbytes).String()
 }
 
+// EthAddressMapping selects how actors are given Ethereum addresses in Eth API responses.
+type EthAddressMapping int
+
+const (
+	// EthAddressDelegatedFirst uses the delegated (f410) address of actors which have one, and the masked ID
+	// address of other actors.
+	EthAddressDelegatedFirst EthAddressMapping = iota
+	// EthAddressIDBased uses the masked ID address of all actors which have an ID; the delegated address is
+	// only used for actors which don't exist yet.
+	EthAddressIDBased
+	// EthAddressStrict presents addresses as EthAddressDelegatedFirst does, but only accepts Ethereum
+	// addresses in requests: masked ID addresses, and calls without a sender, are rejected.
+	EthAddressStrict
+)
+
 // lookupEthAddress makes its best effort at finding the Ethereum address for a
 // Filecoin address. It does the following:
 //
@@ -359,7 +374,17 @@ func parseEthRevert(ret []byte) string {
 //  3. Otherwise, we fall back to returning a masked ID Ethereum address. If the supplied address is an f0 address, we
 //     use that ID to form the masked ID address.
 //  4. Otherwise, we fetch the actor's ID from the state tree and form the masked ID with it.
-func lookupEthAddress(ctx context.Context, addr address.Address, sa StateAPI) (ethtypes.EthAddress, error) {
+//
+// With EthAddressIDBased, the masked ID address is returned for all actors which have an ID.
+func lookupEthAddress(ctx context.Context, addr address.Address, sa StateAPI, mapping EthAddressMapping) (ethtypes.EthAddress, error) {
+	if mapping == EthAddressIDBased {
+		if idAddr, err := sa.StateLookupID(ctx, addr, types.EmptyTSK); err == nil {
+			return ethtypes.EthAddressFromFilecoinAddress(idAddr)
+		}
+		// not created yet, so only an f410 address can be used
+		return ethtypes.EthAddressFromFilecoinAddress(addr)
+	}
+
 	// BLOCK A: We are trying to get an actual Ethereum address from an f410 address.
 	// Attempt to convert directly, if it's an f4 address.
 	ethAddr, err := ethtypes.EthAddressFromFilecoinAddress(addr)
@@ -424,7 +449,8 @@ func ethTxHashFromMessageCid(ctx context.Context, c cid.Cid, sa StateAPI) (ethty
 
 func ethTxHashFromSignedMessage(ctx context.Context, smsg *types.SignedMessage, sa StateAPI) (ethtypes.EthHash, error) {
 	if smsg.Signature.Type == crypto.SigTypeDelegated {
-		ethTx, err := newEthTxFromSignedMessage(ctx, smsg, sa)
+		// the hash doesn't depend on the addresses
+		ethTx, err := newEthTxFromSignedMessage(ctx, smsg, sa, EthAddressDelegatedFirst)
 		if err != nil {
 			return ethtypes.EmptyEthHash, err
 		}
@@ -436,7 +462,7 @@ func ethTxHashFromSignedMessage(ctx context.Context, smsg *types.SignedMessage,
 	}
 }
 
-func newEthTxFromSignedMessage(ctx context.Context, smsg *types.SignedMessage, sa StateAPI) (ethtypes.EthTx, error) {
+func newEthTxFromSignedMessage(ctx context.Context, smsg *types.SignedMessage, sa StateAPI, mapping EthAddressMapping) (ethtypes.EthTx, error) {
 	var tx ethtypes.EthTx
 	var err error
 
@@ -452,20 +478,20 @@ func newEthTxFromSignedMessage(ctx context.Context, smsg *types.SignedMessage, s
 			return ethtypes.EthTx{}, xerrors.Errorf("failed to calculate hash for ethTx: %w", err)
 		}
 
-		fromAddr, err := lookupEthAddress(ctx, smsg.Message.From, sa)
+		fromAddr, err := lookupEthAddress(ctx, smsg.Message.From, sa, mapping)
 		if err != nil {
 			return ethtypes.EthTx{}, xerrors.Errorf("failed to resolve Ethereum address: %w", err)
 		}
 
 		tx.From = fromAddr
 	} else if smsg.Signature.Type == crypto.SigTypeSecp256k1 { // Secp Filecoin Message
-		tx = ethTxFromNativeMessage(ctx, smsg.VMMessage(), sa)
+		tx = ethTxFromNativeMessage(ctx, smsg.VMMessage(), sa, mapping)
 		tx.Hash, err = ethtypes.EthHashFromCid(smsg.Cid())
 		if err != nil {
 			return tx, err
 		}
 	} else { // BLS Filecoin message
-		tx = ethTxFromNativeMessage(ctx, smsg.VMMessage(), sa)
+		tx = ethTxFromNativeMessage(ctx, smsg.VMMessage(), sa, mapping)
 		tx.Hash, err = ethtypes.EthHashFromCid(smsg.Message.Cid())
 		if err != nil {
 			return tx, err
@@ -480,10 +506,10 @@ func newEthTxFromSignedMessage(ctx context.Context, smsg *types.SignedMessage, s
 // - BlockNumber
 // - TransactionIndex
 // - Hash
-func ethTxFromNativeMessage(ctx context.Context, msg *types.Message, sa StateAPI) ethtypes.EthTx {
+func ethTxFromNativeMessage(ctx context.Context, msg *types.Message, sa StateAPI, mapping EthAddressMapping) ethtypes.EthTx {
 	// We don't care if we error here, conversion is best effort for non-eth transactions
-	from, _ := lookupEthAddress(ctx, msg.From, sa)
-	to, _ := lookupEthAddress(ctx, msg.To, sa)
+	from, _ := lookupEthAddress(ctx, msg.From, sa, mapping)
+	to, _ := lookupEthAddress(ctx, msg.To, sa, mapping)
 	return ethtypes.EthTx{
 		To:                   &to,
 		From:                 from,
@@ -520,7 +546,7 @@ func getSignedMessage(ctx context.Context, cs *store.ChainStore, msgCid cid.Cid)
 // newEthTxFromMessageLookup creates an ethereum transaction from filecoin message lookup. If a negative txIdx is passed
 // into the function, it looks up the transaction index of the message in the tipset, otherwise it uses the txIdx passed into the
 // function
-func newEthTxFromMessageLookup(ctx context.Context, msgLookup *api.MsgLookup, txIdx int, cs *store.ChainStore, sa StateAPI) (ethtypes.EthTx, error) {
+func newEthTxFromMessageLookup(ctx context.Context, msgLookup *api.MsgLookup, txIdx int, cs *store.ChainStore, sa StateAPI, mapping EthAddressMapping) (ethtypes.EthTx, error) {
 	ts, err := cs.LoadTipSet(ctx, msgLookup.TipSet)
 	if err != nil {
 		return ethtypes.EthTx{}, err
@@ -532,11 +558,6 @@ func newEthTxFromMessageLookup(ctx context.Context, msgLookup *api.MsgLookup, tx
 		return ethtypes.EthTx{}, err
 	}
 
-	parentTsCid, err := parentTs.Key().Cid()
-	if err != nil {
-		return ethtypes.EthTx{}, err
-	}
-
 	// lookup the transactionIndex
 	if txIdx < 0 {
 		msgs, err := cs.MessagesForTipset(ctx, parentTs)
@@ -554,23 +575,34 @@ func newEthTxFromMessageLookup(ctx context.Context, msgLookup *api.MsgLookup, tx
 		}
 	}
 
-	blkHash, err := ethtypes.EthHashFromCid(parentTsCid)
+	return newEthTxFromIncludedMessage(ctx, parentTs, msgLookup.Message, txIdx, cs, sa, mapping)
+}
+
+// newEthTxFromIncludedMessage returns the transaction for the message msgCid,
+// included in ts at index txIdx of its messages.
+func newEthTxFromIncludedMessage(ctx context.Context, ts *types.TipSet, msgCid cid.Cid, txIdx int, cs *store.ChainStore, sa StateAPI, mapping EthAddressMapping) (ethtypes.EthTx, error) {
+	tsCid, err := ts.Key().Cid()
+	if err != nil {
+		return ethtypes.EthTx{}, err
+	}
+
+	blkHash, err := ethtypes.EthHashFromCid(tsCid)
 	if err != nil {
 		return ethtypes.EthTx{}, err
 	}
 
-	smsg, err := getSignedMessage(ctx, cs, msgLookup.Message)
+	smsg, err := getSignedMessage(ctx, cs, msgCid)
 	if err != nil {
 		return ethtypes.EthTx{}, xerrors.Errorf("failed to get signed msg: %w", err)
 	}
 
-	tx, err := newEthTxFromSignedMessage(ctx, smsg, sa)
+	tx, err := newEthTxFromSignedMessage(ctx, smsg, sa, mapping)
 	if err != nil {
 		return ethtypes.EthTx{}, err
 	}
 
 	var (
-		bn = ethtypes.EthUint64(parentTs.Height())
+		bn = ethtypes.EthUint64(ts.Height())
 		ti = ethtypes.EthUint64(txIdx)
 	)
 
@@ -581,7 +613,7 @@ func newEthTxFromMessageLookup(ctx context.Context, msgLookup *api.MsgLookup, tx
 	return tx, nil
 }
 
-func newEthTxReceipt(ctx context.Context, tx ethtypes.EthTx, lookup *api.MsgLookup, events []types.Event, cs *store.ChainStore, sa StateAPI) (api.EthTxReceipt, error) {
+func newEthTxReceipt(ctx context.Context, tx ethtypes.EthTx, lookup *api.MsgLookup, events []types.Event, cs *store.ChainStore, sa StateAPI, mapping EthAddressMapping) (api.EthTxReceipt, error) {
 	var (
 		transactionIndex ethtypes.EthUint64
 		blockHash        ethtypes.EthHash
@@ -676,7 +708,7 @@ func newEthTxReceipt(ctx context.Context, tx ethtypes.EthTx, lookup *api.MsgLook
 				return api.EthTxReceipt{}, xerrors.Errorf("failed to create ID address: %w", err)
 			}
 
-			l.Address, err = lookupEthAddress(ctx, addr, sa)
+			l.Address, err = lookupEthAddress(ctx, addr, sa, mapping)
 			if err != nil {
 				return api.EthTxReceipt{}, xerrors.Errorf("failed to resolve Ethereum address: %w", err)
 			}
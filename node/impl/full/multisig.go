@@ -26,7 +26,7 @@ type MsigAPI struct {
 }
 
 func (a *MsigAPI) messageBuilder(ctx context.Context, from address.Address) (multisig.MessageBuilder, error) {
-	nver, err := a.StateAPI.StateNetworkVersion(ctx, types.EmptyTSK)
+	nver, err := scheduledNetworkVersion(ctx, a.StateAPI.StateManager, types.EmptyTSK)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +83,8 @@ type StateModule struct {
 
 	StateManager *stmgr.StateManager
 	Chain        *store.ChainStore
+
+	NetworkVersionOverride dtypes.NetworkVersionOverride `optional:"true"`
 }
 
 var _ StateModuleAPI = (*StateModule)(nil)
@@ -909,7 +911,7 @@ func (a *StateAPI) StateGetClaims(ctx context.Context, providerAddr address.Addr
 }
 
 func (a *StateAPI) StateComputeDataCID(ctx context.Context, maddr address.Address, sectorType abi.RegisteredSealProof, deals []abi.DealID, tsk types.TipSetKey) (cid.Cid, error) {
-	nv, err := a.StateNetworkVersion(ctx, tsk)
+	nv, err := scheduledNetworkVersion(ctx, a.StateManager, tsk)
 	if err != nil {
 		return cid.Cid{}, err
 	}
@@ -1619,7 +1621,7 @@ func (m *StateModule) StateVerifiedClientStatus(ctx context.Context, addr addres
 		return nil, err
 	}
 
-	nv, err := m.StateNetworkVersion(ctx, tsk)
+	nv, err := scheduledNetworkVersion(ctx, m.StateManager, tsk)
 	if err != nil {
 		return nil, err
 	}
@@ -1782,14 +1784,28 @@ func stateVMCirculatingSupplyInternal(
 }
 
 func (m *StateModule) StateNetworkVersion(ctx context.Context, tsk types.TipSetKey) (network.Version, error) {
-	ts, err := m.Chain.GetTipSetFromKey(ctx, tsk)
+	nv, err := scheduledNetworkVersion(ctx, m.StateManager, tsk)
+	if err != nil {
+		return network.VersionMax, err
+	}
+
+	if m.NetworkVersionOverride != 0 {
+		return network.Version(m.NetworkVersionOverride), nil
+	}
+	return nv, nil
+}
+
+// scheduledNetworkVersion returns the network version of the upgrade schedule at the tipset. Unlike
+// StateNetworkVersion, it ignores NetworkVersionOverride, so the node's own decisions follow the chain.
+func scheduledNetworkVersion(ctx context.Context, sm *stmgr.StateManager, tsk types.TipSetKey) (network.Version, error) {
+	ts, err := sm.ChainStore().GetTipSetFromKey(ctx, tsk)
 	if err != nil {
 		return network.VersionMax, xerrors.Errorf("loading tipset %s: %w", tsk, err)
 	}
 
 	// TODO: Height-1 to be consistent with the rest of the APIs?
 	// But that's likely going to break a bunch of stuff.
-	return m.StateManager.GetNetworkVersion(ctx, ts.Height()), nil
+	return sm.GetNetworkVersion(ctx, ts.Height()), nil
 }
 
 func (a *StateAPI) StateActorCodeCIDs(ctx context.Context, nv network.Version) (map[string]cid.Cid, error) {
@@ -16,6 +16,8 @@ import (
 type EthTxHashManager struct {
 	StateAPI              StateAPI
 	TransactionHashLookup *ethhashlookup.EthTxHashLookup
+	// TxLookupCache, if set, caches the locations of the transactions in applied tipsets
+	TxLookupCache *EthTxLookupCache
 }
 
 func (m *EthTxHashManager) Revert(ctx context.Context, from, to *types.TipSet) error {
@@ -53,27 +55,28 @@ func (m *EthTxHashManager) PopulateExistingMappings(ctx context.Context, minHeig
 }
 
 func (m *EthTxHashManager) Apply(ctx context.Context, from, to *types.TipSet) error {
-	for _, blk := range to.Blocks() {
-		_, smsgs, err := m.StateAPI.Chain.MessagesForBlock(ctx, blk)
+	msgs, err := m.StateAPI.Chain.MessagesForTipset(ctx, to)
+	if err != nil {
+		return err
+	}
+
+	for i, msg := range msgs {
+		smsg, ok := msg.(*types.SignedMessage)
+		if !ok || smsg.Signature.Type != crypto.SigTypeDelegated {
+			continue
+		}
+
+		hash, err := ethTxHashFromSignedMessage(ctx, smsg, m.StateAPI)
 		if err != nil {
 			return err
 		}
 
-		for _, smsg := range smsgs {
-			if smsg.Signature.Type != crypto.SigTypeDelegated {
-				continue
-			}
-
-			hash, err := ethTxHashFromSignedMessage(ctx, smsg, m.StateAPI)
-			if err != nil {
-				return err
-			}
-
-			err = m.TransactionHashLookup.UpsertHash(hash, smsg.Cid())
-			if err != nil {
-				return err
-			}
+		err = m.TransactionHashLookup.UpsertHash(hash, smsg.Cid())
+		if err != nil {
+			return err
 		}
+
+		m.TxLookupCache.Add(hash, to.Key(), smsg.Cid(), i)
 	}
 
 	return nil
@@ -84,7 +87,7 @@ func (m *EthTxHashManager) ProcessSignedMessage(ctx context.Context, msg *types.
 		return
 	}
 
-	ethTx, err := newEthTxFromSignedMessage(ctx, msg, m.StateAPI)
+	ethTx, err := newEthTxFromSignedMessage(ctx, msg, m.StateAPI, EthAddressDelegatedFirst)
 	if err != nil {
 		log.Errorf("error converting filecoin message to eth tx: %s", err)
 		return
@@ -444,6 +444,10 @@ func (sm *StorageMinerAPI) SectorPreCommitPending(ctx context.Context) ([]abi.Se
 	return sm.Miner.SectorPreCommitPending(ctx)
 }
 
+func (sm *StorageMinerAPI) SectorPreCommitCollateral(ctx context.Context, sid abi.SectorNumber) (api.PreCommitCollateral, error) {
+	return sm.Miner.SectorPreCommitCollateral(ctx, sid)
+}
+
 func (sm *StorageMinerAPI) SectorMarkForUpgrade(ctx context.Context, id abi.SectorNumber, snap bool) error {
 	if !snap {
 		return fmt.Errorf("non-snap upgrades are not supported")
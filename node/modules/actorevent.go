@@ -12,6 +12,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	builtintypes "github.com/filecoin-project/go-state-types/builtin"
 
+	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/chain/events"
 	"github.com/filecoin-project/lotus/chain/events/filter"
 	"github.com/filecoin-project/lotus/chain/messagepool"
@@ -33,6 +34,18 @@ type EventAPI struct {
 
 var _ events.EventAPI = &EventAPI{}
 
+// ethEventAPI subscribes to chain notifications with a buffer sized for the Ethereum event feed.
+type ethEventAPI struct {
+	*EventAPI
+
+	chain      *store.ChainStore
+	bufferSize int
+}
+
+func (e *ethEventAPI) ChainNotify(ctx context.Context) (<-chan []*api.HeadChange, error) {
+	return e.chain.SubHeadChangesWithBuffer(ctx, e.bufferSize), nil
+}
+
 func EthEventAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRepo, fx.Lifecycle, *store.ChainStore, *stmgr.StateManager, EventAPI, *messagepool.MessagePool, full.StateAPI, full.ChainAPI) (*full.EthEvent, error) {
 	return func(mctx helpers.MetricsCtx, r repo.LockedRepo, lc fx.Lifecycle, cs *store.ChainStore, sm *stmgr.StateManager, evapi EventAPI, mp *messagepool.MessagePool, stateapi full.StateAPI, chainapi full.ChainAPI) (*full.EthEvent, error) {
 		ctx := helpers.LifecycleCtx(mctx, lc)
@@ -40,6 +53,7 @@ func EthEventAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRepo
 		ee := &full.EthEvent{
 			Chain:                cs,
 			MaxFilterHeightRange: abi.ChainEpoch(cfg.Events.MaxFilterHeightRange),
+			GetLogsMaxResults:    cfg.EthGetLogsMaxResults,
 			SubscribtionCtx:      ctx,
 		}
 
@@ -50,10 +64,24 @@ func EthEventAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRepo
 		}
 
 		ee.SubManager = &full.EthSubscriptionManager{
-			Chain:    cs,
-			StateAPI: stateapi,
-			ChainAPI: chainapi,
+			Chain:          cs,
+			StateAPI:       stateapi,
+			ChainAPI:       chainapi,
+			AddressMapping: ethAddressMapping(cfg.EthAddressMapping),
+		}
+		if cfg.EthEventBatch {
+			ee.SubManager.EventBatchInterval = time.Duration(cfg.EthEventBatchInterval)
+		}
+		switch cfg.Events.SubscriptionBackpressureMode {
+		case config.SubscriptionBackpressureDropNewest:
+			ee.SubManager.Backpressure = full.BackpressureDropNewest
+		case config.SubscriptionBackpressureBlock:
+			ee.SubManager.Backpressure = full.BackpressureBlock
+		default:
+			ee.SubManager.Backpressure = full.BackpressureDropOldest
 		}
+		ee.SubManager.SendQueueSize = cfg.Events.MaxSubscriptionBufferSize
+		ee.SubManager.SendTimeout = time.Duration(cfg.Events.SubscriptionSendTimeout)
 		ee.FilterStore = filter.NewMemFilterStore(cfg.Events.MaxFilters)
 
 		// Start garbage collection for filters
@@ -128,7 +156,11 @@ func EthEventAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRepo
 
 		lc.Append(fx.Hook{
 			OnStart: func(context.Context) error {
-				ev, err := events.NewEvents(ctx, &evapi)
+				ev, err := events.NewEvents(ctx, &ethEventAPI{
+					EventAPI:   &evapi,
+					chain:      cs,
+					bufferSize: cfg.ChainNotificationBufferSize,
+				})
 				if err != nil {
 					return err
 				}
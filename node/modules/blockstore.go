@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	bstore "github.com/ipfs/boxo/blockstore"
 	"go.uber.org/fx"
@@ -45,33 +46,36 @@ func DiscardColdBlockstore(lc fx.Lifecycle, bs dtypes.UniversalBlockstore) (dtyp
 	return blockstore.NewDiscardStore(bs), nil
 }
 
-func BadgerHotBlockstore(lc fx.Lifecycle, r repo.LockedRepo) (dtypes.HotBlockstore, error) {
-	path, err := r.SplitstorePath()
-	if err != nil {
-		return nil, err
-	}
+func BadgerHotBlockstore(cfg *config.Chainstore) func(lc fx.Lifecycle, r repo.LockedRepo) (dtypes.HotBlockstore, error) {
+	return func(lc fx.Lifecycle, r repo.LockedRepo) (dtypes.HotBlockstore, error) {
+		path, err := r.SplitstorePath()
+		if err != nil {
+			return nil, err
+		}
 
-	path = filepath.Join(path, "hot.badger")
-	if err := os.MkdirAll(path, 0755); err != nil {
-		return nil, err
-	}
+		path = filepath.Join(path, "hot.badger")
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return nil, err
+		}
 
-	opts, err := repo.BadgerBlockstoreOptions(repo.HotBlockstore, path, r.Readonly())
-	if err != nil {
-		return nil, err
-	}
+		opts, err := repo.BadgerBlockstoreOptions(repo.HotBlockstore, path, r.Readonly())
+		if err != nil {
+			return nil, err
+		}
+		opts.CompressValues = cfg.BlockCompressionEnabled
 
-	bs, err := badgerbs.Open(opts)
-	if err != nil {
-		return nil, err
-	}
+		bs, err := badgerbs.Open(opts)
+		if err != nil {
+			return nil, err
+		}
 
-	lc.Append(fx.Hook{
-		OnStop: func(_ context.Context) error {
-			return bs.Close()
-		}})
+		lc.Append(fx.Hook{
+			OnStop: func(_ context.Context) error {
+				return bs.Close()
+			}})
 
-	return bs, nil
+		return bs, nil
+	}
 }
 
 func SplitBlockstore(cfg *config.Chainstore) func(lc fx.Lifecycle, r repo.LockedRepo, ds dtypes.MetadataDS, cold dtypes.ColdBlockstore, hot dtypes.HotBlockstore) (dtypes.SplitBlockstore, error) {
@@ -90,6 +94,8 @@ func SplitBlockstore(cfg *config.Chainstore) func(lc fx.Lifecycle, r repo.Locked
 			HotstoreMaxSpaceTarget:       cfg.Splitstore.HotStoreMaxSpaceTarget,
 			HotstoreMaxSpaceThreshold:    cfg.Splitstore.HotStoreMaxSpaceThreshold,
 			HotstoreMaxSpaceSafetyBuffer: cfg.Splitstore.HotstoreMaxSpaceSafetyBuffer,
+			MovingGCThresholdRatio:       cfg.Splitstore.MovingGCThresholdRatio,
+			GCLockTimeout:                time.Duration(cfg.GCLockTimeout),
 		}
 		ss, err := splitstore.Open(path, ds, hot, cold, cfg)
 		if err != nil {
@@ -133,6 +139,40 @@ func ChainSplitBlockstore(_ fx.Lifecycle, _ helpers.MetricsCtx, bs dtypes.SplitB
 	return bs, nil
 }
 
+// CachedChainBlockstore caches up to maxSize bytes of raw chain blocks in
+// memory, and periodically emits the metrics of the cache.
+func CachedChainBlockstore(maxSize uint64) func(lc fx.Lifecycle, mctx helpers.MetricsCtx, cbs dtypes.BasicChainBlockstore) dtypes.ChainBlockstore {
+	return func(lc fx.Lifecycle, mctx helpers.MetricsCtx, cbs dtypes.BasicChainBlockstore) dtypes.ChainBlockstore {
+		cached := blockstore.NewCachedBlockstore(cbs, "chain_block", maxSize)
+
+		ctx, cancel := context.WithCancel(helpers.LifecycleCtx(mctx, lc))
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go func() {
+					ticker := time.NewTicker(blockstore.CacheMetricsEmitInterval)
+					defer ticker.Stop()
+
+					for {
+						select {
+						case <-ticker.C:
+							cached.EmitMetrics(ctx)
+						case <-ctx.Done():
+							return
+						}
+					}
+				}()
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancel()
+				return nil
+			},
+		})
+
+		return cached
+	}
+}
+
 func FallbackChainBlockstore(cbs dtypes.BasicChainBlockstore) dtypes.ChainBlockstore {
 	return &blockstore.FallbackStore{Blockstore: cbs}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/ipfs/boxo/bitswap/network"
 	"github.com/ipfs/boxo/blockservice"
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/routing"
 	"go.uber.org/fx"
 	"golang.org/x/xerrors"
@@ -27,6 +28,7 @@ import (
 	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/vm"
 	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/lib/peermgr"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 	"github.com/filecoin-project/lotus/node/modules/helpers"
 )
@@ -60,18 +62,31 @@ func ChainBlockService(bs dtypes.ExposedBlockstore, rem dtypes.ChainBitswap) dty
 	return blockservice.New(bs, rem)
 }
 
-func MessagePool(lc fx.Lifecycle, mctx helpers.MetricsCtx, us stmgr.UpgradeSchedule, mpp messagepool.Provider, ds dtypes.MetadataDS, nn dtypes.NetworkName, j journal.Journal, protector dtypes.GCReferenceProtector) (*messagepool.MessagePool, error) {
-	mp, err := messagepool.New(helpers.LifecycleCtx(mctx, lc), mpp, ds, us, nn, j)
-	if err != nil {
-		return nil, xerrors.Errorf("constructing mpool: %w", err)
+func MessagePool(republishInterval, ethPendingTimeout time.Duration, selectPolicy string, maxPendingPerSender, maxRepublishAttempts int) func(lc fx.Lifecycle, mctx helpers.MetricsCtx, us stmgr.UpgradeSchedule, mpp messagepool.Provider, ds dtypes.MetadataDS, nn dtypes.NetworkName, j journal.Journal, protector dtypes.GCReferenceProtector) (*messagepool.MessagePool, error) {
+	return func(lc fx.Lifecycle, mctx helpers.MetricsCtx, us stmgr.UpgradeSchedule, mpp messagepool.Provider, ds dtypes.MetadataDS, nn dtypes.NetworkName, j journal.Journal, protector dtypes.GCReferenceProtector) (*messagepool.MessagePool, error) {
+		sel, err := messagepool.NewMsgSelector(selectPolicy)
+		if err != nil {
+			return nil, err
+		}
+
+		mp, err := messagepool.New(helpers.LifecycleCtx(mctx, lc), mpp, ds, us, nn, j, republishInterval)
+		if err != nil {
+			return nil, xerrors.Errorf("constructing mpool: %w", err)
+		}
+		mp.SetMsgSelector(sel)
+		mp.SetMaxPendingPerSender(maxPendingPerSender)
+		mp.SetMaxRepublishAttempts(maxRepublishAttempts)
+		lc.Append(fx.Hook{
+			OnStop: func(_ context.Context) error {
+				return mp.Close()
+			},
+		})
+		protector.AddProtector(mp.ForEachPendingMessage)
+		if ethPendingTimeout > 0 {
+			mp.RunEthExpiry(ethPendingTimeout)
+		}
+		return mp, nil
 	}
-	lc.Append(fx.Hook{
-		OnStop: func(_ context.Context) error {
-			return mp.Close()
-		},
-	})
-	protector.AddProtector(mp.ForEachPendingMessage)
-	return mp, nil
 }
 
 func ChainStore(lc fx.Lifecycle,
@@ -175,6 +190,18 @@ func NewSyncer(params SyncerParams) (*chain.Syncer, error) {
 	return syncer, nil
 }
 
+// ExchangeClient returns a constructor for chain exchange clients which don't
+// fetch chain data from peers with a gossipsub score below minScore. Peers
+// without a score are accepted.
+func ExchangeClient(minScore float64) func(lc fx.Lifecycle, host host.Host, pmgr peermgr.MaybePeerMgr, sk *dtypes.ScoreKeeper) exchange.Client {
+	return func(lc fx.Lifecycle, host host.Host, pmgr peermgr.MaybePeerMgr, sk *dtypes.ScoreKeeper) exchange.Client {
+		return exchange.NewClientWithPeerFilter(func(p peer.ID) bool {
+			s, ok := sk.Get()[p]
+			return !ok || s.Score >= minScore
+		})(lc, host, pmgr)
+	}
+}
+
 func NewSlashFilter(ds dtypes.MetadataDS) *slashfilter.SlashFilter {
 	return slashfilter.New(ds)
 }
@@ -1,4 +1,10 @@
 package dtypes
 
+import "github.com/filecoin-project/go-state-types/network"
+
 type NetworkName string
 type AfterGenesisSet struct{}
+
+// NetworkVersionOverride is the network version reported by StateNetworkVersion
+// instead of the scheduled one; 0 reports the scheduled version.
+type NetworkVersionOverride network.Version
@@ -17,6 +17,10 @@ import (
 type MinerAddress address.Address
 type MinerID abi.ActorID
 
+// SectorWatcherTopic is the pubsub topic which sector state changes are
+// published to when the sector build watcher is enabled.
+type SectorWatcherTopic string
+
 // ConsiderOnlineStorageDealsConfigFunc is a function which reads from miner
 // config to determine if the user has disabled storage deals (or not).
 type ConsiderOnlineStorageDealsConfigFunc func() (bool, error)
@@ -7,22 +7,44 @@ import (
 	"time"
 
 	"go.uber.org/fx"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-state-types/abi"
 
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/ethhashlookup"
 	"github.com/filecoin-project/lotus/chain/events"
 	"github.com/filecoin-project/lotus/chain/messagepool"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/impl/full"
 	"github.com/filecoin-project/lotus/node/modules/helpers"
 	"github.com/filecoin-project/lotus/node/repo"
 )
 
-func EthModuleAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRepo, fx.Lifecycle, *store.ChainStore, *stmgr.StateManager, EventAPI, *messagepool.MessagePool, full.StateAPI, full.ChainAPI, full.MpoolAPI, full.SyncAPI) (*full.EthModule, error) {
-	return func(mctx helpers.MetricsCtx, r repo.LockedRepo, lc fx.Lifecycle, cs *store.ChainStore, sm *stmgr.StateManager, evapi EventAPI, mp *messagepool.MessagePool, stateapi full.StateAPI, chainapi full.ChainAPI, mpoolapi full.MpoolAPI, syncapi full.SyncAPI) (*full.EthModule, error) {
+// ethBlockNumberLag converts the eth_blockNumber delay to whole epochs, rounding up.
+func ethBlockNumberLag(delay time.Duration) abi.ChainEpoch {
+	epoch := time.Duration(build.BlockDelaySecs) * time.Second
+	return abi.ChainEpoch((delay + epoch - 1) / epoch)
+}
+
+// ethAddressMapping converts the Fevm.EthAddressMapping setting.
+func ethAddressMapping(mapping string) full.EthAddressMapping {
+	switch mapping {
+	case config.EthAddressMappingIDBased:
+		return full.EthAddressIDBased
+	case config.EthAddressMappingStrict:
+		return full.EthAddressStrict
+	default:
+		return full.EthAddressDelegatedFirst
+	}
+}
+
+func EthModuleAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRepo, fx.Lifecycle, *store.ChainStore, *stmgr.StateManager, EventAPI, *messagepool.MessagePool, full.StateAPI, full.ChainAPI, full.MpoolAPI, full.SyncAPI, api.Wallet) (*full.EthModule, error) {
+	return func(mctx helpers.MetricsCtx, r repo.LockedRepo, lc fx.Lifecycle, cs *store.ChainStore, sm *stmgr.StateManager, evapi EventAPI, mp *messagepool.MessagePool, stateapi full.StateAPI, chainapi full.ChainAPI, mpoolapi full.MpoolAPI, syncapi full.SyncAPI, wallet api.Wallet) (*full.EthModule, error) {
 		sqlitePath, err := r.SqlitePath()
 		if err != nil {
 			return nil, err
@@ -45,9 +67,32 @@ func EthModuleAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRep
 			},
 		})
 
+		txLookupCache, err := full.NewEthTxLookupCache(cfg.EthGetTransactionByHashCacheSize)
+		if err != nil {
+			return nil, err
+		}
+
+		var callCache *full.EthCallCache
+		if cfg.EthCallCacheEnabled {
+			callCache = full.NewEthCallCache(cfg.EthCallCacheSize, time.Duration(cfg.EthCallCacheTTL))
+		}
+
+		var callWhitelist map[ethtypes.EthAddress]struct{}
+		if len(cfg.EthCallAddressWhitelist) > 0 {
+			callWhitelist = make(map[ethtypes.EthAddress]struct{}, len(cfg.EthCallAddressWhitelist))
+			for _, s := range cfg.EthCallAddressWhitelist {
+				addr, err := ethtypes.ParseEthAddress(s)
+				if err != nil {
+					return nil, xerrors.Errorf("parsing eth_call whitelist address %q: %w", s, err)
+				}
+				callWhitelist[addr] = struct{}{}
+			}
+		}
+
 		ethTxHashManager := full.EthTxHashManager{
 			StateAPI:              stateapi,
 			TransactionHashLookup: transactionHashLookup,
+			TxLookupCache:         txLookupCache,
 		}
 
 		if !dbAlreadyExists {
@@ -90,6 +135,10 @@ func EthModuleAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRep
 			},
 		})
 
+		if !cfg.EnableEthWalletMethods {
+			wallet = nil
+		}
+
 		return &full.EthModule{
 			Chain:        cs,
 			Mpool:        mp,
@@ -101,6 +150,16 @@ func EthModuleAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRep
 			SyncAPI:  syncapi,
 
 			EthTxHashManager: &ethTxHashManager,
+
+			BlockTransactionCountMax: cfg.EthGetBlockTransactionCountMax,
+			FilecoinSyncStatus:       cfg.EthSyncStatusMode == config.EthSyncStatusModeFilecoin,
+			AddressMapping:           ethAddressMapping(cfg.EthAddressMapping),
+			MaxCodeSize:              cfg.EthMaxCodeSize,
+			EnableTypedTransactions:  cfg.EnableEIP2718Transactions,
+			BlockNumberLag:           ethBlockNumberLag(time.Duration(cfg.EthBlockPropagationDelay)),
+			CallCache:                callCache,
+			Wallet:                   wallet,
+			CallWhitelist:            callWhitelist,
 		}, nil
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/host"
 	p2pbhost "github.com/libp2p/go-libp2p/p2p/host/basic"
+	"github.com/libp2p/go-libp2p/p2p/protocol/identify"
 	mafilter "github.com/libp2p/go-maddr-filter"
 	ma "github.com/multiformats/go-multiaddr"
 	mamask "github.com/whyrusleeping/multiaddr-filter"
@@ -68,6 +69,22 @@ func AddrsFactory(announce []string, noAnnounce []string) func() (opts Libp2pOpt
 	}
 }
 
+// ObservedAddrActivationThreshold sets the number of distinct peers which must
+// report the same observed address before it is considered an address of the
+// host. The identify service keeps the threshold in a package variable, so it
+// applies to all hosts in the process.
+func ObservedAddrActivationThreshold(n int) func() (opts Libp2pOpts, err error) {
+	return func() (opts Libp2pOpts, err error) {
+		if n < 1 {
+			return opts, fmt.Errorf("observed address activation threshold must be at least 1, got %d", n)
+		}
+		if identify.ActivationThresh != n {
+			identify.ActivationThresh = n
+		}
+		return
+	}
+}
+
 func listenAddresses(addresses []string) ([]ma.Multiaddr, error) {
 	var listen []ma.Multiaddr
 	for _, addr := range addresses {
@@ -81,6 +98,23 @@ func listenAddresses(addresses []string) ([]ma.Multiaddr, error) {
 	return listen, nil
 }
 
+// WithoutWebTransport returns addresses without the QUIC WebTransport
+// addresses. Addresses which can't be parsed are kept, to be reported when
+// listening.
+func WithoutWebTransport(addresses []string) []string {
+	out := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		maddr, err := ma.NewMultiaddr(addr)
+		if err == nil {
+			if _, err := maddr.ValueForProtocol(ma.P_WEBTRANSPORT); err == nil {
+				continue
+			}
+		}
+		out = append(out, addr)
+	}
+	return out
+}
+
 func StartListening(addresses []string) func(host host.Host) error {
 	return func(host host.Host) error {
 		listenAddrs, err := listenAddresses(addresses)
@@ -6,7 +6,9 @@ import (
 
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/libp2p/go-libp2p"
+	ifconnmgr "github.com/libp2p/go-libp2p/core/connmgr"
 	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/peerstore"
 	"github.com/libp2p/go-libp2p/p2p/net/connmgr"
@@ -67,9 +69,9 @@ func genLibp2pKey() (crypto.PrivKey, error) {
 
 // Misc options
 
-func ConnectionManager(low, high uint, grace time.Duration, protected []string) func() (opts Libp2pOpts, err error) {
+func ConnectionManager(low, high uint, grace, emergencyGrace, silence time.Duration, protected []string) func() (opts Libp2pOpts, err error) {
 	return func() (Libp2pOpts, error) {
-		cm, err := connmgr.NewConnManager(int(low), int(high), connmgr.WithGracePeriod(grace))
+		cm, err := newConnManager(low, high, grace, emergencyGrace, silence)
 		if err != nil {
 			return Libp2pOpts{}, err
 		}
@@ -98,6 +100,60 @@ func ConnectionManager(low, high uint, grace time.Duration, protected []string)
 	}
 }
 
+// newConnManager creates the basic libp2p connection manager. Zero emergencyGrace
+// and silence values keep the libp2p defaults.
+func newConnManager(low, high uint, grace, emergencyGrace, silence time.Duration) (ifconnmgr.ConnManager, error) {
+	opts := []connmgr.Option{connmgr.WithGracePeriod(grace)}
+	if silence > 0 {
+		opts = append(opts, connmgr.WithSilencePeriod(silence))
+	}
+	if emergencyGrace > 0 {
+		opts = append(opts, connmgr.WithEmergencyTrim(true))
+	}
+
+	cm, err := connmgr.NewConnManager(int(low), int(high), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if emergencyGrace > 0 {
+		return &emergencyGraceConnMgr{BasicConnMgr: cm, gracePeriod: emergencyGrace}, nil
+	}
+	return cm, nil
+}
+
+// emergencyGraceConnMgr protects new connections for gracePeriod. Emergency trims
+// ignore the connection manager grace period, but skip protected peers.
+type emergencyGraceConnMgr struct {
+	*connmgr.BasicConnMgr
+	gracePeriod time.Duration
+}
+
+func emergencyGraceTag(c network.Conn) string {
+	return "emergency-grace-" + c.ID()
+}
+
+func (cm *emergencyGraceConnMgr) Notifee() network.Notifiee {
+	inner := cm.BasicConnMgr.Notifee()
+	return &network.NotifyBundle{
+		ListenF:      inner.Listen,
+		ListenCloseF: inner.ListenClose,
+		ConnectedF: func(n network.Network, c network.Conn) {
+			inner.Connected(n, c)
+
+			p, tag := c.RemotePeer(), emergencyGraceTag(c)
+			cm.Protect(p, tag)
+			time.AfterFunc(cm.gracePeriod, func() {
+				cm.Unprotect(p, tag)
+			})
+		},
+		DisconnectedF: func(n network.Network, c network.Conn) {
+			cm.Unprotect(c.RemotePeer(), emergencyGraceTag(c))
+			inner.Disconnected(n, c)
+		},
+	}
+}
+
 func PstoreAddSelfKeys(id peer.ID, sk crypto.PrivKey, ps peerstore.Peerstore) error {
 	if err := ps.AddPubKey(id, sk.GetPublic()); err != nil {
 		return err
@@ -0,0 +1,81 @@
+package lp2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/metrics"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/net/connmgr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConnManager(t *testing.T) {
+	// zero values keep the plain basic connection manager
+	cm, err := newConnManager(150, 180, 20*time.Second, 0, 0)
+	require.NoError(t, err)
+	basic, ok := cm.(*connmgr.BasicConnMgr)
+	require.True(t, ok)
+	info := basic.GetInfo()
+	require.Equal(t, 150, info.LowWater)
+	require.Equal(t, 180, info.HighWater)
+	require.Equal(t, 20*time.Second, info.GracePeriod)
+	require.NoError(t, cm.Close())
+
+	cm, err = newConnManager(150, 180, 20*time.Second, time.Minute, 30*time.Second)
+	require.NoError(t, err)
+	egcm, ok := cm.(*emergencyGraceConnMgr)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, egcm.gracePeriod)
+	require.Equal(t, 20*time.Second, egcm.GetInfo().GracePeriod)
+	require.NoError(t, cm.Close())
+}
+
+func TestWithoutWebTransport(t *testing.T) {
+	addrs := []string{
+		"/ip4/0.0.0.0/tcp/0",
+		"/ip4/0.0.0.0/udp/0/quic-v1",
+		"/ip4/0.0.0.0/udp/0/quic-v1/webtransport",
+		"/ip6/::/udp/0/quic-v1/webtransport",
+		"not-an-address",
+	}
+	require.Equal(t, []string{
+		"/ip4/0.0.0.0/tcp/0",
+		"/ip4/0.0.0.0/udp/0/quic-v1",
+		"not-an-address",
+	}, WithoutWebTransport(addrs))
+}
+
+func TestHostCollector(t *testing.T) {
+	bwc := metrics.NewBandwidthCounter()
+	h1, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"), libp2p.BandwidthReporter(bwc))
+	require.NoError(t, err)
+	defer h1.Close() //nolint:errcheck
+	h2, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	require.NoError(t, err)
+	defer h2.Close() //nolint:errcheck
+
+	require.NoError(t, h1.Connect(context.Background(), peer.AddrInfo{ID: h2.ID(), Addrs: h2.Addrs()}))
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(&hostCollector{h: h1, bwr: bwc}))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	var outbound float64
+	for _, f := range families {
+		if f.GetName() != "lotus_libp2p_connections" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			if m.GetLabel()[0].GetValue() == "outbound" {
+				outbound = m.GetGauge().GetValue()
+			}
+		}
+	}
+	require.Equal(t, float64(1), outbound)
+}
@@ -0,0 +1,73 @@
+package lp2p
+
+import (
+	"context"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+)
+
+var (
+	bandwidthDesc = prometheus.NewDesc("lotus_libp2p_bandwidth_bytes_total",
+		"Bytes transferred by libp2p streams, by protocol and direction", []string{"protocol", "direction"}, nil)
+	connectionsDesc = prometheus.NewDesc("lotus_libp2p_connections",
+		"Open libp2p connections, by direction", []string{"direction"}, nil)
+	streamsDesc = prometheus.NewDesc("lotus_libp2p_streams",
+		"Open libp2p streams, by direction", []string{"direction"}, nil)
+)
+
+// hostCollector exports the bandwidth counter of the host, and the number of
+// connections and streams it has open.
+type hostCollector struct {
+	h   host.Host
+	bwr metrics.Reporter
+}
+
+func (c *hostCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- bandwidthDesc
+	ch <- connectionsDesc
+	ch <- streamsDesc
+}
+
+func (c *hostCollector) Collect(ch chan<- prometheus.Metric) {
+	for proto, s := range c.bwr.GetBandwidthByProtocol() {
+		ch <- prometheus.MustNewConstMetric(bandwidthDesc, prometheus.CounterValue, float64(s.TotalIn), string(proto), "in")
+		ch <- prometheus.MustNewConstMetric(bandwidthDesc, prometheus.CounterValue, float64(s.TotalOut), string(proto), "out")
+	}
+
+	conns := map[string]int{}
+	streams := map[string]int{}
+	for _, conn := range c.h.Network().Conns() {
+		conns[strings.ToLower(conn.Stat().Direction.String())]++
+		for _, s := range conn.GetStreams() {
+			streams[strings.ToLower(s.Stat().Direction.String())]++
+		}
+	}
+	for dir, n := range conns {
+		ch <- prometheus.MustNewConstMetric(connectionsDesc, prometheus.GaugeValue, float64(n), dir)
+	}
+	for dir, n := range streams {
+		ch <- prometheus.MustNewConstMetric(streamsDesc, prometheus.GaugeValue, float64(n), dir)
+	}
+}
+
+// RegisterHostMetrics exports the bandwidth counter of the host by protocol,
+// and its open connections and streams, in the default Prometheus registry.
+func RegisterHostMetrics(lc fx.Lifecycle, h RawHost, bwr metrics.Reporter) error {
+	c := &hostCollector{h: h, bwr: bwr}
+	if err := prometheus.DefaultRegisterer.Register(c); err != nil {
+		return xerrors.Errorf("registering libp2p host metrics: %w", err)
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			prometheus.DefaultRegisterer.Unregister(c)
+			return nil
+		},
+	})
+	return nil
+}
@@ -85,6 +85,7 @@ type GossipIn struct {
 	Cfg  *config.Pubsub
 	Sk   *dtypes.ScoreKeeper
 	Dr   dtypes.DrandSchedule
+	Swt  dtypes.SectorWatcherTopic `optional:"true"`
 }
 
 func getDrandTopic(chainInfoJSON string) (string, error) {
@@ -379,6 +380,9 @@ func GossipSub(in GossipIn) (service *pubsub.PubSub, err error) {
 		build.IndexerIngestTopic(in.Nn),
 	}
 	allowTopics = append(allowTopics, drandTopics...)
+	if in.Swt != "" {
+		allowTopics = append(allowTopics, string(in.Swt))
+	}
 	options = append(options,
 		pubsub.WithSubscriptionFilter(
 			pubsub.WrapLimitSubscriptionFilter(
@@ -26,7 +26,7 @@ import (
 
 var rcmgrMetricsOnce sync.Once
 
-func ResourceManager(connMgrHi uint) func(lc fx.Lifecycle, repo repo.LockedRepo) (network.ResourceManager, error) {
+func ResourceManager(connMgrHi uint, protocolPeerLimits map[string]int) func(lc fx.Lifecycle, repo repo.LockedRepo) (network.ResourceManager, error) {
 	return func(lc fx.Lifecycle, repo repo.LockedRepo) (network.ResourceManager, error) {
 		isFullNode := repo.RepoType().Type() == "FullNode"
 		envvar := os.Getenv("LOTUS_RCMGR")
@@ -87,6 +87,16 @@ func ResourceManager(connMgrHi uint) func(lc fx.Lifecycle, repo repo.LockedRepo)
 			log.Info("adjusted default resource manager limits")
 		}
 
+		if len(protocolPeerLimits) > 0 {
+			changes.ProtocolPeer = make(map[protocol.ID]rcmgr.ResourceLimits, len(protocolPeerLimits))
+			for proto, limit := range protocolPeerLimits {
+				changes.ProtocolPeer[protocol.ID(proto)] = rcmgr.ResourceLimits{
+					Streams: rcmgr.LimitVal(limit),
+				}
+			}
+			log.Infow("applied per-protocol peer stream limits", "limits", protocolPeerLimits)
+		}
+
 		changedLimitConfig := changes.Build(defaultLimitConfig)
 		// initialize
 		var limiter rcmgr.Limiter
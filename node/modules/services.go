@@ -133,7 +133,7 @@ func waitForSync(stmgr *stmgr.StateManager, epochs int, subscribe func()) {
 	})
 }
 
-func HandleIncomingBlocks(mctx helpers.MetricsCtx,
+func HandleIncomingBlocks(validationTimeout time.Duration, validatorCacheSize, fetchLimit int) func(mctx helpers.MetricsCtx,
 	lc fx.Lifecycle,
 	ps *pubsub.PubSub,
 	s *chain.Syncer,
@@ -142,27 +142,39 @@ func HandleIncomingBlocks(mctx helpers.MetricsCtx,
 	cns consensus.Consensus,
 	h host.Host,
 	nn dtypes.NetworkName) {
-	ctx := helpers.LifecycleCtx(mctx, lc)
-
-	v := sub.NewBlockValidator(
-		h.ID(), chain, cns,
-		func(p peer.ID) {
-			ps.BlacklistPeer(p)
-			h.ConnManager().TagPeer(p, "badblock", -1000)
-		})
+	return func(mctx helpers.MetricsCtx,
+		lc fx.Lifecycle,
+		ps *pubsub.PubSub,
+		s *chain.Syncer,
+		bserv dtypes.ChainBlockService,
+		chain *store.ChainStore,
+		cns consensus.Consensus,
+		h host.Host,
+		nn dtypes.NetworkName) {
+		ctx := helpers.LifecycleCtx(mctx, lc)
+
+		v := sub.NewBlockValidator(
+			h.ID(), chain, cns,
+			func(p peer.ID) {
+				ps.BlacklistPeer(p)
+				h.ConnManager().TagPeer(p, "badblock", -1000)
+			},
+			validationTimeout,
+			validatorCacheSize)
+
+		if err := ps.RegisterTopicValidator(build.BlocksTopic(nn), v.Validate); err != nil {
+			panic(err)
+		}
 
-	if err := ps.RegisterTopicValidator(build.BlocksTopic(nn), v.Validate); err != nil {
-		panic(err)
-	}
+		log.Infof("subscribing to pubsub topic %s", build.BlocksTopic(nn))
 
-	log.Infof("subscribing to pubsub topic %s", build.BlocksTopic(nn))
+		blocksub, err := ps.Subscribe(build.BlocksTopic(nn)) //nolint
+		if err != nil {
+			panic(err)
+		}
 
-	blocksub, err := ps.Subscribe(build.BlocksTopic(nn)) //nolint
-	if err != nil {
-		panic(err)
+		go sub.HandleIncomingBlocks(ctx, blocksub, s, bserv, h.ConnManager(), fetchLimit)
 	}
-
-	go sub.HandleIncomingBlocks(ctx, blocksub, s, bserv, h.ConnManager())
 }
 
 func HandleIncomingMessages(mctx helpers.MetricsCtx, lc fx.Lifecycle, ps *pubsub.PubSub, stmgr *stmgr.StateManager, mpool *messagepool.MessagePool, h host.Host, nn dtypes.NetworkName, bootstrapper dtypes.Bootstrapper) {
@@ -254,6 +266,18 @@ func BuiltinDrandConfig() dtypes.DrandSchedule {
 	return build.DrandConfigSchedule()
 }
 
+// DrandConfigWithServers returns the builtin drand schedule with the HTTP endpoints of
+// every drand network replaced by the given servers.
+func DrandConfigWithServers(servers []string) func() dtypes.DrandSchedule {
+	return func() dtypes.DrandSchedule {
+		sched := build.DrandConfigSchedule()
+		for i := range sched {
+			sched[i].Config.Servers = servers
+		}
+		return sched
+	}
+}
+
 func RandomSchedule(lc fx.Lifecycle, mctx helpers.MetricsCtx, p RandomBeaconParams, _ dtypes.AfterGenesisSet) (beacon.Schedule, error) {
 	gen, err := p.Cs.GetGenesis(helpers.LifecycleCtx(mctx, lc))
 	if err != nil {
@@ -22,3 +22,16 @@ func StateManager(lc fx.Lifecycle, cs *store.ChainStore, exec stmgr.Executor, sy
 	})
 	return sm, nil
 }
+
+// StateManagerWithoutCache is StateManager, with the in-memory caches of the
+// state manager disabled.
+func StateManagerWithoutCache(lc fx.Lifecycle, cs *store.ChainStore, exec stmgr.Executor, sys vm.SyscallBuilder, us stmgr.UpgradeSchedule, b beacon.Schedule, metadataDs dtypes.MetadataDS, msgIndex index.MsgIndex) (*stmgr.StateManager, error) {
+	sm, err := StateManager(lc, cs, exec, sys, us, b, metadataDs, msgIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Warn("state manager cache is disabled (Chainstore.StateManagerCacheEnabled); state queries and execution traces will be slower")
+	sm.DisableCache()
+	return sm, nil
+}
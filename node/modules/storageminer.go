@@ -55,8 +55,12 @@ import (
 	"github.com/filecoin-project/lotus/chain/gen/slashfilter"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/journal/alerting"
+	"github.com/filecoin-project/lotus/lib/bufferds"
+	"github.com/filecoin-project/lotus/lib/gputemp"
 	"github.com/filecoin-project/lotus/markets"
 	"github.com/filecoin-project/lotus/markets/dagstore"
+	"github.com/filecoin-project/lotus/markets/dealfilter"
 	"github.com/filecoin-project/lotus/markets/idxprov"
 	marketevents "github.com/filecoin-project/lotus/markets/loggers"
 	"github.com/filecoin-project/lotus/markets/pricing"
@@ -207,6 +211,15 @@ func AddressSelector(addrConf *config.MinerAddressConfig) func() (*ctladdr.Addre
 			as.DealPublishControl = append(as.DealPublishControl, addr)
 		}
 
+		if addrConf.DealPublishWorker != "" {
+			addr, err := address.NewFromString(addrConf.DealPublishWorker)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing deal publish worker address: %w", err)
+			}
+
+			as.DealPublishWorker = addr
+		}
+
 		return as, nil
 	}
 }
@@ -442,18 +455,31 @@ func NewProviderDataTransfer(lc fx.Lifecycle, net dtypes.ProviderTransferNetwork
 
 // NewProviderPieceStore creates a statestore for storing metadata about pieces
 // shared by the storage and retrieval providers
-func NewProviderPieceStore(lc fx.Lifecycle, ds dtypes.MetadataDS) (dtypes.ProviderPieceStore, error) {
-	ps, err := piecestoreimpl.NewPieceStore(namespace.Wrap(ds, datastore.NewKey("/storagemarket")))
-	if err != nil {
-		return nil, err
+func NewProviderPieceStore(cfg config.DAGStoreConfig) func(lc fx.Lifecycle, ds dtypes.MetadataDS) (dtypes.ProviderPieceStore, error) {
+	return func(lc fx.Lifecycle, ds dtypes.MetadataDS) (dtypes.ProviderPieceStore, error) {
+		var pds datastore.Batching = namespace.Wrap(ds, datastore.NewKey("/storagemarket"))
+		if interval := time.Duration(cfg.PieceStoreFlushInterval); interval > 0 {
+			bds := bufferds.Wrap(pds, interval)
+			lc.Append(fx.Hook{
+				OnStop: func(_ context.Context) error {
+					return bds.Close()
+				},
+			})
+			pds = bds
+		}
+
+		ps, err := piecestoreimpl.NewPieceStore(pds)
+		if err != nil {
+			return nil, err
+		}
+		ps.OnReady(marketevents.ReadyLogger("piecestore"))
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return ps.Start(ctx)
+			},
+		})
+		return ps, nil
 	}
-	ps.OnReady(marketevents.ReadyLogger("piecestore"))
-	lc.Append(fx.Hook{
-		OnStart: func(ctx context.Context) error {
-			return ps.Start(ctx)
-		},
-	})
-	return ps, nil
 }
 
 // StagingBlockstore creates a blockstore for staging blocks for a miner
@@ -530,7 +556,7 @@ func NewStorageAsk(ctx helpers.MetricsCtx, fapi v1api.FullNode, ds dtypes.Metada
 		storagemarket.MaxPieceSize(abi.PaddedPieceSize(mi.SectorSize)))
 }
 
-func BasicDealFilter(cfg config.DealmakingConfig, user dtypes.StorageDealFilter) func(onlineOk dtypes.ConsiderOnlineStorageDealsConfigFunc,
+func BasicDealFilter(cfg config.DealmakingConfig, user dtypes.StorageDealFilter, expr dealfilter.DealFilter) func(onlineOk dtypes.ConsiderOnlineStorageDealsConfigFunc,
 	offlineOk dtypes.ConsiderOfflineStorageDealsConfigFunc,
 	verifiedOk dtypes.ConsiderVerifiedStorageDealsConfigFunc,
 	unverifiedOk dtypes.ConsiderUnverifiedStorageDealsConfigFunc,
@@ -539,6 +565,7 @@ func BasicDealFilter(cfg config.DealmakingConfig, user dtypes.StorageDealFilter)
 	startDelay dtypes.GetMaxDealStartDelayFunc,
 	spn storagemarket.StorageProviderNode,
 	r repo.LockedRepo,
+	full v1api.FullNode,
 ) dtypes.StorageDealFilter {
 	return func(onlineOk dtypes.ConsiderOnlineStorageDealsConfigFunc,
 		offlineOk dtypes.ConsiderOfflineStorageDealsConfigFunc,
@@ -549,7 +576,13 @@ func BasicDealFilter(cfg config.DealmakingConfig, user dtypes.StorageDealFilter)
 		startDelay dtypes.GetMaxDealStartDelayFunc,
 		spn storagemarket.StorageProviderNode,
 		r repo.LockedRepo,
+		full v1api.FullNode,
 	) dtypes.StorageDealFilter {
+		userFilter := user
+		if expr != nil {
+			userFilter = dealfilter.ExpressionStorageDealFilter(expr, full, user)
+		}
+
 
 		return func(ctx context.Context, deal storagemarket.MinerDeal) (bool, string, error) {
 			b, err := onlineOk()
@@ -643,8 +676,13 @@ func BasicDealFilter(cfg config.DealmakingConfig, user dtypes.StorageDealFilter)
 				return false, fmt.Sprintf("deal start epoch is too far in the future: %s > %s", deal.Proposal.StartEpoch, maxStartEpoch), nil
 			}
 
-			if user != nil {
-				return user(ctx, deal)
+			if priceCap := abi.TokenAmount(cfg.StoragePriceCap); !priceCap.Nil() && priceCap.GreaterThan(big.Zero()) && deal.Proposal.StoragePricePerEpoch.GreaterThan(priceCap) {
+				log.Warnw("proposed deal price exceeds StoragePriceCap; rejecting storage deal proposal", "piece_cid", deal.Proposal.PieceCID, "client", deal.Client.String(), "price", types.FIL(deal.Proposal.StoragePricePerEpoch), "cap", cfg.StoragePriceCap)
+				return false, "price exceeds cap", nil
+			}
+
+			if userFilter != nil {
+				return userFilter(ctx, deal)
 			}
 
 			return true, "", nil
@@ -745,7 +783,17 @@ func RetrievalPricingFunc(cfg config.DealmakingConfig) func(_ dtypes.ConsiderOnl
 			return pricing.ExternalRetrievalPricingFunc(cfg.RetrievalPricing.External.Path)
 		}
 
-		return retrievalimpl.DefaultPricingFunc(cfg.RetrievalPricing.Default.VerifiedDealsFreeTransfer)
+		defaultPricing := retrievalimpl.DefaultPricingFunc(cfg.RetrievalPricing.Default.VerifiedDealsFreeTransfer)
+
+		bytePrice := abi.TokenAmount(cfg.RetrievalPricing.Default.RetrievalPricingDefaultBytePrice)
+		if bytePrice.NilOrZero() {
+			return defaultPricing
+		}
+
+		return func(ctx context.Context, pricingInput retrievalmarket.PricingInput) (retrievalmarket.Ask, error) {
+			pricingInput.CurrentAsk.PricePerByte = bytePrice
+			return defaultPricing(ctx, pricingInput)
+		}
 	}
 }
 
@@ -783,16 +831,24 @@ func RetrievalProvider(
 var WorkerCallsPrefix = datastore.NewKey("/worker/calls")
 var ManagerWorkPrefix = datastore.NewKey("/stmgr/calls")
 
-func LocalStorage(mctx helpers.MetricsCtx, lc fx.Lifecycle, ls paths.LocalStorage, si paths.SectorIndex, urls paths.URLs) (*paths.Local, error) {
+func LocalStorage(mctx helpers.MetricsCtx, lc fx.Lifecycle, ls paths.LocalStorage, si paths.SectorIndex, urls paths.URLs, sc config.SealerConfig) (*paths.Local, error) {
 	ctx := helpers.LifecycleCtx(mctx, lc)
-	return paths.NewLocal(ctx, ls, si, urls)
+	lstor, err := paths.NewLocal(ctx, ls, si, urls)
+	if err != nil {
+		return nil, err
+	}
+	lstor.SetStagingPath(sc.StorageSealerStaging)
+	return lstor, nil
 }
 
 func RemoteStorage(lstor *paths.Local, si paths.SectorIndex, sa sealer.StorageAuth, sc config.SealerConfig) *paths.Remote {
-	return paths.NewRemote(lstor, si, http.Header(sa), sc.ParallelFetchLimit, &paths.DefaultPartialFileHandler{})
+	remote := paths.NewRemote(lstor, si, http.Header(sa), sc.ParallelFetchLimit, &paths.DefaultPartialFileHandler{})
+	remote.SetBandwidthLimiter(paths.NewBandwidthLimiter(sc.NetworkBandwidthLimitMBps))
+	remote.SetMoveLimit(sc.ParallelSectorMoveLimit)
+	return remote
 }
 
-func SectorStorage(mctx helpers.MetricsCtx, lc fx.Lifecycle, lstor *paths.Local, stor paths.Store, ls paths.LocalStorage, si paths.SectorIndex, sc config.SealerConfig, pc config.ProvingConfig, ds dtypes.MetadataDS) (*sealer.Manager, error) {
+func SectorStorage(mctx helpers.MetricsCtx, lc fx.Lifecycle, lstor *paths.Local, stor paths.Store, ls paths.LocalStorage, si paths.SectorIndex, sc config.SealerConfig, pc config.ProvingConfig, sa sealer.StorageAuth, ds dtypes.MetadataDS) (*sealer.Manager, error) {
 	ctx := helpers.LifecycleCtx(mctx, lc)
 
 	wsts := statestore.New(namespace.Wrap(ds, WorkerCallsPrefix))
@@ -803,6 +859,13 @@ func SectorStorage(mctx helpers.MetricsCtx, lc fx.Lifecycle, lstor *paths.Local,
 		return nil, err
 	}
 
+	if pc.WindowPoStWorkerType == config.WindowPoStWorkerRemote {
+		sst.UseRemoteWindowPoSt(pc.RemotePoStEndpoint, http.Header(sa))
+	}
+	if pc.UseFallbackProver {
+		sst.UseFallbackWindowPoSt(pc.FallbackProverCommand, time.Duration(pc.FallbackProverTimeout))
+	}
+
 	lc.Append(fx.Hook{
 		OnStop: sst.Close,
 	})
@@ -810,6 +873,50 @@ func SectorStorage(mctx helpers.MetricsCtx, lc fx.Lifecycle, lstor *paths.Local,
 	return sst, nil
 }
 
+// GPUTemperatureGuard pauses new GPU tasks on the local worker while a GPU of this machine is hotter than
+// limit degrees Celsius, raising an alert until they're resumed.
+func GPUTemperatureGuard(limit int, interval time.Duration) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, m *sealer.Manager, al *alerting.Alerting) {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, m *sealer.Manager, al *alerting.Alerting) {
+		if _, err := gputemp.Read(); err != nil {
+			log.Warnw("GPU temperature monitoring not available", "error", err)
+			return
+		}
+
+		alert := al.AddAlertType("sealing", "gpu-temperature")
+		var resume func()
+		g := &gputemp.Guard{
+			Limit:    limit,
+			Interval: interval,
+			Read:     gputemp.Read,
+			Pause: func(temp int) {
+				log.Warnw("GPU temperature above limit, pausing new GPU tasks", "temperature", temp, "limit", limit)
+				resume = m.PauseLocalGPUTasks()
+				al.Raise(alert, map[string]interface{}{
+					"message":     "GPU temperature above limit, new GPU tasks are paused",
+					"temperature": temp,
+					"limit":       limit,
+				})
+			},
+			Resume: func(temp int) {
+				log.Infow("GPU temperature back to normal, resuming GPU tasks", "temperature", temp)
+				resume()
+				al.Resolve(alert, map[string]interface{}{
+					"message":     "GPU temperature back to normal",
+					"temperature": temp,
+				})
+			},
+		}
+
+		ctx := helpers.LifecycleCtx(mctx, lc)
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go g.Run(ctx)
+				return nil
+			},
+		})
+	}
+}
+
 func StorageAuth(ctx helpers.MetricsCtx, ca v0api.Common) (sealer.StorageAuth, error) {
 	token, err := ca.AuthNew(ctx, []auth.Permission{"admin"})
 	if err != nil {
@@ -993,31 +1100,56 @@ func NewSetSealConfigFunc(r repo.LockedRepo) (dtypes.SetSealingConfigFunc, error
 				WaitDealsDelay:                  config.Duration(cfg.WaitDealsDelay),
 				MakeNewSectorForDeals:           cfg.MakeNewSectorForDeals,
 				MinUpgradeSectorExpiration:      cfg.MinUpgradeSectorExpiration,
+				SnapDealSectorReuseWindow:       cfg.SnapDealSectorReuseWindow,
 				MakeCCSectorsAvailable:          cfg.MakeCCSectorsAvailable,
 				AlwaysKeepUnsealedCopy:          cfg.AlwaysKeepUnsealedCopy,
 				FinalizeEarly:                   cfg.FinalizeEarly,
+				SectorSealRetryPolicy:           cfg.SectorSealRetryPolicy,
 
 				CollateralFromMinerBalance: cfg.CollateralFromMinerBalance,
 				AvailableBalanceBuffer:     types.FIL(cfg.AvailableBalanceBuffer),
 				DisableCollateralFallback:  cfg.DisableCollateralFallback,
+				PledgeCollateralBuffer:     types.FIL(cfg.PledgeCollateralBuffer),
 
 				MaxPreCommitBatch:   cfg.MaxPreCommitBatch,
 				PreCommitBatchWait:  config.Duration(cfg.PreCommitBatchWait),
 				PreCommitBatchSlack: config.Duration(cfg.PreCommitBatchSlack),
+				P1StopFraction:      cfg.P1StopFraction,
+
+				PreCommitBatchAbortOnExpiry: cfg.PreCommitBatchAbortOnExpiry,
+
+				MaxPreCommitBatchByValue:  cfg.MaxPreCommitBatchByValue,
+				MaxPreCommitBatchFeeValue: types.FIL(cfg.MaxPreCommitBatchFeeValue),
+
+				PreCommitGasMultiplier:     cfg.PreCommitGasMultiplier,
+				CommitGasMultiplier:        cfg.CommitGasMultiplier,
+				ReplicaUpdateGasMultiplier: cfg.ReplicaUpdateGasMultiplier,
 
 				AggregateCommits:           cfg.AggregateCommits,
 				MinCommitBatch:             cfg.MinCommitBatch,
 				MaxCommitBatch:             cfg.MaxCommitBatch,
 				CommitBatchWait:            config.Duration(cfg.CommitBatchWait),
 				CommitBatchSlack:           config.Duration(cfg.CommitBatchSlack),
+				CommitBatchOverlapWindow:   config.Duration(cfg.CommitBatchOverlapWindow),
 				AggregateAboveBaseFee:      types.FIL(cfg.AggregateAboveBaseFee),
 				BatchPreCommitAboveBaseFee: types.FIL(cfg.BatchPreCommitAboveBaseFee),
 
+				AggregateMinBaseFeeDecayRatio: cfg.AggregateMinBaseFeeDecayRatio,
+
+				BatchPreCommitAboveBaseFeeDynamic: cfg.BatchPreCommitAboveBaseFeeDynamic,
+				SectorPreCommitBatchGasTarget:     cfg.SectorPreCommitBatchGasTarget,
+
 				TerminateBatchMax:                      cfg.TerminateBatchMax,
 				TerminateBatchMin:                      cfg.TerminateBatchMin,
 				TerminateBatchWait:                     config.Duration(cfg.TerminateBatchWait),
 				MaxSectorProveCommitsSubmittedPerEpoch: cfg.MaxSectorProveCommitsSubmittedPerEpoch,
+				MaxConcurrentProveCommits:              cfg.MaxConcurrentProveCommits,
+				MaxPreCommitsInFlight:                  cfg.MaxPreCommitsInFlight,
 				UseSyntheticPoRep:                      cfg.UseSyntheticPoRep,
+				TicketLookaheadEpochs:                  cfg.TicketLookaheadEpochs,
+				FinalizeSectorTimeout:                  config.Duration(cfg.FinalizeSectorTimeout),
+				SectorBuildWatcher:                     cfg.SectorBuildWatcher,
+				SectorWatcherTopicName:                 cfg.SectorWatcherTopicName,
 			}
 			c.SetSealingConfig(newCfg)
 		})
@@ -1032,37 +1164,64 @@ func ToSealingConfig(dealmakingCfg config.DealmakingConfig, sealingCfg config.Se
 		MaxSealingSectorsForDeals:  sealingCfg.MaxSealingSectorsForDeals,
 		PreferNewSectorsForDeals:   sealingCfg.PreferNewSectorsForDeals,
 		MinUpgradeSectorExpiration: sealingCfg.MinUpgradeSectorExpiration,
+		SnapDealSectorReuseWindow:  sealingCfg.SnapDealSectorReuseWindow,
 		MaxUpgradingSectors:        sealingCfg.MaxUpgradingSectors,
 
 		StartEpochSealingBuffer:         abi.ChainEpoch(dealmakingCfg.StartEpochSealingBuffer),
+		MaxDealClientAddressCount:       dealmakingCfg.MaxDealClientAddressCount,
 		MakeNewSectorForDeals:           sealingCfg.MakeNewSectorForDeals,
 		CommittedCapacitySectorLifetime: time.Duration(sealingCfg.CommittedCapacitySectorLifetime),
 		WaitDealsDelay:                  time.Duration(sealingCfg.WaitDealsDelay),
 		MakeCCSectorsAvailable:          sealingCfg.MakeCCSectorsAvailable,
 		AlwaysKeepUnsealedCopy:          sealingCfg.AlwaysKeepUnsealedCopy,
+		DealLevelFastRetrieval:          dealmakingCfg.FastRetrieval,
 		FinalizeEarly:                   sealingCfg.FinalizeEarly,
+		SectorSealRetryPolicy:           sealingCfg.SectorSealRetryPolicy,
 
 		CollateralFromMinerBalance: sealingCfg.CollateralFromMinerBalance,
 		AvailableBalanceBuffer:     types.BigInt(sealingCfg.AvailableBalanceBuffer),
 		DisableCollateralFallback:  sealingCfg.DisableCollateralFallback,
+		PledgeCollateralBuffer:     types.BigInt(sealingCfg.PledgeCollateralBuffer),
 
 		MaxPreCommitBatch:   sealingCfg.MaxPreCommitBatch,
 		PreCommitBatchWait:  time.Duration(sealingCfg.PreCommitBatchWait),
 		PreCommitBatchSlack: time.Duration(sealingCfg.PreCommitBatchSlack),
+		P1StopFraction:      sealingCfg.P1StopFraction,
+
+		PreCommitBatchAbortOnExpiry: sealingCfg.PreCommitBatchAbortOnExpiry,
+
+		MaxPreCommitBatchByValue:  sealingCfg.MaxPreCommitBatchByValue,
+		MaxPreCommitBatchFeeValue: types.BigInt(sealingCfg.MaxPreCommitBatchFeeValue),
+
+		PreCommitGasMultiplier:     sealingCfg.PreCommitGasMultiplier,
+		CommitGasMultiplier:        sealingCfg.CommitGasMultiplier,
+		ReplicaUpdateGasMultiplier: sealingCfg.ReplicaUpdateGasMultiplier,
 
 		AggregateCommits:                       sealingCfg.AggregateCommits,
 		MinCommitBatch:                         sealingCfg.MinCommitBatch,
 		MaxCommitBatch:                         sealingCfg.MaxCommitBatch,
 		CommitBatchWait:                        time.Duration(sealingCfg.CommitBatchWait),
 		CommitBatchSlack:                       time.Duration(sealingCfg.CommitBatchSlack),
+		CommitBatchOverlapWindow:               time.Duration(sealingCfg.CommitBatchOverlapWindow),
 		AggregateAboveBaseFee:                  types.BigInt(sealingCfg.AggregateAboveBaseFee),
 		BatchPreCommitAboveBaseFee:             types.BigInt(sealingCfg.BatchPreCommitAboveBaseFee),
+		AggregateMinBaseFeeDecayRatio:          sealingCfg.AggregateMinBaseFeeDecayRatio,
+		BatchPreCommitAboveBaseFeeDynamic:      sealingCfg.BatchPreCommitAboveBaseFeeDynamic,
+		SectorPreCommitBatchGasTarget:          sealingCfg.SectorPreCommitBatchGasTarget,
 		MaxSectorProveCommitsSubmittedPerEpoch: sealingCfg.MaxSectorProveCommitsSubmittedPerEpoch,
+		MaxConcurrentProveCommits:              sealingCfg.MaxConcurrentProveCommits,
+		MaxPreCommitsInFlight:                  sealingCfg.MaxPreCommitsInFlight,
 
 		TerminateBatchMax:  sealingCfg.TerminateBatchMax,
 		TerminateBatchMin:  sealingCfg.TerminateBatchMin,
 		TerminateBatchWait: time.Duration(sealingCfg.TerminateBatchWait),
 		UseSyntheticPoRep:  sealingCfg.UseSyntheticPoRep,
+
+		TicketLookaheadEpochs: sealingCfg.TicketLookaheadEpochs,
+		FinalizeSectorTimeout: time.Duration(sealingCfg.FinalizeSectorTimeout),
+
+		SectorBuildWatcher:     sealingCfg.SectorBuildWatcher,
+		SectorWatcherTopicName: sealingCfg.SectorWatcherTopicName,
 	}
 }
 
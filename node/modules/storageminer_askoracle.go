@@ -0,0 +1,154 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-fil-markets/storagemarket/impl/storedask"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+)
+
+// oracleClient is used to query http(s) storage price oracles.
+var oracleClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxOraclePrice is the highest price, in attoFIL/GiB/Epoch, accepted from a storage price oracle. It only
+// guards against broken oracles; real prices are many orders of magnitude lower.
+var maxOraclePrice = types.FromFil(1)
+
+// askStore is the part of storedask.StoredAsk used to update the ask.
+type askStore interface {
+	GetAsk() *storagemarket.SignedStorageAsk
+	SetAsk(price abi.TokenAmount, verifiedPrice abi.TokenAmount, duration abi.ChainEpoch, options ...storagemarket.StorageAskOption) error
+}
+
+// storagePriceOracleResponse is the JSON object returned by a storage price oracle.
+type storagePriceOracleResponse struct {
+	Price         string `json:"price"`
+	VerifiedPrice string `json:"verifiedPrice"`
+}
+
+// HandleStoragePriceOracle polls the storage price oracle configured in cfg and
+// updates the storage ask whenever the returned prices differ from the current ask.
+func HandleStoragePriceOracle(cfg config.DealmakingConfig) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, ask *storedask.StoredAsk) {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, ask *storedask.StoredAsk) {
+		ctx := helpers.LifecycleCtx(mctx, lc)
+
+		interval := time.Duration(cfg.StoragePriceOraclePollInterval)
+		if interval <= 0 {
+			interval = time.Hour
+		}
+
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go func() {
+					tk := time.NewTicker(interval)
+					defer tk.Stop()
+
+					for {
+						if err := updateAskFromOracle(ctx, cfg.StoragePriceOracle, ask); err != nil {
+							log.Errorw("updating storage ask from price oracle", "oracle", cfg.StoragePriceOracle, "error", err)
+						}
+
+						select {
+						case <-tk.C:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}()
+				return nil
+			},
+		})
+	}
+}
+
+func updateAskFromOracle(ctx context.Context, oracle string, ask askStore) error {
+	price, verifiedPrice, err := fetchStoragePrice(ctx, oracle)
+	if err != nil {
+		return err
+	}
+
+	duration := storedask.DefaultDuration
+	if cur := ask.GetAsk(); cur != nil {
+		if cur.Ask.Price.Equals(price) && cur.Ask.VerifiedPrice.Equals(verifiedPrice) {
+			return nil
+		}
+		duration = cur.Ask.Expiry - cur.Ask.Timestamp
+	}
+
+	log.Infow("updating storage ask from price oracle", "price", price, "verifiedPrice", verifiedPrice)
+
+	// min and max piece sizes are kept from the current ask
+	if err := ask.SetAsk(price, verifiedPrice, duration); err != nil {
+		return xerrors.Errorf("setting ask: %w", err)
+	}
+	return nil
+}
+
+// fetchStoragePrice reads the prices from an oracle given as an http(s) URL or a file path.
+func fetchStoragePrice(ctx context.Context, oracle string) (abi.TokenAmount, abi.TokenAmount, error) {
+	var data []byte
+	if strings.HasPrefix(oracle, "http://") || strings.HasPrefix(oracle, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, oracle, nil)
+		if err != nil {
+			return abi.TokenAmount{}, abi.TokenAmount{}, xerrors.Errorf("creating oracle request: %w", err)
+		}
+
+		resp, err := oracleClient.Do(req)
+		if err != nil {
+			return abi.TokenAmount{}, abi.TokenAmount{}, xerrors.Errorf("querying oracle: %w", err)
+		}
+		defer resp.Body.Close() //nolint:errcheck
+
+		if resp.StatusCode != http.StatusOK {
+			return abi.TokenAmount{}, abi.TokenAmount{}, xerrors.Errorf("oracle returned status %d", resp.StatusCode)
+		}
+
+		data, err = io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if err != nil {
+			return abi.TokenAmount{}, abi.TokenAmount{}, xerrors.Errorf("reading oracle response: %w", err)
+		}
+	} else {
+		var err error
+		data, err = os.ReadFile(oracle)
+		if err != nil {
+			return abi.TokenAmount{}, abi.TokenAmount{}, xerrors.Errorf("reading oracle file: %w", err)
+		}
+	}
+
+	var res storagePriceOracleResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return abi.TokenAmount{}, abi.TokenAmount{}, xerrors.Errorf("decoding oracle response: %w", err)
+	}
+
+	price, err := big.FromString(res.Price)
+	if err != nil {
+		return abi.TokenAmount{}, abi.TokenAmount{}, xerrors.Errorf("parsing price: %w", err)
+	}
+	verifiedPrice, err := big.FromString(res.VerifiedPrice)
+	if err != nil {
+		return abi.TokenAmount{}, abi.TokenAmount{}, xerrors.Errorf("parsing verified price: %w", err)
+	}
+	if price.Sign() < 0 || verifiedPrice.Sign() < 0 {
+		return abi.TokenAmount{}, abi.TokenAmount{}, xerrors.Errorf("oracle returned negative price (price: %s, verifiedPrice: %s)", price, verifiedPrice)
+	}
+	if price.GreaterThan(maxOraclePrice) || verifiedPrice.GreaterThan(maxOraclePrice) {
+		return abi.TokenAmount{}, abi.TokenAmount{}, xerrors.Errorf("oracle returned price above %s attoFIL/GiB/Epoch (price: %s, verifiedPrice: %s)", maxOraclePrice, price, verifiedPrice)
+	}
+
+	return price, verifiedPrice, nil
+}
@@ -0,0 +1,96 @@
+package modules
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+type mockAskStore struct {
+	ask *storagemarket.SignedStorageAsk
+	set int
+}
+
+func (m *mockAskStore) GetAsk() *storagemarket.SignedStorageAsk {
+	return m.ask
+}
+
+func (m *mockAskStore) SetAsk(price abi.TokenAmount, verifiedPrice abi.TokenAmount, duration abi.ChainEpoch, options ...storagemarket.StorageAskOption) error {
+	m.set++
+	m.ask = &storagemarket.SignedStorageAsk{
+		Ask: &storagemarket.StorageAsk{
+			Price:         price,
+			VerifiedPrice: verifiedPrice,
+			Expiry:        duration,
+		},
+	}
+	return nil
+}
+
+func oracleServer(t *testing.T, status int, body string) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestFetchStoragePrice(t *testing.T) {
+	ctx := context.Background()
+
+	price, verifiedPrice, err := fetchStoragePrice(ctx, oracleServer(t, http.StatusOK, `{"price": "123456789", "verifiedPrice": "0"}`))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(123456789), price)
+	require.Equal(t, big.Zero(), verifiedPrice)
+
+	for name, tc := range map[string]struct {
+		status int
+		body   string
+		err    string
+	}{
+		"bad status":     {status: http.StatusInternalServerError, body: `{"price": "1", "verifiedPrice": "0"}`, err: "status 500"},
+		"bad json":       {status: http.StatusOK, body: `{"price": `, err: "decoding oracle response"},
+		"bad price":      {status: http.StatusOK, body: `{"price": "cheap", "verifiedPrice": "0"}`, err: "parsing price"},
+		"negative price": {status: http.StatusOK, body: `{"price": "-1", "verifiedPrice": "0"}`, err: "negative price"},
+		"price too high": {status: http.StatusOK, body: `{"price": "1000000000000000001", "verifiedPrice": "0"}`, err: "price above"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := fetchStoragePrice(ctx, oracleServer(t, tc.status, tc.body))
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestUpdateAskFromOracle(t *testing.T) {
+	ctx := context.Background()
+	ask := &mockAskStore{}
+
+	oracle := oracleServer(t, http.StatusOK, `{"price": "100", "verifiedPrice": "10"}`)
+	require.NoError(t, updateAskFromOracle(ctx, oracle, ask))
+	require.Equal(t, 1, ask.set)
+	require.Equal(t, big.NewInt(100), ask.ask.Ask.Price)
+	require.Equal(t, big.NewInt(10), ask.ask.Ask.VerifiedPrice)
+
+	// unchanged prices don't update the ask
+	require.NoError(t, updateAskFromOracle(ctx, oracle, ask))
+	require.Equal(t, 1, ask.set)
+
+	// failed queries leave the ask alone
+	for _, oracle := range []string{
+		oracleServer(t, http.StatusBadGateway, ""),
+		oracleServer(t, http.StatusOK, `not json`),
+		oracleServer(t, http.StatusOK, `{"price": "1000000000000000001", "verifiedPrice": "0"}`),
+	} {
+		require.Error(t, updateAskFromOracle(ctx, oracle, ask))
+	}
+	require.Equal(t, 1, ask.set)
+	require.Equal(t, big.NewInt(100), ask.ask.Ask.Price)
+}
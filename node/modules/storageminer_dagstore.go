@@ -13,6 +13,7 @@ import (
 
 	"github.com/filecoin-project/dagstore"
 
+	"github.com/filecoin-project/lotus/journal/alerting"
 	mdagstore "github.com/filecoin-project/lotus/markets/dagstore"
 	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
@@ -24,8 +25,9 @@ const (
 	DefaultDAGStoreDir         = "dagstore"
 )
 
-// NewMinerAPI creates a new MinerAPI adaptor for the dagstore mounts.
-func NewMinerAPI(cfg config.DAGStoreConfig) func(fx.Lifecycle, repo.LockedRepo, dtypes.ProviderPieceStore, mdagstore.SectorAccessor) (mdagstore.MinerAPI, error) {
+// NewMinerAPI creates a new MinerAPI adaptor for the dagstore mounts. When
+// pieceTransfer is false the dagstore can't fetch pieces from sector storage.
+func NewMinerAPI(cfg config.DAGStoreConfig, pieceTransfer bool) func(fx.Lifecycle, repo.LockedRepo, dtypes.ProviderPieceStore, mdagstore.SectorAccessor) (mdagstore.MinerAPI, error) {
 	return func(lc fx.Lifecycle, r repo.LockedRepo, pieceStore dtypes.ProviderPieceStore, sa mdagstore.SectorAccessor) (mdagstore.MinerAPI, error) {
 		// caps the amount of concurrent calls to the storage, so that we don't
 		// spam it during heavy processes like bulk migration.
@@ -53,6 +55,11 @@ func NewMinerAPI(cfg config.DAGStoreConfig) func(fx.Lifecycle, repo.LockedRepo,
 			},
 		})
 
+		if !pieceTransfer {
+			log.Warnw("piece retrieval transfer disabled; the dagstore will not fetch pieces from sector storage, so new shards can't be initialized or indexed")
+			return mdagstore.WithoutPieceTransfer(mountApi), nil
+		}
+
 		return mountApi, nil
 	}
 }
@@ -60,8 +67,8 @@ func NewMinerAPI(cfg config.DAGStoreConfig) func(fx.Lifecycle, repo.LockedRepo,
 // DAGStore constructs a DAG store using the supplied minerAPI, and the
 // user configuration. It returns both the DAGStore and the Wrapper suitable for
 // passing to markets.
-func DAGStore(cfg config.DAGStoreConfig) func(lc fx.Lifecycle, r repo.LockedRepo, minerAPI mdagstore.MinerAPI, h host.Host) (*dagstore.DAGStore, *mdagstore.Wrapper, error) {
-	return func(lc fx.Lifecycle, r repo.LockedRepo, minerAPI mdagstore.MinerAPI, h host.Host) (*dagstore.DAGStore, *mdagstore.Wrapper, error) {
+func DAGStore(cfg config.DAGStoreConfig) func(lc fx.Lifecycle, r repo.LockedRepo, minerAPI mdagstore.MinerAPI, h host.Host, al *alerting.Alerting) (*dagstore.DAGStore, *mdagstore.Wrapper, error) {
+	return func(lc fx.Lifecycle, r repo.LockedRepo, minerAPI mdagstore.MinerAPI, h host.Host, al *alerting.Alerting) (*dagstore.DAGStore, *mdagstore.Wrapper, error) {
 		// fall back to default root directory if not explicitly set in the config.
 		if cfg.RootDir == "" {
 			cfg.RootDir = filepath.Join(r.Path(), DefaultDAGStoreDir)
@@ -75,7 +82,7 @@ func DAGStore(cfg config.DAGStoreConfig) func(lc fx.Lifecycle, r repo.LockedRepo
 			}
 		}
 
-		dagst, w, err := mdagstore.NewDAGStore(cfg, minerAPI, h)
+		dagst, w, err := mdagstore.NewDAGStore(cfg, minerAPI, h, al)
 		if err != nil {
 			return nil, nil, xerrors.Errorf("failed to create DAG store: %w", err)
 		}
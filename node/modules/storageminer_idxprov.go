@@ -2,7 +2,9 @@ package modules
 
 import (
 	"context"
+	"time"
 
+	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
 	"github.com/ipfs/go-datastore/namespace"
 	provider "github.com/ipni/index-provider"
@@ -94,6 +96,10 @@ func IndexProvider(cfg config.IndexProviderConfig) func(params IdxProv, marketHo
 		}
 		llog.Info("Instantiated index provider engine")
 
+		heartbeat := time.Duration(cfg.GossipSubHeartbeatInterval)
+		hbCtx, hbCancel := context.WithCancel(context.Background())
+		hbDone := make(chan struct{})
+
 		args.Lifecycle.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				// Note that the OnStart context is cancelled after startup. Its use in e.Start is
@@ -103,9 +109,18 @@ func IndexProvider(cfg config.IndexProviderConfig) func(params IdxProv, marketHo
 					return xerrors.Errorf("starting indexer provider engine: %w", err)
 				}
 				log.Infof("Started index provider engine")
+
+				if cfg.Enable && heartbeat > 0 {
+					go runAnnounceHeartbeat(hbCtx, e, heartbeat, hbDone)
+				} else {
+					close(hbDone)
+				}
 				return nil
 			},
 			OnStop: func(_ context.Context) error {
+				hbCancel()
+				<-hbDone
+
 				if err := e.Shutdown(); err != nil {
 					return xerrors.Errorf("shutting down indexer provider engine: %w", err)
 				}
@@ -115,3 +130,61 @@ func IndexProvider(cfg config.IndexProviderConfig) func(params IdxProv, marketHo
 		return e, nil
 	}
 }
+
+// announceHeartbeat decides when the latest advertisement is re-announced, so
+// that a head is announced at most once per interval.
+type announceHeartbeat struct {
+	interval time.Duration
+
+	lastHead cid.Cid
+	lastSent time.Time
+}
+
+// shouldAnnounce reports whether head should be re-announced at now. A head
+// seen for the first time since startup is announced right away; a new head
+// was announced by the engine when it was published, so it is only announced
+// again a full interval later.
+func (h *announceHeartbeat) shouldAnnounce(head cid.Cid, now time.Time) bool {
+	if !head.Defined() {
+		return false
+	}
+
+	if head != h.lastHead {
+		first := !h.lastHead.Defined()
+		h.lastHead, h.lastSent = head, now
+		return first
+	}
+
+	if now.Sub(h.lastSent) < h.interval {
+		return false
+	}
+	h.lastSent = now
+	return true
+}
+
+func runAnnounceHeartbeat(ctx context.Context, e *engine.Engine, interval time.Duration, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	hb := &announceHeartbeat{interval: interval}
+	for {
+		select {
+		case now := <-ticker.C:
+			head, _, err := e.GetLatestAdv(ctx)
+			if err != nil {
+				log.Warnw("getting latest advertisement for heartbeat announcement", "error", err)
+				continue
+			}
+			if !hb.shouldAnnounce(head, now) {
+				continue
+			}
+			if _, err := e.PublishLatest(ctx); err != nil {
+				log.Warnw("re-announcing latest advertisement", "head", head, "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+	sealing "github.com/filecoin-project/lotus/storage/pipeline"
+)
+
+// sectorWatcherQueueSize bounds the number of state changes waiting to be
+// published; further changes are dropped so that a slow pubsub never stalls
+// the sealing state machine.
+const sectorWatcherQueueSize = 256
+
+// SectorStateEvent is the JSON message published by the sector build watcher
+// for every sector state transition.
+type SectorStateEvent struct {
+	Miner        address.Address     `json:"miner"`
+	SectorNumber abi.SectorNumber    `json:"sectorNumber"`
+	From         sealing.SectorState `json:"from"`
+	After        sealing.SectorState `json:"after"`
+	Error        string              `json:"error,omitempty"`
+	Time         time.Time           `json:"time"`
+}
+
+// HandleSectorWatcher publishes the sector state transitions of the sealing
+// pipeline to the given pubsub topic.
+func HandleSectorWatcher(mctx helpers.MetricsCtx, lc fx.Lifecycle, maddr dtypes.MinerAddress, topic dtypes.SectorWatcherTopic, pipeline *sealing.Sealing, ps *pubsub.PubSub) error {
+	t, err := ps.Join(string(topic))
+	if err != nil {
+		return xerrors.Errorf("joining sector watcher topic %s: %w", topic, err)
+	}
+
+	evts := make(chan SectorStateEvent, sectorWatcherQueueSize)
+	pipeline.AddNotifee(func(before, after sealing.SectorInfo) {
+		if before.State == after.State {
+			return
+		}
+
+		evt := SectorStateEvent{
+			Miner:        address.Address(maddr),
+			SectorNumber: after.SectorNumber,
+			From:         before.State,
+			After:        after.State,
+			Error:        after.LastErr,
+			Time:         time.Now(),
+		}
+
+		select {
+		case evts <- evt:
+		default:
+			log.Warnw("sector watcher queue full, dropping state change", "sector", after.SectorNumber, "from", before.State, "after", after.State)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(mctx)
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+
+				for {
+					select {
+					case evt := <-evts:
+						msg, err := json.Marshal(evt)
+						if err != nil {
+							log.Errorw("marshaling sector state event", "sector", evt.SectorNumber, "error", err)
+							continue
+						}
+						if err := t.Publish(ctx, msg); err != nil {
+							log.Errorw("publishing sector state event", "sector", evt.SectorNumber, "error", err)
+						}
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return t.Close()
+		},
+	})
+
+	return nil
+}
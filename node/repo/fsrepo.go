@@ -291,7 +291,7 @@ func (fsr *FsRepo) initConfig(t RepoType) error {
 		return err
 	}
 
-	comm, err := config.ConfigComment(t.Config())
+	comm, err := config.ConfigComment(config.WithCurrentVersion(t.Config()))
 	if err != nil {
 		return xerrors.Errorf("comment: %w", err)
 	}
@@ -483,6 +483,19 @@ func (fsr *fsLockedRepo) Blockstore(ctx context.Context, domain BlockstoreDomain
 			opts.SyncWrites = false
 		}
 
+		// Only full nodes store chain state; compressed blocks are readable
+		// regardless of the setting, so tools opening the repo need not know it.
+		if fsr.repoType == FullNode {
+			c, err := fsr.Config()
+			if err != nil {
+				fsr.bsErr = err
+				return
+			}
+			if cfg, ok := c.(*config.FullNode); ok {
+				opts.CompressValues = cfg.Chainstore.BlockCompressionEnabled
+			}
+		}
+
 		bs, err := badgerbs.Open(opts)
 		if err != nil {
 			fsr.bsErr = err
@@ -302,7 +302,7 @@ func (lmem *lockedMemRepo) Config() (interface{}, error) {
 	defer lmem.mem.config.Unlock()
 
 	if lmem.mem.config.val == nil {
-		lmem.mem.config.val = lmem.t.Config()
+		lmem.mem.config.val = config.WithCurrentVersion(lmem.t.Config())
 	}
 
 	return lmem.mem.config.val, nil
@@ -317,7 +317,7 @@ func (lmem *lockedMemRepo) SetConfig(c func(interface{})) error {
 	defer lmem.mem.config.Unlock()
 
 	if lmem.mem.config.val == nil {
-		lmem.mem.config.val = lmem.t.Config()
+		lmem.mem.config.val = config.WithCurrentVersion(lmem.t.Config())
 	}
 
 	c(lmem.mem.config.val)
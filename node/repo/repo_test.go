@@ -50,7 +50,7 @@ func basicTest(t *testing.T, repo Repo) {
 	assert.Equal(t, ma, apima, "returned API multiaddr should be the same")
 
 	c1, err := lrepo.Config()
-	assert.Equal(t, config.DefaultFullNode(), c1, "there should be a default config")
+	assert.Equal(t, config.WithCurrentVersion(config.DefaultFullNode()), c1, "there should be a default config")
 	assert.NoError(t, err, "config should not error")
 
 	// mutate config and persist back to repo
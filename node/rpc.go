@@ -1,14 +1,21 @@
 package node
 
 import (
+	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
 	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +26,7 @@ import (
 	"github.com/multiformats/go-multiaddr"
 	manet "github.com/multiformats/go-multiaddr/net"
 	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-jsonrpc"
@@ -31,6 +39,7 @@ import (
 	"github.com/filecoin-project/lotus/lib/rpcenc"
 	"github.com/filecoin-project/lotus/metrics"
 	"github.com/filecoin-project/lotus/metrics/proxy"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/impl"
 	"github.com/filecoin-project/lotus/node/impl/client"
 )
@@ -50,6 +59,37 @@ func ServeRPC(h http.Handler, id string, addr multiaddr.Multiaddr) (StopFunc, er
 		return nil, xerrors.Errorf("could not listen: %w", err)
 	}
 
+	return serveRPCListener(h, id, manet.NetListener(lst)), nil
+}
+
+// ServeRPCUnix serves an HTTP handler over a Unix domain socket created at
+// path, which is only accessible to the user running the node (mode 0600).
+// A stale socket left at path by a previous run is replaced.
+//
+// Like ServeRPC, it returns immediately with the stop function of the endpoint.
+func ServeRPCUnix(h http.Handler, id string, path string) (StopFunc, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, xerrors.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, xerrors.Errorf("removing stale socket: %w", err)
+		}
+	}
+
+	lst, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, xerrors.Errorf("could not listen: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = lst.Close()
+		return nil, xerrors.Errorf("setting socket permissions: %w", err)
+	}
+
+	return serveRPCListener(h, id, lst), nil
+}
+
+func serveRPCListener(h http.Handler, id string, lst net.Listener) StopFunc {
 	// Instantiate the server and start listening.
 	srv := &http.Server{
 		Handler:           h,
@@ -61,17 +101,18 @@ func ServeRPC(h http.Handler, id string, addr multiaddr.Multiaddr) (StopFunc, er
 	}
 
 	go func() {
-		err = srv.Serve(manet.NetListener(lst))
+		err := srv.Serve(lst)
 		if err != http.ErrServerClosed {
 			rpclog.Warnf("rpc server failed: %s", err)
 		}
 	}()
 
-	return srv.Shutdown, err
+	return srv.Shutdown
 }
 
-// FullNodeHandler returns a full node handler, to be mounted as-is on the server.
-func FullNodeHandler(a v1api.FullNode, permissioned bool, opts ...jsonrpc.ServerOption) (http.Handler, error) {
+// FullNodeHandler returns a full node handler, to be mounted as-is on the server. API calls go through
+// a proxy configured with callOpts.
+func FullNodeHandler(a v1api.FullNode, permissioned bool, callOpts []proxy.Option, opts ...jsonrpc.ServerOption) (http.Handler, error) {
 	m := mux.NewRouter()
 
 	serveRpc := func(path string, hnd interface{}) {
@@ -86,10 +127,10 @@ func FullNodeHandler(a v1api.FullNode, permissioned bool, opts ...jsonrpc.Server
 			handler = &auth.Handler{Verify: a.AuthVerify, Next: rpcServer.ServeHTTP}
 		}
 
-		m.Handle(path, handler)
+		m.Handle(path, callerIPHandler(handler))
 	}
 
-	fnapi := proxy.MetricedFullAPI(a)
+	fnapi := proxy.MetricedFullAPI(a, callOpts...)
 	if permissioned {
 		fnapi = api.PermissionedFullAPI(fnapi)
 	}
@@ -138,15 +179,572 @@ func FullNodeHandler(a v1api.FullNode, permissioned bool, opts ...jsonrpc.Server
 	return m, nil
 }
 
-// MinerHandler returns a miner handler, to be mounted as-is on the server.
-func MinerHandler(a api.StorageMiner, permissioned bool) (http.Handler, error) {
-	mapi := proxy.MetricedStorMinerAPI(a)
+// APIServerOptions returns the JSON-RPC server options set in the API config section.
+func APIServerOptions(cfg *config.API) []jsonrpc.ServerOption {
+	var opts []jsonrpc.ServerOption
+	if cfg.WebSocketHeartbeat > 0 {
+		opts = append(opts, jsonrpc.WithServerPingInterval(time.Duration(cfg.WebSocketHeartbeat)))
+	}
+	if cfg.WebSocketMaxMessageSize > 0 {
+		opts = append(opts, jsonrpc.WithMaxRequestSize(cfg.WebSocketMaxMessageSize))
+	}
+	return opts
+}
+
+// APIMaxRequestSize returns the maximum size, in bytes, of a request accepted by an API server set up
+// with APIServerOptions.
+func APIMaxRequestSize(cfg *config.API) int64 {
+	if cfg.WebSocketMaxMessageSize > 0 {
+		return cfg.WebSocketMaxMessageSize
+	}
+	return jsonrpc.DEFAULT_MAX_REQUEST_SIZE
+}
+
+// readRPCBody reads the body of a request to be passed on to an API server, replacing it so that it can
+// be read again. Bodies larger than maxSize are rejected with HTTP 413, like the API server would; false
+// is returned when the request was answered with an error.
+func readRPCBody(w http.ResponseWriter, r *http.Request, maxSize int64) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("request exceeds the maximum size of %d bytes", maxSize), http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		http.Error(w, fmt.Sprintf("reading request: %s", err), http.StatusBadRequest)
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true
+}
+
+// maxParallelBatchRequests is the maximum number of batch requests ParallelBatchHandler executes at
+// the same time, across all batches.
+const maxParallelBatchRequests = 16
+
+// ParallelBatchHandler wraps a full node handler, executing the requests of JSON-RPC batches sent to
+// the /rpc endpoints concurrently, at most maxParallelBatchRequests at a time. Each request in the batch
+// is passed to next on its own, and the responses are returned in the order of the batch. Batches of
+// more than maxSize requests, and requests larger than maxRequestSize, are rejected.
+func ParallelBatchHandler(next http.Handler, maxSize int, maxRequestSize int64) http.Handler {
+	throttle := make(chan struct{}, maxParallelBatchRequests)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/rpc/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, ok := readRPCBody(w, r, maxRequestSize)
+		if !ok {
+			return
+		}
+
+		var batch []json.RawMessage
+		if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || trimmed[0] != '[' {
+			next.ServeHTTP(w, r)
+			return
+		} else if err := json.Unmarshal(trimmed, &batch); err != nil || len(batch) == 0 {
+			// let the rpc server report malformed batches
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if maxSize > 0 && len(batch) > maxSize {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      nil,
+				"error": map[string]interface{}{
+					"code":    -32600, // invalid request
+					"message": fmt.Sprintf("batch of %d requests exceeds the maximum of %d", len(batch), maxSize),
+				},
+			})
+			return
+		}
+
+		resps := make([][]byte, len(batch))
+		var wg sync.WaitGroup
+		for i, req := range batch {
+			select {
+			case throttle <- struct{}{}:
+			case <-r.Context().Done():
+				wg.Wait()
+				return
+			}
+
+			wg.Add(1)
+			go func(i int, req json.RawMessage) {
+				defer wg.Done()
+				defer func() { <-throttle }()
+
+				sub := r.Clone(r.Context())
+				sub.Body = io.NopCloser(bytes.NewReader(req))
+				sub.ContentLength = int64(len(req))
+
+				rw := &bufferedResponse{header: make(http.Header)}
+				next.ServeHTTP(rw, sub)
+				resps[i] = bytes.TrimSpace(rw.buf.Bytes())
+			}(i, req)
+		}
+		wg.Wait()
+
+		// notifications don't get a response
+		var out [][]byte
+		for _, resp := range resps {
+			if len(resp) > 0 {
+				out = append(out, resp)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(out) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte("["))
+		_, _ = w.Write(bytes.Join(out, []byte(",")))
+		_, _ = w.Write([]byte("]"))
+	})
+}
+
+// bufferedResponse collects the response to a single request of a batch.
+type bufferedResponse struct {
+	header http.Header
+	buf    bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header         { return b.header }
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.buf.Write(p) }
+func (b *bufferedResponse) WriteHeader(int)             {}
+
+// ResponseSizeLimitHandler wraps an API handler, replacing responses to JSON-RPC requests sent to the
+// /rpc endpoints over HTTP which are larger than maxSize bytes with JSON-RPC errors carrying the IDs of
+// the requests, and HTTP 413 (Request Entity Too Large). Responses are buffered up to maxSize before they
+// are sent. WebSocket connections aren't limited. A maxSize of 0 disables the limit.
+func ResponseSizeLimitHandler(next http.Handler, maxSize int64) http.Handler {
+	if maxSize <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/rpc/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// keep a copy of the request as the API server reads it, to answer with its IDs
+		var req bytes.Buffer
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(r.Body, &req), r.Body}
+
+		lw := &limitedResponseWriter{header: w.Header(), status: http.StatusOK, limit: maxSize}
+		next.ServeHTTP(lw, r)
+
+		if lw.exceeded {
+			w.Header().Del("Content-Length")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			_ = json.NewEncoder(w).Encode(responseTooLargeErrors(req.Bytes(), maxSize))
+			return
+		}
+
+		w.WriteHeader(lw.status)
+		_, _ = w.Write(lw.buf.Bytes())
+	})
+}
+
+// responseTooLargeErrors returns the JSON-RPC errors answering the request or batch in body when the
+// response is too large.
+func responseTooLargeErrors(body []byte, maxSize int64) interface{} {
+	rpcErr := func(id json.RawMessage) map[string]interface{} {
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      id,
+			"error": map[string]interface{}{
+				"code":    -32000, // server error
+				"message": fmt.Sprintf("response exceeds the maximum size of %d bytes", maxSize),
+			},
+		}
+	}
+
+	type rpcRequest struct {
+		ID json.RawMessage `json:"id"`
+	}
+
+	var batch []rpcRequest
+	if err := json.Unmarshal(body, &batch); err == nil {
+		var errs []map[string]interface{}
+		for _, req := range batch {
+			// notifications don't get a response
+			if req.ID != nil {
+				errs = append(errs, rpcErr(req.ID))
+			}
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+
+	var req rpcRequest
+	_ = json.Unmarshal(body, &req)
+	return rpcErr(req.ID)
+}
+
+var errResponseTooLarge = xerrors.New("response exceeds the maximum size")
+
+// limitedResponseWriter buffers a response, failing writes once it grows larger than limit.
+type limitedResponseWriter struct {
+	header   http.Header
+	status   int
+	limit    int64
+	buf      bytes.Buffer
+	exceeded bool
+}
+
+func (l *limitedResponseWriter) Header() http.Header  { return l.header }
+func (l *limitedResponseWriter) WriteHeader(code int) { l.status = code }
+
+func (l *limitedResponseWriter) Write(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, errResponseTooLarge
+	}
+	if int64(l.buf.Len()+len(p)) > l.limit {
+		l.exceeded = true
+		l.buf.Reset()
+		return 0, errResponseTooLarge
+	}
+	return l.buf.Write(p)
+}
+
+// MethodFilter returns the method filter for API proxies allowing calls to the methods in allowed (all
+// methods if empty) which aren't in denied, or nil if both are empty. Methods can be given with or
+// without the Filecoin. prefix, and Ethereum methods by their eth_ alias.
+func MethodFilter(allowed, denied []string) func(method string) bool {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil
+	}
+
+	aliases := methodAliases{}
+	api.CreateEthRPCAliases(aliases)
+
+	toSet := func(methods []string) map[string]struct{} {
+		set := make(map[string]struct{}, len(methods))
+		for _, m := range methods {
+			set[aliases.canonical(strings.TrimSpace(m))] = struct{}{}
+		}
+		return set
+	}
+	allowSet, denySet := toSet(allowed), toSet(denied)
+
+	return func(method string) bool {
+		if _, ok := denySet[method]; ok {
+			return false
+		}
+		if len(allowSet) == 0 {
+			return true
+		}
+		_, ok := allowSet[method]
+		return ok
+	}
+}
+
+// APICallOptions returns the options of the API proxies set in the API config section. Calls are traced
+// with tracer unless it is nil.
+func APICallOptions(cfg *config.API, tracer trace.Tracer) []proxy.Option {
+	opts := []proxy.Option{
+		proxy.WithMethodFilter(MethodFilter(cfg.AllowedMethods, cfg.DeniedMethods)),
+	}
+	if tracer != nil {
+		opts = append(opts, proxy.WithTracer(tracer))
+	}
+	if cfg.TraceAllMethods {
+		opts = append(opts, proxy.WithCallLogging())
+	}
+	return opts
+}
+
+// callerIPHandler attaches the IP address of the client to the context of the requests passed to next,
+// for the API proxies. Over WebSocket, the calls inherit the context of the upgrade request.
+func callerIPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			ip = host
+		}
+		next.ServeHTTP(w, r.WithContext(proxy.WithCallerIP(r.Context(), ip)))
+	})
+}
+
+// EthGetBlockByHashDefaultHandler wraps a full node handler, setting the full transactions flag of
+// eth_getBlockByHash calls sent to the /rpc endpoints over HTTP which only pass the block hash, as some
+// Ethereum clients do. Requests larger than maxRequestSize are rejected. WebSocket connections aren't
+// handled.
+func EthGetBlockByHashDefaultHandler(next http.Handler, maxRequestSize int64) http.Handler {
+	aliases := methodAliases{}
+	api.CreateEthRPCAliases(aliases)
+
+	defaultFlag, _ := json.Marshal(true)
+
+	// fill returns whether the call was changed
+	fill := func(call map[string]json.RawMessage) bool {
+		var method string
+		if json.Unmarshal(call["method"], &method) != nil || aliases.canonical(method) != "EthGetBlockByHash" {
+			return false
+		}
+		var params []json.RawMessage
+		if json.Unmarshal(call["params"], &params) != nil || len(params) != 1 {
+			return false
+		}
+		call["params"], _ = json.Marshal(append(params, defaultFlag))
+		return true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/rpc/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, ok := readRPCBody(w, r, maxRequestSize)
+		if !ok {
+			return
+		}
+
+		// malformed requests are left for the rpc server to report
+		changed := false
+		if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+			var calls []map[string]json.RawMessage
+			if json.Unmarshal(trimmed, &calls) == nil {
+				for _, call := range calls {
+					changed = fill(call) || changed
+				}
+				if changed {
+					body, _ = json.Marshal(calls)
+				}
+			}
+		} else {
+			var call map[string]json.RawMessage
+			if json.Unmarshal(trimmed, &call) == nil && fill(call) {
+				changed = true
+				body, _ = json.Marshal(call)
+			}
+		}
+
+		if changed {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MetricsBasicAuthHandler wraps an API handler, requiring HTTP Basic Authentication with the given
+// credentials for requests to /debug/metrics. It returns next unchanged if either is empty.
+func MetricsBasicAuthHandler(next http.Handler, user, pass string) http.Handler {
+	if user == "" || pass == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/debug/metrics" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		u, p, ok := r.BasicAuth()
+		// compare both, so that the time taken doesn't tell which one was wrong
+		userOk := subtle.ConstantTimeCompare([]byte(u), []byte(user)) == 1
+		passOk := subtle.ConstantTimeCompare([]byte(p), []byte(pass)) == 1
+		if !ok || !userOk || !passOk {
+			w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SSEHandler wraps a full node handler, serving chain head changes as Server-Sent Events on /sse, for
+// clients behind proxies which don't pass WebSocket connections through. Each head change is sent as an
+// event named after the change type (current, apply or revert) with the tipset as JSON data. As
+// EventSource clients can't set headers, the API token can be passed in the token query parameter.
+func SSEHandler(next http.Handler, a v1api.FullNode) http.Handler {
+	fnapi := api.PermissionedFullAPI(a)
+	sse := &auth.Handler{
+		Verify: a.AuthVerify,
+		Next: func(w http.ResponseWriter, r *http.Request) {
+			serveHeadChangeEvents(w, r, fnapi)
+		},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sse" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		sse.ServeHTTP(w, r)
+	})
+}
+
+// sseKeepAliveInterval is how often a comment is sent to idle event streams, so that proxies
+// don't close them.
+var sseKeepAliveInterval = 30 * time.Second
+
+func serveHeadChangeEvents(w http.ResponseWriter, r *http.Request, a v1api.FullNode) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	changes, err := a.ChainNotify(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // disable response buffering in nginx
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case hcs, ok := <-changes:
+			if !ok {
+				return
+			}
+			for _, hc := range hcs {
+				data, err := json.Marshal(hc.Val)
+				if err != nil {
+					rpclog.Errorf("marshaling head change event: %s", err)
+					return
+				}
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", hc.Type, data); err != nil {
+					return
+				}
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// ProxyHeadersHandler wraps an API handler, replacing the remote address of requests coming from
+// trustedSubnets with the client address found in the first of headers which holds one, so that the
+// real client is seen behind a proxy. Header values are comma-separated address lists, as in
+// X-Forwarded-For; the rightmost address which isn't itself a trusted proxy is taken as the client.
+// It returns next unchanged if either list is empty.
+func ProxyHeadersHandler(next http.Handler, headers, trustedSubnets []string) (http.Handler, error) {
+	if len(headers) == 0 || len(trustedSubnets) == 0 {
+		return next, nil
+	}
+
+	trusted := make([]*net.IPNet, 0, len(trustedSubnets))
+	for _, subnet := range trustedSubnets {
+		_, ipnet, err := net.ParseCIDR(subnet)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing trusted subnet %q: %w", subnet, err)
+		}
+		trusted = append(trusted, ipnet)
+	}
+
+	isTrusted := func(ip net.IP) bool {
+		for _, ipnet := range trusted {
+			if ipnet.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if peer := net.ParseIP(host); peer == nil || !isTrusted(peer) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		for _, header := range headers {
+			if client := forwardedClient(r.Header.Values(header), isTrusted); client != nil {
+				r.RemoteAddr = net.JoinHostPort(client.String(), "0")
+				break
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	}), nil
+}
+
+// forwardedClient returns the rightmost address in the header values which isn't trusted, or the
+// leftmost one if all of them are, or nil if the values hold no valid address.
+func forwardedClient(values []string, isTrusted func(net.IP) bool) net.IP {
+	var addrs []net.IP
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if ip := net.ParseIP(strings.TrimSpace(part)); ip != nil {
+				addrs = append(addrs, ip)
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil
+	}
+
+	for i := len(addrs) - 1; i >= 0; i-- {
+		if !isTrusted(addrs[i]) {
+			return addrs[i]
+		}
+	}
+	return addrs[0]
+}
+
+// methodAliases maps the eth_ aliases of API methods to the methods they stand for.
+type methodAliases map[string]string
+
+func (a methodAliases) AliasMethod(alias, original string) { a[alias] = original }
+
+// canonical returns the name of the API method called by method, without the Filecoin. prefix.
+func (a methodAliases) canonical(method string) string {
+	if original, ok := a[method]; ok {
+		method = original
+	}
+	return strings.TrimPrefix(method, "Filecoin.")
+}
+
+// MinerHandler returns a miner handler, to be mounted as-is on the server. API calls go through a proxy
+// configured with callOpts.
+func MinerHandler(a api.StorageMiner, permissioned bool, callOpts []proxy.Option, opts ...jsonrpc.ServerOption) (http.Handler, error) {
+	mapi := proxy.MetricedStorMinerAPI(a, callOpts...)
 	if permissioned {
 		mapi = api.PermissionedStorMinerAPI(mapi)
 	}
 
 	readerHandler, readerServerOpt := rpcenc.ReaderParamDecoder()
-	rpcServer := jsonrpc.NewServer(jsonrpc.WithServerErrors(api.RPCErrors), readerServerOpt)
+	rpcServer := jsonrpc.NewServer(append(opts, jsonrpc.WithServerErrors(api.RPCErrors), readerServerOpt)...)
 	rpcServer.Register("Filecoin", mapi)
 	rpcServer.AliasMethod("rpc.discover", "Filecoin.Discover")
 
@@ -171,7 +769,7 @@ func MinerHandler(a api.StorageMiner, permissioned bool) (http.Handler, error) {
 	// local APIs
 	{
 		m := mux.NewRouter()
-		m.Handle("/rpc/v0", rpcServer)
+		m.Handle("/rpc/v0", callerIPHandler(rpcServer))
 		m.Handle("/rpc/streams/v0/push/{uuid}", readerHandler)
 		// debugging
 		m.Handle("/debug/metrics", metrics.Exporter())
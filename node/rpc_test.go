@@ -0,0 +1,296 @@
+// stm: #unit
+package node
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/api/v1api"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+func TestAPIServerOptions(t *testing.T) {
+	// the defaults keep the rpc library defaults
+	require.Empty(t, APIServerOptions(&config.DefaultFullNode().API))
+	require.Empty(t, APIServerOptions(&config.DefaultStorageMiner().API))
+
+	require.Len(t, APIServerOptions(&config.API{WebSocketHeartbeat: config.Duration(time.Minute), WebSocketMaxMessageSize: 1 << 20}), 2)
+}
+
+func TestMethodFilter(t *testing.T) {
+	require.Nil(t, MethodFilter(nil, nil))
+
+	permitted := MethodFilter([]string{"ChainHead", "Filecoin.StateCompute", "eth_blockNumber"}, []string{"StateCompute"})
+	require.True(t, permitted("ChainHead"))
+	require.True(t, permitted("EthBlockNumber"))
+	require.False(t, permitted("StateCompute"))
+	require.False(t, permitted("WalletBalance"))
+
+	// deny only
+	permitted = MethodFilter(nil, []string{" eth_blockNumber "})
+	require.True(t, permitted("WalletBalance"))
+	require.False(t, permitted("EthBlockNumber"))
+}
+
+func TestMetricsBasicAuthHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	call := func(h http.Handler, path, user, pass string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if user != "" || pass != "" {
+			req.SetBasicAuth(user, pass)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	h := MetricsBasicAuthHandler(next, "prom", "secret")
+	require.Equal(t, http.StatusOK, call(h, "/debug/metrics", "prom", "secret"))
+	require.Equal(t, http.StatusUnauthorized, call(h, "/debug/metrics", "", ""))
+	require.Equal(t, http.StatusUnauthorized, call(h, "/debug/metrics", "prom", "wrong"))
+	require.Equal(t, http.StatusUnauthorized, call(h, "/debug/metrics", "admin", "secret"))
+
+	// other endpoints aren't affected
+	require.Equal(t, http.StatusOK, call(h, "/health/livez", "", ""))
+
+	// no credentials configured
+	h = MetricsBasicAuthHandler(next, "", "")
+	require.Equal(t, http.StatusOK, call(h, "/debug/metrics", "", ""))
+}
+
+func TestProxyHeadersHandler(t *testing.T) {
+	var remote string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote = r.RemoteAddr
+	})
+
+	call := func(h http.Handler, peer string, header http.Header) string {
+		req := httptest.NewRequest(http.MethodPost, "/rpc/v1", nil)
+		req.RemoteAddr = peer
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		return remote
+	}
+
+	h, err := ProxyHeadersHandler(next, []string{"X-Real-IP", "X-Forwarded-For"}, []string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	xff := http.Header{"X-Forwarded-For": {"203.0.113.7, 198.51.100.2, 10.0.0.3"}}
+	require.Equal(t, "198.51.100.2:0", call(h, "10.0.0.1:1234", xff))
+	require.Equal(t, "203.0.113.7:0", call(h, "10.0.0.1:1234", http.Header{"X-Real-Ip": {"203.0.113.7"}, "X-Forwarded-For": {"198.51.100.2"}}))
+	require.Equal(t, "10.0.0.3:0", call(h, "10.0.0.1:1234", http.Header{"X-Forwarded-For": {"10.0.0.3"}}))
+	require.Equal(t, "10.0.0.1:1234", call(h, "10.0.0.1:1234", http.Header{"X-Forwarded-For": {"unknown"}}))
+
+	// headers from untrusted peers are ignored
+	require.Equal(t, "192.0.2.1:1234", call(h, "192.0.2.1:1234", xff))
+
+	_, err = ProxyHeadersHandler(next, []string{"X-Forwarded-For"}, []string{"10.0.0.1"})
+	require.Error(t, err)
+}
+
+func TestEthGetBlockByHashDefaultHandler(t *testing.T) {
+	var got string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+	})
+
+	call := func(h http.Handler, body string) string {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/rpc/v1", strings.NewReader(body)))
+		return got
+	}
+
+	h := EthGetBlockByHashDefaultHandler(next, 1<<20)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByHash","params":["0x01",true]}`,
+		call(h, `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByHash","params":["0x01"]}`))
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"Filecoin.EthGetBlockByHash","params":["0x01",true]}`,
+		call(h, `{"jsonrpc":"2.0","id":1,"method":"Filecoin.EthGetBlockByHash","params":["0x01"]}`))
+
+	// explicit flags and other methods are left alone
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByHash","params":["0x01",false]}`,
+		call(h, `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByHash","params":["0x01",false]}`))
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["0x01"]}`,
+		call(h, `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["0x01"]}`))
+
+	require.JSONEq(t, `[{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByHash","params":["0x01",true]},{"jsonrpc":"2.0","id":2,"method":"eth_blockNumber","params":[]}]`,
+		call(h, `[{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByHash","params":["0x01"]},{"jsonrpc":"2.0","id":2,"method":"eth_blockNumber","params":[]}]`))
+
+	// malformed requests are passed through
+	require.Equal(t, `{`, call(h, `{`))
+
+	// oversized requests are rejected
+	got = ""
+	h = EthGetBlockByHashDefaultHandler(next, 16)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc/v1", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByHash","params":["0x01"]}`)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.Empty(t, got)
+}
+
+func TestParallelBatchHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req["id"], req["method"])
+	})
+	h := ParallelBatchHandler(next, 2, 1<<10)
+
+	call := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc/v1", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := call(`[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","id":2,"method":"b"}]`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `[{"jsonrpc":"2.0","id":1,"result":"a"},{"jsonrpc":"2.0","id":2,"result":"b"}]`, rec.Body.String())
+
+	rec = call(`[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","id":2,"method":"b"},{"jsonrpc":"2.0","id":3,"method":"c"}]`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = call(`[{"jsonrpc":"2.0","id":1,"method":"` + strings.Repeat("a", 1<<10) + `"}]`)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestParallelBatchHandlerConcurrency(t *testing.T) {
+	var running, maxRunning int64
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+		for {
+			m := atomic.LoadInt64(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt64(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		var req map[string]json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":null}`, req["id"])
+	})
+	h := ParallelBatchHandler(next, 0, 1<<20)
+
+	reqs := make([]string, 4*maxParallelBatchRequests)
+	for i := range reqs {
+		reqs[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"a"}`, i)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc/v1", strings.NewReader("["+strings.Join(reqs, ",")+"]")))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resps []json.RawMessage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resps))
+	require.Len(t, resps, len(reqs))
+	require.LessOrEqual(t, maxRunning, int64(maxParallelBatchRequests))
+}
+
+type sseTestAPI struct {
+	v1api.FullNode
+
+	changes chan []*api.HeadChange
+}
+
+func (a *sseTestAPI) AuthVerify(context.Context, string) ([]auth.Permission, error) {
+	return api.AllPermissions, nil
+}
+
+func (a *sseTestAPI) ChainNotify(context.Context) (<-chan []*api.HeadChange, error) {
+	return a.changes, nil
+}
+
+func TestSSEHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	a := &sseTestAPI{changes: make(chan []*api.HeadChange, 1)}
+	srv := httptest.NewServer(SSEHandler(next, a))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/rpc/v1")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTeapot, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp, err = http.Get(srv.URL + "/sse")
+	require.NoError(t, err)
+	defer resp.Body.Close() //nolint:errcheck
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	a.changes <- []*api.HeadChange{{Type: store.HCApply, Val: &types.TipSet{}}}
+
+	rd := bufio.NewReader(resp.Body)
+	line, err := rd.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "event: apply\n", line)
+	line, err = rd.ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(line, "data: {"), line)
+}
+
+func TestResponseSizeLimitHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	})
+
+	call := func(h http.Handler, method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/rpc/v1", strings.NewReader(body)))
+		return rec
+	}
+
+	h := ResponseSizeLimitHandler(next, 16)
+
+	rec := call(h, http.MethodPost, `{"id":1}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `{"id":1}`, rec.Body.String())
+
+	rec = call(h, http.MethodPost, `{"id":1,"result":"too large"}`)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"response exceeds the maximum size of 16 bytes"}}`, rec.Body.String())
+
+	// each request of a batch gets an error with its ID
+	rec = call(h, http.MethodPost, `[{"id":"a"},{"id":2},{"method":"notification"}]`)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	var errs []struct {
+		ID json.RawMessage `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errs))
+	require.Len(t, errs, 2)
+	require.Equal(t, `"a"`, string(errs[0].ID))
+	require.Equal(t, `2`, string(errs[1].ID))
+
+	// WebSocket upgrades and other requests aren't limited
+	rec = call(h, http.MethodGet, `{"id":1,"result":"too large"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// 0 disables the limit
+	rec = call(ResponseSizeLimitHandler(next, 0), http.MethodPost, `{"id":1,"result":"too large"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
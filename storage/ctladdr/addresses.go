@@ -2,8 +2,10 @@ package ctladdr
 
 import (
 	"context"
+	"sort"
 
 	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
@@ -28,12 +30,48 @@ type AddressSelector struct {
 }
 
 func (as *AddressSelector) AddressFor(ctx context.Context, a NodeApi, mi api.MinerInfo, use api.AddrUse, goodFunds, minFunds abi.TokenAmount) (address.Address, abi.TokenAmount, error) {
+	return as.RoundRobinAddressFor(ctx, a, mi, use, goodFunds, minFunds, 0)
+}
+
+// RoundRobinAddressFor is like AddressFor, but tries the control addresses for
+// the given use starting with the n-th one (modulo their number), so that
+// successive calls with increasing n spread messages across the addresses.
+// The worker and owner fallbacks are always tried last.
+func (as *AddressSelector) RoundRobinAddressFor(ctx context.Context, a NodeApi, mi api.MinerInfo, use api.AddrUse, goodFunds, minFunds abi.TokenAmount, n uint64) (address.Address, abi.TokenAmount, error) {
 	if as == nil {
 		// should only happen in some tests
 		log.Warnw("smart address selection disabled, using worker address")
 		return mi.Worker, big.Zero(), nil
 	}
 
+	if use == api.DealPublishAddr && as.DealPublishWorker != address.Undef {
+		// deal publish messages are pinned to a single address, there's no
+		// fallback if it doesn't have enough funds
+		b, err := a.WalletBalance(ctx, as.DealPublishWorker)
+		if err != nil {
+			return address.Undef, big.Zero(), xerrors.Errorf("getting balance of deal publish worker %s: %w", as.DealPublishWorker, err)
+		}
+		return as.DealPublishWorker, b, nil
+	}
+
+	var addrs []address.Address
+	if ctl := as.controlAddresses(ctx, a, mi, use); len(ctl) > 0 {
+		k := int(n % uint64(len(ctl)))
+		addrs = append(addrs, ctl[k:]...)
+		addrs = append(addrs, ctl[:k]...)
+	}
+
+	if len(addrs) == 0 || !as.DisableWorkerFallback {
+		addrs = append(addrs, mi.Worker)
+	}
+	if !as.DisableOwnerFallback {
+		addrs = append(addrs, mi.Owner)
+	}
+
+	return pickAddress(ctx, a, mi, goodFunds, minFunds, addrs)
+}
+
+func (as *AddressSelector) controlAddresses(ctx context.Context, a NodeApi, mi api.MinerInfo, use api.AddrUse) []address.Address {
 	var addrs []address.Address
 	switch use {
 	case api.PreCommitAddr:
@@ -73,16 +111,14 @@ func (as *AddressSelector) AddressFor(ctx context.Context, a NodeApi, mi api.Min
 		for a := range defaultCtl {
 			addrs = append(addrs, a)
 		}
+		// keep the order stable, so that messages are sent from the same
+		// address as long as it has enough funds
+		sort.Slice(addrs, func(i, j int) bool {
+			return addrs[i].String() < addrs[j].String()
+		})
 	}
 
-	if len(addrs) == 0 || !as.DisableWorkerFallback {
-		addrs = append(addrs, mi.Worker)
-	}
-	if !as.DisableOwnerFallback {
-		addrs = append(addrs, mi.Owner)
-	}
-
-	return pickAddress(ctx, a, mi, goodFunds, minFunds, addrs)
+	return addrs
 }
 
 func pickAddress(ctx context.Context, a NodeApi, mi api.MinerInfo, goodFunds, minFunds abi.TokenAmount, addrs []address.Address) (address.Address, abi.TokenAmount, error) {
@@ -0,0 +1,66 @@
+package paths
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// BandwidthLimiter is a token bucket limiting the combined throughput of all
+// the readers it wraps. A nil *BandwidthLimiter doesn't limit anything.
+type BandwidthLimiter struct {
+	lim *rate.Limiter
+}
+
+// NewBandwidthLimiter returns a limiter allowing mibps MiB/s in total, or nil
+// if mibps is not positive.
+func NewBandwidthLimiter(mibps float64) *BandwidthLimiter {
+	if mibps <= 0 {
+		return nil
+	}
+	return newBandwidthLimiter(mibps * (1 << 20))
+}
+
+func newBandwidthLimiter(bytesPerSec float64) *BandwidthLimiter {
+	// the burst bounds the size of a single read, so keep it large enough for
+	// reads of CopyBuf, but not much more than a second worth of data
+	burst := CopyBuf
+	if bytesPerSec < float64(burst) {
+		burst = int(bytesPerSec)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &BandwidthLimiter{lim: rate.NewLimiter(rate.Limit(bytesPerSec), burst)}
+}
+
+// Reader wraps r, so that reads from it are throttled by the limiter.
+func (l *BandwidthLimiter) Reader(ctx context.Context, r io.ReadCloser) io.ReadCloser {
+	if l == nil {
+		return r
+	}
+	return &limitedReader{ctx: ctx, ReadCloser: r, lim: l.lim}
+}
+
+type limitedReader struct {
+	io.ReadCloser
+
+	ctx context.Context
+	lim *rate.Limiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.lim.Burst() {
+		p = p[:r.lim.Burst()]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if werr := r.lim.WaitN(r.ctx, n); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return n, err
+}
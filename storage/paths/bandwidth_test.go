@@ -0,0 +1,40 @@
+package paths
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBandwidthLimiter(t *testing.T) {
+	require.Nil(t, NewBandwidthLimiter(0))
+
+	data := bytes.Repeat([]byte{1}, 1000)
+	r := io.NopCloser(bytes.NewReader(data))
+	require.Equal(t, r, (*BandwidthLimiter)(nil).Reader(context.Background(), r))
+
+	// two concurrent readers share the limit; the first second worth of data
+	// is served from the initial burst
+	lim := newBandwidthLimiter(1000)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			got, err := io.ReadAll(lim.Reader(context.Background(), io.NopCloser(bytes.NewReader(data))))
+			require.NoError(t, err)
+			require.Equal(t, data, got)
+		}()
+	}
+	wg.Wait()
+
+	require.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+}
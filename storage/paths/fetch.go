@@ -14,7 +14,7 @@ import (
 	"github.com/filecoin-project/lotus/storage/sealer/tarutil"
 )
 
-func fetch(ctx context.Context, url, outname string, header http.Header) (rerr error) {
+func fetch(ctx context.Context, url, outname string, header http.Header, bw *BandwidthLimiter) (rerr error) {
 	log.Infof("Fetch %s -> %s", url, outname)
 
 	req, err := http.NewRequest("GET", url, nil)
@@ -51,16 +51,18 @@ func fetch(ctx context.Context, url, outname string, header http.Header) (rerr e
 		return xerrors.Errorf("removing dest: %w", err)
 	}
 
+	body := bw.Reader(ctx, resp.Body)
+
 	switch mediatype {
 	case "application/x-tar":
-		bytes, err = tarutil.ExtractTar(resp.Body, outname, make([]byte, CopyBuf))
+		bytes, err = tarutil.ExtractTar(body, outname, make([]byte, CopyBuf))
 		return err
 	case "application/octet-stream":
 		f, err := os.Create(outname)
 		if err != nil {
 			return err
 		}
-		bytes, err = io.CopyBuffer(f, resp.Body, make([]byte, CopyBuf))
+		bytes, err = io.CopyBuffer(f, body, make([]byte, CopyBuf))
 		if err != nil {
 			f.Close() // nolint
 			return err
@@ -85,7 +87,7 @@ func FetchWithTemp(ctx context.Context, urls []string, dest string, header http.
 			return "", xerrors.Errorf("removing dest: %w", err)
 		}
 
-		err = fetch(ctx, url, tempDest, header)
+		err = fetch(ctx, url, tempDest, header, nil)
 		if err != nil {
 			merr = multierror.Append(merr, xerrors.Errorf("fetch error %s -> %s: %w", url, tempDest, err))
 			continue
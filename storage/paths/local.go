@@ -41,6 +41,9 @@ type Local struct {
 
 	paths map[storiface.ID]*path
 
+	// stagingPath, when set, is the local path preferred for sealing allocations
+	stagingPath string
+
 	localLk sync.RWMutex
 }
 
@@ -146,6 +149,42 @@ func NewLocal(ctx context.Context, ls LocalStorage, index SectorIndex, urls []st
 	return l, l.open(ctx)
 }
 
+// SetStagingPath makes the local storage path at p the preferred location for
+// files of sectors being sealed, regardless of the path weights. Sealing files
+// are allocated in other paths as before when p isn't an attached path which
+// can seal the file type. An empty p restores the default allocation order.
+func (st *Local) SetStagingPath(p string) {
+	st.localLk.Lock()
+	defer st.localLk.Unlock()
+
+	if p != "" {
+		p = filepath.Clean(p)
+	}
+	st.stagingPath = p
+}
+
+// preferStaging moves the staging path, if any, to the front of the candidate
+// list, keeping the order of the other paths.
+func (st *Local) preferStaging(sis []storiface.StorageInfo) []storiface.StorageInfo {
+	if st.stagingPath == "" {
+		return sis
+	}
+
+	for i, si := range sis {
+		p, ok := st.paths[si.ID]
+		if !ok || filepath.Clean(p.local) != st.stagingPath {
+			continue
+		}
+
+		out := make([]storiface.StorageInfo, 0, len(sis))
+		out = append(out, si)
+		out = append(out, sis[:i]...)
+		return append(out, sis[i+1:]...)
+	}
+
+	return sis
+}
+
 func (st *Local) OpenPath(ctx context.Context, p string) error {
 	st.localLk.Lock()
 	defer st.localLk.Unlock()
@@ -515,6 +554,10 @@ func (st *Local) AcquireSector(ctx context.Context, sid storiface.SectorRef, exi
 			return storiface.SectorPaths{}, storiface.SectorPaths{}, xerrors.Errorf("finding best storage for allocating : %w", err)
 		}
 
+		if pathType == storiface.PathSealing {
+			sis = st.preferStaging(sis)
+		}
+
 		var best string
 		var bestID storiface.ID
 
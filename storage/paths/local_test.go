@@ -10,6 +10,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
+	"github.com/filecoin-project/go-state-types/abi"
+
 	"github.com/filecoin-project/lotus/storage/sealer/fsutil"
 	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 )
@@ -93,3 +95,33 @@ func TestLocalStorage(t *testing.T) {
 
 	// TODO: put more things here
 }
+
+func TestLocalStagingPath(t *testing.T) {
+	ctx := context.TODO()
+
+	tstor := &TestingLocalStorage{
+		root: t.TempDir(),
+	}
+
+	st, err := NewLocal(ctx, tstor, NewIndex(nil), nil)
+	require.NoError(t, err)
+
+	for _, p := range []string{"1", "2"} {
+		require.NoError(t, tstor.init(p))
+		require.NoError(t, st.OpenPath(ctx, filepath.Join(tstor.root, p)))
+	}
+
+	sref := storiface.SectorRef{
+		ID:        abi.SectorID{Miner: 1000, Number: 1},
+		ProofType: abi.RegisteredSealProof_StackedDrg2KiBV1,
+	}
+
+	for _, staging := range []string{"1", "2"} {
+		st.SetStagingPath(filepath.Join(tstor.root, staging) + "/")
+
+		out, _, err := st.AcquireSector(ctx, sref, storiface.FTNone, storiface.FTSealed|storiface.FTCache, storiface.PathSealing, storiface.AcquireMove)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(tstor.root, staging, storiface.FTSealed.String(), storiface.SectorName(sref.ID)), out.Sealed)
+		require.Equal(t, filepath.Join(tstor.root, staging, storiface.FTCache.String(), storiface.SectorName(sref.ID)), out.Cache)
+	}
+}
@@ -41,6 +41,11 @@ type Remote struct {
 
 	limit chan struct{}
 
+	// bandwidth limits the combined throughput of sector transfers, if set
+	bandwidth *BandwidthLimiter
+	// moveLimit limits the number of concurrent sector moves, if set
+	moveLimit chan struct{}
+
 	fetchLk  sync.Mutex
 	fetching map[abi.SectorID]chan struct{}
 
@@ -92,6 +97,22 @@ func NewRemote(local Store, index SectorIndex, auth http.Header, fetchLimit int,
 	}
 }
 
+// SetBandwidthLimiter limits the combined throughput of the sector data
+// fetched and read from other storage paths. It must be called before the
+// store is used.
+func (r *Remote) SetBandwidthLimiter(l *BandwidthLimiter) {
+	r.bandwidth = l
+}
+
+// SetMoveLimit limits the number of sector moves to long-term storage running
+// at once to n; further moves are queued until a running move completes. 0
+// means unlimited. It must be called before the store is used.
+func (r *Remote) SetMoveLimit(n int) {
+	if n > 0 {
+		r.moveLimit = make(chan struct{}, n)
+	}
+}
+
 func (r *Remote) AcquireSector(ctx context.Context, s storiface.SectorRef, existing storiface.SectorFileType, allocate storiface.SectorFileType, pathType storiface.PathType, op storiface.AcquireMode) (storiface.SectorPaths, storiface.SectorPaths, error) {
 	if existing|allocate != existing^allocate {
 		return storiface.SectorPaths{}, storiface.SectorPaths{}, xerrors.New("can't both find and allocate a sector")
@@ -279,7 +300,7 @@ func (r *Remote) fetchThrottled(ctx context.Context, url, outname string) (rerr
 		return xerrors.Errorf("context error while waiting for fetch limiter: %w", ctx.Err())
 	}
 
-	return fetch(ctx, url, outname, r.auth)
+	return fetch(ctx, url, outname, r.auth, r.bandwidth)
 }
 
 func (r *Remote) checkAllocated(ctx context.Context, url string, spt abi.RegisteredSealProof, offset, size abi.PaddedPieceSize) (bool, error) {
@@ -308,6 +329,19 @@ func (r *Remote) checkAllocated(ctx context.Context, url string, spt abi.Registe
 }
 
 func (r *Remote) MoveStorage(ctx context.Context, s storiface.SectorRef, types storiface.SectorFileType) error {
+	if r.moveLimit != nil {
+		if len(r.moveLimit) >= cap(r.moveLimit) {
+			log.Infof("Throttling sector move, %d already running", len(r.moveLimit))
+		}
+
+		select {
+		case r.moveLimit <- struct{}{}:
+			defer func() { <-r.moveLimit }()
+		case <-ctx.Done():
+			return xerrors.Errorf("context error while waiting for move limiter: %w", ctx.Err())
+		}
+	}
+
 	// Make sure we have the data local
 	_, _, err := r.AcquireSector(ctx, s, types, storiface.FTNone, storiface.PathStorage, storiface.AcquireMove)
 	if err != nil {
@@ -476,7 +510,7 @@ func (r *Remote) readRemote(ctx context.Context, url string, offset, size abi.Pa
 		return nil, xerrors.Errorf("non-200 code: %d", resp.StatusCode)
 	}
 
-	return resp.Body, nil
+	return r.bandwidth.Reader(ctx, resp.Body), nil
 }
 
 // CheckIsUnsealed checks if we have an unsealed piece at the given offset in an already unsealed sector file for the given piece
@@ -72,6 +72,11 @@ type CommitBatcher struct {
 	todo    map[abi.SectorNumber]AggregateInput
 	waiting map[abi.SectorNumber][]chan sealiface.CommitBatchRes
 
+	// basefees seen while commits were pending, oldest first, and whether the last
+	// aggregate was held back because the basefee is falling
+	baseFees    []baseFeeSample
+	feeDecaying bool
+
 	notify, stop, stopped chan struct{}
 	force                 chan chan []sealiface.CommitBatchRes
 	lk                    sync.Mutex
@@ -111,7 +116,12 @@ func (b *CommitBatcher) run() {
 		panic(err)
 	}
 
-	timer := time.NewTimer(b.batchWait(cfg.CommitBatchWait, cfg.CommitBatchSlack))
+	deadline := time.Now().Add(b.batchWait(cfg.CommitBatchWait, cfg.CommitBatchSlack))
+	wait, recheck := b.nextWait(cfg, deadline)
+	timer := time.NewTimer(wait)
+
+	// set while a due batch is kept open for CommitBatchOverlapWindow
+	var overlapEnd time.Time
 	for {
 		if forceRes != nil {
 			forceRes <- lastMsg
@@ -120,7 +130,7 @@ func (b *CommitBatcher) run() {
 		lastMsg = nil
 
 		// indicates whether we should only start a batch if we have reached or exceeded cfg.MaxCommitBatch
-		var sendAboveMax bool
+		var sendAboveMax, sampleOnly, due bool
 		select {
 		case <-b.stop:
 			close(b.stopped)
@@ -128,13 +138,22 @@ func (b *CommitBatcher) run() {
 		case <-b.notify:
 			sendAboveMax = true
 		case <-timer.C:
-			// do nothing
+			// when woken up only to sample the basefee, don't force the batch out
+			sendAboveMax, sampleOnly = recheck, recheck
+			due = !recheck
 		case fr := <-b.force: // user triggered
 			forceRes = fr
 		}
 
+		if due && overlapEnd.IsZero() && cfg.CommitBatchOverlapWindow > 0 && b.batchPending() {
+			// let sectors becoming ready shortly join the batch
+			overlapEnd = time.Now().Add(cfg.CommitBatchOverlapWindow)
+			timer.Reset(cfg.CommitBatchOverlapWindow)
+			continue
+		}
+
 		var err error
-		lastMsg, err = b.maybeStartBatch(sendAboveMax)
+		lastMsg, err = b.maybeStartBatch(sendAboveMax, forceRes != nil)
 		if err != nil {
 			log.Warnw("CommitBatcher processBatch error", "error", err)
 		}
@@ -146,16 +165,66 @@ func (b *CommitBatcher) run() {
 			}
 		}
 
-		timer.Reset(b.batchWait(cfg.CommitBatchWait, cfg.CommitBatchSlack))
+		if !overlapEnd.IsZero() && !due && forceRes == nil && lastMsg == nil {
+			// the batch wasn't sent, keep it open until the window ends
+			wait := time.Until(overlapEnd)
+			if wait <= 0 {
+				wait = time.Nanosecond // can't return 0
+			}
+			timer.Reset(wait)
+			continue
+		}
+		overlapEnd = time.Time{}
+
+		if !sampleOnly {
+			deadline = time.Now().Add(b.batchWait(cfg.CommitBatchWait, cfg.CommitBatchSlack))
+		}
+		wait, recheck = b.nextWait(cfg, deadline)
+		timer.Reset(wait)
 	}
 }
 
-func (b *CommitBatcher) batchWait(maxWait, slack time.Duration) time.Duration {
-	now := time.Now()
+// nextWait returns how long to wait before evaluating the batch again, and whether
+// that evaluation is only a basefee sample (as opposed to a batch deadline).
+func (b *CommitBatcher) nextWait(cfg sealiface.Config, deadline time.Time) (time.Duration, bool) {
+	wait := time.Until(deadline)
+	if wait <= 0 {
+		wait = time.Nanosecond // can't return 0
+	}
+
+	b.lk.Lock()
+	pending, decaying := len(b.todo), b.feeDecaying
+	b.lk.Unlock()
 
+	epoch := time.Duration(build.BlockDelaySecs) * time.Second
+	if cfg.AggregateMinBaseFeeDecayRatio <= 0 || pending == 0 || wait <= epoch {
+		return wait, false
+	}
+
+	// a held back batch is evaluated again after an epoch, otherwise the basefee
+	// is sampled every epoch to tell whether it's falling
+	return epoch, !decaying
+}
+
+// batchPending is true if there are sectors waiting for a batch which isn't
+// being held back for a falling basefee.
+func (b *CommitBatcher) batchPending() bool {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	return len(b.todo) > 0 && !b.feeDecaying
+}
+
+func (b *CommitBatcher) batchWait(maxWait, slack time.Duration) time.Duration {
 	b.lk.Lock()
 	defer b.lk.Unlock()
 
+	return b.batchWaitLocked(maxWait, slack)
+}
+
+func (b *CommitBatcher) batchWaitLocked(maxWait, slack time.Duration) time.Duration {
+	now := time.Now()
+
 	if len(b.todo) == 0 {
 		return maxWait
 	}
@@ -191,7 +260,7 @@ func (b *CommitBatcher) batchWait(maxWait, slack time.Duration) time.Duration {
 	return wait
 }
 
-func (b *CommitBatcher) maybeStartBatch(notif bool) ([]sealiface.CommitBatchRes, error) {
+func (b *CommitBatcher) maybeStartBatch(notif, force bool) ([]sealiface.CommitBatchRes, error) {
 	b.lk.Lock()
 	defer b.lk.Unlock()
 
@@ -205,15 +274,29 @@ func (b *CommitBatcher) maybeStartBatch(notif bool) ([]sealiface.CommitBatchRes,
 		return nil, xerrors.Errorf("getting config: %w", err)
 	}
 
+	b.feeDecaying = false
+
+	var ts *types.TipSet
+	if cfg.AggregateMinBaseFeeDecayRatio > 0 {
+		// sample the basefee on every evaluation, including those which don't send the batch
+		ts, err = b.api.ChainHead(b.mctx)
+		if err != nil {
+			return nil, err
+		}
+		b.sampleBaseFee(ts, cfg.CommitBatchWait/2)
+	}
+
 	if notif && total < cfg.MaxCommitBatch {
 		return nil, nil
 	}
 
 	var res []sealiface.CommitBatchRes
 
-	ts, err := b.api.ChainHead(b.mctx)
-	if err != nil {
-		return nil, err
+	if ts == nil {
+		ts, err = b.api.ChainHead(b.mctx)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	blackedOut := func() bool {
@@ -232,6 +315,12 @@ func (b *CommitBatcher) maybeStartBatch(notif bool) ([]sealiface.CommitBatchRes,
 		}
 	}
 
+	if !individual && !force && b.holdForBaseFee(cfg, ts) {
+		log.Infow("basefee is falling, holding back commit aggregate", "sectors", total, "basefee", ts.MinTicketBlock().ParentBaseFee, "reference", b.baseFees[0].fee)
+		b.feeDecaying = true
+		return nil, nil
+	}
+
 	if individual {
 		res, err = b.processIndividually(cfg)
 	} else {
@@ -269,6 +358,40 @@ func (b *CommitBatcher) maybeStartBatch(notif bool) ([]sealiface.CommitBatchRes,
 	return res, nil
 }
 
+type baseFeeSample struct {
+	epoch abi.ChainEpoch
+	fee   abi.TokenAmount
+}
+
+// sampleBaseFee records the basefee of ts, forgetting samples older than window.
+// Must be called with b.lk held.
+func (b *CommitBatcher) sampleBaseFee(ts *types.TipSet, window time.Duration) {
+	if n := len(b.baseFees); n == 0 || b.baseFees[n-1].epoch < ts.Height() {
+		b.baseFees = append(b.baseFees, baseFeeSample{epoch: ts.Height(), fee: ts.MinTicketBlock().ParentBaseFee})
+	}
+
+	oldest := ts.Height() - abi.ChainEpoch(window/(time.Duration(build.BlockDelaySecs)*time.Second))
+	for len(b.baseFees) > 0 && b.baseFees[0].epoch < oldest {
+		b.baseFees = b.baseFees[1:]
+	}
+}
+
+// holdForBaseFee reports whether an aggregate should be held back because the
+// basefee dropped below AggregateMinBaseFeeDecayRatio of the oldest sample. Batches
+// are never held back past the commit slack. Must be called with b.lk held.
+func (b *CommitBatcher) holdForBaseFee(cfg sealiface.Config, ts *types.TipSet) bool {
+	if cfg.AggregateMinBaseFeeDecayRatio <= 0 || len(b.baseFees) == 0 {
+		return false
+	}
+
+	epoch := time.Duration(build.BlockDelaySecs) * time.Second
+	if b.batchWaitLocked(cfg.CommitBatchWait, cfg.CommitBatchSlack) <= epoch {
+		return false
+	}
+
+	return baseFeeDecayed(b.baseFees[0].fee, ts.MinTicketBlock().ParentBaseFee, cfg.AggregateMinBaseFeeDecayRatio)
+}
+
 func (b *CommitBatcher) processBatch(cfg sealiface.Config, sectors []abi.SectorNumber) ([]sealiface.CommitBatchRes, error) {
 	ts, err := b.api.ChainHead(b.mctx)
 	if err != nil {
@@ -358,7 +481,10 @@ func (b *CommitBatcher) processBatch(cfg sealiface.Config, sectors []abi.SectorN
 		return []sealiface.CommitBatchRes{res}, xerrors.Errorf("couldn't get miner info: %w", err)
 	}
 
-	maxFee := b.feeCfg.MaxCommitBatchGasFee.FeeForSectors(len(infos))
+	maxFee, capped := b.feeCfg.MaxCommitBatchGasFee.EffectiveFeeForSectors(len(infos))
+	if capped {
+		log.Warnw("commit batch max fee capped by MaxTotalFee", "sectors", len(infos), "maxFee", types.FIL(maxFee))
+	}
 
 	aggFeeRaw, err := policy.AggregateProveCommitNetworkFee(nv, len(infos), ts.MinTicketBlock().ParentBaseFee)
 	if err != nil {
@@ -384,7 +510,7 @@ func (b *CommitBatcher) processBatch(cfg sealiface.Config, sectors []abi.SectorN
 		return []sealiface.CommitBatchRes{res}, xerrors.Errorf("no good address found: %w", err)
 	}
 
-	_, err = simulateMsgGas(b.mctx, b.api, from, b.maddr, builtin.MethodsMiner.ProveCommitAggregate, needFunds, maxFee, enc.Bytes())
+	estMsg, err := simulateMsgGas(b.mctx, b.api, from, b.maddr, builtin.MethodsMiner.ProveCommitAggregate, needFunds, maxFee, enc.Bytes())
 
 	if err != nil && (!api.ErrorIsIn(err, []error{&api.ErrOutOfGas{}}) || len(sectors) < miner.MinAggregatedSectors*2) {
 		log.Errorf("simulating CommitBatch message failed: %s", err)
@@ -402,7 +528,10 @@ func (b *CommitBatcher) processBatch(cfg sealiface.Config, sectors []abi.SectorN
 		return append(ret0, ret1...), nil
 	}
 
-	mcid, err := sendMsg(b.mctx, b.api, from, b.maddr, builtin.MethodsMiner.ProveCommitAggregate, needFunds, maxFee, enc.Bytes())
+	gasLimit := multipliedGasLimit(estMsg.GasLimit, cfg.CommitGasMultiplier)
+	log.Debugw("commit aggregate gas limit", "sectors", len(infos), "estimated", estMsg.GasLimit, "effective", gasLimit, "multiplier", cfg.CommitGasMultiplier)
+
+	mcid, err := sendMsgWithGasLimit(b.mctx, b.api, from, b.maddr, builtin.MethodsMiner.ProveCommitAggregate, needFunds, maxFee, gasLimit, enc.Bytes())
 	if err != nil {
 		return []sealiface.CommitBatchRes{res}, xerrors.Errorf("sending message failed: %w", err)
 	}
@@ -519,7 +648,12 @@ func (b *CommitBatcher) processSingle(cfg sealiface.Config, mi api.MinerInfo, av
 		return cid.Undef, xerrors.Errorf("no good address to send commit message from: %w", err)
 	}
 
-	mcid, err := sendMsg(b.mctx, b.api, from, b.maddr, builtin.MethodsMiner.ProveCommitSector, collateral, big.Int(b.feeCfg.MaxCommitGasFee), enc.Bytes())
+	gasLimit, err := estimateGasLimit(b.mctx, b.api, from, b.maddr, builtin.MethodsMiner.ProveCommitSector, collateral, big.Int(b.feeCfg.MaxCommitGasFee), enc.Bytes(), cfg.CommitGasMultiplier)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("estimating commit message gas: %w", err)
+	}
+
+	mcid, err := sendMsgWithGasLimit(b.mctx, b.api, from, b.maddr, builtin.MethodsMiner.ProveCommitSector, collateral, big.Int(b.feeCfg.MaxCommitGasFee), gasLimit, enc.Bytes())
 	if err != nil {
 		return cid.Undef, xerrors.Errorf("pushing message to mpool: %w", err)
 	}
@@ -0,0 +1,65 @@
+package sealing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// commitLimiter tracks sectors with a ProveCommitSector message in flight,
+// bounding their number when MaxConcurrentProveCommits is set.
+type commitLimiter struct {
+	lk      sync.Mutex
+	sectors map[abi.SectorNumber]struct{}
+
+	// closed and replaced whenever a sector is released
+	released chan struct{}
+}
+
+func newCommitLimiter() *commitLimiter {
+	return &commitLimiter{
+		sectors:  map[abi.SectorNumber]struct{}{},
+		released: make(chan struct{}),
+	}
+}
+
+// acquire waits until fewer than limit sectors are in flight, then marks the
+// sector as in flight. A limit of 0 means no limit.
+func (l *commitLimiter) acquire(ctx context.Context, sn abi.SectorNumber, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	for {
+		l.lk.Lock()
+		if _, ok := l.sectors[sn]; ok || len(l.sectors) < limit {
+			l.sectors[sn] = struct{}{}
+			l.lk.Unlock()
+			return nil
+		}
+		released := l.released
+		l.lk.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release marks the sector as no longer in flight; it's a no-op for sectors
+// which were never acquired.
+func (l *commitLimiter) release(sn abi.SectorNumber) {
+	l.lk.Lock()
+	defer l.lk.Unlock()
+
+	if _, ok := l.sectors[sn]; !ok {
+		return
+	}
+
+	delete(l.sectors, sn)
+	close(l.released)
+	l.released = make(chan struct{})
+}
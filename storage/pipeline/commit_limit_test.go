@@ -0,0 +1,42 @@
+package sealing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitLimiter(t *testing.T) {
+	ctx := context.Background()
+	l := newCommitLimiter()
+
+	require.NoError(t, l.acquire(ctx, 1, 2))
+	require.NoError(t, l.acquire(ctx, 2, 2))
+
+	// re-acquiring an in-flight sector doesn't take another slot
+	require.NoError(t, l.acquire(ctx, 1, 2))
+
+	// no limit
+	require.NoError(t, l.acquire(ctx, 3, 0))
+
+	tctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, l.acquire(tctx, 3, 2), context.DeadlineExceeded)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- l.acquire(ctx, 3, 2)
+	}()
+
+	l.release(4) // not in flight, no-op
+	l.release(1)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("acquire didn't return after release")
+	}
+}
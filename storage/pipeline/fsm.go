@@ -105,6 +105,7 @@ var fsmPlanners = map[SectorState]func(events []statemachine.Event, state *Secto
 	),
 	SubmitPreCommitBatch: planOne(
 		on(SectorPreCommitBatchSent{}, PreCommitBatchWait),
+		on(SectorOldTicket{}, GetTicket),
 		on(SectorSealPreCommit1Failed{}, SealPreCommit1Failed),
 		on(SectorChainPreCommitFailed{}, PreCommitFailed),
 		on(SectorPreCommitLanded{}, WaitSeed),
@@ -3,6 +3,7 @@ package sealing
 import (
 	"context"
 	"testing"
+	"time"
 
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/stretchr/testify/require"
@@ -12,6 +13,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-statemachine"
 
+	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 )
 
@@ -381,6 +383,12 @@ func TestTicketExpired(t *testing.T) {
 	expired := checkTicketExpired(0, MaxTicketAge+1)
 	require.True(t, expired)
 
+	require.False(t, checkTicketTooOld(0, MaxTicketAge, 0, 0))
+	require.False(t, checkTicketTooOld(0, MaxTicketAge/2, 0.5, 0))
+	require.True(t, checkTicketTooOld(0, MaxTicketAge/2+1, 0.5, 0))
+	slack := time.Duration(MaxTicketAge/2) * time.Duration(build.BlockDelaySecs) * time.Second
+	require.True(t, checkTicketTooOld(0, MaxTicketAge/4+1, 0.5, slack))
+
 	m.planSingle(SectorOldTicket{})
 	require.Equal(m.t, m.state.State, GetTicket)
 
@@ -476,3 +484,25 @@ func TestRetrySoftErr(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 2, i)
 }
+
+func TestSealRetryWait(t *testing.T) {
+	for _, tc := range []struct {
+		policy   string
+		failures uint64
+		wait     time.Duration
+		retry    bool
+	}{
+		{"exponential", 0, MinRetryTime, true},
+		{"exponential", 1, MinRetryTime, true},
+		{"exponential", 3, 4 * MinRetryTime, true},
+		{"exponential", 100, MaxRetryTime, true},
+		{"linear", 1, MinRetryTime, true},
+		{"linear", 3, 3 * MinRetryTime, true},
+		{"linear", 1 << 62, MaxRetryTime, true},
+		{"none", 1, 0, false},
+	} {
+		wait, retry := sealRetryWait(tc.policy, tc.failures)
+		require.Equal(t, tc.retry, retry, "%s/%d", tc.policy, tc.failures)
+		require.Equal(t, tc.wait, wait, "%s/%d", tc.policy, tc.failures)
+	}
+}
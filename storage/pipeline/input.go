@@ -9,6 +9,7 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/xerrors"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-commp-utils/zerocomm"
 	"github.com/filecoin-project/go-padreader"
 	"github.com/filecoin-project/go-state-types/abi"
@@ -31,9 +32,14 @@ import (
 func (m *Sealing) handleWaitDeals(ctx statemachine.Context, sector SectorInfo) error {
 	var used abi.UnpaddedPieceSize
 	var lastDealEnd abi.ChainEpoch
+	clients := map[address.Address]struct{}{}
 	for _, piece := range sector.Pieces {
 		used += piece.Piece.Size.Unpadded()
 
+		if piece.DealInfo != nil {
+			clients[piece.DealInfo.DealProposal.Client] = struct{}{}
+		}
+
 		if piece.DealInfo != nil && piece.DealInfo.DealProposal.EndEpoch > lastDealEnd {
 			lastDealEnd = piece.DealInfo.DealProposal.EndEpoch
 		}
@@ -80,6 +86,7 @@ func (m *Sealing) handleWaitDeals(ctx statemachine.Context, sector SectorInfo) e
 		m.openSectors[sid].used = used
 	}
 	m.openSectors[sid].lastDealEnd = lastDealEnd
+	m.openSectors[sid].clients = clients
 
 	go func() {
 		defer m.inputLk.Unlock()
@@ -487,6 +494,11 @@ func (m *Sealing) updateInput(ctx context.Context, sp abi.RegisteredSealProof) e
 		return err
 	}
 
+	cfg, err := m.getConfig()
+	if err != nil {
+		return xerrors.Errorf("getting config: %w", err)
+	}
+
 	type match struct {
 		sector abi.SectorID
 		deal   cid.Cid
@@ -523,6 +535,10 @@ func (m *Sealing) updateInput(ctx context.Context, sp abi.RegisteredSealProof) e
 				continue
 			}
 
+			if !sector.acceptsClient(piece.deal.DealProposal.Client, cfg.MaxDealClientAddressCount) {
+				continue
+			}
+
 			if piece.size <= avail { // (note: if we have enough space for the piece, we also have enough space for inter-piece padding)
 				matches = append(matches, match{
 					sector: id,
@@ -576,6 +592,11 @@ func (m *Sealing) updateInput(ctx context.Context, sp abi.RegisteredSealProof) e
 			continue
 		}
 
+		client := m.pendingPieces[mt.deal].deal.DealProposal.Client
+		if !m.openSectors[mt.sector].acceptsClient(client, cfg.MaxDealClientAddressCount) {
+			continue
+		}
+
 		// assign the piece!
 
 		err := m.openSectors[mt.sector].maybeAccept(mt.deal)
@@ -587,6 +608,10 @@ func (m *Sealing) updateInput(ctx context.Context, sp abi.RegisteredSealProof) e
 		if mt.dealEnd > m.openSectors[mt.sector].lastDealEnd {
 			m.openSectors[mt.sector].lastDealEnd = mt.dealEnd
 		}
+		if m.openSectors[mt.sector].clients == nil {
+			m.openSectors[mt.sector].clients = map[address.Address]struct{}{}
+		}
+		m.openSectors[mt.sector].clients[client] = struct{}{}
 
 		m.pendingPieces[mt.deal].assigned = true
 		delete(toAssign, mt.deal)
@@ -736,6 +761,11 @@ func (m *Sealing) maybeUpgradeSector(ctx context.Context, sp abi.RegisteredSealP
 			log.Debugw("skipping available sector", "sector", s.Number, "reason", "expiration below MinUpgradeSectorExpiration")
 		}
 
+		if cfg.SnapDealSectorReuseWindow > 0 && expirationEpoch-ts.Height() < cfg.SnapDealSectorReuseWindow {
+			log.Debugw("skipping available sector", "sector", s.Number, "reason", "remaining lifetime below SnapDealSectorReuseWindow", "expiration", expirationEpoch)
+			continue
+		}
+
 		pb := findBound(expirationEpoch)
 		if pb == nil {
 			log.Debugw("skipping available sector", "sector", s.Number, "reason", "expiration below deal bounds")
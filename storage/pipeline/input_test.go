@@ -0,0 +1,33 @@
+package sealing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestOpenSectorAcceptsClient(t *testing.T) {
+	c1, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	c2, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+	c3, err := address.NewIDAddress(1002)
+	require.NoError(t, err)
+
+	o := &openSector{clients: map[address.Address]struct{}{c1: {}, c2: {}}}
+
+	// no limit
+	require.True(t, o.acceptsClient(c3, 0))
+
+	// clients already in the sector are always accepted
+	require.True(t, o.acceptsClient(c1, 2))
+	require.True(t, o.acceptsClient(c2, 1))
+
+	require.False(t, o.acceptsClient(c3, 2))
+	require.True(t, o.acceptsClient(c3, 3))
+
+	// empty sectors accept any client
+	require.True(t, (&openSector{}).acceptsClient(c3, 1))
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ipfs/go-cid"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
@@ -26,6 +27,10 @@ import (
 	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
 )
 
+// ErrPreCommitBatchAborted is returned by AddPreCommit when the sector was removed
+// from the batch because it was about to expire, see PreCommitBatchAbortOnExpiry.
+var ErrPreCommitBatchAborted = xerrors.New("sector removed from pre-commit batch close to expiry")
+
 //go:generate go run github.com/golang/mock/mockgen -destination=mocks/mock_precommit_batcher.go -package=mocks . PreCommitBatcherApi
 
 type PreCommitBatcherApi interface {
@@ -60,10 +65,16 @@ type PreCommitBatcher struct {
 	cutoffs map[abi.SectorNumber]time.Time
 	todo    map[abi.SectorNumber]*preCommitEntry
 	waiting map[abi.SectorNumber][]chan sealiface.PreCommitBatchRes
+	aborted map[abi.SectorNumber]struct{}
+
+	// pre-commit messages sent but not yet landed, and whether a batch is held
+	// back because MaxPreCommitsInFlight were in flight
+	inflight map[cid.Cid]struct{}
+	blocked  bool
 
-	notify, stop, stopped chan struct{}
-	force                 chan chan []sealiface.PreCommitBatchRes
-	lk                    sync.Mutex
+	notify, landed, stop, stopped chan struct{}
+	force                         chan chan []sealiface.PreCommitBatchRes
+	lk                            sync.Mutex
 }
 
 func NewPreCommitBatcher(mctx context.Context, maddr address.Address, api PreCommitBatcherApi, addrSel AddressSelector, feeCfg config.MinerFeeConfig, getConfig dtypes.GetSealingConfigFunc) *PreCommitBatcher {
@@ -78,8 +89,12 @@ func NewPreCommitBatcher(mctx context.Context, maddr address.Address, api PreCom
 		cutoffs: map[abi.SectorNumber]time.Time{},
 		todo:    map[abi.SectorNumber]*preCommitEntry{},
 		waiting: map[abi.SectorNumber][]chan sealiface.PreCommitBatchRes{},
+		aborted: map[abi.SectorNumber]struct{}{},
+
+		inflight: map[cid.Cid]struct{}{},
 
 		notify:  make(chan struct{}, 1),
+		landed:  make(chan struct{}, 1),
 		force:   make(chan chan []sealiface.PreCommitBatchRes),
 		stop:    make(chan struct{}),
 		stopped: make(chan struct{}),
@@ -99,7 +114,8 @@ func (b *PreCommitBatcher) run() {
 		panic(err)
 	}
 
-	timer := time.NewTimer(b.batchWait(cfg.PreCommitBatchWait, cfg.PreCommitBatchSlack))
+	wait, recheck := b.nextWait(cfg)
+	timer := time.NewTimer(wait)
 	for {
 		if forceRes != nil {
 			forceRes <- lastRes
@@ -114,14 +130,17 @@ func (b *PreCommitBatcher) run() {
 			return
 		case <-b.notify:
 			sendAboveMax = true
+		case <-b.landed:
+			// a held back batch can go out now
 		case <-timer.C:
-			// do nothing
+			// when woken up only to re-evaluate the basefee, don't force the batch out
+			sendAboveMax = recheck
 		case fr := <-b.force: // user triggered
 			forceRes = fr
 		}
 
 		var err error
-		lastRes, err = b.maybeStartBatch(sendAboveMax)
+		lastRes, err = b.maybeStartBatch(sendAboveMax, forceRes != nil)
 		if err != nil {
 			log.Warnw("PreCommitBatcher processBatch error", "error", err)
 		}
@@ -133,8 +152,31 @@ func (b *PreCommitBatcher) run() {
 			}
 		}
 
-		timer.Reset(b.batchWait(cfg.PreCommitBatchWait, cfg.PreCommitBatchSlack))
+		wait, recheck = b.nextWait(cfg)
+		timer.Reset(wait)
+	}
+}
+
+// nextWait returns how long to wait before evaluating the batch again, and whether
+// that evaluation is only a basefee re-check (as opposed to a batch deadline).
+func (b *PreCommitBatcher) nextWait(cfg sealiface.Config) (time.Duration, bool) {
+	wait := b.batchWait(cfg.PreCommitBatchWait, cfg.PreCommitBatchSlack)
+
+	dynamic := cfg.BatchPreCommitAboveBaseFeeDynamic || cfg.SectorPreCommitBatchGasTarget > 0
+	if !dynamic || cfg.BatchPreCommitAboveBaseFee.NilOrZero() {
+		return wait, false
+	}
+
+	b.lk.Lock()
+	pending := len(b.todo)
+	b.lk.Unlock()
+
+	epoch := time.Duration(build.BlockDelaySecs) * time.Second
+	if pending == 0 || wait <= epoch {
+		return wait, false
 	}
+
+	return epoch, true
 }
 
 func (b *PreCommitBatcher) batchWait(maxWait, slack time.Duration) time.Duration {
@@ -178,7 +220,7 @@ func (b *PreCommitBatcher) batchWait(maxWait, slack time.Duration) time.Duration
 	return wait
 }
 
-func (b *PreCommitBatcher) maybeStartBatch(notif bool) ([]sealiface.PreCommitBatchRes, error) {
+func (b *PreCommitBatcher) maybeStartBatch(notif, force bool) ([]sealiface.PreCommitBatchRes, error) {
 	b.lk.Lock()
 	defer b.lk.Unlock()
 
@@ -198,13 +240,36 @@ func (b *PreCommitBatcher) maybeStartBatch(notif bool) ([]sealiface.PreCommitBat
 	}
 
 	curBasefeeLow := false
-	if !cfg.BatchPreCommitAboveBaseFee.Equals(big.Zero()) && ts.MinTicketBlock().ParentBaseFee.LessThan(cfg.BatchPreCommitAboveBaseFee) {
+	if !cfg.BatchPreCommitAboveBaseFee.Equals(big.Zero()) && ts.MinTicketBlock().ParentBaseFee.LessThan(preCommitBaseFeeTarget(cfg.BatchPreCommitAboveBaseFee, cfg.SectorPreCommitBatchGasTarget)) {
 		curBasefeeLow = true
 	}
 
-	// if this wasn't an user-forced batch, and we're not at/above the max batch size,
+	full := total >= cfg.MaxPreCommitBatch
+	if cfg.MaxPreCommitBatchByValue {
+		fee, err := b.estimateBatchFee(total, ts)
+		if err != nil {
+			return nil, xerrors.Errorf("estimating pre-commit batch fee: %w", err)
+		}
+		full = fee.GreaterThanEqual(cfg.MaxPreCommitBatchFeeValue)
+	}
+
+	// if this wasn't an user-forced batch, and we're not at/above the max batch size (or value),
 	// and we're not above the basefee threshold, don't batch yet
-	if notif && total < cfg.MaxPreCommitBatch && !curBasefeeLow {
+	if notif && !full && !curBasefeeLow {
+		return nil, nil
+	}
+
+	// instead of forcing the batch out because some sectors are about to expire,
+	// drop those sectors and keep batching the rest
+	if cfg.PreCommitBatchAbortOnExpiry && !force && !full && !curBasefeeLow {
+		if b.abortExpiring(cfg.PreCommitBatchSlack) > 0 {
+			return nil, nil
+		}
+	}
+
+	b.blocked = cfg.MaxPreCommitsInFlight > 0 && len(b.inflight) >= cfg.MaxPreCommitsInFlight
+	if b.blocked {
+		log.Infow("holding back pre-commit batch until in-flight pre-commits land", "sectors", total, "inflight", len(b.inflight))
 		return nil, nil
 	}
 
@@ -224,6 +289,9 @@ func (b *PreCommitBatcher) maybeStartBatch(notif bool) ([]sealiface.PreCommitBat
 		if err != nil {
 			r.Error = err.Error()
 		}
+		if r.Msg != nil {
+			b.inflight[*r.Msg] = struct{}{}
+		}
 
 		for _, sn := range r.Sectors {
 			for _, ch := range b.waiting[sn] {
@@ -239,6 +307,75 @@ func (b *PreCommitBatcher) maybeStartBatch(notif bool) ([]sealiface.PreCommitBat
 	return res, nil
 }
 
+// abortExpiring removes sectors whose cutoff, minus slack, has passed from the batch,
+// returning the number of removed sectors. Must be called with b.lk held.
+func (b *PreCommitBatcher) abortExpiring(slack time.Duration) int {
+	now := time.Now()
+
+	var aborted int
+	for sn := range b.todo {
+		cutoff := b.cutoffs[sn]
+		if cutoff.IsZero() || cutoff.Add(-slack).After(now) {
+			continue
+		}
+
+		log.Warnw("removing sector close to expiry from pre-commit batch", "sector", sn, "cutoff", cutoff)
+
+		b.aborted[sn] = struct{}{}
+		for _, ch := range b.waiting[sn] {
+			ch <- sealiface.PreCommitBatchRes{ // buffered
+				Sectors: []abi.SectorNumber{sn},
+				Error:   ErrPreCommitBatchAborted.Error(),
+			}
+		}
+
+		delete(b.waiting, sn)
+		delete(b.todo, sn)
+		delete(b.cutoffs, sn)
+		aborted++
+	}
+
+	return aborted
+}
+
+// messageLanded marks the pre-commit message as no longer in flight, sending out
+// a batch held back by MaxPreCommitsInFlight.
+func (b *PreCommitBatcher) messageLanded(mcid cid.Cid) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	if _, ok := b.inflight[mcid]; !ok {
+		return
+	}
+	delete(b.inflight, mcid)
+
+	if b.blocked {
+		select {
+		case b.landed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// estimateBatchFee estimates the total fee of a batch of the given number of
+// sectors: the aggregate network fee at the basefee of ts plus the gas fee
+// allowed for the batch.
+func (b *PreCommitBatcher) estimateBatchFee(sectors int, ts *types.TipSet) (abi.TokenAmount, error) {
+	nv, err := b.api.StateNetworkVersion(b.mctx, ts.Key())
+	if err != nil {
+		return big.Zero(), xerrors.Errorf("couldn't get network version: %w", err)
+	}
+
+	aggFeeRaw, err := policy.AggregatePreCommitNetworkFee(nv, sectors, ts.MinTicketBlock().ParentBaseFee)
+	if err != nil {
+		return big.Zero(), xerrors.Errorf("getting aggregate precommit network fee: %w", err)
+	}
+	aggFee := big.Div(big.Mul(aggFeeRaw, aggFeeNum), aggFeeDen)
+
+	maxFee, _ := b.feeCfg.MaxPreCommitBatchGasFee.EffectiveFeeForSectors(sectors)
+	return big.Add(aggFee, maxFee), nil
+}
+
 func (b *PreCommitBatcher) processPreCommitBatch(cfg sealiface.Config, bf abi.TokenAmount, entries []*preCommitEntry, nv network.Version) ([]sealiface.PreCommitBatchRes, error) {
 	params := miner.PreCommitSectorBatchParams2{}
 	deposit := big.Zero()
@@ -262,7 +399,10 @@ func (b *PreCommitBatcher) processPreCommitBatch(cfg sealiface.Config, bf abi.To
 		return []sealiface.PreCommitBatchRes{res}, xerrors.Errorf("couldn't get miner info: %w", err)
 	}
 
-	maxFee := b.feeCfg.MaxPreCommitBatchGasFee.FeeForSectors(len(params.Sectors))
+	maxFee, capped := b.feeCfg.MaxPreCommitBatchGasFee.EffectiveFeeForSectors(len(params.Sectors))
+	if capped {
+		log.Warnw("pre-commit batch max fee capped by MaxTotalFee", "sectors", len(params.Sectors), "maxFee", types.FIL(maxFee))
+	}
 
 	aggFeeRaw, err := policy.AggregatePreCommitNetworkFee(nv, len(params.Sectors), bf)
 	if err != nil {
@@ -286,7 +426,7 @@ func (b *PreCommitBatcher) processPreCommitBatch(cfg sealiface.Config, bf abi.To
 		return []sealiface.PreCommitBatchRes{res}, xerrors.Errorf("no good address found: %w", err)
 	}
 
-	_, err = simulateMsgGas(b.mctx, b.api, from, b.maddr, builtin.MethodsMiner.PreCommitSectorBatch2, needFunds, maxFee, enc.Bytes())
+	estMsg, err := simulateMsgGas(b.mctx, b.api, from, b.maddr, builtin.MethodsMiner.PreCommitSectorBatch2, needFunds, maxFee, enc.Bytes())
 
 	if err != nil && (!api.ErrorIsIn(err, []error{&api.ErrOutOfGas{}}) || len(entries) == 1) {
 		res.Error = err.Error()
@@ -303,8 +443,15 @@ func (b *PreCommitBatcher) processPreCommitBatch(cfg sealiface.Config, bf abi.To
 		return append(ret0, ret1...), nil
 	}
 
+	gasLimit := multipliedGasLimit(estMsg.GasLimit, cfg.PreCommitGasMultiplier)
+	effectiveGasLimit := gasLimit
+	if effectiveGasLimit == 0 {
+		effectiveGasLimit = estMsg.GasLimit
+	}
+	log.Debugw("pre-commit batch gas limit", "sectors", len(params.Sectors), "estimated", estMsg.GasLimit, "effective", effectiveGasLimit, "multiplier", cfg.PreCommitGasMultiplier)
+
 	// If state call succeeds, we can send the message for real
-	mcid, err := sendMsg(b.mctx, b.api, from, b.maddr, builtin.MethodsMiner.PreCommitSectorBatch2, needFunds, maxFee, enc.Bytes())
+	mcid, err := sendMsgWithGasLimit(b.mctx, b.api, from, b.maddr, builtin.MethodsMiner.PreCommitSectorBatch2, needFunds, maxFee, gasLimit, enc.Bytes())
 	if err != nil {
 		res.Error = err.Error()
 		return []sealiface.PreCommitBatchRes{res}, xerrors.Errorf("pushing message to mpool: %w", err)
@@ -365,6 +512,14 @@ func (b *PreCommitBatcher) AddPreCommit(ctx context.Context, s SectorInfo, depos
 
 	select {
 	case c := <-sent:
+		b.lk.Lock()
+		_, aborted := b.aborted[sn]
+		delete(b.aborted, sn)
+		b.lk.Unlock()
+
+		if aborted {
+			return c, ErrPreCommitBatchAborted
+		}
 		return c, nil
 	case <-ctx.Done():
 		return sealiface.PreCommitBatchRes{}, ctx.Err()
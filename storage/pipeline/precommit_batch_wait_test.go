@@ -0,0 +1,67 @@
+package sealing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
+)
+
+func TestPreCommitBatcherNextWait(t *testing.T) {
+	epoch := time.Duration(build.BlockDelaySecs) * time.Second
+
+	newBatcher := func(cutoffs ...time.Duration) *PreCommitBatcher {
+		b := &PreCommitBatcher{
+			cutoffs: map[abi.SectorNumber]time.Time{},
+			todo:    map[abi.SectorNumber]*preCommitEntry{},
+			waiting: map[abi.SectorNumber][]chan sealiface.PreCommitBatchRes{},
+		}
+		for i, c := range cutoffs {
+			sn := abi.SectorNumber(i)
+			b.todo[sn] = &preCommitEntry{}
+			b.cutoffs[sn] = time.Now().Add(c)
+		}
+		return b
+	}
+
+	cfg := func(dynamic bool) sealiface.Config {
+		return sealiface.Config{
+			PreCommitBatchWait:                24 * time.Hour,
+			BatchPreCommitAboveBaseFee:        big.NewInt(10000),
+			BatchPreCommitAboveBaseFeeDynamic: dynamic,
+		}
+	}
+
+	// without the dynamic re-check, only the batch deadline wakes the batcher
+	wait, recheck := newBatcher(time.Hour).nextWait(cfg(false))
+	require.False(t, recheck)
+	require.Greater(t, wait, 59*time.Minute)
+
+	// with it, pending sectors are re-checked against the basefee every epoch
+	wait, recheck = newBatcher(time.Hour).nextWait(cfg(true))
+	require.True(t, recheck)
+	require.Equal(t, epoch, wait)
+
+	// a batch deadline within the next epoch comes first
+	wait, recheck = newBatcher(epoch/2).nextWait(cfg(true))
+	require.False(t, recheck)
+	require.LessOrEqual(t, wait, epoch/2)
+
+	// there is nothing to re-check without pending sectors
+	wait, recheck = newBatcher().nextWait(cfg(true))
+	require.False(t, recheck)
+	require.Equal(t, 24*time.Hour, wait)
+
+	// nor when batching doesn't depend on the basefee
+	noThreshold := cfg(true)
+	noThreshold.BatchPreCommitAboveBaseFee = big.Zero()
+	wait, recheck = newBatcher(time.Hour).nextWait(noThreshold)
+	require.False(t, recheck)
+	require.Greater(t, wait, 59*time.Minute)
+}
@@ -22,6 +22,8 @@ type Config struct {
 
 	MinUpgradeSectorExpiration uint64
 
+	SnapDealSectorReuseWindow abi.ChainEpoch
+
 	MaxUpgradingSectors uint64
 
 	MakeNewSectorForDeals bool
@@ -34,32 +36,88 @@ type Config struct {
 
 	StartEpochSealingBuffer abi.ChainEpoch
 
+	// MaxDealClientAddressCount limits the number of distinct deal clients
+	// in a sector; 0 = no limit
+	MaxDealClientAddressCount int
+
 	AlwaysKeepUnsealedCopy bool
 
+	// DealLevelFastRetrieval makes the fast retrieval flag of each deal decide
+	// whether its unsealed copy is kept, overriding AlwaysKeepUnsealedCopy
+	DealLevelFastRetrieval bool
+
 	FinalizeEarly bool
 
+	SectorSealRetryPolicy string
+
 	CollateralFromMinerBalance bool
 	AvailableBalanceBuffer     abi.TokenAmount
 	DisableCollateralFallback  bool
+	PledgeCollateralBuffer     abi.TokenAmount
 
 	MaxPreCommitBatch   int
 	PreCommitBatchWait  time.Duration
 	PreCommitBatchSlack time.Duration
 
+	PreCommitBatchAbortOnExpiry bool
+
+	P1StopFraction float64
+
+	MaxPreCommitsInFlight int
+
+	MaxPreCommitBatchByValue  bool
+	MaxPreCommitBatchFeeValue abi.TokenAmount
+
+	PreCommitGasMultiplier float64
+
+	CommitGasMultiplier float64
+
+	ReplicaUpdateGasMultiplier float64
+
 	AggregateCommits bool
 	MinCommitBatch   int
 	MaxCommitBatch   int
 	CommitBatchWait  time.Duration
 	CommitBatchSlack time.Duration
 
+	// CommitBatchOverlapWindow keeps due commit batches open for more sectors
+	// for this long; 0 = send batches when due
+	CommitBatchOverlapWindow time.Duration
+
 	AggregateAboveBaseFee      abi.TokenAmount
 	BatchPreCommitAboveBaseFee abi.TokenAmount
 
+	// AggregateMinBaseFeeDecayRatio holds back commit aggregates while the
+	// BaseFee falls below this fraction of its value CommitBatchWait / 2 ago;
+	// 0 = no check
+	AggregateMinBaseFeeDecayRatio float64
+
+	BatchPreCommitAboveBaseFeeDynamic bool
+
+	// SectorPreCommitBatchGasTarget is the fraction of BatchPreCommitAboveBaseFee
+	// below which pending precommits are sent early; 0 = no target
+	SectorPreCommitBatchGasTarget float64
+
 	MaxSectorProveCommitsSubmittedPerEpoch uint64
 
+	MaxConcurrentProveCommits int
+
 	TerminateBatchMax  uint64
 	TerminateBatchMin  uint64
 	TerminateBatchWait time.Duration
 
 	UseSyntheticPoRep bool
+
+	// TicketLookaheadEpochs lets new sectors reuse a ticket fetched up to this
+	// many epochs earlier; 0 = fetch a ticket for every sector
+	TicketLookaheadEpochs int
+
+	// FinalizeSectorTimeout cancels sector finalization taking longer than
+	// this; 0 = no timeout
+	FinalizeSectorTimeout time.Duration
+
+	// SectorBuildWatcher publishes sector state changes to the
+	// SectorWatcherTopicName pubsub topic; changes apply on restart
+	SectorBuildWatcher     bool
+	SectorWatcherTopicName string
 }
@@ -116,6 +116,9 @@ type Sealing struct {
 
 	available map[abi.SectorID]struct{}
 
+	collateralLk sync.Mutex
+	collateral   map[abi.SectorNumber]api.PreCommitCollateral // pre-commit deposits last computed per sector, for debugging
+
 	journal        journal.Journal
 	sealingEvtType journal.EventType
 	notifee        SectorStateNotifee
@@ -127,6 +130,9 @@ type Sealing struct {
 	precommiter *PreCommitBatcher
 	commiter    *CommitBatcher
 
+	inflightCommits *commitLimiter
+	tickets         ticketCache
+
 	sclk     sync.Mutex
 	legacySc *storedcounter.StoredCounter
 
@@ -138,10 +144,23 @@ type openSector struct {
 	lastDealEnd abi.ChainEpoch
 	number      abi.SectorNumber
 	ccUpdate    bool
+	clients     map[address.Address]struct{} // clients of the deals in the sector
 
 	maybeAccept func(cid.Cid) error // called with inputLk
 }
 
+// acceptsClient checks whether a deal from the client can be added without the sector
+// holding deals from more than maxClients distinct clients. 0 = no limit.
+func (o *openSector) acceptsClient(client address.Address, maxClients int) bool {
+	if maxClients <= 0 {
+		return true
+	}
+	if _, ok := o.clients[client]; ok {
+		return true
+	}
+	return len(o.clients) < maxClients
+}
+
 func (o *openSector) checkDealAssignable(piece *pendingPiece, expF expFn) (bool, error) {
 	log := log.With(
 		"sector", o.number,
@@ -246,6 +265,8 @@ func New(mctx context.Context, api SealingAPI, fc config.MinerFeeConfig, events
 		precommiter: NewPreCommitBatcher(mctx, maddr, api, addrSel, fc, gc),
 		commiter:    NewCommitBatcher(mctx, maddr, api, addrSel, fc, gc, prov),
 
+		inflightCommits: newCommitLimiter(),
+
 		getConfig: gc,
 
 		legacySc: storedcounter.New(ds, datastore.NewKey(StorageCounterDSPrefix)),
@@ -275,6 +296,18 @@ func New(mctx context.Context, api SealingAPI, fc config.MinerFeeConfig, events
 	return s
 }
 
+// AddNotifee registers an additional callback which is called on every sector
+// state transition. It must be called before the pipeline is started.
+func (m *Sealing) AddNotifee(n SectorStateNotifee) {
+	prev := m.notifee
+	m.notifee = func(before, after SectorInfo) {
+		if prev != nil {
+			prev(before, after)
+		}
+		n(before, after)
+	}
+}
+
 func (m *Sealing) Run(ctx context.Context) {
 	if err := m.restartSectors(ctx); err != nil {
 		log.Errorf("failed load sector states: %+v", err)
@@ -320,6 +353,31 @@ func (m *Sealing) SectorPreCommitPending(ctx context.Context) ([]abi.SectorID, e
 	return m.precommiter.Pending(ctx)
 }
 
+func (m *Sealing) SectorPreCommitCollateral(ctx context.Context, sid abi.SectorNumber) (api.PreCommitCollateral, error) {
+	m.collateralLk.Lock()
+	defer m.collateralLk.Unlock()
+
+	c, ok := m.collateral[sid]
+	if !ok {
+		return api.PreCommitCollateral{}, xerrors.Errorf("no pre-commit collateral computed for sector %d", sid)
+	}
+	return c, nil
+}
+
+func (m *Sealing) setPreCommitCollateral(sid abi.SectorNumber, c *api.PreCommitCollateral) {
+	m.collateralLk.Lock()
+	defer m.collateralLk.Unlock()
+
+	if c == nil {
+		delete(m.collateral, sid)
+		return
+	}
+	if m.collateral == nil {
+		m.collateral = map[abi.SectorNumber]api.PreCommitCollateral{}
+	}
+	m.collateral[sid] = *c
+}
+
 func (m *Sealing) CommitFlush(ctx context.Context) ([]sealiface.CommitBatchRes, error) {
 	return m.commiter.Flush(ctx)
 }
@@ -22,11 +22,66 @@ import (
 
 var MinRetryTime = 1 * time.Minute
 
+// MaxRetryTime caps the wait between automatic retries of failed sealing steps
+var MaxRetryTime = 1 * time.Hour
+
 func failedCooldown(ctx statemachine.Context, sector SectorInfo) error {
-	// TODO: Exponential backoff when we see consecutive failures
+	return cooldown(ctx, sector, MinRetryTime)
+}
+
+// sealFailedCooldown waits before retrying a failed sealing computation, as
+// configured by SectorSealRetryPolicy. failures is the number of consecutive
+// failures of the step. It returns false if the sector shouldn't be retried
+// automatically.
+func (m *Sealing) sealFailedCooldown(ctx statemachine.Context, sector SectorInfo, failures uint64) (bool, error) {
+	cfg, err := m.getConfig()
+	if err != nil {
+		return false, xerrors.Errorf("getting config: %w", err)
+	}
+
+	wait, retry := sealRetryWait(cfg.SectorSealRetryPolicy, failures)
+	if !retry {
+		log.Warnw("not retrying failed sector, SectorSealRetryPolicy is none", "sector", sector.SectorNumber, "state", sector.State)
+		return false, nil
+	}
 
-	retryStart := time.Unix(int64(sector.Log[len(sector.Log)-1].Timestamp), 0).Add(MinRetryTime)
-	if len(sector.Log) > 0 && !time.Now().After(retryStart) {
+	return true, cooldown(ctx, sector, wait)
+}
+
+func sealRetryWait(policy string, failures uint64) (time.Duration, bool) {
+	if failures < 1 {
+		failures = 1
+	}
+
+	var wait time.Duration
+	switch policy {
+	case "none":
+		return 0, false
+	case "linear":
+		wait = MaxRetryTime
+		if failures < uint64(MaxRetryTime/MinRetryTime) {
+			wait = MinRetryTime * time.Duration(failures)
+		}
+	default: // "exponential"
+		wait = MinRetryTime
+		for i := uint64(1); i < failures && wait < MaxRetryTime; i++ {
+			wait *= 2
+		}
+	}
+
+	if wait > MaxRetryTime {
+		wait = MaxRetryTime
+	}
+	return wait, true
+}
+
+func cooldown(ctx statemachine.Context, sector SectorInfo, wait time.Duration) error {
+	if len(sector.Log) == 0 {
+		return nil
+	}
+
+	retryStart := time.Unix(int64(sector.Log[len(sector.Log)-1].Timestamp), 0).Add(wait)
+	if !time.Now().After(retryStart) {
 		log.Infof("%s(%d), waiting %s before retrying", sector.State, sector.SectorNumber, time.Until(retryStart))
 		select {
 		case <-time.After(time.Until(retryStart)):
@@ -61,7 +116,7 @@ func (m *Sealing) handleSealPrecommit1Failed(ctx statemachine.Context, sector Se
 		return ctx.Send(SectorRemove{})
 	}
 
-	if err := failedCooldown(ctx, sector); err != nil {
+	if retry, err := m.sealFailedCooldown(ctx, sector, sector.PreCommit1Fails); err != nil || !retry {
 		return err
 	}
 
@@ -69,7 +124,7 @@ func (m *Sealing) handleSealPrecommit1Failed(ctx statemachine.Context, sector Se
 }
 
 func (m *Sealing) handleSealPrecommit2Failed(ctx statemachine.Context, sector SectorInfo) error {
-	if err := failedCooldown(ctx, sector); err != nil {
+	if retry, err := m.sealFailedCooldown(ctx, sector, sector.PreCommit2Fails); err != nil || !retry {
 		return err
 	}
 
@@ -180,7 +235,7 @@ func (m *Sealing) handlePreCommitFailed(ctx statemachine.Context, sector SectorI
 func (m *Sealing) handleComputeProofFailed(ctx statemachine.Context, sector SectorInfo) error {
 	// TODO: Check sector files
 
-	if err := failedCooldown(ctx, sector); err != nil {
+	if retry, err := m.sealFailedCooldown(ctx, sector, sector.InvalidProofs+1); err != nil || !retry {
 		return err
 	}
 
@@ -192,7 +247,7 @@ func (m *Sealing) handleComputeProofFailed(ctx statemachine.Context, sector Sect
 }
 
 func (m *Sealing) handleRemoteCommitFailed(ctx statemachine.Context, sector SectorInfo) error {
-	if err := failedCooldown(ctx, sector); err != nil {
+	if retry, err := m.sealFailedCooldown(ctx, sector, sector.InvalidProofs+1); err != nil || !retry {
 		return err
 	}
 
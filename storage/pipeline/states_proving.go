@@ -149,6 +149,8 @@ func (m *Sealing) handleProvingSector(ctx statemachine.Context, sector SectorInf
 	// into the Proving state, breaking the deal input pipeline in the process.
 	m.cleanupAssignedDeals(sector)
 
+	m.setPreCommitCollateral(sector.SectorNumber, nil)
+
 	// TODO: Watch termination
 	// TODO: Auto-extend if set
 
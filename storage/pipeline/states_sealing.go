@@ -129,6 +129,23 @@ func checkTicketExpired(ticket, head abi.ChainEpoch) bool {
 	return head-ticket > MaxTicketAge // TODO: allow configuring expected seal durations
 }
 
+// checkTicketTooOld returns whether PC1 shouldn't be started with a ticket,
+// because more than stopFraction of the time the ticket can be pre-committed
+// with, minus the pre-commit batch slack, has already passed. A stopFraction
+// of 0 never stops PC1.
+func checkTicketTooOld(ticket, head abi.ChainEpoch, stopFraction float64, slack time.Duration) bool {
+	if stopFraction <= 0 {
+		return false
+	}
+
+	window := MaxTicketAge - abi.ChainEpoch(slack/(time.Duration(build.BlockDelaySecs)*time.Second))
+	if window <= 0 {
+		return false
+	}
+
+	return float64(head-ticket) > stopFraction*float64(window)
+}
+
 func checkProveCommitExpired(preCommitEpoch, msd abi.ChainEpoch, currEpoch abi.ChainEpoch) bool {
 	return currEpoch > preCommitEpoch+msd
 }
@@ -185,11 +202,26 @@ func (m *Sealing) getTicket(ctx statemachine.Context, sector SectorInfo) (abi.Se
 		return nil, 0, allocated, xerrors.Errorf("sector %s precommitted but expired", sector.SectorNumber)
 	}
 
+	if pci == nil {
+		cfg, err := m.getConfig()
+		if err != nil {
+			return nil, 0, allocated, xerrors.Errorf("getting sealing config: %w", err)
+		}
+
+		if rand, epoch, ok := m.tickets.get(ticketEpoch, abi.ChainEpoch(cfg.TicketLookaheadEpochs)); ok {
+			return rand, epoch, allocated, nil
+		}
+	}
+
 	rand, err := m.Api.StateGetRandomnessFromTickets(ctx.Context(), crypto.DomainSeparationTag_SealRandomness, ticketEpoch, buf.Bytes(), ts.Key())
 	if err != nil {
 		return nil, 0, allocated, err
 	}
 
+	if pci == nil {
+		m.tickets.put(ticketEpoch, abi.SealRandomness(rand))
+	}
+
 	return abi.SealRandomness(rand), ticketEpoch, allocated, nil
 }
 
@@ -305,6 +337,24 @@ func (m *Sealing) handlePreCommit1(ctx statemachine.Context, sector SectorInfo)
 		if checkProveCommitExpired(pci.PreCommitEpoch, msd, ts.Height()) {
 			return ctx.Send(SectorOldTicket{}) // will be removed
 		}
+	} else {
+		cfg, err := m.getConfig()
+		if err != nil {
+			return xerrors.Errorf("getting config: %w", err)
+		}
+
+		if checkTicketTooOld(sector.TicketEpoch, ts.Height(), cfg.P1StopFraction, cfg.PreCommitBatchSlack) {
+			pci, err := m.Api.StateSectorPreCommitInfo(ctx.Context(), m.maddr, sector.SectorNumber, ts.Key())
+			if err != nil {
+				log.Errorf("handlePreCommit1: StateSectorPreCommitInfo: api error, not proceeding: %+v", err)
+				return nil
+			}
+
+			if pci == nil {
+				log.Warnw("ticket too close to expiry to start PC1, getting a new ticket", "sector", sector.SectorNumber, "ticketEpoch", sector.TicketEpoch, "head", ts.Height())
+				return ctx.Send(SectorOldTicket{})
+			}
+		}
 	}
 
 	var pc1o storiface.PreCommit1Out
@@ -429,6 +479,25 @@ func (m *Sealing) preCommitInfo(ctx statemachine.Context, sector SectorInfo) (*m
 		return nil, big.Zero(), types.EmptyTSK, xerrors.Errorf("getting initial pledge collateral: %w", err)
 	}
 
+	cfg, err := m.getConfig()
+	if err != nil {
+		return nil, big.Zero(), types.EmptyTSK, xerrors.Errorf("getting config: %w", err)
+	}
+
+	buffer := big.Zero()
+	if !cfg.PledgeCollateralBuffer.NilOrZero() {
+		buffer = cfg.PledgeCollateralBuffer
+	}
+	estimated := collateral
+	collateral = big.Add(collateral, buffer)
+	log.Debugw("pre-commit collateral", "sector", sector.SectorNumber, "estimated", types.FIL(estimated), "effective", types.FIL(collateral))
+
+	m.setPreCommitCollateral(sector.SectorNumber, &api.PreCommitCollateral{
+		Estimated: estimated,
+		Buffer:    buffer,
+		Effective: collateral,
+	})
+
 	return params, collateral, ts.Key(), nil
 }
 
@@ -453,6 +522,10 @@ func (m *Sealing) handleSubmitPreCommitBatch(ctx statemachine.Context, sector Se
 	}
 
 	res, err := m.precommiter.AddPreCommit(ctx.Context(), sector, deposit, params)
+	if errors.Is(err, ErrPreCommitBatchAborted) {
+		log.Warnw("sector removed from pre-commit batch close to expiry, restarting with a new ticket", "sector", sector.SectorNumber)
+		return ctx.Send(SectorOldTicket{})
+	}
 	if err != nil {
 		return ctx.Send(SectorChainPreCommitFailed{xerrors.Errorf("queuing precommit batch failed: %w", err)})
 	}
@@ -476,6 +549,7 @@ func (m *Sealing) handlePreCommitWait(ctx statemachine.Context, sector SectorInf
 	// would be ideal to just use the events.Called handler, but it wouldn't be able to handle individual message timeouts
 	log.Info("Sector precommitted: ", sector.SectorNumber)
 	mw, err := m.Api.StateWaitMsg(ctx.Context(), *sector.PreCommitMessage, build.MessageConfidence, api.LookbackNoLimit, true)
+	m.precommiter.messageLanded(*sector.PreCommitMessage)
 	if err != nil {
 		return ctx.Send(SectorChainPreCommitFailed{err})
 	}
@@ -772,9 +846,21 @@ func (m *Sealing) handleSubmitCommit(ctx statemachine.Context, sector SectorInfo
 		return ctx.Send(SectorCommitFailed{xerrors.Errorf("no good address to send commit message from: %w", err)})
 	}
 
+	// released in handleCommitWait once the message lands
+	if err := m.inflightCommits.acquire(ctx.Context(), sector.SectorNumber, cfg.MaxConcurrentProveCommits); err != nil {
+		return xerrors.Errorf("waiting for in-flight prove commits: %w", err)
+	}
+
+	gasLimit, err := estimateGasLimit(ctx.Context(), m.Api, from, m.maddr, builtin.MethodsMiner.ProveCommitSector, collateral, big.Int(m.feeCfg.MaxCommitGasFee), enc.Bytes(), cfg.CommitGasMultiplier)
+	if err != nil {
+		m.inflightCommits.release(sector.SectorNumber)
+		return ctx.Send(SectorCommitFailed{xerrors.Errorf("estimating commit message gas: %w", err)})
+	}
+
 	// TODO: check seed / ticket / deals are up to date
-	mcid, err := sendMsg(ctx.Context(), m.Api, from, m.maddr, builtin.MethodsMiner.ProveCommitSector, collateral, big.Int(m.feeCfg.MaxCommitGasFee), enc.Bytes())
+	mcid, err := sendMsgWithGasLimit(ctx.Context(), m.Api, from, m.maddr, builtin.MethodsMiner.ProveCommitSector, collateral, big.Int(m.feeCfg.MaxCommitGasFee), gasLimit, enc.Bytes())
 	if err != nil {
+		m.inflightCommits.release(sector.SectorNumber)
 		return ctx.Send(SectorCommitFailed{xerrors.Errorf("pushing message to mpool: %w", err)})
 	}
 
@@ -832,6 +918,7 @@ func (m *Sealing) handleCommitWait(ctx statemachine.Context, sector SectorInfo)
 	}
 
 	mw, err := m.Api.StateWaitMsg(ctx.Context(), *sector.CommitMessage, build.MessageConfidence, api.LookbackNoLimit, true)
+	m.inflightCommits.release(sector.SectorNumber)
 	if err != nil {
 		return ctx.Send(SectorCommitFailed{xerrors.Errorf("failed to wait for porep inclusion: %w", err)})
 	}
@@ -867,11 +954,22 @@ func (m *Sealing) handleFinalizeSector(ctx statemachine.Context, sector SectorIn
 		return xerrors.Errorf("getting sealing config: %w", err)
 	}
 
-	if err := m.sealer.ReleaseUnsealed(ctx.Context(), m.minerSector(sector.SectorType, sector.SectorNumber), sector.keepUnsealedRanges(sector.Pieces, false, cfg.AlwaysKeepUnsealedCopy)); err != nil {
+	if err := m.sealer.ReleaseUnsealed(ctx.Context(), m.minerSector(sector.SectorType, sector.SectorNumber), sector.keepUnsealedRanges(sector.Pieces, false, cfg.AlwaysKeepUnsealedCopy && !cfg.DealLevelFastRetrieval)); err != nil {
 		return ctx.Send(SectorFinalizeFailed{xerrors.Errorf("release unsealed: %w", err)})
 	}
 
-	if err := m.sealer.FinalizeSector(sector.sealingCtx(ctx.Context()), m.minerSector(sector.SectorType, sector.SectorNumber)); err != nil {
+	fctx := sector.sealingCtx(ctx.Context())
+	if cfg.FinalizeSectorTimeout > 0 {
+		var cancel context.CancelFunc
+		fctx, cancel = context.WithTimeout(fctx, cfg.FinalizeSectorTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	if err := m.sealer.FinalizeSector(fctx, m.minerSector(sector.SectorType, sector.SectorNumber)); err != nil {
+		if errors.Is(fctx.Err(), context.DeadlineExceeded) {
+			log.Errorw("sector finalization timed out", "sector", sector.SectorNumber, "elapsed", time.Since(start), "timeout", cfg.FinalizeSectorTimeout)
+		}
 		return ctx.Send(SectorFinalizeFailed{xerrors.Errorf("finalize sector: %w", err)})
 	}
 
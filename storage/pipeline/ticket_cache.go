@@ -0,0 +1,46 @@
+package sealing
+
+import (
+	"sync"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// ticketCache keeps the most recently fetched sealing ticket so that sectors
+// getting a ticket shortly after it can reuse it instead of querying the chain,
+// when TicketLookaheadEpochs is set.
+type ticketCache struct {
+	lk    sync.Mutex
+	epoch abi.ChainEpoch
+	rand  abi.SealRandomness
+}
+
+// get returns the cached ticket if it's no more than lookahead epochs older than
+// the ticket epoch the sector would get otherwise. A lookahead of 0 disables the cache.
+func (c *ticketCache) get(ticketEpoch, lookahead abi.ChainEpoch) (abi.SealRandomness, abi.ChainEpoch, bool) {
+	if lookahead <= 0 {
+		return nil, 0, false
+	}
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	if c.rand == nil || c.epoch > ticketEpoch || ticketEpoch-c.epoch > lookahead {
+		return nil, 0, false
+	}
+
+	return c.rand, c.epoch, true
+}
+
+// put caches a freshly fetched ticket, replacing older ones.
+func (c *ticketCache) put(ticketEpoch abi.ChainEpoch, rand abi.SealRandomness) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	if c.rand != nil && c.epoch > ticketEpoch {
+		return
+	}
+
+	c.epoch = ticketEpoch
+	c.rand = rand
+}
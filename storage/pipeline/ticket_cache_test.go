@@ -0,0 +1,47 @@
+package sealing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+func TestTicketCache(t *testing.T) {
+	var c ticketCache
+
+	_, _, ok := c.get(100, 10)
+	require.False(t, ok)
+
+	c.put(100, abi.SealRandomness{1})
+
+	// disabled without a lookahead
+	_, _, ok = c.get(100, 0)
+	require.False(t, ok)
+
+	rand, epoch, ok := c.get(110, 10)
+	require.True(t, ok)
+	require.Equal(t, abi.ChainEpoch(100), epoch)
+	require.Equal(t, abi.SealRandomness{1}, rand)
+
+	// too old for the lookahead window
+	_, _, ok = c.get(111, 10)
+	require.False(t, ok)
+
+	// newer than the ticket epoch, e.g. after a reorg
+	_, _, ok = c.get(99, 10)
+	require.False(t, ok)
+
+	// older tickets don't replace newer ones
+	c.put(90, abi.SealRandomness{2})
+	_, epoch, ok = c.get(110, 10)
+	require.True(t, ok)
+	require.Equal(t, abi.ChainEpoch(100), epoch)
+
+	c.put(111, abi.SealRandomness{3})
+	rand, epoch, ok = c.get(111, 10)
+	require.True(t, ok)
+	require.Equal(t, abi.ChainEpoch(111), epoch)
+	require.Equal(t, abi.SealRandomness{3}, rand)
+}
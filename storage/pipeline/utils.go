@@ -12,6 +12,7 @@ import (
 	"github.com/filecoin-project/go-state-types/big"
 
 	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
 )
@@ -111,12 +112,21 @@ func simulateMsgGas(ctx context.Context, sa interface {
 func sendMsg(ctx context.Context, sa interface {
 	MpoolPushMessage(context.Context, *types.Message, *api.MessageSendSpec) (*types.SignedMessage, error)
 }, from, to address.Address, method abi.MethodNum, value, maxFee abi.TokenAmount, params []byte) (cid.Cid, error) {
+	return sendMsgWithGasLimit(ctx, sa, from, to, method, value, maxFee, 0, params)
+}
+
+// sendMsgWithGasLimit is like sendMsg, but sends the message with the given gas
+// limit instead of letting the mpool estimate it. A gasLimit of 0 means estimate.
+func sendMsgWithGasLimit(ctx context.Context, sa interface {
+	MpoolPushMessage(context.Context, *types.Message, *api.MessageSendSpec) (*types.SignedMessage, error)
+}, from, to address.Address, method abi.MethodNum, value, maxFee abi.TokenAmount, gasLimit int64, params []byte) (cid.Cid, error) {
 	msg := types.Message{
-		To:     to,
-		From:   from,
-		Value:  value,
-		Method: method,
-		Params: params,
+		To:       to,
+		From:     from,
+		Value:    value,
+		Method:   method,
+		Params:   params,
+		GasLimit: gasLimit,
 	}
 
 	smsg, err := sa.MpoolPushMessage(ctx, &msg, &api.MessageSendSpec{MaxFee: maxFee})
@@ -126,3 +136,58 @@ func sendMsg(ctx context.Context, sa interface {
 
 	return smsg.Cid(), nil
 }
+
+// multipliedGasLimit applies the configured multiplier to an estimated gas
+// limit, capped at the block gas limit. It returns 0, leaving estimation to the
+// mpool, when the multiplier doesn't increase the limit.
+func multipliedGasLimit(estimated int64, multiplier float64) int64 {
+	if multiplier <= 1 {
+		return 0
+	}
+
+	gasLimit := int64(float64(estimated) * multiplier)
+	if gasLimit > build.BlockGasLimit {
+		gasLimit = build.BlockGasLimit
+	}
+	return gasLimit
+}
+
+// estimateGasLimit simulates a message and applies the multiplier to its
+// estimated gas limit, see multipliedGasLimit. The message isn't simulated when
+// the multiplier doesn't increase the limit.
+func estimateGasLimit(ctx context.Context, sa interface {
+	GasEstimateMessageGas(context.Context, *types.Message, *api.MessageSendSpec, types.TipSetKey) (*types.Message, error)
+}, from, to address.Address, method abi.MethodNum, value, maxFee abi.TokenAmount, params []byte, multiplier float64) (int64, error) {
+	if multiplier <= 1 {
+		return 0, nil
+	}
+
+	estMsg, err := simulateMsgGas(ctx, sa, from, to, method, value, maxFee, params)
+	if err != nil {
+		return 0, err
+	}
+	return multipliedGasLimit(estMsg.GasLimit, multiplier), nil
+}
+
+// preCommitBaseFeeTarget returns the basefee below which pending precommits are
+// sent out without waiting for the batch: the given fraction of the batching
+// threshold, or the threshold itself when no fraction is set.
+func preCommitBaseFeeTarget(threshold abi.TokenAmount, fraction float64) abi.TokenAmount {
+	if fraction <= 0 || fraction >= 1 {
+		return threshold
+	}
+
+	const precision = 1_000_000
+	return big.Div(big.Mul(threshold, big.NewInt(int64(fraction*precision))), big.NewInt(precision))
+}
+
+// baseFeeDecayed reports whether the basefee cur dropped below the given fraction
+// of the earlier basefee ref. A fraction of 0 never reports a decay.
+func baseFeeDecayed(ref, cur abi.TokenAmount, fraction float64) bool {
+	if fraction <= 0 || ref.NilOrZero() {
+		return false
+	}
+
+	const precision = 1_000_000
+	return cur.LessThan(big.Div(big.Mul(ref, big.NewInt(int64(fraction*precision))), big.NewInt(precision)))
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/build"
 )
 
 func testFill(t *testing.T, n abi.UnpaddedPieceSize, exp []abi.UnpaddedPieceSize) {
@@ -43,3 +45,30 @@ func TestFillersFromRem(t *testing.T) {
 		testFill(t, ub, []abi.UnpaddedPieceSize{ub1, ub4})
 	}
 }
+
+func TestMultipliedGasLimit(t *testing.T) {
+	assert.Equal(t, int64(0), multipliedGasLimit(100_000, 0))
+	assert.Equal(t, int64(0), multipliedGasLimit(100_000, 1))
+	assert.Equal(t, int64(105_000), multipliedGasLimit(100_000, 1.05))
+	assert.Equal(t, int64(200_000), multipliedGasLimit(100_000, 2))
+	assert.Equal(t, build.BlockGasLimit, multipliedGasLimit(build.BlockGasLimit, 1.5))
+}
+
+func TestPreCommitBaseFeeTarget(t *testing.T) {
+	threshold := abi.NewTokenAmount(1_000_000)
+
+	assert.Equal(t, threshold, preCommitBaseFeeTarget(threshold, 0))
+	assert.Equal(t, threshold, preCommitBaseFeeTarget(threshold, 1))
+	assert.Equal(t, abi.NewTokenAmount(500_000), preCommitBaseFeeTarget(threshold, 0.5))
+	assert.Equal(t, abi.NewTokenAmount(125_000), preCommitBaseFeeTarget(threshold, 0.125))
+}
+
+func TestBaseFeeDecayed(t *testing.T) {
+	ref := abi.NewTokenAmount(1_000_000)
+
+	assert.False(t, baseFeeDecayed(ref, abi.NewTokenAmount(1), 0))
+	assert.False(t, baseFeeDecayed(ref, abi.NewTokenAmount(900_000), 0.9))
+	assert.True(t, baseFeeDecayed(ref, abi.NewTokenAmount(899_999), 0.9))
+	assert.False(t, baseFeeDecayed(ref, abi.NewTokenAmount(1_200_000), 1))
+	assert.True(t, baseFeeDecayed(ref, abi.NewTokenAmount(999_999), 1))
+}
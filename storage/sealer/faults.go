@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"runtime"
 	"sync"
 
 	"golang.org/x/xerrors"
@@ -11,6 +12,7 @@ import (
 	ffi "github.com/filecoin-project/filecoin-ffi"
 	"github.com/filecoin-project/go-state-types/abi"
 
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 )
 
@@ -36,6 +38,9 @@ func (m *Manager) CheckProvable(ctx context.Context, pp abi.RegisteredPoStProof,
 	postRand[31] &= 0x3f
 
 	limit := m.parallelCheckLimit
+	if m.adaptiveCheckLimit {
+		limit = config.RecommendedParallelCheckLimit(len(sectors), runtime.NumCPU())
+	}
 	if limit <= 0 {
 		limit = len(sectors)
 	}
@@ -9,6 +9,14 @@ var log = logging.Logger("ffiwrapper")
 type Sealer struct {
 	sectors  SectorProvider
 	stopping chan struct{}
+
+	writeVerify bool
+}
+
+// EnableWriteVerify makes AddPiece read back and verify the sector data it writes,
+// see partialfile.VerifyingWriter.
+func (sb *Sealer) EnableWriteVerify() {
+	sb.writeVerify = true
 }
 
 func (sb *Sealer) Stop() {
@@ -259,7 +259,12 @@ func (sb *Sealer) AddPiece(ctx context.Context, sector storiface.SectorRef, exis
 		}
 	}
 
-	w, err := stagedFile.Writer(storiface.UnpaddedByteIndex(offset).Padded(), pieceSize.Padded())
+	newWriter := stagedFile.Writer
+	if sb.writeVerify {
+		newWriter = stagedFile.VerifyingWriter
+	}
+
+	w, err := newWriter(storiface.UnpaddedByteIndex(offset).Padded(), pieceSize.Padded())
 	if err != nil {
 		return abi.PieceInfo{}, xerrors.Errorf("getting partial file writer: %w", err)
 	}
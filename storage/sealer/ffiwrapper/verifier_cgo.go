@@ -5,7 +5,9 @@ package ffiwrapper
 
 import (
 	"context"
+	"time"
 
+	"go.opencensus.io/stats"
 	"go.opencensus.io/trace"
 	"golang.org/x/xerrors"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/specs-actors/v7/actors/runtime/proof"
 
+	"github.com/filecoin-project/lotus/metrics"
 	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 )
 
@@ -136,15 +139,25 @@ type proofVerifier struct{}
 
 var ProofVerifier = proofVerifier{}
 
+func recordVerify(ctx context.Context, start time.Time) {
+	stats.Record(ctx, metrics.ProofVerifyDuration.M(metrics.SinceInMilliseconds(start)))
+}
+
 func (proofVerifier) VerifySeal(info proof.SealVerifyInfo) (bool, error) {
+	defer recordVerify(context.TODO(), time.Now())
+
 	return ffi.VerifySeal(info)
 }
 
 func (proofVerifier) VerifyAggregateSeals(aggregate proof.AggregateSealVerifyProofAndInfos) (bool, error) {
+	defer recordVerify(context.TODO(), time.Now())
+
 	return ffi.VerifyAggregateSeals(aggregate)
 }
 
 func (proofVerifier) VerifyReplicaUpdate(update proof.ReplicaUpdateInfo) (bool, error) {
+	defer recordVerify(context.TODO(), time.Now())
+
 	return ffi.SectorUpdate.VerifyUpdateProof(update)
 }
 
@@ -152,6 +165,7 @@ func (proofVerifier) VerifyWinningPoSt(ctx context.Context, info proof.WinningPo
 	info.Randomness[31] &= 0x3f
 	_, span := trace.StartSpan(ctx, "VerifyWinningPoSt")
 	defer span.End()
+	defer recordVerify(ctx, time.Now())
 
 	return ffi.VerifyWinningPoSt(info)
 }
@@ -160,6 +174,7 @@ func (proofVerifier) VerifyWindowPoSt(ctx context.Context, info proof.WindowPoSt
 	info.Randomness[31] &= 0x3f
 	_, span := trace.StartSpan(ctx, "VerifyWindowPoSt")
 	defer span.End()
+	defer recordVerify(ctx, time.Now())
 
 	return ffi.VerifyWindowPoSt(info)
 }
@@ -3,8 +3,10 @@ package sealer
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"sort"
 	"sync"
 	"time"
@@ -68,16 +70,27 @@ type Manager struct {
 	winningPoStSched *poStScheduler
 
 	localProver storiface.ProverPoSt
+	// remotePoSt is set when window PoSt is computed by a remote proving service
+	remotePoSt externalPoStProver
+	// fallbackPoSt is set when partitions which fail on PoSt workers are
+	// retried with an external command
+	fallbackPoSt externalPoStProver
 
 	workLk sync.Mutex
 	work   *statestore.StateStore
 
 	parallelCheckLimit        int
+	adaptiveCheckLimit        bool
 	singleCheckTimeout        time.Duration
 	partitionCheckTimeout     time.Duration
 	disableBuiltinWindowPoSt  bool
 	disableBuiltinWinningPoSt bool
 	disallowRemoteFinalize    bool
+	highPriorityWindowPoSt    bool
+	maxOpenSectorsPerWorker   int
+
+	// set once the local worker is added
+	localWorkerID storiface.WorkerID
 
 	callToWork map[storiface.CallID]WorkID
 	// used when we get an early return and there's no callToWork mapping
@@ -109,6 +122,17 @@ func New(ctx context.Context, lstor *paths.Local, stor paths.Store, ls paths.Loc
 	if err != nil {
 		return nil, err
 	}
+	sh.maxPending = int64(sc.MaxPendingStorageRequests)
+	if sc.TaskSheddingPolicy != "" {
+		sh.shedPolicy = sc.TaskSheddingPolicy
+	}
+
+	switch sc.WorkerSelectionHeuristic {
+	case config.WorkerSelectionLocality:
+		sh.assigner = NewLocalityAssigner(si)
+	case config.WorkerSelectionCapacity:
+		sh.assigner = NewCapacityAssigner()
+	}
 
 	m := &Manager{
 		ls:         ls,
@@ -124,11 +148,14 @@ func New(ctx context.Context, lstor *paths.Local, stor paths.Store, ls paths.Loc
 		localProver: prover,
 
 		parallelCheckLimit:        pc.ParallelCheckLimit,
+		adaptiveCheckLimit:        pc.AdaptiveParallelCheckLimit,
 		singleCheckTimeout:        time.Duration(pc.SingleCheckTimeout),
 		partitionCheckTimeout:     time.Duration(pc.PartitionCheckTimeout),
 		disableBuiltinWindowPoSt:  pc.DisableBuiltinWindowPoSt,
 		disableBuiltinWinningPoSt: pc.DisableBuiltinWinningPoSt,
 		disallowRemoteFinalize:    sc.DisallowRemoteFinalize,
+		maxOpenSectorsPerWorker:   sc.MaxOpenSectorsPerWorker,
+		highPriorityWindowPoSt:    pc.HighPriorityWindowPoSt,
 
 		work:       mss,
 		callToWork: map[storiface.CallID]WorkID{},
@@ -172,20 +199,63 @@ func New(ctx context.Context, lstor *paths.Local, stor paths.Store, ls paths.Loc
 		localTasks = append(localTasks, sealtasks.TTRegenSectorKey)
 	}
 
+	localName := sc.LocalWorkerName
+	if localName == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, xerrors.Errorf("getting hostname for local worker name: %w", err)
+		}
+		localName = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	filtering := sc.ResourceFiltering
+	if filtering == config.ResourceFilteringSchedule {
+		filtering = config.ActiveResourceFilteringStrategy(sc.ResourceFilteringSchedule, time.Now())
+	}
+
 	wcfg := WorkerConfig{
-		IgnoreResourceFiltering: sc.ResourceFiltering == config.ResourceFilteringDisabled,
+		IgnoreResourceFiltering: filtering == config.ResourceFilteringDisabled,
 		TaskTypes:               localTasks,
-		Name:                    sc.LocalWorkerName,
+		Name:                    localName,
+		WriteVerify:             sc.StorageWriteVerify,
 	}
 	worker := NewLocalWorker(wcfg, stor, lstor, si, m, wss)
 	err = m.AddWorker(ctx, worker)
 	if err != nil {
 		return nil, xerrors.Errorf("adding local worker: %w", err)
 	}
+	m.localWorkerID = storiface.WorkerID(worker.session)
+
+	if sc.ResourceFiltering == config.ResourceFilteringSchedule {
+		go m.runResourceFilteringSchedule(m.localWorkerID, sc.ResourceFilteringSchedule)
+	}
 
 	return m, nil
 }
 
+// resourceFilteringScheduleInterval is how often the resource filtering
+// schedule of the local worker is re-evaluated.
+const resourceFilteringScheduleInterval = time.Minute
+
+// runResourceFilteringSchedule keeps the resource filtering strategy of the
+// local worker in line with the configured schedule.
+func (m *Manager) runResourceFilteringSchedule(wid storiface.WorkerID, schedule []config.ResourceFilteringWindow) {
+	ticker := time.NewTicker(resourceFilteringScheduleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			strategy := config.ActiveResourceFilteringStrategy(schedule, now)
+			if m.sched.setIgnoreResources(wid, strategy == config.ResourceFilteringDisabled) {
+				log.Infow("local worker resource filtering changed", "strategy", strategy)
+			}
+		case <-m.sched.closing:
+			return
+		}
+	}
+}
+
 func (m *Manager) AddLocalStorage(ctx context.Context, path string) error {
 	path, err := homedir.Expand(path)
 	if err != nil {
@@ -256,6 +326,13 @@ func (m *Manager) RedeclareLocalStorage(ctx context.Context, id *storiface.ID, d
 	return m.localStore.Redeclare(ctx, id, dropMissing)
 }
 
+// PauseLocalGPUTasks keeps new tasks needing a GPU from starting on the local
+// worker until resume is called. Tasks already running aren't affected.
+func (m *Manager) PauseLocalGPUTasks() (resume func()) {
+	// the reservation isn't for a real task, so it doesn't count against task limits
+	return m.sched.reserveGPUs(m.localWorkerID, sealtasks.SealTaskType{})
+}
+
 func (m *Manager) AddWorker(ctx context.Context, w Worker) error {
 	sessID, err := w.Session(ctx)
 	if err != nil {
@@ -432,6 +509,9 @@ func (m *Manager) AddPiece(ctx context.Context, sector storiface.SectorRef, exis
 	var err error
 	if len(existingPieces) == 0 { // new
 		selector = newAllocSelector(m.index, storiface.FTUnsealed, storiface.PathSealing)
+		if m.maxOpenSectorsPerWorker > 0 {
+			selector = newOpenSectorsSelector(selector, m.index, m.maxOpenSectorsPerWorker)
+		}
 	} else { // use existing
 		selector = newExistingSelector(m.index, sector.ID, storiface.FTUnsealed, false)
 	}
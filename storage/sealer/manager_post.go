@@ -14,6 +14,7 @@ import (
 	"github.com/filecoin-project/go-state-types/builtin"
 	"github.com/filecoin-project/specs-actors/v7/actors/runtime/proof"
 
+	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
 	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 )
 
@@ -79,12 +80,24 @@ func (m *Manager) generateWinningPoSt(ctx context.Context, minerID abi.ActorID,
 }
 
 func (m *Manager) GenerateWindowPoSt(ctx context.Context, minerID abi.ActorID, postProofType abi.RegisteredPoStProof, sectorInfo []proof.ExtendedSectorInfo, randomness abi.PoStRandomness) (proof []proof.PoStProof, skipped []abi.SectorID, err error) {
-	if !m.disableBuiltinWindowPoSt && !m.windowPoStSched.CanSched(ctx) {
+	if m.remotePoSt == nil && !m.disableBuiltinWindowPoSt && !m.windowPoStSched.CanSched(ctx) {
 		// if builtin PoSt isn't disabled, and there are no workers, compute the PoSt locally
 
 		log.Info("GenerateWindowPoSt run at lotus-miner")
+
+		if m.highPriorityWindowPoSt && len(sectorInfo) > 0 {
+			// keep sealing tasks of the local worker off the GPU while the proof is computed
+			release := m.sched.reserveGPUs(m.localWorkerID, sealtasks.TTGenerateWindowPoSt.SealTask(sectorInfo[0].SealProof))
+			defer release()
+		}
+
 		p, s, err := m.localProver.GenerateWindowPoSt(ctx, minerID, postProofType, sectorInfo, randomness)
 		if err != nil {
+			if m.fallbackPoSt != nil {
+				// without PoSt workers, the partitions are all computed by the fallback prover
+				log.Errorw("local prover failed, using fallback prover", "error", err)
+				return m.generateWindowPoSt(ctx, minerID, postProofType, sectorInfo, randomness)
+			}
 			return p, s, xerrors.Errorf("local prover: %w", err)
 		}
 
@@ -221,15 +234,31 @@ func (m *Manager) generatePartitionWindowPost(ctx context.Context, spt abi.Regis
 	start := time.Now()
 
 	var result storiface.WindowPoStResult
-	err := m.windowPoStSched.Schedule(ctx, true, spt, func(ctx context.Context, w Worker) error {
-		out, err := w.GenerateWindowPoSt(ctx, ppt, minerID, sc, partIndex, randomness)
+	var err error
+	if m.remotePoSt != nil {
+		result, err = generateExternalWindowPoSt(ctx, m.storage, m.remotePoSt, ppt, minerID, sc, partIndex, randomness)
 		if err != nil {
-			return xerrors.Errorf("post worker: %w", err)
+			err = xerrors.Errorf("remote prover: %w", err)
 		}
+	} else {
+		err = m.windowPoStSched.Schedule(ctx, true, spt, func(ctx context.Context, w Worker) error {
+			out, err := w.GenerateWindowPoSt(ctx, ppt, minerID, sc, partIndex, randomness)
+			if err != nil {
+				return xerrors.Errorf("post worker: %w", err)
+			}
 
-		result = out
-		return nil
-	})
+			result = out
+			return nil
+		})
+
+		if err != nil && m.fallbackPoSt != nil {
+			log.Errorw("window PoSt partition failed, using fallback prover", "index", partIndex, "error", err)
+			result, err = generateExternalWindowPoSt(ctx, m.storage, m.fallbackPoSt, ppt, minerID, sc, partIndex, randomness)
+			if err != nil {
+				err = xerrors.Errorf("fallback prover: %w", err)
+			}
+		}
+	}
 
 	log.Warnw("generateWindowPost done", "index", partIndex, "skipped", len(result.Skipped), "took", time.Since(start).String(), "err", err)
 
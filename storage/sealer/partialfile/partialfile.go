@@ -1,6 +1,8 @@
 package partialfile
 
 import (
+	"context"
+	"crypto/sha256"
 	"encoding/binary"
 	"io"
 	"os"
@@ -8,12 +10,14 @@ import (
 
 	"github.com/detailyang/go-fallocate"
 	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/stats"
 	"golang.org/x/xerrors"
 
 	rlepluslazy "github.com/filecoin-project/go-bitfield/rle"
 	"github.com/filecoin-project/go-state-types/abi"
 
 	"github.com/filecoin-project/lotus/lib/readerutil"
+	"github.com/filecoin-project/lotus/metrics"
 	"github.com/filecoin-project/lotus/storage/sealer/fsutil"
 	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 )
@@ -210,6 +214,54 @@ func (pf *PartialFile) Writer(offset storiface.PaddedByteIndex, size abi.PaddedP
 	return pf.file, nil
 }
 
+// ErrVerificationFailed is returned by writers from VerifyingWriter when the data read back after
+// a write doesn't match the written data.
+var ErrVerificationFailed = xerrors.New("sector write verification failed")
+
+// VerifyingWriter is like Writer, but every chunk is read back from the file and hashed right after
+// it is written. Writes whose read-back hash doesn't match the hash of the written data fail with
+// ErrVerificationFailed.
+func (pf *PartialFile) VerifyingWriter(offset storiface.PaddedByteIndex, size abi.PaddedPieceSize) (io.Writer, error) {
+	w, err := pf.Writer(offset, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &verifyingWriter{w: w, file: pf.file, off: int64(offset)}, nil
+}
+
+type verifyingWriter struct {
+	w    io.Writer
+	file io.ReaderAt
+	off  int64
+	buf  []byte
+}
+
+func (vw *verifyingWriter) Write(p []byte) (int, error) {
+	n, err := vw.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	if cap(vw.buf) < n {
+		vw.buf = make([]byte, n)
+	}
+	readBack := vw.buf[:n]
+
+	// ReadAt doesn't move the file offset the writer writes at
+	if _, err := vw.file.ReadAt(readBack, vw.off); err != nil {
+		stats.Record(context.TODO(), metrics.SectorWriteVerifyErrors.M(1))
+		return n, xerrors.Errorf("reading back %d bytes at offset %d: %w", n, vw.off, err)
+	}
+	if sha256.Sum256(p[:n]) != sha256.Sum256(readBack) {
+		stats.Record(context.TODO(), metrics.SectorWriteVerifyErrors.M(1))
+		return n, xerrors.Errorf("%d bytes at offset %d: %w", n, vw.off, ErrVerificationFailed)
+	}
+
+	vw.off += int64(n)
+	return n, nil
+}
+
 func (pf *PartialFile) MarkAllocated(offset storiface.PaddedByteIndex, size abi.PaddedPieceSize) error {
 	have, err := pf.allocated.RunIterator()
 	if err != nil {
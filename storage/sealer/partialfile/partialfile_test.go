@@ -0,0 +1,49 @@
+package partialfile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// corruptingWriter flips the first bit of the data it writes to f
+type corruptingWriter struct {
+	f *os.File
+}
+
+func (c *corruptingWriter) Write(p []byte) (int, error) {
+	b := append([]byte{}, p...)
+	b[0] ^= 1
+	return c.f.Write(b)
+}
+
+func TestVerifyingWriter(t *testing.T) {
+	const size = abi.PaddedPieceSize(2048)
+
+	pf, err := CreatePartialFile(size, filepath.Join(t.TempDir(), "unsealed"))
+	require.NoError(t, err)
+	defer pf.Close() //nolint:errcheck
+
+	w, err := pf.VerifyingWriter(0, size)
+	require.NoError(t, err)
+
+	data := bytes.Repeat([]byte{0xab}, 512)
+	for i := 0; i < 2; i++ {
+		n, err := w.Write(data)
+		require.NoError(t, err)
+		require.Equal(t, len(data), n)
+	}
+
+	// data which doesn't read back as written fails verification
+	_, err = pf.file.Seek(1024, 0)
+	require.NoError(t, err)
+	vw := &verifyingWriter{w: &corruptingWriter{f: pf.file}, file: pf.file, off: 1024}
+	_, err = vw.Write(data)
+	require.True(t, xerrors.Is(err, ErrVerificationFailed), err)
+}
@@ -0,0 +1,63 @@
+package sealer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// commandPoStProver computes window PoSt partition proofs by running a shell
+// command. The command gets the RemotePoStRequest as JSON on stdin, and must
+// write the proof bytes to stdout and exit with status 0.
+type commandPoStProver struct {
+	command string
+	timeout time.Duration
+}
+
+// UseFallbackWindowPoSt makes the manager run command to compute the window
+// PoSt of partitions which couldn't be computed on PoSt workers or locally.
+// The command is killed if it doesn't finish within timeout, unless timeout is
+// 0.
+func (m *Manager) UseFallbackWindowPoSt(command string, timeout time.Duration) {
+	m.fallbackPoSt = &commandPoStProver{
+		command: command,
+		timeout: timeout,
+	}
+}
+
+func (c *commandPoStProver) prove(ctx context.Context, preq *RemotePoStRequest) ([]byte, error) {
+	in, err := json.Marshal(preq)
+	if err != nil {
+		return nil, xerrors.Errorf("marshaling request: %w", err)
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.command)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, xerrors.Errorf("running prover command: %w", ctx.Err())
+		}
+		return nil, xerrors.Errorf("running prover command: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, xerrors.Errorf("prover command returned an empty proof")
+	}
+
+	return stdout.Bytes(), nil
+}
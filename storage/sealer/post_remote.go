@@ -0,0 +1,158 @@
+package sealer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/specs-actors/v7/actors/runtime/proof"
+
+	"github.com/filecoin-project/lotus/storage/paths"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+// RemotePoStRequest is the body of the requests sent to remote proving
+// services, asking for the window PoSt SNARK of a partition.
+type RemotePoStRequest struct {
+	ProofType      abi.RegisteredPoStProof
+	MinerID        abi.ActorID
+	PartitionIndex int
+	Randomness     abi.PoStRandomness
+
+	Sectors []storiface.PostSectorChallenge
+	// VanillaProofs holds the vanilla proof of each of Sectors, in order
+	VanillaProofs [][]byte
+}
+
+// RemotePoStResponse is the body of successful responses from remote proving
+// services.
+type RemotePoStResponse struct {
+	ProofBytes []byte
+}
+
+// externalPoStProver computes the window PoSt SNARK of a partition from the
+// vanilla proofs read from its sectors by the miner.
+type externalPoStProver interface {
+	prove(ctx context.Context, preq *RemotePoStRequest) ([]byte, error)
+}
+
+// remotePoStProver computes window PoSt partition proofs on a remote proving
+// service.
+type remotePoStProver struct {
+	endpoint string
+	auth     http.Header
+	client   *http.Client
+}
+
+// UseRemoteWindowPoSt makes the manager compute window PoSt on the remote
+// proving service at endpoint, instead of on PoSt workers or locally. auth is
+// added to the requests to the service.
+func (m *Manager) UseRemoteWindowPoSt(endpoint string, auth http.Header) {
+	m.remotePoSt = &remotePoStProver{
+		endpoint: endpoint,
+		auth:     auth,
+		client:   http.DefaultClient,
+	}
+}
+
+// generateExternalWindowPoSt reads the vanilla proofs of the partition sectors
+// from storage and has prover compute the partition proof out of them.
+func generateExternalWindowPoSt(ctx context.Context, storage paths.Store, prover externalPoStProver, ppt abi.RegisteredPoStProof, mid abi.ActorID, sectors []storiface.PostSectorChallenge, partitionIdx int, randomness abi.PoStRandomness) (storiface.WindowPoStResult, error) {
+	var slk sync.Mutex
+	var skipped []abi.SectorID
+
+	var wg sync.WaitGroup
+	wg.Add(len(sectors))
+
+	vproofs := make([][]byte, len(sectors))
+
+	for i, s := range sectors {
+		go func(i int, s storiface.PostSectorChallenge) {
+			defer wg.Done()
+
+			vanilla, err := storage.GenerateSingleVanillaProof(ctx, mid, s, ppt)
+			slk.Lock()
+			defer slk.Unlock()
+
+			if err != nil || vanilla == nil {
+				skipped = append(skipped, abi.SectorID{
+					Miner:  mid,
+					Number: s.SectorNumber,
+				})
+				log.Errorf("reading PoSt challenge for sector %d, vlen:%d, err: %s", s.SectorNumber, len(vanilla), err)
+				return
+			}
+
+			vproofs[i] = vanilla
+		}(i, s)
+	}
+	wg.Wait()
+
+	if len(skipped) > 0 {
+		log.Errorf("couldn't read some challenges (skipped %d)", len(skipped))
+		return storiface.WindowPoStResult{Skipped: skipped}, nil
+	}
+
+	proofBytes, err := prover.prove(ctx, &RemotePoStRequest{
+		ProofType:      ppt,
+		MinerID:        mid,
+		PartitionIndex: partitionIdx,
+		Randomness:     randomness,
+		Sectors:        sectors,
+		VanillaProofs:  vproofs,
+	})
+	if err != nil {
+		return storiface.WindowPoStResult{}, err
+	}
+
+	return storiface.WindowPoStResult{
+		PoStProofs: proof.PoStProof{
+			PoStProof:  ppt,
+			ProofBytes: proofBytes,
+		},
+	}, nil
+}
+
+func (r *remotePoStProver) prove(ctx context.Context, preq *RemotePoStRequest) ([]byte, error) {
+	body, err := json.Marshal(preq)
+	if err != nil {
+		return nil, xerrors.Errorf("marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, xerrors.Errorf("creating request: %w", err)
+	}
+	req.Header = r.auth.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close() // nolint
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, xerrors.Errorf("non-200 code: %d: %s", resp.StatusCode, string(msg))
+	}
+
+	var out RemotePoStResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, xerrors.Errorf("decoding response: %w", err)
+	}
+	if len(out.ProofBytes) == 0 {
+		return nil, xerrors.Errorf("empty proof in response")
+	}
+
+	return out.ProofBytes, nil
+}
@@ -0,0 +1,93 @@
+package sealer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+func TestRemotePoStProve(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req RemotePoStRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.PartitionIndex == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(RemotePoStResponse{ProofBytes: req.VanillaProofs[0]})
+	}))
+	defer srv.Close()
+
+	auth := http.Header{}
+	auth.Set("Authorization", "Bearer token")
+	prover := &remotePoStProver{endpoint: srv.URL, auth: auth, client: srv.Client()}
+
+	req := &RemotePoStRequest{
+		ProofType: abi.RegisteredPoStProof_StackedDrgWindow2KiBV1_1,
+		MinerID:   1000,
+		Sectors:   []storiface.PostSectorChallenge{{SectorNumber: 1, Challenge: []uint64{1, 2}}},
+		VanillaProofs: [][]byte{
+			[]byte("vanilla"),
+		},
+	}
+
+	proofBytes, err := prover.prove(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, []byte("vanilla"), proofBytes)
+
+	req.PartitionIndex = 1
+	_, err = prover.prove(context.Background(), req)
+	require.Error(t, err)
+
+	prover.auth = nil
+	req.PartitionIndex = 0
+	_, err = prover.prove(context.Background(), req)
+	require.Error(t, err)
+}
+
+func TestCommandPoStProve(t *testing.T) {
+	req := &RemotePoStRequest{
+		ProofType: abi.RegisteredPoStProof_StackedDrgWindow2KiBV1_1,
+		MinerID:   1000,
+		Sectors:   []storiface.PostSectorChallenge{{SectorNumber: 1, Challenge: []uint64{1, 2}}},
+		VanillaProofs: [][]byte{
+			[]byte("vanilla"),
+		},
+	}
+
+	prover := &commandPoStProver{command: "grep -q '\"MinerID\":1000' && printf proof"}
+	proofBytes, err := prover.prove(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, []byte("proof"), proofBytes)
+
+	prover.command = "cat >/dev/null; echo failed >&2; exit 1"
+	_, err = prover.prove(context.Background(), req)
+	require.ErrorContains(t, err, "failed")
+
+	prover.command = "cat >/dev/null"
+	_, err = prover.prove(context.Background(), req)
+	require.Error(t, err)
+
+	prover.command = "exec sleep 10"
+	prover.timeout = 100 * time.Millisecond
+	_, err = prover.prove(context.Background(), req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
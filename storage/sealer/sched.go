@@ -6,11 +6,14 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-state-types/abi"
 
 	"github.com/filecoin-project/lotus/metrics"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
 	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 )
@@ -41,6 +44,10 @@ func WithPriority(ctx context.Context, priority int) context.Context {
 
 const mib = 1 << 20
 
+// ErrQueueFull is returned when a task can't be scheduled because the maximum
+// number of tasks are already waiting to start on a worker.
+var ErrQueueFull = xerrors.New("too many pending storage requests")
+
 type WorkerAction func(ctx context.Context, w Worker) error
 type PrepareAction struct {
 	Action   WorkerAction
@@ -64,6 +71,14 @@ type WorkerSelector interface {
 type Scheduler struct {
 	mctx context.Context // metrics context
 
+	// number of scheduled tasks which haven't started on a worker yet, use
+	// with pendingLk; only tracked when maxPending is set
+	pendingLk    sync.Mutex
+	pending      int64
+	pendingFreed chan struct{} // closed and replaced when a pending task starts
+	maxPending   int64
+	shedPolicy   string // config.TaskShedding*
+
 	assigner Assigner
 
 	workersLk sync.RWMutex
@@ -81,8 +96,9 @@ type Scheduler struct {
 
 	workTracker *workTracker
 
-	info      chan func(interface{})
-	rmRequest chan *rmRequest
+	info       chan func(interface{})
+	rmRequest  chan *rmRequest
+	shedOldest chan chan bool
 
 	closing  chan struct{}
 	closed   chan struct{}
@@ -104,6 +120,9 @@ type WorkerHandle struct {
 
 	Enabled bool
 
+	// signalled when resources reserved outside of scheduled tasks are freed
+	reservationFreed chan struct{}
+
 	// for sync manager goroutine closing
 	cleanupStarted bool
 	closedMgr      chan struct{}
@@ -193,8 +212,12 @@ func newScheduler(ctx context.Context, assigner string) (*Scheduler, error) {
 			prepared: map[uuid.UUID]trackedWork{},
 		},
 
-		info:      make(chan func(interface{})),
-		rmRequest: make(chan *rmRequest),
+		pendingFreed: make(chan struct{}),
+		shedPolicy:   config.TaskSheddingBlock,
+
+		info:       make(chan func(interface{})),
+		rmRequest:  make(chan *rmRequest),
+		shedOldest: make(chan chan bool),
 
 		closing: make(chan struct{}),
 		closed:  make(chan struct{}),
@@ -202,6 +225,25 @@ func newScheduler(ctx context.Context, assigner string) (*Scheduler, error) {
 }
 
 func (sh *Scheduler) Schedule(ctx context.Context, sector storiface.SectorRef, taskType sealtasks.TaskType, sel WorkerSelector, prepare PrepareAction, work WorkerAction) error {
+	if sh.maxPending > 0 {
+		if err := sh.reservePending(ctx, taskType); err != nil {
+			return err
+		}
+
+		// the task stops being pending once a worker starts preparing it
+		var once sync.Once
+		started := func() {
+			once.Do(sh.releasePending)
+		}
+		defer started()
+
+		prepAction := prepare.Action
+		prepare.Action = func(ctx context.Context, w Worker) error {
+			started()
+			return prepAction(ctx, w)
+		}
+	}
+
 	ret := make(chan workerResponse)
 
 	select {
@@ -236,6 +278,108 @@ func (sh *Scheduler) Schedule(ctx context.Context, sector storiface.SectorRef, t
 	}
 }
 
+// reservePending counts a new task as pending, applying the shedding policy
+// when maxPending tasks are pending already.
+func (sh *Scheduler) reservePending(ctx context.Context, taskType sealtasks.TaskType) error {
+	for {
+		sh.pendingLk.Lock()
+		if sh.pending < sh.maxPending {
+			sh.pending++
+			sh.pendingLk.Unlock()
+			return nil
+		}
+
+		switch sh.shedPolicy {
+		case config.TaskSheddingReject:
+			sh.pendingLk.Unlock()
+			sh.recordShed(config.TaskSheddingReject, taskType)
+			return ErrQueueFull
+		case config.TaskSheddingOldestFirst:
+			// shed while holding pendingLk, so that every task over the limit
+			// sheds exactly one queued task. The new task takes the place of
+			// the shed one right away, which stops being pending once its
+			// Schedule call returns; if nothing could be shed, wait like with
+			// 'block'
+			shed, err := sh.shedOldestRequest(ctx)
+			if err != nil {
+				sh.pendingLk.Unlock()
+				return err
+			}
+			if shed {
+				sh.pending++
+				sh.pendingLk.Unlock()
+				return nil
+			}
+		}
+
+		freed := sh.pendingFreed
+		sh.pendingLk.Unlock()
+
+		select {
+		case <-freed:
+		case <-sh.closing:
+			return xerrors.New("closing")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (sh *Scheduler) releasePending() {
+	sh.pendingLk.Lock()
+	defer sh.pendingLk.Unlock()
+
+	sh.pending--
+	close(sh.pendingFreed)
+	sh.pendingFreed = make(chan struct{})
+}
+
+func (sh *Scheduler) shedOldestRequest(ctx context.Context) (bool, error) {
+	ret := make(chan bool, 1)
+
+	select {
+	case sh.shedOldest <- ret:
+	case <-sh.closing:
+		return false, xerrors.New("closing")
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+
+	// once the request is sent runSched always answers; keep waiting if ctx
+	// is cancelled so that a task shed for this one is never unaccounted for
+	select {
+	case shed := <-ret:
+		return shed, nil
+	case <-sh.closing:
+		return false, xerrors.New("closing")
+	}
+}
+
+// removeOldest fails the request which has been in the queue the longest, and
+// returns whether there was one.
+func (sh *Scheduler) removeOldest() bool {
+	queue := sh.SchedQueue
+	oldest := -1
+	for i, r := range *queue {
+		if oldest < 0 || r.start.Before((*queue)[oldest].start) {
+			oldest = i
+		}
+	}
+	if oldest < 0 {
+		return false
+	}
+
+	r := queue.Remove(oldest)
+	sh.recordShed(config.TaskSheddingOldestFirst, r.TaskType)
+	go r.respond(xerrors.Errorf("removed to make room for newer tasks: %w", ErrQueueFull))
+	return true
+}
+
+func (sh *Scheduler) recordShed(policy string, taskType sealtasks.TaskType) {
+	ctx, _ := tag.New(sh.mctx, tag.Upsert(metrics.ShedPolicy, policy), tag.Upsert(metrics.TaskType, string(taskType)))
+	stats.Record(ctx, metrics.SchedTasksShed.M(1))
+}
+
 func (r *WorkerRequest) respond(err error) {
 	select {
 	case r.ret <- workerResponse{err: err}:
@@ -284,6 +428,9 @@ func (sh *Scheduler) runSched() {
 		case rmreq := <-sh.rmRequest:
 			sh.removeRequest(rmreq)
 			doSched = true
+		case ret := <-sh.shedOldest:
+			ret <- sh.removeOldest()
+			doSched = true
 		case <-sh.workerChange:
 			doSched = true
 		case dreq := <-sh.workerDisable:
@@ -397,6 +544,58 @@ func (sh *Scheduler) trySched() {
 	sh.assigner.TrySched(sh)
 }
 
+// setIgnoreResources changes whether the available resources of a worker are
+// ignored when scheduling tasks on it. It returns true if the setting changed.
+func (sh *Scheduler) setIgnoreResources(wid storiface.WorkerID, ignore bool) bool {
+	sh.workersLk.Lock()
+	defer sh.workersLk.Unlock()
+
+	w, ok := sh.Workers[wid]
+	if !ok || w.Info.IgnoreResources == ignore {
+		return false
+	}
+	w.Info.IgnoreResources = ignore
+
+	select {
+	case sh.workerChange <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// reserveGPUs marks all GPUs of a worker as in use until the returned function
+// is called, even if tasks are already using them. Scheduled tasks needing a
+// GPU don't start on the worker in the meantime.
+func (sh *Scheduler) reserveGPUs(wid storiface.WorkerID, tt sealtasks.SealTaskType) (release func()) {
+	sh.workersLk.RLock()
+	w, ok := sh.Workers[wid]
+	sh.workersLk.RUnlock()
+	if !ok || len(w.Info.Resources.GPUs) == 0 {
+		return func() {}
+	}
+
+	res := storiface.Resources{GPUUtilization: float64(len(w.Info.Resources.GPUs))}
+	schedID := uuid.New()
+
+	w.lk.Lock()
+	w.active.Add(schedID, tt, w.Info.Resources, res)
+	w.lk.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			w.lk.Lock()
+			w.active.Free(schedID, tt, w.Info.Resources, res)
+			w.lk.Unlock()
+
+			select {
+			case w.reservationFreed <- struct{}{}:
+			default: // there is a notification pending already
+			}
+		})
+	}
+}
+
 func (sh *Scheduler) schedClose() {
 	sh.workersLk.Lock()
 	defer sh.workersLk.Unlock()
@@ -0,0 +1,90 @@
+package sealer
+
+import (
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+func NewCapacityAssigner() Assigner {
+	return &AssignerCommon{
+		WindowSel: LargestFreeMemoryWS,
+	}
+}
+
+// LargestFreeMemoryWS assigns tasks to the worker with the most physical memory left over
+// after the memory reserved by its tasks, including the ones assigned in this cycle.
+func LargestFreeMemoryWS(sh *Scheduler, queueLen int, acceptableWindows [][]int, windows []SchedWindow) int {
+	scheduled := 0
+	rmQueue := make([]int, 0, queueLen)
+	workerFree := map[storiface.WorkerID]uint64{}
+
+	for sqi := 0; sqi < queueLen; sqi++ {
+		task := (*sh.SchedQueue)[sqi]
+
+		selectedWindow := -1
+		var needRes storiface.Resources
+		var info storiface.WorkerInfo
+		var bestWid storiface.WorkerID
+		var bestFree uint64 // larger = better
+
+		for i, wnd := range acceptableWindows[task.IndexHeap] {
+			wid := sh.OpenWindows[wnd].Worker
+			w := sh.Workers[wid]
+
+			res := w.Info.Resources.ResourceSpec(task.Sector.ProofType, task.TaskType)
+
+			log.Debugf("SCHED try assign sqi:%d sector %d to window %d (awi:%d)", sqi, task.Sector.ID.Number, wnd, i)
+
+			if !windows[wnd].Allocated.CanHandleRequest(task.SchedId, task.SealTask(), res, wid, "schedAssign", w.Info) {
+				continue
+			}
+
+			free, found := workerFree[wid]
+			if !found {
+				free = w.FreeMemory()
+				workerFree[wid] = free
+			}
+			if selectedWindow >= 0 && free <= bestFree {
+				continue
+			}
+
+			info = w.Info
+			needRes = res
+			bestWid = wid
+			selectedWindow = wnd
+			bestFree = free
+		}
+
+		if selectedWindow < 0 {
+			// all windows full
+			continue
+		}
+
+		log.Debugw("SCHED ASSIGNED",
+			"assigner", "capacity",
+			"sqi", sqi,
+			"sector", task.Sector.ID.Number,
+			"task", task.TaskType,
+			"window", selectedWindow,
+			"worker", bestWid,
+			"free", bestFree)
+
+		windows[selectedWindow].Allocated.Add(task.SchedId, task.SealTask(), info.Resources, needRes)
+		windows[selectedWindow].Todo = append(windows[selectedWindow].Todo, task)
+		if needRes.MinMemory < bestFree {
+			workerFree[bestWid] = bestFree - needRes.MinMemory
+		} else {
+			workerFree[bestWid] = 0
+		}
+
+		rmQueue = append(rmQueue, sqi)
+		scheduled++
+	}
+
+	if len(rmQueue) > 0 {
+		for i := len(rmQueue) - 1; i >= 0; i-- {
+			sh.SchedQueue.Remove(rmQueue[i])
+		}
+	}
+
+	return scheduled
+}
@@ -0,0 +1,143 @@
+package sealer
+
+import (
+	"math"
+
+	"github.com/filecoin-project/lotus/storage/paths"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+// sectorFileTypes are the sector files which make a worker holding them local to a task
+const sectorFileTypes = storiface.FTUnsealed | storiface.FTSealed | storiface.FTCache | storiface.FTUpdate | storiface.FTUpdateCache
+
+func NewLocalityAssigner(index paths.SectorIndex) Assigner {
+	return &AssignerCommon{
+		WindowSel: LocalityWS(index),
+	}
+}
+
+// LocalityWS assigns tasks to workers which already have files of the task sector in their
+// local storage paths, picking the lowest utilization one among them. Tasks for which no such
+// worker can take the task are assigned to the lowest utilization worker.
+func LocalityWS(index paths.SectorIndex) WindowSelector {
+	return func(sh *Scheduler, queueLen int, acceptableWindows [][]int, windows []SchedWindow) int {
+		scheduled := 0
+		rmQueue := make([]int, 0, queueLen)
+		workerUtil := map[storiface.WorkerID]float64{}
+
+		cachedWorkers := &schedWorkerCache{
+			Workers: sh.Workers,
+			cached:  map[storiface.WorkerID]*cachedSchedWorker{},
+		}
+		workerPaths := map[storiface.WorkerID]map[storiface.ID]struct{}{}
+
+		for sqi := 0; sqi < queueLen; sqi++ {
+			task := (*sh.SchedQueue)[sqi]
+
+			holders := map[storiface.ID]struct{}{}
+			if ssize, err := task.Sector.ProofType.SectorSize(); err == nil {
+				sis, err := index.StorageFindSector(task.Ctx, task.Sector.ID, sectorFileTypes, ssize, false)
+				if err != nil {
+					log.Warnw("finding sector storage for locality", "sector", task.Sector.ID, "error", err)
+				}
+				for _, si := range sis {
+					holders[si.ID] = struct{}{}
+				}
+			}
+
+			selectedWindow := -1
+			var needRes storiface.Resources
+			var info storiface.WorkerInfo
+			var bestWid storiface.WorkerID
+			bestLocal := false
+			bestUtilization := math.MaxFloat64 // smaller = better
+
+			for i, wnd := range acceptableWindows[task.IndexHeap] {
+				wid := sh.OpenWindows[wnd].Worker
+				w := sh.Workers[wid]
+
+				res := w.Info.Resources.ResourceSpec(task.Sector.ProofType, task.TaskType)
+
+				log.Debugf("SCHED try assign sqi:%d sector %d to window %d (awi:%d)", sqi, task.Sector.ID.Number, wnd, i)
+
+				if !windows[wnd].Allocated.CanHandleRequest(task.SchedId, task.SealTask(), res, wid, "schedAssign", w.Info) {
+					continue
+				}
+
+				local := false
+				if len(holders) > 0 {
+					wp, found := workerPaths[wid]
+					if !found {
+						wp = map[storiface.ID]struct{}{}
+						if cw, ok := cachedWorkers.Get(wid); ok {
+							sps, err := cw.Paths(task.Ctx)
+							if err != nil {
+								log.Warnw("getting worker paths for locality", "worker", wid, "error", err)
+							}
+							for _, sp := range sps {
+								wp[sp.ID] = struct{}{}
+							}
+						}
+						workerPaths[wid] = wp
+					}
+
+					for id := range holders {
+						if _, ok := wp[id]; ok {
+							local = true
+							break
+						}
+					}
+				}
+
+				wu, found := workerUtil[wid]
+				if !found {
+					wu = w.Utilization()
+					workerUtil[wid] = wu
+				}
+
+				if bestLocal && !local {
+					continue
+				}
+				if local == bestLocal && wu >= bestUtilization {
+					continue
+				}
+
+				info = w.Info
+				needRes = res
+				bestWid = wid
+				selectedWindow = wnd
+				bestLocal = local
+				bestUtilization = wu
+			}
+
+			if selectedWindow < 0 {
+				// all windows full
+				continue
+			}
+
+			log.Debugw("SCHED ASSIGNED",
+				"assigner", "locality",
+				"sqi", sqi,
+				"sector", task.Sector.ID.Number,
+				"task", task.TaskType,
+				"window", selectedWindow,
+				"worker", bestWid,
+				"local", bestLocal,
+				"utilization", bestUtilization)
+
+			workerUtil[bestWid] += windows[selectedWindow].Allocated.Add(task.SchedId, task.SealTask(), info.Resources, needRes)
+			windows[selectedWindow].Todo = append(windows[selectedWindow].Todo, task)
+
+			rmQueue = append(rmQueue, sqi)
+			scheduled++
+		}
+
+		if len(rmQueue) > 0 {
+			for i := len(rmQueue) - 1; i >= 0; i-- {
+				sh.SchedQueue.Remove(rmQueue[i])
+			}
+		}
+
+		return scheduled
+	}
+}
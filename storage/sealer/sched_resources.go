@@ -260,6 +260,27 @@ func (wh *WorkerHandle) Utilization() float64 {
 	return u
 }
 
+// FreeMemory returns the physical memory of the worker which isn't reserved by
+// tasks running or scheduled on it, nor used by other processes
+func (wh *WorkerHandle) FreeMemory() uint64 {
+	wh.lk.Lock()
+	used := wh.active.memUsedMin + wh.preparing.memUsedMin
+	wh.lk.Unlock()
+	wh.wndLk.Lock()
+	for _, window := range wh.activeWindows {
+		used += window.Allocated.memUsedMin
+	}
+	wh.wndLk.Unlock()
+
+	if used < wh.Info.Resources.MemUsed {
+		used = wh.Info.Resources.MemUsed
+	}
+	if used >= wh.Info.Resources.MemPhysical {
+		return 0
+	}
+	return wh.Info.Resources.MemPhysical - used
+}
+
 func (wh *WorkerHandle) TaskCounts() int {
 	wh.lk.Lock()
 	u := wh.active.taskCount(nil)
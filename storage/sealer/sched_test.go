@@ -20,6 +20,7 @@ import (
 	prooftypes "github.com/filecoin-project/go-state-types/proof"
 
 	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/storage/paths"
 	"github.com/filecoin-project/lotus/storage/sealer/fsutil"
 	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
@@ -754,3 +755,308 @@ func TestWindowCompact(t *testing.T) {
 		[][]sealtasks.TaskType{{sealtasks.TTPreCommit1, sealtasks.TTPreCommit1, sealtasks.TTAddPiece}, {sealtasks.TTPreCommit1, sealtasks.TTPreCommit2}}),
 	)
 }
+
+func TestReserveGPUs(t *testing.T) {
+	spt := abi.RegisteredSealProof_StackedDrg32GiBV1
+	wr := decentWorkerResources
+	wr.GPUs = []string{"gpu0"}
+
+	sched, err := newScheduler(context.Background(), "")
+	require.NoError(t, err)
+
+	wid := storiface.WorkerID{1}
+	wh := &WorkerHandle{
+		Info:             storiface.WorkerInfo{Resources: wr},
+		Enabled:          true,
+		preparing:        NewActiveResources(newTaskCounter()),
+		active:           NewActiveResources(newTaskCounter()),
+		reservationFreed: make(chan struct{}, 1),
+	}
+	sched.Workers[wid] = wh
+
+	c2 := sealtasks.TTCommit2.SealTask(spt)
+	needRes := wr.ResourceSpec(spt, sealtasks.TTCommit2)
+	require.True(t, wh.active.CanHandleRequest(uuid.New(), c2, needRes, wid, "test", wh.Info))
+
+	release := sched.reserveGPUs(wid, sealtasks.TTGenerateWindowPoSt.SealTask(spt))
+	require.False(t, wh.active.CanHandleRequest(uuid.New(), c2, needRes, wid, "test", wh.Info))
+
+	release()
+	release() // releasing twice is a no-op
+	require.True(t, wh.active.CanHandleRequest(uuid.New(), c2, needRes, wid, "test", wh.Info))
+	require.Len(t, wh.reservationFreed, 1)
+
+	// workers without GPUs aren't affected
+	require.NotPanics(t, sched.reserveGPUs(storiface.WorkerID{2}, c2))
+}
+
+func TestMaxPendingRequests(t *testing.T) {
+	sched, err := newScheduler(context.Background(), "")
+	require.NoError(t, err)
+	sched.maxPending = 1
+	sched.shedPolicy = config.TaskSheddingReject
+
+	go sched.runSched()
+	defer func() {
+		require.NoError(t, sched.Close(context.Background()))
+	}()
+
+	nop := func(ctx context.Context, w Worker) error { return nil }
+
+	// with no workers, the first task stays queued until cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- sched.Schedule(ctx, storiface.NoSectorRef, sealtasks.TTFetch, newTaskSelector(), schedNop, nop)
+	}()
+	require.Eventually(t, func() bool {
+		return pendingTasks(sched) == 1
+	}, time.Second, time.Millisecond)
+
+	err = sched.Schedule(context.Background(), storiface.NoSectorRef, sealtasks.TTFetch, newTaskSelector(), schedNop, nop)
+	require.ErrorIs(t, err, ErrQueueFull)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.Equal(t, int64(0), pendingTasks(sched))
+}
+
+func TestTaskSheddingPolicy(t *testing.T) {
+	nop := func(ctx context.Context, w Worker) error { return nil }
+
+	run := func(t *testing.T, policy string) (first, second chan error, cancelFirst, cancelSecond context.CancelFunc, sched *Scheduler) {
+		sched, err := newScheduler(context.Background(), "")
+		require.NoError(t, err)
+		sched.maxPending = 1
+		sched.shedPolicy = policy
+		sched.testSync = make(chan struct{}, 2)
+
+		go sched.runSched()
+		t.Cleanup(func() {
+			require.NoError(t, sched.Close(context.Background()))
+		})
+
+		schedule := func() (chan error, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- sched.Schedule(ctx, storiface.NoSectorRef, sealtasks.TTFetch, newTaskSelector(), schedNop, nop)
+			}()
+			return done, cancel
+		}
+
+		// with no workers, tasks stay queued until cancelled
+		first, cancelFirst = schedule()
+		<-sched.testSync
+
+		second, cancelSecond = schedule()
+		return first, second, cancelFirst, cancelSecond, sched
+	}
+
+	t.Run("block", func(t *testing.T) {
+		first, second, cancelFirst, cancelSecond, sched := run(t, config.TaskSheddingBlock)
+
+		select {
+		case err := <-second:
+			t.Fatalf("second task didn't wait for room: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		cancelFirst()
+		require.ErrorIs(t, <-first, context.Canceled)
+		require.Eventually(t, func() bool {
+			return pendingTasks(sched) == 1
+		}, time.Second, time.Millisecond)
+
+		cancelSecond()
+		require.ErrorIs(t, <-second, context.Canceled)
+	})
+
+	t.Run("oldest-first", func(t *testing.T) {
+		first, second, cancelFirst, cancelSecond, sched := run(t, config.TaskSheddingOldestFirst)
+		defer cancelFirst()
+
+		require.ErrorIs(t, <-first, ErrQueueFull)
+		require.Eventually(t, func() bool {
+			return pendingTasks(sched) == 1
+		}, time.Second, time.Millisecond)
+
+		cancelSecond()
+		require.ErrorIs(t, <-second, context.Canceled)
+		require.Equal(t, int64(0), pendingTasks(sched))
+	})
+
+	t.Run("oldest-first concurrent", func(t *testing.T) {
+		sched, err := newScheduler(context.Background(), "")
+		require.NoError(t, err)
+		sched.maxPending = 2
+		sched.shedPolicy = config.TaskSheddingOldestFirst
+		sched.testSync = make(chan struct{}, 4)
+
+		go sched.runSched()
+		t.Cleanup(func() {
+			require.NoError(t, sched.Close(context.Background()))
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		done := make(chan error, 4)
+		schedule := func() {
+			done <- sched.Schedule(ctx, storiface.NoSectorRef, sealtasks.TTFetch, newTaskSelector(), schedNop, nop)
+		}
+
+		go schedule()
+		<-sched.testSync
+		go schedule()
+		<-sched.testSync
+
+		// two tasks over the limit shed exactly the two queued ones
+		go schedule()
+		go schedule()
+
+		require.ErrorIs(t, <-done, ErrQueueFull)
+		require.ErrorIs(t, <-done, ErrQueueFull)
+		select {
+		case err := <-done:
+			t.Fatalf("more tasks shed than went over the limit: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		require.Equal(t, int64(2), pendingTasks(sched))
+
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+}
+
+func pendingTasks(sh *Scheduler) int64 {
+	sh.pendingLk.Lock()
+	defer sh.pendingLk.Unlock()
+	return sh.pending
+}
+
+func TestOpenSectorsSelector(t *testing.T) {
+	ctx := context.Background()
+	index := paths.NewIndex(nil)
+
+	w := &schedTestWorker{
+		taskTypes: map[sealtasks.TaskType]struct{}{sealtasks.TTAddPiece: {}},
+		paths: []storiface.StoragePath{
+			{ID: "seal", CanSeal: true},
+			{ID: "store", CanStore: true},
+		},
+		session:   uuid.New(),
+		resources: decentWorkerResources,
+	}
+	for _, path := range w.paths {
+		require.NoError(t, index.StorageAttach(ctx, storiface.StorageInfo{
+			ID:       path.ID,
+			CanSeal:  path.CanSeal,
+			CanStore: path.CanStore,
+		}, fsutil.FsStat{Capacity: 1 << 40, Available: 1 << 40, FSAvailable: 1 << 40}))
+	}
+
+	for i := 0; i < 2; i++ {
+		sid := abi.SectorID{Miner: 1000, Number: abi.SectorNumber(i)}
+		require.NoError(t, index.StorageDeclareSector(ctx, "seal", sid, storiface.FTUnsealed, true))
+	}
+	// sectors in long-term storage don't count
+	require.NoError(t, index.StorageDeclareSector(ctx, "store", abi.SectorID{Miner: 1000, Number: 9}, storiface.FTSealed, true))
+
+	wid := storiface.WorkerID(w.session)
+	wh, err := newWorkerHandle(ctx, w)
+	require.NoError(t, err)
+
+	cached := &schedWorkerCache{
+		Workers: map[storiface.WorkerID]*WorkerHandle{wid: wh},
+		cached:  map[storiface.WorkerID]*cachedSchedWorker{},
+	}
+	sw, _ := cached.Get(wid)
+
+	ok, _, err := newOpenSectorsSelector(newTaskSelector(), index, 3).Ok(ctx, sealtasks.TTAddPiece, abi.RegisteredSealProof_StackedDrg2KiBV1, sw)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, _, err = newOpenSectorsSelector(newTaskSelector(), index, 2).Ok(ctx, sealtasks.TTAddPiece, abi.RegisteredSealProof_StackedDrg2KiBV1, sw)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+// selectWindow runs a window selector on a single PC2 task for the given workers, each of which
+// has one open window, and returns the index of the worker the task was assigned to
+func selectWindow(t *testing.T, ws WindowSelector, sector abi.SectorID, workers ...*schedTestWorker) int {
+	ctx := context.Background()
+
+	sh, err := newScheduler(ctx, "")
+	require.NoError(t, err)
+
+	windows := make([]SchedWindow, len(workers))
+	for i, w := range workers {
+		wh, err := newWorkerHandle(ctx, w)
+		require.NoError(t, err)
+
+		wid := storiface.WorkerID(w.session)
+		sh.Workers[wid] = wh
+		sh.OpenWindows = append(sh.OpenWindows, &SchedWindowRequest{Worker: wid})
+		windows[i].Allocated = *NewActiveResources(newTaskCounter())
+	}
+
+	sh.SchedQueue.Push(&WorkerRequest{
+		Sector:   storiface.SectorRef{ID: sector, ProofType: abi.RegisteredSealProof_StackedDrg2KiBV1},
+		TaskType: sealtasks.TTPreCommit2,
+		SchedId:  uuid.New(),
+		Ctx:      ctx,
+	})
+
+	acceptable := make([]int, len(workers))
+	for i := range acceptable {
+		acceptable[i] = i
+	}
+	require.Equal(t, 1, ws(sh, 1, [][]int{acceptable}, windows))
+
+	for i, wnd := range windows {
+		if len(wnd.Todo) > 0 {
+			return i
+		}
+	}
+	t.Fatal("task not assigned")
+	return -1
+}
+
+func TestLocalityWS(t *testing.T) {
+	ctx := context.Background()
+	index := paths.NewIndex(nil)
+
+	var workers []*schedTestWorker
+	for _, id := range []storiface.ID{"a", "b"} {
+		workers = append(workers, &schedTestWorker{
+			paths:     []storiface.StoragePath{{ID: id, CanSeal: true}},
+			session:   uuid.New(),
+			resources: decentWorkerResources,
+		})
+		require.NoError(t, index.StorageAttach(ctx, storiface.StorageInfo{ID: id, CanSeal: true},
+			fsutil.FsStat{Capacity: 1 << 40, Available: 1 << 40, FSAvailable: 1 << 40}))
+	}
+
+	local := abi.SectorID{Miner: 1000, Number: 1}
+	require.NoError(t, index.StorageDeclareSector(ctx, "b", local, storiface.FTSealed|storiface.FTCache, true))
+
+	// the second worker holds the sector files
+	require.Equal(t, 1, selectWindow(t, LocalityWS(index), local, workers...))
+
+	// no worker holds the files, so the equally utilized first worker is picked
+	require.Equal(t, 0, selectWindow(t, LocalityWS(index), abi.SectorID{Miner: 1000, Number: 2}, workers...))
+}
+
+func TestLargestFreeMemoryWS(t *testing.T) {
+	small := decentWorkerResources
+	small.MemPhysical = 64 << 30
+
+	workers := []*schedTestWorker{
+		{session: uuid.New(), resources: small},
+		{session: uuid.New(), resources: decentWorkerResources},
+	}
+
+	require.Equal(t, 1, selectWindow(t, LargestFreeMemoryWS, abi.SectorID{Miner: 1000, Number: 1}, workers...))
+}
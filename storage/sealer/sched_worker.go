@@ -40,6 +40,8 @@ func newWorkerHandle(ctx context.Context, w Worker) (*WorkerHandle, error) {
 		active:    NewActiveResources(tc),
 		Enabled:   true,
 
+		reservationFreed: make(chan struct{}, 1),
+
 		closingMgr: make(chan struct{}),
 		closedMgr:  make(chan struct{}),
 	}
@@ -275,6 +277,8 @@ func (sw *schedWorker) waitForUpdates() (update bool, sched bool, ok bool) {
 	case <-sw.taskDone:
 		log.Debugw("task done", "workerid", sw.wid)
 		return true, true, true
+	case <-sw.worker.reservationFreed:
+		return true, true, true
 	case <-sw.sched.closing:
 	case <-sw.worker.closingMgr:
 	}
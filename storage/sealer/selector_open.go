@@ -0,0 +1,72 @@
+package sealer
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/storage/paths"
+	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
+)
+
+// openSectorsSelector wraps a selector, rejecting workers which already hold
+// maxOpen sectors in their sealing paths.
+type openSectorsSelector struct {
+	WorkerSelector
+
+	index   paths.SectorIndex
+	maxOpen int
+}
+
+func newOpenSectorsSelector(sel WorkerSelector, index paths.SectorIndex, maxOpen int) *openSectorsSelector {
+	return &openSectorsSelector{
+		WorkerSelector: sel,
+		index:          index,
+		maxOpen:        maxOpen,
+	}
+}
+
+func (s *openSectorsSelector) Ok(ctx context.Context, task sealtasks.TaskType, spt abi.RegisteredSealProof, whnd SchedWorker) (bool, bool, error) {
+	ok, preferred, err := s.WorkerSelector.Ok(ctx, task, spt, whnd)
+	if err != nil || !ok {
+		return ok, preferred, err
+	}
+
+	open, err := s.openSectors(ctx, whnd)
+	if err != nil {
+		return false, false, err
+	}
+
+	return open < s.maxOpen, preferred, nil
+}
+
+// openSectors counts the sectors with files in the sealing paths of the worker.
+// Paths which also hold long-term storage aren't counted, as finalized sectors
+// stay there.
+func (s *openSectorsSelector) openSectors(ctx context.Context, whnd SchedWorker) (int, error) {
+	wpaths, err := whnd.Paths(ctx)
+	if err != nil {
+		return 0, xerrors.Errorf("getting worker paths: %w", err)
+	}
+
+	decls, err := s.index.StorageList(ctx)
+	if err != nil {
+		return 0, xerrors.Errorf("listing storage: %w", err)
+	}
+
+	open := map[abi.SectorID]struct{}{}
+	for _, path := range wpaths {
+		if !path.CanSeal || path.CanStore {
+			continue
+		}
+		for _, decl := range decls[path.ID] {
+			open[decl.SectorID] = struct{}{}
+		}
+	}
+
+	return len(open), nil
+}
+
+var _ WorkerSelector = &openSectorsSelector{}
@@ -44,6 +44,9 @@ type WorkerConfig struct {
 
 	MaxParallelChallengeReads int           // 0 = no limit
 	ChallengeReadTimeout      time.Duration // 0 = no timeout
+
+	// WriteVerify makes AddPiece read back and verify the sector data it writes
+	WriteVerify bool
 }
 
 // used do provide custom proofs impl (mostly used in testing)
@@ -72,6 +75,8 @@ type LocalWorker struct {
 	challengeThrottle    chan struct{}
 	challengeReadTimeout time.Duration
 
+	writeVerify bool
+
 	session     uuid.UUID
 	testDisable int64
 	closing     chan struct{}
@@ -99,6 +104,7 @@ func newLocalWorker(executor ExecutorFunc, wcfg WorkerConfig, envLookup EnvFunc,
 		envLookup:            envLookup,
 		ignoreResources:      wcfg.IgnoreResourceFiltering,
 		challengeReadTimeout: wcfg.ChallengeReadTimeout,
+		writeVerify:          wcfg.WriteVerify,
 		session:              uuid.New(),
 		closing:              make(chan struct{}),
 	}
@@ -185,7 +191,16 @@ func (l *localWorkerPathProvider) AcquireSectorCopy(ctx context.Context, id stor
 }
 
 func (l *LocalWorker) ffiExec() (storiface.Storage, error) {
-	return ffiwrapper.New(&localWorkerPathProvider{w: l})
+	sb, err := ffiwrapper.New(&localWorkerPathProvider{w: l})
+	if err != nil {
+		return nil, err
+	}
+
+	if l.writeVerify {
+		sb.EnableWriteVerify()
+	}
+
+	return sb, nil
 }
 
 type ReturnType string
@@ -38,10 +38,13 @@ type changeHandler struct {
 	submitHdlr *submitHandler
 }
 
-func newChangeHandler(api wdPoStCommands, actor address.Address) *changeHandler {
+// newChangeHandler creates a changeHandler which submits proofs submitDelay
+// epochs later than it otherwise would.
+func newChangeHandler(api wdPoStCommands, actor address.Address, submitDelay abi.ChainEpoch) *changeHandler {
 	posts := newPostsCache()
 	p := newProver(api, posts)
 	s := newSubmitter(api, posts)
+	s.submitDelay = submitDelay
 	return &changeHandler{api: api, actor: actor, proveHdlr: p, submitHdlr: s}
 }
 
@@ -300,6 +303,9 @@ type submitHandler struct {
 	api   wdPoStCommands
 	posts *postsCache
 
+	// extra epochs to wait past SubmitConfidence, capped at half the window
+	submitDelay abi.ChainEpoch
+
 	submitResults chan *submitResult
 	hcs           chan *headChange
 
@@ -463,7 +469,11 @@ func (s *submitHandler) submitIfReady(ctx context.Context, advance *types.TipSet
 	}
 
 	// Check if we've reached the confidence height to submit
-	if advance.Height() < pw.di.Open+SubmitConfidence {
+	delay := s.submitDelay
+	if maxDelay := (pw.di.Close - pw.di.Open) / 2; delay > maxDelay {
+		delay = maxDelay
+	}
+	if advance.Height() < pw.di.Open+SubmitConfidence+delay {
 		return
 	}
 
@@ -248,6 +248,52 @@ func TestChangeHandlerBasic(t *testing.T) {
 	require.Equal(t, SubmitStateComplete, s.submitState(di))
 }
 
+// TestChangeHandlerSubmitDelay verifies that proofs are submitted submitDelay
+// epochs after the confidence height
+func TestChangeHandlerSubmitDelay(t *testing.T) {
+	s := makeScaffolding(t)
+	s.ch.submitHdlr.submitDelay = 2
+	mock := s.mock
+
+	defer s.ch.shutdown()
+	s.ch.start()
+
+	// Trigger a head change
+	currentEpoch := abi.ChainEpoch(1)
+	go triggerHeadAdvance(t, s, currentEpoch)
+
+	// Should start proving
+	<-s.ch.proveHdlr.processedHeadChanges
+	di := mock.getDeadline(currentEpoch)
+	require.Equal(t, postStatusProving, s.mock.getPostStatus(di))
+	<-s.ch.submitHdlr.processedHeadChanges
+
+	// Send a response to the call to generate proofs
+	posts := []minertypes.SubmitWindowedPoStParams{{Deadline: di.Index}}
+	mock.proveResult <- &proveRes{posts: posts}
+	<-s.ch.proveHdlr.processedPostResults
+	require.Equal(t, postStatusComplete, s.mock.getPostStatus(di))
+
+	// The confidence height alone isn't enough to submit
+	currentEpoch = 1 + SubmitConfidence
+	go triggerHeadAdvance(t, s, currentEpoch)
+	<-s.ch.proveHdlr.processedHeadChanges
+	<-s.ch.submitHdlr.processedHeadChanges
+	require.Equal(t, SubmitStateStart, s.submitState(di))
+
+	// Move past the delay
+	currentEpoch = 1 + SubmitConfidence + 2
+	go triggerHeadAdvance(t, s, currentEpoch)
+	<-s.ch.proveHdlr.processedHeadChanges
+	<-s.ch.submitHdlr.processedHeadChanges
+	require.Equal(t, SubmitStateSubmitting, s.submitState(di))
+
+	// Send a response to the submit call
+	mock.submitResult <- nil
+	<-s.ch.submitHdlr.processedSubmitResults
+	require.Equal(t, SubmitStateComplete, s.submitState(di))
+}
+
 // TestChangeHandlerFromProvingToSubmittingNoHeadChange tests that when the
 // chain is already advanced past the confidence interval, we should move from
 // proving to submitting without a head change in between.
@@ -1149,7 +1195,7 @@ func makeScaffolding(t *testing.T) *smScaffolding {
 	ctx := context.Background()
 	actor := tutils.NewActorAddr(t, "actor")
 	mock := newMockAPI()
-	ch := newChangeHandler(mock, actor)
+	ch := newChangeHandler(mock, actor, 0)
 	mock.setChangeHandler(ch)
 
 	ch.proveHdlr.processedHeadChanges = make(chan *headChange)
@@ -57,6 +57,8 @@ type WdPoStProofsProcessedEvt struct {
 	evtCommon
 	Partitions []miner.PoStPartition
 	MessageCID cid.Cid `json:",omitempty"`
+	// GasBuffer is the amount of gas added to the estimated gas limit of the message.
+	GasBuffer uint64 `json:",omitempty"`
 }
 
 // WdPoStRecoveriesProcessedEvt is the journal event that gets recorded when
@@ -65,6 +67,8 @@ type WdPoStRecoveriesProcessedEvt struct {
 	evtCommon
 	Declarations []miner.RecoveryDeclaration
 	MessageCID   cid.Cid `json:",omitempty"`
+	// GasBuffer is the amount of gas added to the estimated gas limit of the message.
+	GasBuffer uint64 `json:",omitempty"`
 }
 
 // WdPoStFaultsProcessedEvt is the journal event that gets recorded when
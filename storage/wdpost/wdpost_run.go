@@ -3,6 +3,7 @@ package wdpost
 import (
 	"bytes"
 	"context"
+	"sync"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -28,6 +29,7 @@ import (
 	"github.com/filecoin-project/lotus/chain/actors/policy"
 	"github.com/filecoin-project/lotus/chain/messagepool"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 )
 
@@ -57,6 +59,7 @@ func (s *WindowPoStScheduler) recordProofsEvent(partitions []miner.PoStPartition
 			evtCommon:  s.getEvtCommon(nil),
 			Partitions: partitions,
 			MessageCID: mcid,
+			GasBuffer:  s.gasBuffer,
 		}
 	})
 }
@@ -168,22 +171,48 @@ func (s *WindowPoStScheduler) runSubmitPoST(
 		return err
 	}
 
-	var submitErr error
+	// Add randomness to PoST
+	for i := range posts {
+		posts[i].ChainCommitEpoch = commEpoch
+		posts[i].ChainCommitRand = commRand
+	}
+
+	parallel := 1
+	if s.nonceStrategy == config.WindowPoStNonceParallelGap {
+		// keep the pending messages of a sender within the nonce gap accepted by the mpool
+		parallel = int(messagepool.MaxNonceGap)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		errLk     sync.Mutex
+		submitErr error
+	)
+	throttle := make(chan struct{}, parallel)
 	for i := range posts {
-		// Add randomness to PoST
 		post := &posts[i]
-		post.ChainCommitEpoch = commEpoch
-		post.ChainCommitRand = commRand
 
-		// Submit PoST
-		sm, err := s.submitPoStMessage(ctx, post)
-		if err != nil {
-			log.Errorf("submit window post failed: %+v", err)
-			submitErr = err
-		} else {
+		throttle <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-throttle
+				wg.Done()
+			}()
+
+			// Submit PoST
+			sm, err := s.submitPoStMessage(ctx, post)
+			if err != nil {
+				log.Errorf("submit window post failed: %+v", err)
+				errLk.Lock()
+				submitErr = err
+				errLk.Unlock()
+				return
+			}
 			s.recordProofsEvent(post.Partitions, sm.Cid())
-		}
+		}()
 	}
+	wg.Wait()
 
 	return submitErr
 }
@@ -651,7 +680,7 @@ func (s *WindowPoStScheduler) submitPoStMessage(ctx context.Context, proof *mine
 		Value:  types.NewInt(0),
 	}
 	spec := &api.MessageSendSpec{MaxFee: abi.TokenAmount(s.feeCfg.MaxWindowPoStGasFee), MaximizeFeeCap: s.feeCfg.MaximizeWindowPoStFeeCap}
-	if err := s.prepareMessage(ctx, msg, spec); err != nil {
+	if err := s.prepareMessage(ctx, msg, spec, 1); err != nil {
 		return nil, err
 	}
 
@@ -662,15 +691,20 @@ func (s *WindowPoStScheduler) submitPoStMessage(ctx context.Context, proof *mine
 
 	log.Infof("Submitted window post: %s (deadline %d)", sm.Cid(), proof.Deadline)
 
+	confidence := build.MessageConfidence
+	if s.postMessageConfirmDepth > 0 && uint64(s.postMessageConfirmDepth) > confidence {
+		confidence = uint64(s.postMessageConfirmDepth)
+	}
+
 	go func() {
-		rec, err := s.api.StateWaitMsg(context.TODO(), sm.Cid(), build.MessageConfidence, api.LookbackNoLimit, true)
+		rec, err := s.api.StateWaitMsg(context.TODO(), sm.Cid(), confidence, api.LookbackNoLimit, true)
 		if err != nil {
 			log.Error(err)
 			return
 		}
 
 		if rec.Receipt.ExitCode == 0 {
-			log.Infow("Window post submission successful", "cid", sm.Cid(), "deadline", proof.Deadline, "epoch", rec.Height, "ts", rec.TipSet.Cids())
+			log.Infow("Window post submission successful", "cid", sm.Cid(), "deadline", proof.Deadline, "epoch", rec.Height, "ts", rec.TipSet.Cids(), "confidence", confidence)
 			return
 		}
 
@@ -684,7 +718,10 @@ func (s *WindowPoStScheduler) submitPoStMessage(ctx context.Context, proof *mine
 //
 // * the sender (from the AddressSelector, falling back to the worker address if none set)
 // * the right gas parameters
-func (s *WindowPoStScheduler) prepareMessage(ctx context.Context, msg *types.Message, spec *api.MessageSendSpec) error {
+//
+// The estimated gas limit is multiplied by gasLimitMultiplier when it's greater than 1,
+// and then raised by the configured gas buffer.
+func (s *WindowPoStScheduler) prepareMessage(ctx context.Context, msg *types.Message, spec *api.MessageSendSpec, gasLimitMultiplier float64) error {
 	mi, err := s.api.StateMinerInfo(ctx, s.actor, types.EmptyTSK)
 	if err != nil {
 		return xerrors.Errorf("error getting miner info: %w", err)
@@ -701,6 +738,19 @@ func (s *WindowPoStScheduler) prepareMessage(ctx context.Context, msg *types.Mes
 	}
 	*msg = *gm
 
+	if gasLimitMultiplier > 1 {
+		estimated := msg.GasLimit
+		msg.GasLimit = int64(float64(msg.GasLimit) * gasLimitMultiplier)
+		if msg.GasLimit > build.BlockGasLimit {
+			msg.GasLimit = build.BlockGasLimit
+		}
+		log.Debugw("adjusted gas limit", "method", msg.Method, "estimated", estimated, "adjusted", msg.GasLimit)
+	}
+
+	if s.gasBuffer > 0 {
+		msg.GasLimit = applyGasBuffer(msg.GasLimit, s.gasBuffer)
+	}
+
 	// calculate a more frugal estimation; premium is estimated to guarantee
 	// inclusion within 5 tipsets, and fee cap is estimated for inclusion
 	// within 4 tipsets.
@@ -723,7 +773,12 @@ func (s *WindowPoStScheduler) prepareMessage(ctx context.Context, msg *types.Mes
 	goodFunds := big.Add(msg.RequiredFunds(), msg.Value)
 	minFunds := big.Min(big.Add(minGasFeeMsg.RequiredFunds(), minGasFeeMsg.Value), goodFunds)
 
-	pa, avail, err := s.addrSel.AddressFor(ctx, s.api, mi, api.PoStAddr, goodFunds, minFunds)
+	var rr uint64
+	if s.nonceStrategy == config.WindowPoStNonceRoundRobin {
+		rr = s.postAddrRR.Add(1) - 1
+	}
+
+	pa, avail, err := s.addrSel.RoundRobinAddressFor(ctx, s.api, mi, api.PoStAddr, goodFunds, minFunds, rr)
 	if err != nil {
 		log.Errorw("error selecting address for window post", "error", err)
 		return nil
@@ -741,6 +796,14 @@ func (s *WindowPoStScheduler) prepareMessage(ctx context.Context, msg *types.Mes
 	return nil
 }
 
+// applyGasBuffer adds buffer to the gas limit, capped at the block gas limit.
+func applyGasBuffer(gasLimit int64, buffer uint64) int64 {
+	if buffer >= uint64(build.BlockGasLimit) || gasLimit > build.BlockGasLimit-int64(buffer) {
+		return build.BlockGasLimit
+	}
+	return gasLimit + int64(buffer)
+}
+
 func (s *WindowPoStScheduler) ComputePoSt(ctx context.Context, dlIdx uint64, ts *types.TipSet) ([]miner.SubmitWindowedPoStParams, error) {
 	dl, err := s.api.StateMinerProvingDeadline(ctx, s.actor, ts.Key())
 	if err != nil {
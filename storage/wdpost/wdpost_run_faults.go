@@ -142,6 +142,11 @@ func (s *WindowPoStScheduler) declareRecoveries(ctx context.Context, dlIdx uint6
 		}
 	}
 
+	// respect the message limit if set, spreading the partitions over fewer, larger messages
+	if s.maxFaultRecoveryMessages > 0 && len(batchedRecoveryDecls) > s.maxFaultRecoveryMessages {
+		batchedRecoveryDecls = rebatchRecoveries(batchedRecoveryDecls, s.maxFaultRecoveryMessages)
+	}
+
 	if totalSectorsToRecover == 0 {
 		if faulty != 0 {
 			log.Warnw("No recoveries to declare", "deadline", dlIdx, "faulty", faulty)
@@ -151,8 +156,12 @@ func (s *WindowPoStScheduler) declareRecoveries(ctx context.Context, dlIdx uint6
 	}
 
 	log.Infof("attempting recovery declarations for %d sectors", totalSectorsToRecover)
+	var sent [][]miner.RecoveryDeclaration
 	var msgs []*types.SignedMessage
-	for _, recovery := range batchedRecoveryDecls {
+	for len(batchedRecoveryDecls) > 0 {
+		recovery := batchedRecoveryDecls[0]
+		batchedRecoveryDecls = batchedRecoveryDecls[1:]
+
 		params := &miner.DeclareFaultsRecoveredParams{
 			Recoveries: recovery,
 		}
@@ -169,30 +178,62 @@ func (s *WindowPoStScheduler) declareRecoveries(ctx context.Context, dlIdx uint6
 			Value:  types.NewInt(0),
 		}
 		spec := &api.MessageSendSpec{MaxFee: abi.TokenAmount(s.feeCfg.MaxWindowPoStGasFee), MaximizeFeeCap: s.feeCfg.MaximizeWindowPoStFeeCap}
-		if err := s.prepareMessage(ctx, msg, spec); err != nil {
+		if err := s.prepareMessage(ctx, msg, spec, s.faultDeclarationGasMultiplier); err != nil {
 			return nil, nil, err
 		}
+
+		// split declarations which don't fit in a block in half, until every part does
+		if msg.GasLimit >= build.BlockGasLimit && len(recovery) > 1 {
+			log.Warnw("recovery declaration doesn't fit in a block, splitting it", "deadline", dlIdx, "partitions", len(recovery), "gasLimit", msg.GasLimit)
+
+			half := len(recovery) / 2
+			batchedRecoveryDecls = append([][]miner.RecoveryDeclaration{recovery[:half], recovery[half:]}, batchedRecoveryDecls...)
+			continue
+		}
+
 		sm, err := s.api.MpoolPushMessage(ctx, msg, spec)
 		if err != nil {
 			return nil, nil, xerrors.Errorf("pushing message to mpool: %w", err)
 		}
 
 		log.Warnw("declare faults recovered Message CID", "cid", sm.Cid())
+		sent = append(sent, recovery)
 		msgs = append(msgs, sm)
 	}
 
 	for _, msg := range msgs {
 		rec, err := s.api.StateWaitMsg(context.TODO(), msg.Cid(), build.MessageConfidence, api.LookbackNoLimit, true)
 		if err != nil {
-			return batchedRecoveryDecls, msgs, xerrors.Errorf("declare faults recovered wait error: %w", err)
+			return sent, msgs, xerrors.Errorf("declare faults recovered wait error: %w", err)
 		}
 
 		if rec.Receipt.ExitCode != 0 {
-			return batchedRecoveryDecls, msgs, xerrors.Errorf("declare faults recovered wait non-0 exit code: %d", rec.Receipt.ExitCode)
+			return sent, msgs, xerrors.Errorf("declare faults recovered wait non-0 exit code: %d", rec.Receipt.ExitCode)
 		}
 	}
 
-	return batchedRecoveryDecls, msgs, nil
+	return sent, msgs, nil
+}
+
+// rebatchRecoveries spreads the declarations in batches evenly over at most maxMsgs batches.
+func rebatchRecoveries(batches [][]miner.RecoveryDeclaration, maxMsgs int) [][]miner.RecoveryDeclaration {
+	var decls []miner.RecoveryDeclaration
+	for _, batch := range batches {
+		decls = append(decls, batch...)
+	}
+
+	perMsg := (len(decls) + maxMsgs - 1) / maxMsgs
+
+	var out [][]miner.RecoveryDeclaration
+	for len(decls) > 0 {
+		n := perMsg
+		if n > len(decls) {
+			n = len(decls)
+		}
+		out = append(out, decls[:n])
+		decls = decls[n:]
+	}
+	return out
 }
 
 func (s *WindowPoStScheduler) asyncFaultRecover(di dline.Info, ts *types.TipSet) {
@@ -238,6 +279,7 @@ func (s *WindowPoStScheduler) asyncFaultRecover(di dline.Info, ts *types.TipSet)
 						evtCommon:    s.getEvtCommon(err),
 						Declarations: recovery,
 						MessageCID:   msgCID,
+						GasBuffer:    s.gasBuffer,
 					}
 					j.Error = err
 					return j
@@ -344,7 +386,7 @@ func (s *WindowPoStScheduler) manualRecoveryMsg(ctx context.Context, Recovery []
 		Value:  types.NewInt(0),
 	}
 	spec := &api.MessageSendSpec{MaxFee: abi.TokenAmount(s.feeCfg.MaxWindowPoStGasFee)}
-	if err := s.prepareMessage(ctx, msg, spec); err != nil {
+	if err := s.prepareMessage(ctx, msg, spec, s.faultDeclarationGasMultiplier); err != nil {
 		return cid.Undef, err
 	}
 	sm, err := s.api.MpoolPushMessage(ctx, msg, &api.MessageSendSpec{MaxFee: abi.TokenAmount(s.feeCfg.MaxWindowPoStGasFee)})
@@ -37,6 +37,8 @@ import (
 type mockStorageMinerAPI struct {
 	partitions     []api.Partition
 	pushedMessages chan *types.Message
+	// gas estimated for each partition in a DeclareFaultsRecovered message, if set
+	recoveryPartitionGas int64
 	NodeAPI
 }
 
@@ -531,6 +533,107 @@ func TestWDPostDeclareRecoveriesPartLimitConfig(t *testing.T) {
 	}
 }
 
+// TestWDPostDeclareRecoveriesMsgLimitConfig verifies that declareRecoveries spreads the partitions
+// over at most the number of DeclareFaultsRecovered messages allowed by user config
+func TestWDPostDeclareRecoveriesMsgLimitConfig(t *testing.T) {
+	// Let's have 11 faulty partitions, 3 partitions per message and at most 2 messages
+	msgs := testDeclareRecoveriesMsgLimit(t, 11, 3, 2, 0)
+
+	// The partitions are spread over 2 messages instead of 4
+	require.Equal(t, []int{6, 5}, msgs)
+}
+
+// TestWDPostDeclareRecoveriesGasSplit verifies that declareRecoveries splits DeclareFaultsRecovered
+// messages which don't fit in a block, without dropping any partition
+func TestWDPostDeclareRecoveriesGasSplit(t *testing.T) {
+	// Messages with 4 or more partitions don't fit in a block
+	msgs := testDeclareRecoveriesMsgLimit(t, 11, 3, 2, build.BlockGasLimit/4)
+
+	require.Equal(t, []int{3, 3, 2, 3}, msgs)
+}
+
+// testDeclareRecoveriesMsgLimit declares recoveries for faultyPartitionCount faulty partitions and returns
+// the number of partitions in each DeclareFaultsRecovered message sent
+func testDeclareRecoveriesMsgLimit(t *testing.T, faultyPartitionCount, userPartLimit, userMsgLimit int, partitionGas int64) []int {
+	ctx := context.Background()
+
+	proofType := abi.RegisteredPoStProof_StackedDrgWindow2KiBV1
+	postAct := tutils.NewIDAddr(t, 100)
+
+	mockStgMinerAPI := newMockStorageMinerAPI()
+	mockStgMinerAPI.recoveryPartitionGas = partitionGas
+
+	sectorsPerPartition, err := builtin.PoStProofWindowPoStPartitionSectors(proofType)
+	require.NoError(t, err)
+
+	var partitions []api.Partition
+	for p := 0; p < faultyPartitionCount; p++ {
+		sectors := bitfield.New()
+		for s := uint64(0); s < sectorsPerPartition; s++ {
+			sectors.Set(s)
+		}
+
+		partitions = append(partitions, api.Partition{
+			AllSectors:        sectors,
+			FaultySectors:     sectors,
+			RecoveringSectors: bitfield.New(),
+			LiveSectors:       sectors,
+			ActiveSectors:     sectors,
+		})
+	}
+
+	mockStgMinerAPI.setPartitions(partitions)
+
+	scheduler := &WindowPoStScheduler{
+		api:          mockStgMinerAPI,
+		prover:       &mockProver{},
+		verifier:     &mockVerif{},
+		faultTracker: &mockFaultTracker{},
+		proofType:    proofType,
+		actor:        postAct,
+		journal:      journal.NilJournal(),
+		addrSel:      &ctladdr.AddressSelector{},
+
+		maxPartitionsPerRecoveryMessage: userPartLimit,
+		maxFaultRecoveryMessages:        userMsgLimit,
+	}
+
+	di := uint64(0)
+	ts := mockTipSet(t)
+
+	type declareRes struct {
+		batches [][]minertypes.RecoveryDeclaration
+		msgs    []*types.SignedMessage
+		err     error
+	}
+	done := make(chan declareRes, 1)
+	go func() {
+		batchedRecoveries, msgs, err := scheduler.declareRecoveries(ctx, di, partitions, ts.Key())
+		done <- declareRes{batchedRecoveries, msgs, err}
+	}()
+
+	var sent []int
+	declared := 0
+	for declared < faultyPartitionCount {
+		msg := <-mockStgMinerAPI.pushedMessages
+		require.Equal(t, builtin.MethodsMiner.DeclareFaultsRecovered, msg.Method)
+		var params minertypes.DeclareFaultsRecoveredParams
+		err := params.UnmarshalCBOR(bytes.NewReader(msg.Params))
+		require.NoError(t, err)
+
+		sent = append(sent, len(params.Recoveries))
+		declared += len(params.Recoveries)
+	}
+
+	res := <-done
+	require.NoError(t, res.err, "failed to declare recoveries")
+	require.Equal(t, len(sent), len(res.msgs))
+	require.Equal(t, len(sent), len(res.batches))
+	require.Equal(t, faultyPartitionCount, declared)
+
+	return sent
+}
+
 func mockTipSet(t *testing.T) *types.TipSet {
 	minerAct := tutils.NewActorAddr(t, "miner")
 	c, err := cid.Decode("QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH")
@@ -589,6 +692,13 @@ func (m *mockStorageMinerAPI) GasEstimateMessageGas(ctx context.Context, message
 	msg.GasFeeCap = big.NewInt(1)
 	msg.GasPremium = big.NewInt(1)
 	msg.GasLimit = 2
+	if m.recoveryPartitionGas > 0 && msg.Method == builtin.MethodsMiner.DeclareFaultsRecovered {
+		var params minertypes.DeclareFaultsRecoveredParams
+		if err := params.UnmarshalCBOR(bytes.NewReader(msg.Params)); err != nil {
+			return nil, err
+		}
+		msg.GasLimit = int64(len(params.Recoveries)) * m.recoveryPartitionGas
+	}
 	return &msg, nil
 }
 
@@ -609,3 +719,9 @@ func (m *mockStorageMinerAPI) WalletHas(ctx context.Context, address address.Add
 }
 
 var _ NodeAPI = &mockStorageMinerAPI{}
+
+func TestApplyGasBuffer(t *testing.T) {
+	require.Equal(t, int64(1_500_000), applyGasBuffer(500_000, 1_000_000))
+	require.Equal(t, build.BlockGasLimit, applyGasBuffer(build.BlockGasLimit-10, 1_000_000))
+	require.Equal(t, build.BlockGasLimit, applyGasBuffer(1, uint64(build.BlockGasLimit)+1))
+}
@@ -2,6 +2,7 @@ package wdpost
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -74,7 +75,14 @@ type WindowPoStScheduler struct {
 	disablePreChecks                        bool
 	maxPartitionsPerPostMessage             int
 	maxPartitionsPerRecoveryMessage         int
+	maxFaultRecoveryMessages                int
 	singleRecoveringPartitionPerPostMessage bool
+	faultDeclarationGasMultiplier           float64
+	postMessageConfirmDepth                 int
+	gasBuffer                               uint64
+	nonceStrategy                           string
+	submitDelay                             abi.ChainEpoch
+	postAddrRR                              atomic.Uint64 // next PoSt control address, for the round-robin strategy
 	ch                                      *changeHandler
 
 	actor address.Address
@@ -101,6 +109,10 @@ func NewWindowedPoStScheduler(api NodeAPI,
 		return nil, xerrors.Errorf("getting sector size: %w", err)
 	}
 
+	if pcfg.FaultDeclarationGasMultiplier != 0 && (pcfg.FaultDeclarationGasMultiplier < 1.0 || pcfg.FaultDeclarationGasMultiplier > 3.0) {
+		return nil, xerrors.Errorf("FaultDeclarationGasMultiplier must be in the range [1.0, 3.0], got %f", pcfg.FaultDeclarationGasMultiplier)
+	}
+
 	return &WindowPoStScheduler{
 		api:                                     api,
 		feeCfg:                                  cfg,
@@ -113,7 +125,13 @@ func NewWindowedPoStScheduler(api NodeAPI,
 		disablePreChecks:                        pcfg.DisableWDPoStPreChecks,
 		maxPartitionsPerPostMessage:             pcfg.MaxPartitionsPerPoStMessage,
 		maxPartitionsPerRecoveryMessage:         pcfg.MaxPartitionsPerRecoveryMessage,
+		maxFaultRecoveryMessages:                pcfg.MaxFaultRecoveryMessages,
 		singleRecoveringPartitionPerPostMessage: pcfg.SingleRecoveringPartitionPerPostMessage,
+		faultDeclarationGasMultiplier:           pcfg.FaultDeclarationGasMultiplier,
+		postMessageConfirmDepth:                 pcfg.PoStMessageConfirmDepth,
+		gasBuffer:                               pcfg.WdPoStMessageGasBuffer,
+		nonceStrategy:                           pcfg.WindowPoStNonceStrategy,
+		submitDelay:                             abi.ChainEpoch(pcfg.WindowPoStDispatchDeadlineBuffer),
 		actor:                                   actor,
 		evtTypes: [...]journal.EventType{
 			evtTypeWdPoStScheduler:  j.RegisterEventType("wdpost", "scheduler"),
@@ -134,7 +152,7 @@ func (s *WindowPoStScheduler) Run(ctx context.Context) {
 		*WindowPoStScheduler
 	}{s.api, s}
 
-	s.ch = newChangeHandler(callbacks, s.actor)
+	s.ch = newChangeHandler(callbacks, s.actor, s.submitDelay)
 	defer s.ch.shutdown()
 	s.ch.start()
 